@@ -0,0 +1,203 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/implementations/addresses"
+	"pm/infrastructure/mapper"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+)
+
+const addressEntity string = "addresses"
+
+type AddressUsecase interface {
+	GetAddresses(*gin.Context, entity.Requester) ([]entity.Address, error)
+	GetAddressByID(*gin.Context, entity.Requester, int64) (*entity.Address, error)
+	CreateAddress(*gin.Context, entity.Requester, *payload.CreateAddressRequest) (*entity.Address, error)
+	UpdateAddress(c *gin.Context, requester entity.Requester, id int64, reqPayload *payload.UpdateAddressRequest) (*entity.Address, error)
+	DeleteAddress(*gin.Context, entity.Requester, int64) error
+	SetDefaultAddress(*gin.Context, entity.Requester, int64) (*entity.Address, error)
+}
+
+type addressUsecase struct {
+	p *base.Persistence
+}
+
+func NewAddressUsecase(p *base.Persistence) AddressUsecase {
+	return addressUsecase{p}
+}
+
+func (a addressUsecase) GetAddresses(c *gin.Context, requester entity.Requester) ([]entity.Address, error) {
+	span := a.p.Logger.Start(c, "GET_ADDRESSES: USECASES", a.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	listAddresses, err := addresses.NewAddressRepository(c, a.p, a.p.GormDB).GetAddressesByUserID(requester.UserID)
+	if err != nil {
+		a.p.Logger.Error("GET_ADDRESSES: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return listAddresses, nil
+}
+
+func (a addressUsecase) GetAddressByID(c *gin.Context, requester entity.Requester, id int64) (*entity.Address, error) {
+	span := a.p.Logger.Start(c, "GET_ADDRESS_BY_ID: USECASES", a.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	address, err := ownedAddress(c, a.p, a.p.GormDB, requester, id)
+	if err != nil {
+		a.p.Logger.Error("GET_ADDRESS_BY_ID: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return address, nil
+}
+
+// CreateAddress adds the address to the address book of the requester, the first address of a user always becomes
+// the default one
+func (a addressUsecase) CreateAddress(c *gin.Context, requester entity.Requester, reqPayload *payload.CreateAddressRequest) (*entity.Address, error) {
+	span := a.p.Logger.Start(c, "CREATE_ADDRESS: USECASES", a.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	a.p.Logger.Info("STARTING: CREATE_ADDRESS", map[string]interface{}{"data": reqPayload, "user_id": requester.UserID})
+
+	if err := utils.ValidateReqPayload(reqPayload); err != nil {
+		a.p.Logger.Error("CREATE_ADDRESS: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInvalidRequest(err)
+	}
+
+	address := mapper.CreateAddressPayloadToAddress(reqPayload, uint(requester.UserID))
+	err := a.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		addressRepo := addresses.NewAddressRepository(c, a.p, tx)
+		if err := addressRepo.Create(address); err != nil {
+			return err
+		}
+
+		setDefault := reqPayload.IsDefault
+		if !setDefault {
+			_, err := addressRepo.GetDefaultAddress(requester.UserID)
+			if err != nil && !isNotFound(err) {
+				return err
+			}
+			setDefault = err != nil
+		}
+		if setDefault {
+			return addressRepo.SetDefault(address)
+		}
+		return nil
+	})
+	if err != nil {
+		a.p.Logger.Error("CREATE_ADDRESS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	a.p.Logger.Info("CREATE_ADDRESS: SUCCESSFULLY", map[string]interface{}{"address": address})
+	return address, nil
+}
+
+func (a addressUsecase) UpdateAddress(c *gin.Context, requester entity.Requester, id int64, reqPayload *payload.UpdateAddressRequest) (*entity.Address, error) {
+	span := a.p.Logger.Start(c, "UPDATE_ADDRESS: USECASES", a.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	a.p.Logger.Info("STARTING: UPDATE_ADDRESS", map[string]interface{}{"id": id, "data": reqPayload})
+
+	if err := utils.ValidateReqPayload(reqPayload); err != nil {
+		a.p.Logger.Error("UPDATE_ADDRESS: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInvalidRequest(err)
+	}
+
+	address, err := ownedAddress(c, a.p, a.p.GormDB, requester, id)
+	if err != nil {
+		a.p.Logger.Error("UPDATE_ADDRESS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	mapper.UpdateAddressPayloadToAddress(reqPayload, address)
+	if err := addresses.NewAddressRepository(c, a.p, a.p.GormDB).Update(address); err != nil {
+		a.p.Logger.Error("UPDATE_ADDRESS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	a.p.Logger.Info("UPDATE_ADDRESS: SUCCESSFULLY", map[string]interface{}{"address": address})
+	return address, nil
+}
+
+// DeleteAddress removes the address from the address book, when it was the default address the oldest address
+// left takes its place. Orders keep their own copy of the address
+func (a addressUsecase) DeleteAddress(c *gin.Context, requester entity.Requester, id int64) error {
+	span := a.p.Logger.Start(c, "DELETE_ADDRESS: USECASES", a.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	a.p.Logger.Info("STARTING: DELETE_ADDRESS", map[string]interface{}{"id": id, "user_id": requester.UserID})
+
+	err := a.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		address, err := ownedAddress(c, a.p, tx, requester, id)
+		if err != nil {
+			return err
+		}
+		addressRepo := addresses.NewAddressRepository(c, a.p, tx)
+		if err := addressRepo.DeleteAddress(address); err != nil {
+			return err
+		}
+		if !address.IsDefault {
+			return nil
+		}
+
+		remaining, err := addressRepo.GetAddressesByUserID(requester.UserID)
+		if err != nil || len(remaining) == 0 {
+			return err
+		}
+		return addressRepo.SetDefault(&remaining[0])
+	})
+	if err != nil {
+		a.p.Logger.Error("DELETE_ADDRESS: ERROR", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+
+	a.p.Logger.Info("DELETE_ADDRESS: SUCCESSFULLY", map[string]interface{}{"deleted_id": id})
+	return nil
+}
+
+func (a addressUsecase) SetDefaultAddress(c *gin.Context, requester entity.Requester, id int64) (*entity.Address, error) {
+	span := a.p.Logger.Start(c, "SET_DEFAULT_ADDRESS: USECASES", a.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	a.p.Logger.Info("STARTING: SET_DEFAULT_ADDRESS", map[string]interface{}{"id": id, "user_id": requester.UserID})
+
+	address, err := ownedAddress(c, a.p, a.p.GormDB, requester, id)
+	if err != nil {
+		a.p.Logger.Error("SET_DEFAULT_ADDRESS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	if err := addresses.NewAddressRepository(c, a.p, a.p.GormDB).SetDefault(address); err != nil {
+		a.p.Logger.Error("SET_DEFAULT_ADDRESS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return address, nil
+}
+
+// ownedAddress loads an address of the requester, the addresses of other users are reported as not found
+func ownedAddress(c *gin.Context, p *base.Persistence, db *gorm.DB, requester entity.Requester, id int64) (*entity.Address, error) {
+	address, err := addresses.NewAddressRepository(c, p, db).GetAddressByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if int64(address.UserID) != requester.UserID {
+		return nil, payload.ErrEntityNotFound(addressEntity, fmt.Errorf("address with id [%d] not found", id))
+	}
+	return address, nil
+}
+
+// shippingAddressOf picks the address an order of the requester is shipped to, the given address of the address
+// book or the default one when addressID is 0
+func shippingAddressOf(c *gin.Context, p *base.Persistence, db *gorm.DB, requester entity.Requester, addressID uint) (*entity.Address, error) {
+	if addressID != 0 {
+		return ownedAddress(c, p, db, requester, int64(addressID))
+	}
+	address, err := addresses.NewAddressRepository(c, p, db).GetDefaultAddress(requester.UserID)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, payload.ErrInvalidRequest(errors.New("a shipping address is required, add one to the address book or give its addressId"))
+		}
+		return nil, err
+	}
+	return address, nil
+}
\ No newline at end of file
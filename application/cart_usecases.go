@@ -0,0 +1,249 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/implementations/carts"
+	"pm/infrastructure/implementations/products"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+)
+
+type CartUsecase interface {
+	GetCart(*gin.Context, entity.Requester) (*entity.Cart, error)
+	AddCartItem(*gin.Context, entity.Requester, *payload.AddCartItemRequest) (*entity.Cart, error)
+	UpdateCartItem(c *gin.Context, requester entity.Requester, productID int64, variantID *uint, reqPayload *payload.UpdateCartItemRequest) (*entity.Cart, error)
+	RemoveCartItem(c *gin.Context, requester entity.Requester, productID int64, variantID *uint) (*entity.Cart, error)
+	ClearCart(*gin.Context, entity.Requester) error
+	Checkout(*gin.Context, entity.Requester, *payload.CheckoutRequest) (*entity.Order, error)
+}
+
+type cartUsecase struct {
+	p *base.Persistence
+}
+
+func NewCartUsecase(p *base.Persistence) CartUsecase {
+	return cartUsecase{p}
+}
+
+// GetCart returns the cart of the requester with the current price and stock of every product in it
+func (cu cartUsecase) GetCart(c *gin.Context, requester entity.Requester) (*entity.Cart, error) {
+	span := cu.p.Logger.Start(c, "GET_CART: USECASES", cu.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	cart, err := cu.loadCart(c, requester)
+	if err != nil {
+		cu.p.Logger.Error("GET_CART: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return cart, nil
+}
+
+// AddCartItem puts the product, or one of its variants, in the cart, or adds the quantity to the line the cart
+// already has for it
+func (cu cartUsecase) AddCartItem(c *gin.Context, requester entity.Requester, reqPayload *payload.AddCartItemRequest) (*entity.Cart, error) {
+	span := cu.p.Logger.Start(c, "ADD_CART_ITEM: USECASES", cu.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	cu.p.Logger.Info("STARTING: ADD_CART_ITEM", map[string]interface{}{"data": reqPayload, "user_id": requester.UserID})
+
+	if err := utils.ValidateReqPayload(reqPayload); err != nil {
+		cu.p.Logger.Error("ADD_CART_ITEM: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInvalidRequest(err)
+	}
+
+	cartRepo := carts.NewCartRepository(c, cu.p, cu.p.GormDB)
+	items, err := cartRepo.GetCartItems(requester.UserID)
+	if err != nil {
+		cu.p.Logger.Error("ADD_CART_ITEM: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	quantity := reqPayload.Quantity
+	for _, item := range items {
+		if item.IsLineOf(reqPayload.ProductID, reqPayload.VariantID) {
+			quantity += item.Quantity
+		}
+	}
+
+	if err := cu.setCartItem(c, requester, int64(reqPayload.ProductID), reqPayload.VariantID, quantity); err != nil {
+		cu.p.Logger.Error("ADD_CART_ITEM: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return cu.GetCart(c, requester)
+}
+
+// UpdateCartItem replaces the quantity of a line of the cart
+func (cu cartUsecase) UpdateCartItem(c *gin.Context, requester entity.Requester, productID int64, variantID *uint, reqPayload *payload.UpdateCartItemRequest) (*entity.Cart, error) {
+	span := cu.p.Logger.Start(c, "UPDATE_CART_ITEM: USECASES", cu.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	cu.p.Logger.Info("STARTING: UPDATE_CART_ITEM", map[string]interface{}{"product_id": productID, "variant_id": variantID, "data": reqPayload, "user_id": requester.UserID})
+
+	if err := utils.ValidateReqPayload(reqPayload); err != nil {
+		cu.p.Logger.Error("UPDATE_CART_ITEM: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInvalidRequest(err)
+	}
+
+	cartRepo := carts.NewCartRepository(c, cu.p, cu.p.GormDB)
+	items, err := cartRepo.GetCartItems(requester.UserID)
+	if err != nil {
+		cu.p.Logger.Error("UPDATE_CART_ITEM: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	if !hasCartItem(items, productID, variantID) {
+		err := payload.ErrEntityNotFound("cart items", fmt.Errorf("%s is not in the cart", cartLineName(productID, variantID)))
+		cu.p.Logger.Error("UPDATE_CART_ITEM: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	if err := cu.setCartItem(c, requester, productID, variantID, reqPayload.Quantity); err != nil {
+		cu.p.Logger.Error("UPDATE_CART_ITEM: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return cu.GetCart(c, requester)
+}
+
+func (cu cartUsecase) RemoveCartItem(c *gin.Context, requester entity.Requester, productID int64, variantID *uint) (*entity.Cart, error) {
+	span := cu.p.Logger.Start(c, "REMOVE_CART_ITEM: USECASES", cu.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	cu.p.Logger.Info("STARTING: REMOVE_CART_ITEM", map[string]interface{}{"product_id": productID, "variant_id": variantID, "user_id": requester.UserID})
+
+	cartRepo := carts.NewCartRepository(c, cu.p, cu.p.GormDB)
+	if err := cartRepo.RemoveCartItem(requester.UserID, productID, variantID); err != nil {
+		cu.p.Logger.Error("REMOVE_CART_ITEM: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return cu.GetCart(c, requester)
+}
+
+func (cu cartUsecase) ClearCart(c *gin.Context, requester entity.Requester) error {
+	span := cu.p.Logger.Start(c, "CLEAR_CART: USECASES", cu.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	cu.p.Logger.Info("STARTING: CLEAR_CART", map[string]interface{}{"user_id": requester.UserID})
+
+	if err := carts.NewCartRepository(c, cu.p, cu.p.GormDB).ClearCart(requester.UserID); err != nil {
+		cu.p.Logger.Error("CLEAR_CART: ERROR", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+	return nil
+}
+
+// Checkout places an order with the lines of the cart through CreateOrder, so the order is priced and its stock is
+// taken exactly like any other order. The cart is emptied once the order exists
+func (cu cartUsecase) Checkout(c *gin.Context, requester entity.Requester, reqPayload *payload.CheckoutRequest) (*entity.Order, error) {
+	span := cu.p.Logger.Start(c, "CHECKOUT: USECASES", cu.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	cu.p.Logger.Info("STARTING: CHECKOUT", map[string]interface{}{"user_id": requester.UserID})
+
+	cartRepo := carts.NewCartRepository(c, cu.p, cu.p.GormDB)
+	items, err := cartRepo.GetCartItems(requester.UserID)
+	if err != nil {
+		cu.p.Logger.Error("CHECKOUT: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	if len(items) == 0 {
+		err := payload.ErrInvalidRequest(errors.New("the cart is empty"))
+		cu.p.Logger.Error("CHECKOUT: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	orderRequest := payload.CreateOrderRequest{
+		OrderItems:    make([]payload.OrderItemRequest, 0),
+		AddressID:     reqPayload.AddressID,
+		PromotionCode: reqPayload.PromotionCode,
+	}
+	for _, item := range items {
+		orderRequest.OrderItems = append(orderRequest.OrderItems, payload.OrderItemRequest{ProductID: item.ProductID, VariantID: item.VariantID, Quantity: item.Quantity})
+	}
+	order, err := NewOrderUsecase(cu.p).CreateOrder(c, requester, &orderRequest)
+	if err != nil {
+		cu.p.Logger.Error("CHECKOUT: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	// the order is placed already, a cart left behind must not fail the checkout
+	if err := cartRepo.ClearCart(requester.UserID); err != nil {
+		cu.p.Logger.Error("CHECKOUT: ERROR CLEAR CART", map[string]interface{}{"error": err.Error()})
+	}
+
+	cu.p.Logger.Info("CHECKOUT: SUCCESSFULLY", map[string]interface{}{"order": order})
+	return order, nil
+}
+
+// setCartItem saves the quantity of the product, or of its variant, in the cart of the requester. The product must
+// exist, be added by one of its variants when it has some, and have enough stock for the whole quantity
+func (cu cartUsecase) setCartItem(c *gin.Context, requester entity.Requester, productID int64, variantID *uint, quantity int) error {
+	span := cu.p.Logger.Start(c, "SET_CART_ITEM", cu.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	product, err := products.NewProductRepository(c, cu.p, cu.p.GormDB).GetProductByID(span, productID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case variantID != nil && product.Variant(*variantID) == nil:
+		return payload.ErrEntityNotFound("product variants", fmt.Errorf("%s does not exist", cartLineName(productID, variantID)))
+	case variantID == nil && product.HasVariants():
+		return payload.ErrInvalidRequest(fmt.Errorf("the product [%d] must be added to the cart by one of its variants", productID))
+	}
+	if stock := product.StockOf(variantID); stock < int64(quantity) {
+		return payload.ErrInvalidRequest(fmt.Errorf("only %d of %s left in stock", stock, cartLineName(productID, variantID)))
+	}
+
+	return carts.NewCartRepository(c, cu.p, cu.p.GormDB).SetCartItem(&entity.CartItem{
+		UserID:    uint(requester.UserID),
+		ProductID: uint(productID),
+		VariantID: variantID,
+		Quantity:  quantity,
+	})
+}
+
+// loadCart prices the lines of the cart with their products as they are now, a product or variant deleted since it
+// was added stays in the cart as unavailable so the user can see what happened to it
+func (cu cartUsecase) loadCart(c *gin.Context, requester entity.Requester) (*entity.Cart, error) {
+	span := cu.p.Logger.Start(c, "LOAD_CART", cu.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	items, err := carts.NewCartRepository(c, cu.p, cu.p.GormDB).GetCartItems(requester.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	productRepo := products.NewProductRepository(c, cu.p, cu.p.GormDB)
+	cart := &entity.Cart{UserID: uint(requester.UserID), Lines: make([]entity.CartLine, 0)}
+	for _, item := range items {
+		line := entity.CartLine{ProductID: item.ProductID, VariantID: item.VariantID, Quantity: item.Quantity}
+		product, err := productRepo.GetProductByID(span, int64(item.ProductID))
+		switch {
+		case err == nil:
+			line.Product = product
+		case !isNotFound(err):
+			return nil, err
+		}
+		cart.Lines = append(cart.Lines, line)
+	}
+	return cart, nil
+}
+
+func hasCartItem(items []entity.CartItem, productID int64, variantID *uint) bool {
+	for _, item := range items {
+		if item.IsLineOf(uint(productID), variantID) {
+			return true
+		}
+	}
+	return false
+}
+
+func cartLineName(productID int64, variantID *uint) string {
+	if variantID == nil {
+		return fmt.Sprintf("product [%d]", productID)
+	}
+	return fmt.Sprintf("variant [%d] of product [%d]", *variantID, productID)
+}
+
+func isNotFound(err error) bool {
+	var appErr *payload.AppError
+	return errors.As(err, &appErr) && appErr.StatusCode == http.StatusNotFound
+}
\ No newline at end of file
@@ -5,6 +5,7 @@ import (
 	"pm/domain/entity"
 	"pm/infrastructure/controllers/payload"
 	"pm/infrastructure/implementations/categories"
+	"pm/infrastructure/implementations/products"
 	"pm/infrastructure/mapper"
 	"pm/infrastructure/persistences/base"
 	"pm/utils"
@@ -31,18 +32,32 @@ func (categoryUsecase categoryUsecase) UpdateCategoryByID(c *gin.Context, id int
 	defer span.End()
 	categoryUsecase.p.Logger.Info("UPDATE_CATEGORY", map[string]interface{}{"data": updatePayload})
 
+	if err := checkTaxClass(c, categoryUsecase.p, updatePayload.TaxClassID); err != nil {
+		categoryUsecase.p.Logger.Error("UPDATE_CATEGORY: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
 	categoryRepo := categories.NewCategoryRepository(c, categoryUsecase.p, categoryUsecase.p.GormDB)
 	cate, err := categoryRepo.GetCategoryByID(span, id)
 	if err != nil {
 		categoryUsecase.p.Logger.Error("UPDATE_CATEGORY: ERROR", map[string]interface{}{"error": err.Error()})
 		return nil, err
 	}
+	renamed := updatePayload.Name != "" && cate.Name != updatePayload.Name
 	mapper.UpdateCategory(cate, &updatePayload)
 	cate, err = categoryRepo.Update(span, cate)
 	if err != nil {
 		categoryUsecase.p.Logger.Error("UPDATE_CATEGORY: ERROR", map[string]interface{}{"error": err.Error()})
 		return nil, err
 	}
+	// the products of the category are searched by its name too
+	if renamed {
+		go indexSuggestion(categoryUsecase.p, entity.CategorySuggestion(cate))
+		if err := products.NewProductRepository(c, categoryUsecase.p, categoryUsecase.p.GormDB).ReindexCategory(span, cate); err != nil {
+			categoryUsecase.p.Logger.Error("UPDATE_CATEGORY: ERROR", map[string]interface{}{"error": err.Error()})
+			return nil, err
+		}
+	}
 
 	categoryUsecase.p.Logger.Info("UPDATE_CATEGORY_SUCCESSFULLY", map[string]interface{}{"category_response": cate})
 	return cate, nil
@@ -79,6 +94,7 @@ func (categoryUsecase categoryUsecase) DeleteCategoryByID(c *gin.Context, id int
 		categoryUsecase.p.Logger.Error("DELETE_CATEGORY_FAILED", map[string]interface{}{"message": err.Error()})
 		return err
 	}
+	go removeSuggestion(categoryUsecase.p, entity.SuggestionKindCategory, cate.ID)
 
 	categoryUsecase.p.Logger.Info("CREATE_CATEGORY_SUCCESSFULLY", map[string]interface{}{"data": cate.ID})
 	return nil
@@ -93,6 +109,10 @@ func (categoryUsecase categoryUsecase) CreateCategory(c *gin.Context, reqPayload
 		categoryUsecase.p.Logger.Error("CREATE_CATEGORY_FAILED", map[string]interface{}{"message": err.Error()})
 		return payload.ErrValidateFailed(err)
 	}
+	if err := checkTaxClass(c, categoryUsecase.p, reqPayload.TaxClassID); err != nil {
+		categoryUsecase.p.Logger.Error("CREATE_CATEGORY_FAILED", map[string]interface{}{"message": err.Error()})
+		return err
+	}
 
 	categoryEntity := mapper.CreateCatePayloadToCategory(reqPayload)
 	cateRepo := categories.NewCategoryRepository(c, categoryUsecase.p, categoryUsecase.p.GormDB)
@@ -101,6 +121,7 @@ func (categoryUsecase categoryUsecase) CreateCategory(c *gin.Context, reqPayload
 		categoryUsecase.p.Logger.Error("CREATE_CATEGORY_FAILED", map[string]interface{}{"message": err.Error()})
 		return err
 	}
+	go indexSuggestion(categoryUsecase.p, entity.CategorySuggestion(categoryEntity))
 
 	categoryUsecase.p.Logger.Info("CREATE_CATEGORY_SUCCESSFULLY", map[string]interface{}{"data": categoryEntity.ID})
 	return nil
@@ -0,0 +1,187 @@
+package application
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"os"
+	"path/filepath"
+	"pm/domain/entity"
+	"pm/infrastructure/config"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/implementations/invoices"
+	"pm/infrastructure/implementations/mailer"
+	"pm/infrastructure/implementations/orders"
+	"pm/infrastructure/implementations/products"
+	"pm/infrastructure/implementations/users"
+	"pm/infrastructure/persistences/base"
+	"time"
+)
+
+type InvoiceUsecase interface {
+	GetInvoiceDocument(c *gin.Context, requester entity.Requester, orderID int64) (*entity.Invoice, []byte, error)
+	EmailInvoice(c *gin.Context, requester entity.Requester, orderID int64) (*entity.Invoice, error)
+}
+
+type invoiceUsecase struct {
+	p   *base.Persistence
+	cfg config.InvoiceConfig
+}
+
+func NewInvoiceUsecase(p *base.Persistence) InvoiceUsecase {
+	return invoiceUsecase{p, invoiceConfig()}
+}
+
+// GetInvoiceDocument renders the invoice of the order, only its owner and admins can get it
+func (iu invoiceUsecase) GetInvoiceDocument(c *gin.Context, requester entity.Requester, orderID int64) (*entity.Invoice, []byte, error) {
+	span := iu.p.Logger.Start(c, "GET_INVOICE_DOCUMENT: USECASES", iu.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	invoice, err := iu.getInvoice(c, requester, orderID)
+	if err != nil {
+		iu.p.Logger.Error("GET_INVOICE_DOCUMENT: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, nil, err
+	}
+	content, err := invoices.RenderInvoice(invoice)
+	if err != nil {
+		iu.p.Logger.Error("GET_INVOICE_DOCUMENT: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, nil, payload.ErrInternal(err)
+	}
+	return invoice, content, nil
+}
+
+// EmailInvoice sends the invoice of the order to its owner again
+func (iu invoiceUsecase) EmailInvoice(c *gin.Context, requester entity.Requester, orderID int64) (*entity.Invoice, error) {
+	span := iu.p.Logger.Start(c, "EMAIL_INVOICE: USECASES", iu.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	iu.p.Logger.Info("STARTING: EMAIL_INVOICE", map[string]interface{}{"order_id": orderID})
+
+	invoice, err := iu.getInvoice(c, requester, orderID)
+	if err != nil {
+		iu.p.Logger.Error("EMAIL_INVOICE: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	if err := deliverInvoice(c, iu.p, iu.cfg, invoice); err != nil {
+		iu.p.Logger.Error("EMAIL_INVOICE: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInternal(err)
+	}
+
+	iu.p.Logger.Info("EMAIL_INVOICE: SUCCESSFULLY", map[string]interface{}{"number": invoice.Number})
+	return invoice, nil
+}
+
+func (iu invoiceUsecase) getInvoice(c *gin.Context, requester entity.Requester, orderID int64) (*entity.Invoice, error) {
+	order, err := orders.NewOrderRepository(c, iu.p, iu.p.GormDB).GetOrderByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeOrder(requester, order); err != nil {
+		return nil, err
+	}
+	return invoices.NewInvoiceRepository(c, iu.p, iu.p.GormDB).GetInvoiceByOrderID(orderID)
+}
+
+func invoiceConfig() config.InvoiceConfig {
+	cfg := config.InvoiceConfig{Prefix: "INV", Storage: invoices.StorageLocal, Dir: "invoices"}
+	if config.Configs != nil {
+		cfg = config.Configs.InvoiceConfig
+	}
+	return cfg
+}
+
+// issueInvoice issues the invoice of a paid order. It has to run in the transaction that marks the order paid,
+// which also holds the number of the invoice so the numbering has no gap. An order is only invoiced once, nil is
+// returned when it already has an invoice
+func issueInvoice(c *gin.Context, p *base.Persistence, db *gorm.DB, cfg config.InvoiceConfig, order *entity.Order, currency string) (*entity.Invoice, error) {
+	span := p.Logger.Start(c, "ISSUE_INVOICE: USECASES", p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	invoiceRepo := invoices.NewInvoiceRepository(c, p, db)
+	if _, err := invoiceRepo.GetInvoiceByOrderID(int64(order.ID)); err == nil {
+		return nil, nil
+	} else if !isNotFound(err) {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(order.OrderItems))
+	for _, v := range order.OrderItems {
+		ids = append(ids, v.ProductID)
+	}
+	prods, err := products.NewProductRepository(c, p, db).GetProductsByIDs(span, ids...)
+	if err != nil {
+		return nil, err
+	}
+	productsByID := make(map[uint]entity.Product)
+	for _, v := range prods {
+		productsByID[v.ID] = v
+	}
+
+	now := time.Now()
+	invoice := entity.NewInvoice(order, productsByID, now)
+	invoice.Currency = currency
+	invoice.SellerName = cfg.SellerName
+	invoice.SellerAddress = cfg.SellerAddress
+	invoice.SellerTaxCode = cfg.SellerTaxCode
+
+	series := entity.InvoiceSeries(cfg.Prefix, now)
+	number, err := invoiceRepo.NextNumber(series)
+	if err != nil {
+		return nil, err
+	}
+	invoice.Number = entity.FormatInvoiceNumber(series, number)
+	if err := invoiceRepo.Create(invoice); err != nil {
+		return nil, err
+	}
+
+	p.Logger.Info("ISSUE_INVOICE: SUCCESSFULLY", map[string]interface{}{"order_id": order.ID, "number": invoice.Number})
+	return invoice, nil
+}
+
+// deliverInvoice stores the document of the invoice and emails it to the owner of the order. It runs once the
+// invoice is committed, the invoice stays valid when the delivery fails and can be sent again later
+func deliverInvoice(c *gin.Context, p *base.Persistence, cfg config.InvoiceConfig, invoice *entity.Invoice) error {
+	span := p.Logger.Start(c, "DELIVER_INVOICE: USECASES", p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	content, err := invoices.RenderInvoice(invoice)
+	if err != nil {
+		return err
+	}
+	invoiceRepo := invoices.NewInvoiceRepository(c, p, p.GormDB)
+	if invoice.FileURL == "" {
+		storage, err := invoices.NewInvoiceStorage(cfg)
+		if err != nil {
+			return err
+		}
+		if invoice.FileURL, err = storage.Store(invoice.FileName(), content); err != nil {
+			return err
+		}
+		if err := invoiceRepo.UpdateDelivery(invoice); err != nil {
+			return err
+		}
+	}
+
+	user, err := users.NewUserRepository(c, p, p.GormDB).GetUserByID(span, int64(invoice.UserID))
+	if err != nil {
+		return err
+	}
+	// the mailer attaches files from disk, the document is written to a temporary directory under its own name
+	dir, err := os.MkdirTemp("", "invoice")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, invoice.FileName())
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return err
+	}
+	body := fmt.Sprintf("Thank you for your order #%d, the invoice %s is attached.", invoice.OrderID, invoice.Number)
+	err = mailer.NewMailerRepository(p).SendEmailWithAttachment("Invoice "+invoice.Number, body, "text/plain", []string{user.Email}, path)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	invoice.EmailedAt = &now
+	return invoiceRepo.UpdateDelivery(invoice)
+}
\ No newline at end of file
@@ -0,0 +1,50 @@
+package application
+
+import (
+	"github.com/gin-gonic/gin"
+	"pm/domain/entity"
+	"pm/infrastructure/implementations/notifications"
+	"pm/infrastructure/persistences/base"
+)
+
+type NotificationUsecase interface {
+	GetAllNotifications(c *gin.Context, filter *entity.NotificationFilter, pagination *entity.Pagination) ([]entity.Notification, error)
+	MarkNotificationRead(c *gin.Context, id int64) (*entity.Notification, error)
+}
+
+type notificationUsecase struct {
+	p *base.Persistence
+}
+
+func NewNotificationUsecase(p *base.Persistence) NotificationUsecase {
+	return notificationUsecase{p}
+}
+
+func (n notificationUsecase) GetAllNotifications(c *gin.Context, filter *entity.NotificationFilter, pagination *entity.Pagination) ([]entity.Notification, error) {
+	span := n.p.Logger.Start(c, "GET_ALL_NOTIFICATIONS: USECASES", n.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	listNotifications, err := notifications.NewNotificationRepository(c, n.p, n.p.GormDB).GetAllNotifications(filter, pagination)
+	if err != nil {
+		n.p.Logger.Error("GET_ALL_NOTIFICATIONS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return listNotifications, nil
+}
+
+func (n notificationUsecase) MarkNotificationRead(c *gin.Context, id int64) (*entity.Notification, error) {
+	span := n.p.Logger.Start(c, "MARK_NOTIFICATION_READ: USECASES", n.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	notificationRepo := notifications.NewNotificationRepository(c, n.p, n.p.GormDB)
+	notification, err := notificationRepo.GetNotificationByID(id)
+	if err != nil {
+		n.p.Logger.Error("MARK_NOTIFICATION_READ: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	if err := notificationRepo.MarkRead(notification); err != nil {
+		n.p.Logger.Error("MARK_NOTIFICATION_READ: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return notification, nil
+}
\ No newline at end of file
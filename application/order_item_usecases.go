@@ -3,31 +3,31 @@ package application
 import (
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"go.uber.org/zap"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
 	"pm/domain/entity"
 	"pm/infrastructure/controllers/payload"
 	orderItems "pm/infrastructure/implementations/order_items"
+	"pm/infrastructure/implementations/orders"
 	"pm/infrastructure/implementations/products"
-	"pm/infrastructure/jobs"
 	"pm/infrastructure/mapper"
 	"pm/infrastructure/persistences/base"
 	"pm/utils"
-	"strconv"
 )
 
 type OrderItemUsecase interface {
-	CreateNewOrderItem(*gin.Context, []entity.OrderItem) error
-	UpdateOrderItem(*gin.Context, []entity.OrderItem) ([]payload.OrderItemResponse, error)
-	GetOrderItemByID(*gin.Context, int64) (*payload.OrderItemResponse, error)
-	DeleteOrderItemByID(*gin.Context, int64) error
-	GetAllOrderItems(*gin.Context) (*payload.ListOrderItemResponses, error)
+	CreateNewOrderItem(*gin.Context, entity.Requester, []entity.OrderItem) error
+	UpdateOrderItem(*gin.Context, entity.Requester, []entity.OrderItem) ([]payload.OrderItemResponse, error)
+	GetOrderItemByID(*gin.Context, entity.Requester, int64) (*payload.OrderItemResponse, error)
+	DeleteOrderItemByID(*gin.Context, entity.Requester, int64) error
+	GetAllOrderItems(*gin.Context, *entity.Pagination) (*payload.ListOrderItemResponses, error)
 }
 
 type orderItemUsecase struct {
 	p *base.Persistence
 }
 
-func (o orderItemUsecase) CreateNewOrderItem(c *gin.Context, items []entity.OrderItem) error {
+func (o orderItemUsecase) CreateNewOrderItem(c *gin.Context, requester entity.Requester, items []entity.OrderItem) error {
 	span := o.p.Logger.Start(c, "CREATE_ORDER_ITEM_USECASE", o.p.Logger.SetContextWithSpanFunc())
 	defer span.End()
 
@@ -39,85 +39,53 @@ func (o orderItemUsecase) CreateNewOrderItem(c *gin.Context, items []entity.Orde
 	}
 
 	prods := make([]entity.Product, 0)
-
-	// check product stock on redis
-	for i, e := range items {
-		var p entity.Product
-		utils.RedisGetHashGenericKey(redisHashKey, strconv.Itoa(int(e.ProductID)), &p)
-		var isAvailable bool = false
-		if p.ID == 0 {
-			continue
-		}
-		isAvailable = (p.Stock - int64(e.Quantity)) >= 0
-		if !isAvailable {
-			err := fmt.Errorf("product: %v is not available, please check again", e.ProductID)
-			o.p.Logger.Error("CREATE_ORDER: ERROR PRODUCT IS NOT AVAILABLE", map[string]interface{}{"error": err.Error()})
-			prods = make([]entity.Product, 0)
-			break
+	err := o.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		before := make(map[uint]entity.Snapshot)
+		for _, item := range items {
+			order, err := o.editableOrderOfItem(c, tx, requester, item.OrderID)
+			if err != nil {
+				return err
+			}
+			before[order.ID] = order.Snapshot()
 		}
-		p.Stock = p.Stock - int64(e.Quantity)
-		prods[i] = p
-	}
 
-	if len(prods) == 0 {
-		var err error = nil
-		productRepo := products.NewProductRepository(c, o.p, o.p.GormDB)
-		prods, err = productRepo.IsAvailableStockByOrderItems(span, items...)
+		var err error
+		productRepo := products.NewProductRepository(c, o.p, tx)
+		prods, err = productRepo.DecreaseStock(span, items...)
 		if err != nil {
-			o.p.Logger.Error("CREATE_ORDER: ERROR PRODUCT IS NOT AVAILABLE", map[string]interface{}{"error": err.Error()})
 			return err
 		}
-	}
-
-	oiRepo := orderItems.NewOrderItemRepository(o.p.GormDB, c, o.p, span)
-	if err := oiRepo.CreateNewOrderItems(items); err != nil {
-		o.p.Logger.Error("CREATE_ORDER_ITEMS: ERROR", map[string]interface{}{"error": err.Error(), "order_items": items})
-		return err
-	}
-
-	// this goroutine is for updating product on redis
-	go func() {
-		logger, err := zap.NewProduction()
-		if err != nil {
-			fmt.Println("error trying to initialize logger")
+		for i := range items {
+			items[i].Price = prods[i].UnitPrice(items[i].VariantID)
 		}
-		defer logger.Sync()
-		sugar := logger.Sugar()
 
-		sugar.Debugw("GOROUTINE_UPDATE_PRODUCT_STOCK", map[string]interface{}{"products": prods})
-		errMap := make(map[string]interface{})
-		for _, e := range prods {
-			err := utils.RedisSetHashGenericKey(redisHashKey, strconv.Itoa(int(e.ID)), e, o.p.Redis.KeyExpirationTime)
-			if err != nil {
-				errMap[strconv.Itoa(int(e.ID))] = err
-				sugar.Errorw("GOROUTINE_UPDATE_PRODUCT_STOCK", map[string]interface{}{"products": prods})
-				go jobs.LoadProductToRedis(o.p)
-			}
+		oiRepo := orderItems.NewOrderItemRepository(tx, c, o.p, span)
+		if err := oiRepo.CreateNewOrderItems(items); err != nil {
+			return err
 		}
-	}()
-
-	// this goroutine is for updating product stock
-	go func(gProds []entity.Product) {
-		logger, err := zap.NewProduction()
-		if err != nil {
-			fmt.Println("error trying to initialize logger")
+		sale := entity.StockCause{Reason: entity.StockReasonSale, ActorID: requester.ActorID()}
+		if err := recordStockMovements(c, o.p, tx, sale, -1, items, prods); err != nil {
+			return err
 		}
-		defer logger.Sync()
-		sugar := logger.Sugar()
-
-		sugar.Infow("GOROUTINE_UPDATE_PRODUCT_QUANTITY", map[string]interface{}{"data": gProds})
-		prodRepo := products.NewProductRepository(c, o.p, o.p.GormDB)
-		prods, err := prodRepo.UpdateMultiProduct(nil, prods...)
-		if err != nil {
-			sugar.Errorw("GOROUTINE_UPDATE_QUANTITY_PRODUCT_FAILED", map[string]interface{}{"products": prods, "message": err.Error()})
+		if err := allocateStock(c, o.p, tx, items); err != nil {
+			return err
 		}
-	}(prods)
+		return o.recalculateOrderTotals(c, span, tx, requester, entity.OrderEventItemsAdded, before, items...)
+	})
+	if err != nil {
+		o.p.Logger.Error("CREATE_ORDER_ITEMS: ERROR", map[string]interface{}{"error": err.Error(), "order_items": items})
+		return err
+	}
 
+	cacheProducts(o.p, prods)
 	o.p.Logger.Info("CREATE_ORDER_ITEMS: SUCCESSFULLY", map[string]interface{}{"order_items": items})
 	return nil
 }
 
-func (o orderItemUsecase) UpdateOrderItem(c *gin.Context, items []entity.OrderItem) ([]payload.OrderItemResponse, error) {
+// UpdateOrderItem saves the items and moves only the difference between the old and the new quantities
+// in and out of the product stock, all in one transaction. An item keeps its price snapshot unless it is
+// switched to another product or variant, which is then priced at its current price
+func (o orderItemUsecase) UpdateOrderItem(c *gin.Context, requester entity.Requester, items []entity.OrderItem) ([]payload.OrderItemResponse, error) {
 	span := o.p.Logger.Start(c, "UPDATE_ORDER_ITEM_USECASE", o.p.Logger.SetContextWithSpanFunc())
 	defer span.End()
 
@@ -126,96 +94,108 @@ func (o orderItemUsecase) UpdateOrderItem(c *gin.Context, items []entity.OrderIt
 			o.p.Logger.Error("UPDATE_ORDER_ITEMS: ERROR INVALID DATA", map[string]interface{}{"error": err.Error(), "order_item": item})
 			return nil, payload.ErrInvalidRequest(err)
 		}
-
-	}
-
-	oiRepo := orderItems.NewOrderItemRepository(o.p.GormDB, c, o.p, span)
-	orderItems, err := oiRepo.UpdateOrderItems(items)
-	if err != nil {
-		o.p.Logger.Error("UPDATE_ORDER_ITEMS: ERROR", map[string]interface{}{"error": err.Error(), "order_items": items})
-		return nil, err
+		// a quantity below one would turn the difference with the old quantity into stock given back for nothing
+		if item.Quantity <= 0 {
+			err := fmt.Errorf("the quantity of order item [%d] must be greater than 0", item.ID)
+			o.p.Logger.Error("UPDATE_ORDER_ITEMS: ERROR INVALID DATA", map[string]interface{}{"error": err.Error(), "order_item": item})
+			return nil, payload.ErrInvalidRequest(err)
+		}
 	}
 
+	var updatedItems []entity.OrderItem
 	prods := make([]entity.Product, 0)
+	err := o.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		oiRepo := orderItems.NewOrderItemRepository(tx, c, o.p, span)
+		released := make([]entity.OrderItem, 0)
+		reserved := make([]entity.OrderItem, 0)
+		// repriced maps the index of an item switched to another product to the index of its reservation
+		repriced := make(map[int]int)
+		touched := make([]entity.OrderItem, 0)
+		// before keeps every touched order as it was before the first change of this transaction
+		before := make(map[uint]entity.Snapshot)
+		for i, item := range items {
+			old, err := oiRepo.GetOrderItemByID(int64(item.ID))
+			if err != nil {
+				return err
+			}
+			for _, orderID := range []uint{old.OrderID, item.OrderID} {
+				if _, ok := before[orderID]; ok {
+					continue
+				}
+				order, err := o.editableOrderOfItem(c, tx, requester, orderID)
+				if err != nil {
+					return err
+				}
+				before[order.ID] = order.Snapshot()
+			}
+			touched = append(touched, *old, item)
+
+			items[i].Price = old.Price
+			if !old.SameStock(item) {
+				released = append(released, entity.OrderItem{Model: gorm.Model{ID: old.ID}, OrderID: old.OrderID, ProductID: old.ProductID, VariantID: old.VariantID, Quantity: old.Quantity})
+				repriced[i] = len(reserved)
+				reserved = append(reserved, entity.OrderItem{Model: gorm.Model{ID: item.ID}, OrderID: item.OrderID, ProductID: item.ProductID, VariantID: item.VariantID, Quantity: item.Quantity})
+				continue
+			}
+			switch delta := item.Quantity - old.Quantity; {
+			case delta > 0:
+				reserved = append(reserved, entity.OrderItem{Model: gorm.Model{ID: item.ID}, OrderID: item.OrderID, ProductID: item.ProductID, VariantID: item.VariantID, Quantity: delta})
+			case delta < 0:
+				released = append(released, entity.OrderItem{Model: gorm.Model{ID: item.ID}, OrderID: item.OrderID, ProductID: item.ProductID, VariantID: item.VariantID, Quantity: -delta})
+			}
+		}
 
-	// check product stock on redis
-	for i, e := range items {
-		var p entity.Product
-		utils.RedisGetHashGenericKey(redisHashKey, strconv.Itoa(int(e.ProductID)), &p)
-		var isAvailable bool = false
-		if p.ID == 0 {
-			continue
+		productRepo := products.NewProductRepository(c, o.p, tx)
+		restocked, err := productRepo.IncreaseStock(span, released...)
+		if err != nil {
+			return err
+		}
+		taken, err := productRepo.DecreaseStock(span, reserved...)
+		if err != nil {
+			return err
 		}
-		isAvailable = (p.Stock - int64(e.Quantity)) >= 0
-		if !isAvailable {
-			err := fmt.Errorf("product: %v is not available, please check again", e.ProductID)
-			o.p.Logger.Error("UPDATE_ORDER_ITEM: ERROR PRODUCT IS NOT AVAILABLE", map[string]interface{}{"error": err.Error()})
-			prods = make([]entity.Product, 0)
-			break
+		cancellation := entity.StockCause{Reason: entity.StockReasonCancellation, ActorID: requester.ActorID()}
+		if err := recordStockMovements(c, o.p, tx, cancellation, 1, released, restocked); err != nil {
+			return err
+		}
+		sale := entity.StockCause{Reason: entity.StockReasonSale, ActorID: requester.ActorID()}
+		if err := recordStockMovements(c, o.p, tx, sale, -1, reserved, taken); err != nil {
+			return err
+		}
+		if err := releaseStock(c, o.p, tx, released); err != nil {
+			return err
+		}
+		if err := allocateStock(c, o.p, tx, reserved); err != nil {
+			return err
+		}
+		prods = append(restocked, taken...)
+		for i, r := range repriced {
+			items[i].Price = taken[r].UnitPrice(items[i].VariantID)
 		}
-		p.Stock = p.Stock - int64(e.Quantity)
-		prods[i] = p
-	}
 
-	if len(prods) == 0 {
-		var err error = nil
-		productRepo := products.NewProductRepository(c, o.p, o.p.GormDB)
-		prods, err = productRepo.IsAvailableStockByOrderItems(span, items...)
+		updatedItems, err = oiRepo.UpdateOrderItems(items)
 		if err != nil {
-			o.p.Logger.Error("UPDATE_ORDER_ITEM: ERROR PRODUCT IS NOT AVAILABLE", map[string]interface{}{"error": err.Error()})
-			return nil, err
+			return err
 		}
+		return o.recalculateOrderTotals(c, span, tx, requester, entity.OrderEventItemsUpdated, before, touched...)
+	})
+	if err != nil {
+		o.p.Logger.Error("UPDATE_ORDER_ITEMS: ERROR", map[string]interface{}{"error": err.Error(), "order_items": items})
+		return nil, err
 	}
 
 	orderItemResponses := make([]payload.OrderItemResponse, 0)
-	for _, item := range orderItems {
+	for _, item := range updatedItems {
 		oir := mapper.OrderItemToOrderItemResponse(&item)
 		orderItemResponses = append(orderItemResponses, oir)
 	}
 
-	// this goroutine is for updating product on redis
-	go func() {
-		logger, err := zap.NewProduction()
-		if err != nil {
-			fmt.Println("error trying to initialize logger")
-		}
-		defer logger.Sync()
-		sugar := logger.Sugar()
-
-		sugar.Debugw("GOROUTINE_UPDATE_PRODUCT_STOCK", map[string]interface{}{"products": prods})
-		errMap := make(map[string]interface{})
-		for _, e := range prods {
-			err := utils.RedisSetHashGenericKey(redisHashKey, strconv.Itoa(int(e.ID)), e, o.p.Redis.KeyExpirationTime)
-			if err != nil {
-				errMap[strconv.Itoa(int(e.ID))] = err
-				sugar.Errorw("GOROUTINE_UPDATE_PRODUCT_STOCK", map[string]interface{}{"products": prods})
-				go jobs.LoadProductToRedis(o.p)
-			}
-		}
-	}()
-
-	// this goroutine is for updating product stock
-	go func(gProds []entity.Product) {
-		logger, err := zap.NewProduction()
-		if err != nil {
-			fmt.Println("error trying to initialize logger")
-		}
-		defer logger.Sync()
-		sugar := logger.Sugar()
-
-		sugar.Infow("GOROUTINE_UPDATE_PRODUCT_QUANTITY", map[string]interface{}{"data": gProds})
-		prodRepo := products.NewProductRepository(c, o.p, o.p.GormDB)
-		prods, err := prodRepo.UpdateMultiProduct(nil, prods...)
-		if err != nil {
-			sugar.Errorw("GOROUTINE_UPDATE_QUANTITY_PRODUCT_FAILED", map[string]interface{}{"products": prods, "message": err.Error()})
-		}
-	}(prods)
-
+	cacheProducts(o.p, prods)
 	o.p.Logger.Info("UPDATE_ORDER_ITEMS: SUCCESSFULLY", map[string]interface{}{"order_items": items})
 	return orderItemResponses, nil
 }
 
-func (o orderItemUsecase) GetOrderItemByID(c *gin.Context, id int64) (*payload.OrderItemResponse, error) {
+func (o orderItemUsecase) GetOrderItemByID(c *gin.Context, requester entity.Requester, id int64) (*payload.OrderItemResponse, error) {
 	span := o.p.Logger.Start(c, "GET_ORDER_ITEM_BY_ID_USECASE", o.p.Logger.SetContextWithSpanFunc())
 	defer span.End()
 
@@ -225,6 +205,10 @@ func (o orderItemUsecase) GetOrderItemByID(c *gin.Context, id int64) (*payload.O
 		o.p.Logger.Error("GET_ORDER_ITEM_BY_ID: ERROR", map[string]interface{}{"error": err.Error()})
 		return nil, err
 	}
+	if _, err := o.authorizeOrderOfItem(c, o.p.GormDB, requester, orderItem.OrderID); err != nil {
+		o.p.Logger.Error("GET_ORDER_ITEM_BY_ID: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
 
 	oir := mapper.OrderItemToOrderItemResponse(orderItem)
 
@@ -232,27 +216,56 @@ func (o orderItemUsecase) GetOrderItemByID(c *gin.Context, id int64) (*payload.O
 	return &oir, nil
 }
 
-func (o orderItemUsecase) DeleteOrderItemByID(c *gin.Context, id int64) error {
+func (o orderItemUsecase) DeleteOrderItemByID(c *gin.Context, requester entity.Requester, id int64) error {
 	span := o.p.Logger.Start(c, "DELETE_ORDER_ITEM_BY_ID_USECASE", o.p.Logger.SetContextWithSpanFunc())
 	defer span.End()
 
-	oiRepo := orderItems.NewOrderItemRepository(o.p.GormDB, c, o.p, span)
-	err := oiRepo.DeleteOrderItemByID(id)
+	prods := make([]entity.Product, 0)
+	err := o.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		oiRepo := orderItems.NewOrderItemRepository(tx, c, o.p, span)
+		orderItem, err := oiRepo.GetOrderItemByID(id)
+		if err != nil {
+			return err
+		}
+		order, err := o.editableOrderOfItem(c, tx, requester, orderItem.OrderID)
+		if err != nil {
+			return err
+		}
+		before := map[uint]entity.Snapshot{order.ID: order.Snapshot()}
+		if err := oiRepo.DeleteOrderItemByID(id); err != nil {
+			return err
+		}
+
+		productRepo := products.NewProductRepository(c, o.p, tx)
+		prods, err = productRepo.IncreaseStock(span, *orderItem)
+		if err != nil {
+			return err
+		}
+		cancellation := entity.StockCause{Reason: entity.StockReasonCancellation, ActorID: requester.ActorID()}
+		if err := recordStockMovements(c, o.p, tx, cancellation, 1, []entity.OrderItem{*orderItem}, prods); err != nil {
+			return err
+		}
+		if err := releaseStock(c, o.p, tx, []entity.OrderItem{*orderItem}); err != nil {
+			return err
+		}
+		return o.recalculateOrderTotals(c, span, tx, requester, entity.OrderEventItemRemoved, before, *orderItem)
+	})
 	if err != nil {
 		o.p.Logger.Error("DELETE_ORDER_ITEM_BY_ID: ERROR", map[string]interface{}{"error": err.Error()})
 		return err
 	}
 
+	cacheProducts(o.p, prods)
 	o.p.Logger.Info("DELETE_ORDER_ITEM_BY_ID: SUCCESSFULLY", map[string]interface{}{"deleted_id": id})
 	return nil
 }
 
-func (o orderItemUsecase) GetAllOrderItems(c *gin.Context) (*payload.ListOrderItemResponses, error) {
+func (o orderItemUsecase) GetAllOrderItems(c *gin.Context, pagination *entity.Pagination) (*payload.ListOrderItemResponses, error) {
 	span := o.p.Logger.Start(c, "GET_ALL_ORDER_ITEMS_USECASE", o.p.Logger.SetContextWithSpanFunc())
 	defer span.End()
 
 	oiRepo := orderItems.NewOrderItemRepository(o.p.GormDB, c, o.p, span)
-	items, err := oiRepo.GetAllOrderItems()
+	items, err := oiRepo.GetAllOrderItems(pagination)
 	if err != nil {
 		o.p.Logger.Error("GET_ALL_ORDER_ITEMS: ERROR", map[string]interface{}{"error": err.Error(), "order_items": items})
 		return nil, err
@@ -263,12 +276,79 @@ func (o orderItemUsecase) GetAllOrderItems(c *gin.Context) (*payload.ListOrderIt
 		oir := mapper.OrderItemToOrderItemResponse(&item)
 		orderItemResponses = append(orderItemResponses, oir)
 	}
-	response := payload.ListOrderItemResponses{Orders: orderItemResponses}
+	response := payload.ListOrderItemResponses{
+		Orders:             orderItemResponses,
+		PaginationResponse: mapper.PaginationToPaginationResponse(pagination),
+	}
 
 	o.p.Logger.Info("GET_ALL_ORDER_ITEMS: SUCCESSFULLY", map[string]interface{}{"order_items": response})
 	return &response, nil
 }
 
+// authorizeOrderOfItem loads the order an item belongs to and makes sure the requester can access it
+func (o orderItemUsecase) authorizeOrderOfItem(c *gin.Context, db *gorm.DB, requester entity.Requester, orderID uint) (*entity.Order, error) {
+	order, err := orders.NewOrderRepository(c, o.p, db).GetOrderByID(int64(orderID))
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeOrder(requester, order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// editableOrderOfItem is authorizeOrderOfItem for changes to the items, which are only allowed while the order is
+// still pending so the totals never move after the order is confirmed. It locks the order first, so db has to be
+// the transaction of the change and a confirmation or a payment cannot slip in between the check and the change
+func (o orderItemUsecase) editableOrderOfItem(c *gin.Context, db *gorm.DB, requester entity.Requester, orderID uint) (*entity.Order, error) {
+	if err := orders.NewOrderRepository(c, o.p, db).LockOrder(int64(orderID)); err != nil {
+		return nil, err
+	}
+	order, err := o.authorizeOrderOfItem(c, db, requester, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != entity.OrderStatusPending {
+		return nil, payload.ErrInvalidRequest(fmt.Errorf("the items of order [%d] cannot be changed in status %s", order.ID, order.Status))
+	}
+	return order, nil
+}
+
+// recalculateOrderTotals reloads every order the items belong to, saves its totals again with the tax of its items
+// and the discount of its promotion worked out again and records the change of its items against the snapshot the order had in before
+func (o orderItemUsecase) recalculateOrderTotals(c *gin.Context, span trace.Span, db *gorm.DB, requester entity.Requester, eventType string, before map[uint]entity.Snapshot, items ...entity.OrderItem) error {
+	orderRepo := orders.NewOrderRepository(c, o.p, db)
+	done := make(map[uint]bool)
+	for _, item := range items {
+		if done[item.OrderID] {
+			continue
+		}
+		done[item.OrderID] = true
+
+		order, err := orderRepo.GetOrderByID(int64(item.OrderID))
+		if err != nil {
+			return err
+		}
+		prods, err := products.NewProductRepository(c, o.p, db).GetProductByOrderItem(span, order.OrderItems...)
+		if err != nil {
+			return payload.ErrDB(err)
+		}
+		if err := taxOrderItems(c, o.p, db, span, order, prods); err != nil {
+			return err
+		}
+		if err := repriceOrderPromotion(c, o.p, db, order, productCategories(prods)); err != nil {
+			return err
+		}
+		if err := orderRepo.UpdateTotals(order); err != nil {
+			return err
+		}
+		if err := recordOrderEvent(c, o.p, db, order.ID, requester.ActorID(), eventType, before[order.ID], order.Snapshot()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func NewOrderItemUsecase(p *base.Persistence) OrderItemUsecase {
 	return orderItemUsecase{p: p}
 }
\ No newline at end of file
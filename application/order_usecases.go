@@ -1,151 +1,522 @@
 package application
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"go.uber.org/zap"
+	"gorm.io/gorm"
 	"pm/domain/entity"
-	products2 "pm/domain/repository/products"
+	"pm/infrastructure/config"
 	"pm/infrastructure/controllers/payload"
+	orderEvents "pm/infrastructure/implementations/order_events"
 	"pm/infrastructure/implementations/orders"
 	"pm/infrastructure/implementations/products"
-	"pm/infrastructure/jobs"
+	"pm/infrastructure/implementations/promotions"
 	"pm/infrastructure/mapper"
 	"pm/infrastructure/persistences/base"
 	"pm/utils"
-	"strconv"
 )
 
 const orderEntity string = "orders"
 
 type OrderUsecase interface {
-	CreateOrder(*gin.Context, *payload.CreateOrderRequest) error
-	GetAllOrders(filter *entity.OrderFilter, pagination *entity.Pagination) ([]entity.Order, error)
-	GetOrderByID(*gin.Context, int64) (*entity.Order, error)
-	DeleteOrderByID(id int64) error
-	UpdateOrderByID(id int64, updatePayload payload.UpdateOrderRequest) (*entity.Order, error)
+	CreateOrder(*gin.Context, entity.Requester, *payload.CreateOrderRequest) (*entity.Order, error)
+	GetAllOrders(*gin.Context, entity.Requester, *entity.OrderFilter, *entity.Pagination) ([]entity.Order, error)
+	GetOrderByID(*gin.Context, entity.Requester, int64) (*entity.Order, error)
+	DeleteOrderByID(c *gin.Context, id int64, requester entity.Requester) error
+	UpdateOrderByID(c *gin.Context, id int64, requester entity.Requester, updatePayload payload.UpdateOrderRequest) (*entity.Order, error)
+	UpdateOrderStatus(c *gin.Context, id int64, requester entity.Requester, status string) (*entity.Order, error)
+	CancelOrder(c *gin.Context, id int64, requester entity.Requester, reason string) (*entity.Order, error)
+	ShipOrder(c *gin.Context, id int64, requester entity.Requester, reqPayload *payload.ShipOrderRequest) (*entity.Order, error)
+	GetOrderHistory(c *gin.Context, id int64, requester entity.Requester) ([]entity.OrderEvent, error)
 }
 
 type orderUsecase struct {
-	p *base.Persistence
+	p           *base.Persistence
+	shippingFee float64
 }
 
 func NewOrderUsecase(p *base.Persistence) OrderUsecase {
-	return orderUsecase{p}
+	var shippingFee float64
+	if config.Configs != nil {
+		shippingFee = config.Configs.ShippingConfig.Fee
+	}
+	return orderUsecase{p, shippingFee}
 }
 
-func (o orderUsecase) CreateOrder(c *gin.Context, reqPayload *payload.CreateOrderRequest) error {
+// CreateOrder saves the order for the requester and takes the stock of its items in one transaction, so the order
+// is rejected when any product does not have enough stock left at write time. The price of every item is the price
+// of its product, or of its variant, at that moment, whatever the client sent, taxed with the rate of the region the order is shipped to. The order keeps a copy of the shipping address, the given
+// address of the address book of the requester or their default address. A promotion code is applied in the same
+// transaction and counted against its usage limits once the order is saved. Once there are warehouses every item is
+// allocated to the warehouses it is taken from, with the configured allocation strategy
+func (o orderUsecase) CreateOrder(c *gin.Context, requester entity.Requester, reqPayload *payload.CreateOrderRequest) (*entity.Order, error) {
 	span := o.p.Logger.Start(c, "CREATE_ORDER: USECASES", o.p.Logger.SetContextWithSpanFunc())
 	defer span.End()
-	o.p.Logger.Info("STARTING: CREATE_ORDER", map[string]interface{}{"data": reqPayload})
-
-	order := mapper.CreateOrderPayloadToOrder(reqPayload)
-	prods := make([]entity.Product, 0)
-	productRepo := products.NewProductRepository(c, o.p, o.p.GormDB)
-	orderRepo := orders.NewOrderRepository(c, o.p, o.p.GormDB)
-	var err error
+	o.p.Logger.Info("STARTING: CREATE_ORDER", map[string]interface{}{"data": reqPayload, "user_id": requester.UserID})
 
-	// check product stock on redis
-	for i, e := range order.OrderItems {
-		var p entity.Product
-		utils.RedisGetHashGenericKey(redisHashKey, strconv.Itoa(int(e.ProductID)), &p)
-		var isAvailable bool = false
-		if p.ID == 0 {
-			continue
-		}
-		isAvailable = (p.Stock - int64(e.Quantity)) >= 0
-		if !isAvailable {
-			err := fmt.Errorf("product: %v is not available, please check again", e.ProductID)
-			o.p.Logger.Error("CREATE_ORDER: ERROR PRODUCT IS NOT AVAILABLE", map[string]interface{}{"error": err.Error()})
-			prods = make([]entity.Product, 0)
-			break
+	if err := utils.ValidateReqPayload(reqPayload); err != nil {
+		o.p.Logger.Error("CREATE_ORDER: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInvalidRequest(err)
+	}
+	for _, item := range reqPayload.OrderItems {
+		if err := utils.ValidateReqPayload(item); err != nil {
+			o.p.Logger.Error("CREATE_ORDER: ERROR INVALID DATA", map[string]interface{}{"error": err.Error(), "order_item": item})
+			return nil, payload.ErrInvalidRequest(err)
 		}
-		p.Stock = p.Stock - int64(e.Quantity)
-		prods[i] = p
 	}
 
-	if len(prods) == 0 {
-		prods, err = productRepo.IsAvailableStockByOrderItems(span, order.OrderItems...)
+	order := mapper.CreateOrderPayloadToOrder(reqPayload, uint(requester.UserID))
+	if len(order.OrderItems) == 0 {
+		err := fmt.Errorf("order must contain at least one item")
+		o.p.Logger.Error("CREATE_ORDER: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInvalidRequest(err)
+	}
+
+	address, err := shippingAddressOf(c, o.p, o.p.GormDB, requester, reqPayload.AddressID)
+	if err != nil {
+		o.p.Logger.Error("CREATE_ORDER: ERROR SHIPPING ADDRESS", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	order.ShippingAddress = address.ToShippingAddress()
+	order.ShippingFee = o.shippingFee
+
+	prods := make([]entity.Product, 0)
+	err = o.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		productRepo := products.NewProductRepository(c, o.p, tx)
+		prods, err = productRepo.DecreaseStock(span, order.OrderItems...)
 		if err != nil {
-			o.p.Logger.Error("CREATE_ORDER: ERROR PRODUCT IS NOT AVAILABLE", map[string]interface{}{"error": err.Error()})
 			return err
 		}
-	}
+		for i := range order.OrderItems {
+			order.OrderItems[i].Price = prods[i].UnitPrice(order.OrderItems[i].VariantID)
+		}
+		if err := taxOrderItems(c, o.p, tx, span, &order, prods); err != nil {
+			return err
+		}
+		order.CalculateTotals()
 
-	o.p.Logger.Info("CREATE_ORDER", map[string]interface{}{"order": order})
-	if err := orderRepo.Create(&order); err != nil {
+		var promotion *entity.Promotion
+		if reqPayload.PromotionCode != "" {
+			if promotion, err = applyPromotion(c, o.p, tx, requester, &order, reqPayload.PromotionCode, productCategories(prods)); err != nil {
+				return err
+			}
+		}
+
+		orderRepo := orders.NewOrderRepository(c, o.p, tx)
+		if err := orderRepo.Create(&order); err != nil {
+			return err
+		}
+		sale := entity.StockCause{Reason: entity.StockReasonSale, ActorID: requester.ActorID()}
+		if err := recordStockMovements(c, o.p, tx, sale, -1, order.OrderItems, prods); err != nil {
+			return err
+		}
+		if err := allocateStock(c, o.p, tx, order.OrderItems); err != nil {
+			return err
+		}
+		if promotion != nil {
+			if err := promotions.NewPromotionRepository(c, o.p, tx).Use(promotion, uint(requester.UserID), order.ID); err != nil {
+				return err
+			}
+		}
+		return recordOrderEvent(c, o.p, tx, order.ID, requester.ActorID(), entity.OrderEventCreated, nil, order.Snapshot())
+	})
+	if err != nil {
 		o.p.Logger.Error("CREATE_ORDER: ERROR", map[string]interface{}{"error": err.Error()})
-		return err
+		return nil, err
 	}
+
+	cacheProducts(o.p, prods)
 	o.p.Logger.Info("CREATE_ORDER: SUCCESSFULLY", map[string]interface{}{"order": order})
+	return &order, nil
+}
+
+// GetAllOrders lists the orders matching the filter, a requester who is not an admin only ever sees their own orders
+func (o orderUsecase) GetAllOrders(c *gin.Context, requester entity.Requester, filter *entity.OrderFilter, pagination *entity.Pagination) ([]entity.Order, error) {
+	span := o.p.Logger.Start(c, "GET_ALL_ORDERS: USECASES", o.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	o.p.Logger.Info("STARTING: GET ALL ORDERS", map[string]interface{}{
+		"params": struct {
+			Filter     *entity.OrderFilter `json:"filter"`
+			Pagination *entity.Pagination  `json:"pagination"`
+		}{
+			Filter:     filter,
+			Pagination: pagination,
+		},
+	})
+
+	if !requester.IsAdmin() {
+		filter.UserID = requester.UserID
+	}
+	if filter.Status != "" && !entity.IsValidOrderStatus(filter.Status) {
+		err := payload.ErrInvalidRequest(fmt.Errorf("invalid order status [%s]", filter.Status))
+		o.p.Logger.Error("GET_ALL_ORDERS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	if filter.TotalFrom != 0 && filter.TotalTo != 0 && filter.TotalFrom > filter.TotalTo {
+		err := payload.ErrInvalidRequest(errors.New("totalFrom must not be greater than totalTo"))
+		o.p.Logger.Error("GET_ALL_ORDERS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	orderRepo := orders.NewOrderRepository(c, o.p, o.p.GormDB)
+	listOrders, err := orderRepo.GetAllOrders(filter, pagination)
+	if err != nil {
+		o.p.Logger.Error("GET_ALL_ORDERS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
 
-	// this goroutine is for updating product on redis
-	go func() {
-		logger, err := zap.NewProduction()
+	o.p.Logger.Info("GET_ALL_ORDERS: SUCCESSFULLY", map[string]interface{}{"orders": listOrders})
+	return listOrders, nil
+}
+
+func (o orderUsecase) GetOrderByID(c *gin.Context, requester entity.Requester, id int64) (*entity.Order, error) {
+	span := o.p.Logger.Start(c, "GET_ORDER_BY_ID: USECASES", o.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	o.p.Logger.Info("STARTING: GET ORDER BY ID", map[string]interface{}{"id": id, "user_id": requester.UserID})
+
+	db := o.p.GormDB
+	orderRepo := orders.NewOrderRepository(c, o.p, db)
+	order, err := orderRepo.GetOrderByID(id)
+	if err != nil {
+		o.p.Logger.Error("GET_ORDER: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	if err := authorizeOrder(requester, order); err != nil {
+		o.p.Logger.Error("GET_ORDER: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	o.p.Logger.Info("GET_ORDER: SUCCESSFULLY", map[string]interface{}{"order_response": order})
+	return order, nil
+}
+
+// DeleteOrderByID soft deletes the order with its items. An order that can still be cancelled is cancelled first
+// so its stock goes back to the products in the same transaction
+func (o orderUsecase) DeleteOrderByID(c *gin.Context, id int64, requester entity.Requester) error {
+	span := o.p.Logger.Start(c, "DELETE_ORDER: USECASES", o.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	o.p.Logger.Info("STARTING: DELETE_ORDER", map[string]interface{}{"id": id, "actor_id": requester.UserID})
+
+	restocked := make([]entity.Product, 0)
+	authorized := make([]entity.Payment, 0)
+	err := o.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		orderRepo := orders.NewOrderRepository(c, o.p, tx)
+		if err := orderRepo.LockOrder(id); err != nil {
+			return err
+		}
+		order, err := orderRepo.GetOrderByID(id)
 		if err != nil {
-			fmt.Println("error trying to initialize logger")
+			return err
+		}
+		if err := authorizeOrder(requester, order); err != nil {
+			return err
 		}
-		defer logger.Sync()
-		sugar := logger.Sugar()
 
-		sugar.Debugw("GOROUTINE_UPDATE_PRODUCT_STOCK", map[string]interface{}{"products": prods})
-		errMap := make(map[string]interface{})
-		for _, e := range prods {
-			err := utils.RedisSetHashGenericKey(redisHashKey, strconv.Itoa(int(e.ID)), e, o.p.Redis.KeyExpirationTime)
+		switch {
+		case order.CanTransitionTo(entity.OrderStatusCancelled):
+			if authorized, err = paymentsToVoid(c, o.p, tx, id); err != nil {
+				return err
+			}
+			before := order.Snapshot()
+			if err := orderRepo.Cancel(order, requester.UserID, "order deleted"); err != nil {
+				return err
+			}
+			if err := recordOrderEvent(c, o.p, tx, order.ID, requester.ActorID(), entity.OrderEventCancelled, before, order.Snapshot()); err != nil {
+				return err
+			}
+			if err := promotions.NewPromotionRepository(c, o.p, tx).Release(order.ID); err != nil {
+				return err
+			}
+			productRepo := products.NewProductRepository(c, o.p, tx)
+			restocked, err = productRepo.IncreaseStock(span, order.OrderItems...)
 			if err != nil {
-				errMap[strconv.Itoa(int(e.ID))] = err
-				sugar.Errorw("GOROUTINE_UPDATE_PRODUCT_STOCK", map[string]interface{}{"products": prods})
-				go jobs.LoadProductToRedis(o.p)
+				return err
+			}
+			cancellation := entity.StockCause{Reason: entity.StockReasonCancellation, ActorID: requester.ActorID()}
+			if err := recordStockMovements(c, o.p, tx, cancellation, 1, order.OrderItems, restocked); err != nil {
+				return err
+			}
+			if err := releaseStock(c, o.p, tx, order.OrderItems); err != nil {
+				return err
 			}
+		case order.Status != entity.OrderStatusCancelled && order.Status != entity.OrderStatusRefunded:
+			return payload.ErrInvalidOrderTransition(order.Status, entity.OrderStatusCancelled)
 		}
-	}()
 
-	// this goroutine is for updating product stock
-	go func(prodRepo products2.ProductRepository, gProds []entity.Product) {
-		logger, err := zap.NewProduction()
-		if err != nil {
-			fmt.Println("error trying to initialize logger")
+		if err := orderRepo.DeleteOrder(order); err != nil {
+			return err
 		}
-		defer logger.Sync()
-		sugar := logger.Sugar()
+		return recordOrderEvent(c, o.p, tx, order.ID, requester.ActorID(), entity.OrderEventDeleted, order.Snapshot(), nil)
+	})
+	if err != nil {
+		o.p.Logger.Error("DELETE_ORDER: ERROR", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+
+	cacheProducts(o.p, restocked)
+	voidPayments(c, o.p, requester.ActorID(), authorized)
+	o.p.Logger.Info("DELETE_ORDER: SUCCESSFULLY", map[string]interface{}{"deleted_id": id})
+	return nil
+}
+
+func (o orderUsecase) UpdateOrderByID(c *gin.Context, id int64, requester entity.Requester, updatePayload payload.UpdateOrderRequest) (*entity.Order, error) {
+	span := o.p.Logger.Start(c, "UPDATE_ORDER: USECASES", o.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	o.p.Logger.Info("STARTING: UPDATE_ORDER", map[string]interface{}{"id": id, "data": updatePayload})
+
+	if err := utils.ValidateReqPayload(updatePayload); err != nil {
+		o.p.Logger.Error("UPDATE_ORDER: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInvalidRequest(err)
+	}
+
+	if updatePayload.Status == entity.OrderStatusCancelled {
+		return o.CancelOrder(c, id, requester, updatePayload.Reason)
+	}
+	return o.UpdateOrderStatus(c, id, requester, updatePayload.Status)
+}
+
+func (o orderUsecase) UpdateOrderStatus(c *gin.Context, id int64, requester entity.Requester, status string) (*entity.Order, error) {
+	span := o.p.Logger.Start(c, "UPDATE_ORDER_STATUS: USECASES", o.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	o.p.Logger.Info("STARTING: UPDATE_ORDER_STATUS", map[string]interface{}{"id": id, "status": status})
+
+	if !entity.IsValidOrderStatus(status) {
+		err := fmt.Errorf("unknown order status: %v", status)
+		o.p.Logger.Error("UPDATE_ORDER_STATUS: ERROR INVALID STATUS", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInvalidRequest(err)
+	}
+	if status == entity.OrderStatusCancelled {
+		err := fmt.Errorf("orders must be cancelled through the cancel operation so their stock is restored")
+		o.p.Logger.Error("UPDATE_ORDER_STATUS: ERROR INVALID STATUS", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInvalidRequest(err)
+	}
+	if status == entity.OrderStatusPaid {
+		err := fmt.Errorf("orders must be paid by capturing their payment so the payment is recorded")
+		o.p.Logger.Error("UPDATE_ORDER_STATUS: ERROR INVALID STATUS", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInvalidRequest(err)
+	}
+	if entity.IsRefundStatus(status) {
+		err := fmt.Errorf("orders must be refunded through the refund operation so their payment is refunded")
+		o.p.Logger.Error("UPDATE_ORDER_STATUS: ERROR INVALID STATUS", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInvalidRequest(err)
+	}
+	if status == entity.OrderStatusShipped {
+		return o.ShipOrder(c, id, requester, &payload.ShipOrderRequest{})
+	}
 
-		sugar.Infow("GOROUTINE_UPDATE_PRODUCT_QUANTITY", map[string]interface{}{"data": gProds})
-		prods, err := prodRepo.UpdateMultiProduct(nil, prods...)
+	var order *entity.Order
+	var invoice *entity.Invoice
+	err := o.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		orderRepo := orders.NewOrderRepository(c, o.p, tx)
+		if err := orderRepo.LockOrder(id); err != nil {
+			return err
+		}
+		order, err = orderRepo.GetOrderByID(id)
 		if err != nil {
-			sugar.Errorw("GOROUTINE_UPDATE_QUANTITY_PRODUCT_FAILED", map[string]interface{}{"products": prods, "message": err.Error()})
+			return err
 		}
-	}(productRepo, prods)
-	return nil
+		invoice, err = transitionOrder(c, o.p, tx, requester.ActorID(), order, status)
+		return err
+	})
+	if err != nil {
+		o.p.Logger.Error("UPDATE_ORDER_STATUS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	if invoice != nil {
+		if err := deliverInvoice(c, o.p, invoiceConfig(), invoice); err != nil {
+			o.p.Logger.Error("DELIVER_INVOICE: ERROR", map[string]interface{}{"number": invoice.Number, "error": err.Error()})
+		}
+	}
+
+	o.p.Logger.Info("UPDATE_ORDER_STATUS: SUCCESSFULLY", map[string]interface{}{"order": order})
+	return order, nil
 }
 
-func (o orderUsecase) GetAllOrders(filter *entity.OrderFilter, pagination *entity.Pagination) ([]entity.Order, error) {
-	//TODO implement me
-	panic("implement me")
+// CancelOrder cancels the order and gives the stock of all of its items and the use of its promotion back in a single
+// transaction, then refreshes the restocked products on redis
+func (o orderUsecase) CancelOrder(c *gin.Context, id int64, requester entity.Requester, reason string) (*entity.Order, error) {
+	span := o.p.Logger.Start(c, "CANCEL_ORDER: USECASES", o.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	o.p.Logger.Info("STARTING: CANCEL_ORDER", map[string]interface{}{"id": id, "actor_id": requester.UserID, "reason": reason})
+
+	var order *entity.Order
+	restocked := make([]entity.Product, 0)
+	authorized := make([]entity.Payment, 0)
+	err := o.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		orderRepo := orders.NewOrderRepository(c, o.p, tx)
+		// the lock keeps a capture of a payment of the order from starting while it gets cancelled
+		if err := orderRepo.LockOrder(id); err != nil {
+			return err
+		}
+		order, err = orderRepo.GetOrderByID(id)
+		if err != nil {
+			return err
+		}
+		if err := authorizeOrder(requester, order); err != nil {
+			return err
+		}
+		if !order.CanTransitionTo(entity.OrderStatusCancelled) {
+			return payload.ErrInvalidOrderTransition(order.Status, entity.OrderStatusCancelled)
+		}
+		if authorized, err = paymentsToVoid(c, o.p, tx, id); err != nil {
+			return err
+		}
+		before := order.Snapshot()
+		if err := orderRepo.Cancel(order, requester.UserID, reason); err != nil {
+			return err
+		}
+		if err := recordOrderEvent(c, o.p, tx, order.ID, requester.ActorID(), entity.OrderEventCancelled, before, order.Snapshot()); err != nil {
+			return err
+		}
+		if err := promotions.NewPromotionRepository(c, o.p, tx).Release(order.ID); err != nil {
+			return err
+		}
+
+		productRepo := products.NewProductRepository(c, o.p, tx)
+		restocked, err = productRepo.IncreaseStock(span, order.OrderItems...)
+		if err != nil {
+			return err
+		}
+		cancellation := entity.StockCause{Reason: entity.StockReasonCancellation, ActorID: requester.ActorID()}
+		if err := recordStockMovements(c, o.p, tx, cancellation, 1, order.OrderItems, restocked); err != nil {
+			return err
+		}
+		return releaseStock(c, o.p, tx, order.OrderItems)
+	})
+	if err != nil {
+		o.p.Logger.Error("CANCEL_ORDER: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	cacheProducts(o.p, restocked)
+	voidPayments(c, o.p, requester.ActorID(), authorized)
+	o.p.Logger.Info("CANCEL_ORDER: SUCCESSFULLY", map[string]interface{}{"order": order})
+	return order, nil
 }
 
-func (o orderUsecase) GetOrderByID(c *gin.Context, id int64) (*entity.Order, error) {
-	span := o.p.Logger.Start(c, "GET_ORDER_BY_ID: USECASES", o.p.Logger.SetContextWithSpanFunc())
+// ShipOrder moves the order to shipped and records how it is shipped, the shipping method and the tracking number
+// it already has are kept when the request leaves them empty
+func (o orderUsecase) ShipOrder(c *gin.Context, id int64, requester entity.Requester, reqPayload *payload.ShipOrderRequest) (*entity.Order, error) {
+	span := o.p.Logger.Start(c, "SHIP_ORDER: USECASES", o.p.Logger.SetContextWithSpanFunc())
 	defer span.End()
-	o.p.Logger.Info("STARTING: GET ORDER BY ID", map[string]interface{}{"id": id})
+	o.p.Logger.Info("STARTING: SHIP_ORDER", map[string]interface{}{"id": id, "data": reqPayload})
 
-	db := o.p.GormDB
-	orderRepo := orders.NewOrderRepository(c, o.p, db)
-	order, err := orderRepo.GetOrderByID(id)
+	if err := utils.ValidateReqPayload(reqPayload); err != nil {
+		o.p.Logger.Error("SHIP_ORDER: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInvalidRequest(err)
+	}
+
+	var order *entity.Order
+	err := o.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		orderRepo := orders.NewOrderRepository(c, o.p, tx)
+		if err := orderRepo.LockOrder(id); err != nil {
+			return err
+		}
+		order, err = orderRepo.GetOrderByID(id)
+		if err != nil {
+			return err
+		}
+		if !order.CanTransitionTo(entity.OrderStatusShipped) {
+			return payload.ErrInvalidOrderTransition(order.Status, entity.OrderStatusShipped)
+		}
+
+		before := order.Snapshot()
+		if err := orderRepo.Ship(order, reqPayload.ShippingMethod, reqPayload.TrackingNumber); err != nil {
+			return err
+		}
+		return recordOrderEvent(c, o.p, tx, order.ID, requester.ActorID(), entity.OrderEventStatusChanged, before, order.Snapshot())
+	})
 	if err != nil {
-		o.p.Logger.Error("GET_ORDER: ERROR", map[string]interface{}{"error": err.Error()})
+		o.p.Logger.Error("SHIP_ORDER: ERROR", map[string]interface{}{"error": err.Error()})
 		return nil, err
 	}
 
-	o.p.Logger.Error("GET_ORDER: SUCCESSFULLY", map[string]interface{}{"order_response": order})
+	o.p.Logger.Info("SHIP_ORDER: SUCCESSFULLY", map[string]interface{}{"order": order})
 	return order, nil
 }
 
-func (o orderUsecase) DeleteOrderByID(id int64) error {
-	//TODO implement me
-	panic("implement me")
+// GetOrderHistory returns every change made to the order, oldest first. Admins can still read the history of a
+// deleted order, the history outlives its order so disputes can be settled afterwards
+func (o orderUsecase) GetOrderHistory(c *gin.Context, id int64, requester entity.Requester) ([]entity.OrderEvent, error) {
+	span := o.p.Logger.Start(c, "GET_ORDER_HISTORY: USECASES", o.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	o.p.Logger.Info("STARTING: GET_ORDER_HISTORY", map[string]interface{}{"id": id, "user_id": requester.UserID})
+
+	if !requester.IsAdmin() {
+		order, err := orders.NewOrderRepository(c, o.p, o.p.GormDB).GetOrderByID(id)
+		if err != nil {
+			o.p.Logger.Error("GET_ORDER_HISTORY: ERROR", map[string]interface{}{"error": err.Error()})
+			return nil, err
+		}
+		if err := authorizeOrder(requester, order); err != nil {
+			o.p.Logger.Error("GET_ORDER_HISTORY: ERROR", map[string]interface{}{"error": err.Error()})
+			return nil, err
+		}
+	}
+
+	events, err := orderEvents.NewOrderEventRepository(c, o.p, o.p.GormDB).GetEventsByOrderID(id)
+	if err != nil {
+		o.p.Logger.Error("GET_ORDER_HISTORY: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	// orders placed before their history was recorded have no events, they still exist
+	if len(events) == 0 && requester.IsAdmin() {
+		if _, err := orders.NewOrderRepository(c, o.p, o.p.GormDB).GetOrderByID(id); err != nil {
+			o.p.Logger.Error("GET_ORDER_HISTORY: ERROR", map[string]interface{}{"error": err.Error()})
+			return nil, err
+		}
+	}
+
+	o.p.Logger.Info("GET_ORDER_HISTORY: SUCCESSFULLY", map[string]interface{}{"events": len(events)})
+	return events, nil
+}
+
+// authorizeOrder reports an order of another user as not found, so a requester who is not an admin
+// cannot even tell whether the order exists
+func authorizeOrder(requester entity.Requester, order *entity.Order) error {
+	if requester.CanAccessOrder(order) {
+		return nil
+	}
+	return payload.ErrEntityNotFound(orderEntity, fmt.Errorf("order with id [%d] not found", order.ID))
 }
 
-func (o orderUsecase) UpdateOrderByID(id int64, updatePayload payload.UpdateOrderRequest) (*entity.Order, error) {
-	//TODO implement me
-	panic("implement me")
+// transitionOrder moves the order to the given status and records the change in its history. An order getting paid
+// is invoiced in the same transaction, whatever paid it, which also holds the number of the invoice so the numbering
+// has no gap. The invoice issued is returned so it can be delivered once the transaction is committed
+func transitionOrder(c *gin.Context, p *base.Persistence, tx *gorm.DB, actorID *uint, order *entity.Order, status string) (*entity.Invoice, error) {
+	if !order.CanTransitionTo(status) {
+		return nil, payload.ErrInvalidOrderTransition(order.Status, status)
+	}
+	before := order.Snapshot()
+	if err := orders.NewOrderRepository(c, p, tx).UpdateStatus(order, status); err != nil {
+		return nil, err
+	}
+	if err := recordOrderEvent(c, p, tx, order.ID, actorID, entity.OrderEventStatusChanged, before, order.Snapshot()); err != nil {
+		return nil, err
+	}
+	if status != entity.OrderStatusPaid {
+		return nil, nil
+	}
+	return issueInvoice(c, p, tx, invoiceConfig(), order, paymentConfig().Currency)
+}
+
+// recordOrderEvent appends to the history of the order what changed between both snapshots. It has to run in the
+// transaction of the change itself, so the history never misses a change nor records one that was rolled back
+func recordOrderEvent(c *gin.Context, p *base.Persistence, db *gorm.DB, orderID uint, actorID *uint, eventType string, before, after entity.Snapshot) error {
+	diff, err := json.Marshal(entity.DiffSnapshots(before, after))
+	if err != nil {
+		return payload.ErrInternal(err)
+	}
+	return orderEvents.NewOrderEventRepository(c, p, db).Create(&entity.OrderEvent{
+		OrderID: orderID,
+		ActorID: actorID,
+		Type:    eventType,
+		Diff:    string(diff),
+	})
 }
\ No newline at end of file
@@ -0,0 +1,241 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/implementations/loggers"
+	"pm/infrastructure/persistences/base"
+	"pm/infrastructure/persistences/base/db"
+	"pm/utils"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The tests of this file run the usecases against a real database, TEST_DATABASE_DSN has to point to a PostgreSQL
+// database they are free to write to, they are skipped otherwise
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	utils.InitValidatorHelper()
+	os.Exit(m.Run())
+}
+
+func openTestPersistence(t *testing.T) *base.Persistence {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	gormDB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	if err != nil {
+		t.Fatalf("error connecting to database: %v", err)
+	}
+	if err := db.Migrate(gormDB); err != nil {
+		t.Fatalf("error migrating database: %v", err)
+	}
+	return &base.Persistence{Ctx: context.Background(), GormDB: gormDB, Logger: newTestLogger()}
+}
+
+// newTestLogger traces to the global tracer, which does nothing unless one is set up
+func newTestLogger() *loggers.LoggerRepo {
+	return loggers.NewLoggerRepository([]string{loggers.Honeycomb})
+}
+
+// forRequest gives every concurrent request its own logger, as the logger keeps the span of the request it serves
+func forRequest(p *base.Persistence) *base.Persistence {
+	requestP := *p
+	requestP.Logger = newTestLogger()
+	return &requestP
+}
+
+func newTestContext() *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	return c
+}
+
+// seedCustomer creates a user with a default shipping address, orders of the user are shipped to it
+func seedCustomer(t *testing.T, p *base.Persistence) entity.Requester {
+	t.Helper()
+	role := entity.UserRole{ID: entity.RoleUser, Name: "USER"}
+	if err := p.GormDB.Where(entity.UserRole{ID: entity.RoleUser}).Attrs(role).FirstOrCreate(&role).Error; err != nil {
+		t.Fatalf("error creating role: %v", err)
+	}
+
+	user := entity.User{Name: "stock test", RoleID: entity.RoleUser, Email: fmt.Sprintf("stock-%d@test.local", time.Now().UnixNano())}
+	if err := p.GormDB.Create(&user).Error; err != nil {
+		t.Fatalf("error creating user: %v", err)
+	}
+	address := entity.Address{UserID: user.ID, RecipientName: user.Name, Line1: "1 Test street", City: "Test", Country: "VN", IsDefault: true}
+	if err := p.GormDB.Create(&address).Error; err != nil {
+		t.Fatalf("error creating address: %v", err)
+	}
+	return entity.Requester{UserID: int64(user.ID), RoleID: entity.RoleUser}
+}
+
+// seedProduct creates a product with the given stock the way products are created, with its opening stock
+// recorded and put in the first warehouse if there is one
+func seedProduct(t *testing.T, p *base.Persistence, stock int64) entity.Product {
+	t.Helper()
+	name := fmt.Sprintf("stock test %d", time.Now().UnixNano())
+	category := entity.Category{Name: name}
+	product := entity.Product{Name: name, Price: 10, Stock: stock}
+	err := p.GormDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&category).Error; err != nil {
+			return err
+		}
+		product.CategoryID = int64(category.ID)
+		if err := tx.Create(&product).Error; err != nil {
+			return err
+		}
+		return recordOpeningStock(newTestContext(), p, tx, nil, product.ID, nil, stock)
+	})
+	if err != nil {
+		t.Fatalf("error creating product: %v", err)
+	}
+	return product
+}
+
+// runConcurrently starts every call at the same time and returns their errors once all of them are done
+func runConcurrently(n int, call func(i int) error) []error {
+	errs := make([]error, n)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			errs[i] = call(i)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	return errs
+}
+
+// countSucceeded counts the calls that succeeded and fails the test on any error other than a rejected request,
+// which is how a reservation beyond the stock left is reported
+func countSucceeded(t *testing.T, errs []error) int {
+	t.Helper()
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		var appErr *payload.AppError
+		if !errors.As(err, &appErr) || appErr.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected an out of stock error, got: %v", err)
+		}
+	}
+	return succeeded
+}
+
+func stockOfProduct(t *testing.T, p *base.Persistence, productID uint) int64 {
+	t.Helper()
+	var product entity.Product
+	if err := p.GormDB.First(&product, productID).Error; err != nil {
+		t.Fatalf("error reading product: %v", err)
+	}
+	return product.Stock
+}
+
+func orderedQuantity(t *testing.T, p *base.Persistence, productID uint) int64 {
+	t.Helper()
+	var quantity int64
+	err := p.GormDB.Model(&entity.OrderItem{}).
+		Where("product_id = ?", productID).
+		Select("COALESCE(SUM(quantity), 0)").
+		Scan(&quantity).Error
+	if err != nil {
+		t.Fatalf("error reading order items: %v", err)
+	}
+	return quantity
+}
+
+func TestCreateOrderNeverOversells(t *testing.T) {
+	p := openTestPersistence(t)
+	requester := seedCustomer(t, p)
+	const stock, buyers = 5, 20
+	product := seedProduct(t, p, stock)
+
+	errs := runConcurrently(buyers, func(int) error {
+		_, err := NewOrderUsecase(forRequest(p)).CreateOrder(newTestContext(), requester, &payload.CreateOrderRequest{
+			OrderItems: []payload.OrderItemRequest{{ProductID: product.ID, Quantity: 1}},
+		})
+		return err
+	})
+
+	if succeeded := countSucceeded(t, errs); succeeded != stock {
+		t.Errorf("expected %d orders to succeed, got %d", stock, succeeded)
+	}
+	if left := stockOfProduct(t, p, product.ID); left != 0 {
+		t.Errorf("expected the stock to be sold out, got %d", left)
+	}
+	if ordered := orderedQuantity(t, p, product.ID); ordered != stock {
+		t.Errorf("expected %d units to be ordered, got %d", stock, ordered)
+	}
+}
+
+func TestUpdateOrderItemNeverOversells(t *testing.T) {
+	p := openTestPersistence(t)
+	requester := seedCustomer(t, p)
+	const stock, buyers = 10, 8
+	product := seedProduct(t, p, stock)
+
+	items := make([]entity.OrderItem, buyers)
+	for i := range items {
+		order, err := NewOrderUsecase(p).CreateOrder(newTestContext(), requester, &payload.CreateOrderRequest{
+			OrderItems: []payload.OrderItemRequest{{ProductID: product.ID, Quantity: 1}},
+		})
+		if err != nil {
+			t.Fatalf("error creating order: %v", err)
+		}
+		items[i] = order.OrderItems[0]
+	}
+
+	// every buyer wants one more unit while only stock - buyers units are left
+	errs := runConcurrently(buyers, func(i int) error {
+		item := entity.OrderItem{Model: gorm.Model{ID: items[i].ID}, OrderID: items[i].OrderID, ProductID: product.ID, Quantity: 2}
+		_, err := NewOrderItemUsecase(forRequest(p)).UpdateOrderItem(newTestContext(), requester, []entity.OrderItem{item})
+		return err
+	})
+
+	if succeeded := countSucceeded(t, errs); succeeded != stock-buyers {
+		t.Errorf("expected %d updates to succeed, got %d", stock-buyers, succeeded)
+	}
+	if left := stockOfProduct(t, p, product.ID); left != 0 {
+		t.Errorf("expected the stock to be sold out, got %d", left)
+	}
+	if ordered := orderedQuantity(t, p, product.ID); ordered != stock {
+		t.Errorf("expected %d units to be ordered, got %d", stock, ordered)
+	}
+}
+
+func TestUpdateOrderItemRejectsQuantityBelowOne(t *testing.T) {
+	// the quantity is rejected before the database is ever used
+	p := &base.Persistence{Ctx: context.Background(), Logger: newTestLogger()}
+
+	for _, quantity := range []int{0, -100} {
+		item := entity.OrderItem{Model: gorm.Model{ID: 1}, OrderID: 1, ProductID: 1, Quantity: quantity}
+		_, err := NewOrderItemUsecase(p).UpdateOrderItem(newTestContext(), entity.Requester{UserID: 1, RoleID: entity.RoleUser}, []entity.OrderItem{item})
+
+		var appErr *payload.AppError
+		if !errors.As(err, &appErr) || appErr.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected quantity %d to be rejected, got: %v", quantity, err)
+		}
+	}
+}
\ No newline at end of file
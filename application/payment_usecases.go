@@ -0,0 +1,431 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"pm/domain/entity"
+	paymentRepo "pm/domain/repository/payments"
+	"pm/infrastructure/config"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/implementations/orders"
+	"pm/infrastructure/implementations/payments"
+	"pm/infrastructure/persistences/base"
+	"time"
+)
+
+type PaymentUsecase interface {
+	InitiatePayment(c *gin.Context, requester entity.Requester, orderID int64, provider string) (*entity.Payment, *paymentRepo.GatewayResult, error)
+	CapturePayment(c *gin.Context, requester entity.Requester, orderID int64, paymentID int64) (*entity.Payment, error)
+	HandleCallback(c *gin.Context, provider string, body []byte, signature string) (*entity.Payment, error)
+	GetPaymentsByOrderID(c *gin.Context, requester entity.Requester, orderID int64) ([]entity.Payment, error)
+}
+
+type paymentUsecase struct {
+	p          *base.Persistence
+	cfg        config.PaymentConfig
+	invoiceCfg config.InvoiceConfig
+}
+
+func NewPaymentUsecase(p *base.Persistence) PaymentUsecase {
+	return paymentUsecase{p, paymentConfig(), invoiceConfig()}
+}
+
+func paymentConfig() config.PaymentConfig {
+	var cfg config.PaymentConfig
+	if config.Configs != nil {
+		cfg = config.Configs.PaymentConfig
+	}
+	return cfg
+}
+
+// InitiatePayment opens a payment for the grand total of the order on the given gateway, or on the default one
+// when provider is empty. An order can only have one payment in flight or captured at a time
+func (pu paymentUsecase) InitiatePayment(c *gin.Context, requester entity.Requester, orderID int64, provider string) (*entity.Payment, *paymentRepo.GatewayResult, error) {
+	span := pu.p.Logger.Start(c, "INITIATE_PAYMENT: USECASES", pu.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	pu.p.Logger.Info("STARTING: INITIATE_PAYMENT", map[string]interface{}{"order_id": orderID, "provider": provider})
+
+	if provider == "" {
+		provider = pu.cfg.Gateway
+	}
+	gateway, err := payments.NewPaymentGateway(provider, pu.cfg)
+	if err != nil {
+		pu.p.Logger.Error("INITIATE_PAYMENT: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, nil, payload.ErrInvalidRequest(err)
+	}
+
+	var payment *entity.Payment
+	err = pu.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		orderRepo := orders.NewOrderRepository(c, pu.p, tx)
+		if err := orderRepo.LockOrder(orderID); err != nil {
+			return err
+		}
+		order, err := orderRepo.GetOrderByID(orderID)
+		if err != nil {
+			return err
+		}
+		if err := authorizeOrder(requester, order); err != nil {
+			return err
+		}
+		if !order.CanTransitionTo(entity.OrderStatusPaid) {
+			return payload.ErrInvalidOrderTransition(order.Status, entity.OrderStatusPaid)
+		}
+
+		repo := payments.NewPaymentRepository(c, pu.p, tx)
+		existing, err := repo.GetPaymentsByOrderID(orderID)
+		if err != nil {
+			return err
+		}
+		for _, v := range existing {
+			if v.IsActive() {
+				return payload.ErrInvalidRequest(fmt.Errorf("order [%d] already has the payment [%d] in status %s", orderID, v.ID, v.Status))
+			}
+		}
+
+		payment = &entity.Payment{
+			OrderID:  order.ID,
+			Provider: gateway.Name(),
+			Amount:   order.GrandTotal,
+			Currency: pu.cfg.Currency,
+			Status:   entity.PaymentStatusPending,
+		}
+		if err := repo.Create(payment); err != nil {
+			return err
+		}
+		return recordOrderEvent(c, pu.p, tx, order.ID, requester.ActorID(), entity.OrderEventPaymentInitiated, nil, payment.Snapshot())
+	})
+	if err != nil {
+		pu.p.Logger.Error("INITIATE_PAYMENT: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, nil, err
+	}
+
+	// the gateway is called outside of the transaction so a slow gateway never holds the lock on the order
+	before := payment.Snapshot()
+	result, err := gateway.Initiate(payment)
+	if err != nil {
+		payment.Status = entity.PaymentStatusFailed
+		payment.FailureReason = err.Error()
+		if errU := pu.updatePayment(c, pu.p.GormDB, requester.ActorID(), payment, before); errU != nil {
+			pu.p.Logger.Error("INITIATE_PAYMENT: ERROR", map[string]interface{}{"error": errU.Error()})
+		}
+		pu.p.Logger.Error("INITIATE_PAYMENT: ERROR GATEWAY", map[string]interface{}{"error": err.Error()})
+		return nil, nil, payload.ErrInternal(err)
+	}
+	payment.ProviderRef = result.ProviderRef
+	payment.Status = result.Status
+	payment.FailureReason = result.FailureReason
+	if err := pu.updatePayment(c, pu.p.GormDB, requester.ActorID(), payment, before); err != nil {
+		pu.p.Logger.Error("INITIATE_PAYMENT: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, nil, err
+	}
+
+	pu.p.Logger.Info("INITIATE_PAYMENT: SUCCESSFULLY", map[string]interface{}{"payment": payment})
+	return payment, result, nil
+}
+
+// CapturePayment takes the money of an authorized payment and moves its order to paid. The payment is moved to
+// capturing under the lock of its order first, so a second capture or the cancellation of the order cannot run
+// alongside, then the gateway is called outside of any transaction and the capture is finished in a second one
+func (pu paymentUsecase) CapturePayment(c *gin.Context, requester entity.Requester, orderID int64, paymentID int64) (*entity.Payment, error) {
+	span := pu.p.Logger.Start(c, "CAPTURE_PAYMENT: USECASES", pu.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	pu.p.Logger.Info("STARTING: CAPTURE_PAYMENT", map[string]interface{}{"order_id": orderID, "payment_id": paymentID})
+
+	var payment *entity.Payment
+	var gateway paymentRepo.PaymentGateway
+	err := pu.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		orderRepo := orders.NewOrderRepository(c, pu.p, tx)
+		if err := orderRepo.LockOrder(orderID); err != nil {
+			return err
+		}
+		var err error
+		payment, err = payments.NewPaymentRepository(c, pu.p, tx).GetPaymentByID(paymentID)
+		if err != nil {
+			return err
+		}
+		if int64(payment.OrderID) != orderID {
+			return payload.ErrEntityNotFound("payments", fmt.Errorf("payment [%d] does not belong to order [%d]", paymentID, orderID))
+		}
+		switch payment.Status {
+		case entity.PaymentStatusCaptured:
+			return nil
+		case entity.PaymentStatusCapturing:
+			return payload.ErrInvalidRequest(fmt.Errorf("payment [%d] is being captured already", paymentID))
+		case entity.PaymentStatusAuthorized:
+		default:
+			return payload.ErrInvalidRequest(fmt.Errorf("only authorized payments can be captured, payment [%d] is %s", paymentID, payment.Status))
+		}
+		order, err := orderRepo.GetOrderByID(orderID)
+		if err != nil {
+			return err
+		}
+		if !order.CanTransitionTo(entity.OrderStatusPaid) {
+			return payload.ErrInvalidOrderTransition(order.Status, entity.OrderStatusPaid)
+		}
+		if gateway, err = payments.NewPaymentGateway(payment.Provider, pu.cfg); err != nil {
+			return payload.ErrInternal(err)
+		}
+
+		before := payment.Snapshot()
+		payment.Status = entity.PaymentStatusCapturing
+		payment.FailureReason = ""
+		return pu.updatePayment(c, tx, requester.ActorID(), payment, before)
+	})
+	if err != nil {
+		pu.p.Logger.Error("CAPTURE_PAYMENT: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	if payment.Status == entity.PaymentStatusCaptured {
+		return payment, nil
+	}
+
+	before := payment.Snapshot()
+	result, err := gateway.Capture(payment)
+	if err != nil {
+		// the gateway may have taken the money before failing, the payment stays capturing until its callback tells
+		payment.FailureReason = err.Error()
+		if errU := pu.updatePayment(c, pu.p.GormDB, requester.ActorID(), payment, before); errU != nil {
+			pu.p.Logger.Error("CAPTURE_PAYMENT: ERROR", map[string]interface{}{"error": errU.Error()})
+		}
+		pu.p.Logger.Error("CAPTURE_PAYMENT: ERROR GATEWAY", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInternal(err)
+	}
+	if result.Status != entity.PaymentStatusCaptured {
+		// nothing was taken, the payment is still authorized and can be captured again
+		payment.Status = entity.PaymentStatusAuthorized
+		payment.FailureReason = result.FailureReason
+		if errU := pu.updatePayment(c, pu.p.GormDB, requester.ActorID(), payment, before); errU != nil {
+			pu.p.Logger.Error("CAPTURE_PAYMENT: ERROR", map[string]interface{}{"error": errU.Error()})
+		}
+		err := payload.ErrInvalidRequest(fmt.Errorf("the gateway refused to capture payment [%d]: %s", paymentID, result.FailureReason))
+		pu.p.Logger.Error("CAPTURE_PAYMENT: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	var invoice *entity.Invoice
+	err = pu.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		if err := orders.NewOrderRepository(c, pu.p, tx).LockOrder(orderID); err != nil {
+			return err
+		}
+		var err error
+		if payment, err = payments.NewPaymentRepository(c, pu.p, tx).GetPaymentByID(paymentID); err != nil {
+			return err
+		}
+		// the callback of the gateway may have finished the capture already
+		if payment.Status == entity.PaymentStatusCaptured {
+			return nil
+		}
+		invoice, err = pu.markCaptured(c, tx, requester.ActorID(), payment)
+		return err
+	})
+	if err != nil {
+		// the money is taken, the payment stays capturing and the callback of the gateway finishes the capture
+		pu.p.Logger.Error("CAPTURE_PAYMENT: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	pu.deliverInvoice(c, invoice)
+
+	pu.p.Logger.Info("CAPTURE_PAYMENT: SUCCESSFULLY", map[string]interface{}{"payment": payment})
+	return payment, nil
+}
+
+// HandleCallback applies a signed notification of a gateway to its payment. Gateways may send the same
+// notification more than once, so a status the payment already has is ignored
+func (pu paymentUsecase) HandleCallback(c *gin.Context, provider string, body []byte, signature string) (*entity.Payment, error) {
+	span := pu.p.Logger.Start(c, "PAYMENT_CALLBACK: USECASES", pu.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	pu.p.Logger.Info("STARTING: PAYMENT_CALLBACK", map[string]interface{}{"provider": provider})
+
+	gateway, err := payments.NewPaymentGateway(provider, pu.cfg)
+	if err != nil {
+		pu.p.Logger.Error("PAYMENT_CALLBACK: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrEntityNotFound("payment gateway", err)
+	}
+	event, err := gateway.ParseCallback(body, signature)
+	if err != nil {
+		pu.p.Logger.Error("PAYMENT_CALLBACK: ERROR", map[string]interface{}{"error": err.Error()})
+		if errors.Is(err, entity.ErrInvalidPaymentSignature) {
+			return nil, payload.ErrInvalidSignature(err)
+		}
+		return nil, payload.ErrInvalidRequest(err)
+	}
+	// every callback has to state its amount, the payment is only moved when the gateway handled what it was opened for
+	if event.Amount <= 0 {
+		err := errors.New("the callback has no amount")
+		pu.p.Logger.Error("PAYMENT_CALLBACK: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInvalidRequest(err)
+	}
+
+	var payment *entity.Payment
+	var invoice *entity.Invoice
+	err = pu.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		repo := payments.NewPaymentRepository(c, pu.p, tx)
+		payment, err = repo.GetPaymentByProviderRef(gateway.Name(), event.ProviderRef)
+		if err != nil {
+			return err
+		}
+		// the payment is read again under the lock of its order, a capture or a cancellation may be changing it
+		if err := orders.NewOrderRepository(c, pu.p, tx).LockOrder(int64(payment.OrderID)); err != nil {
+			return err
+		}
+		if payment, err = repo.GetPaymentByID(int64(payment.ID)); err != nil {
+			return err
+		}
+		if entity.RoundMoney(event.Amount) != payment.Amount {
+			return payload.ErrInvalidRequest(fmt.Errorf("the callback amount %v does not match the payment amount %v", event.Amount, payment.Amount))
+		}
+
+		switch event.Status {
+		case entity.PaymentStatusAuthorized:
+			if payment.Status != entity.PaymentStatusPending {
+				return nil
+			}
+			before := payment.Snapshot()
+			payment.Status = entity.PaymentStatusAuthorized
+			return pu.updatePayment(c, tx, nil, payment, before)
+		case entity.PaymentStatusCaptured:
+			if payment.Status == entity.PaymentStatusCaptured {
+				return nil
+			}
+			if payment.Status == entity.PaymentStatusFailed || payment.Status == entity.PaymentStatusVoided {
+				return payload.ErrInvalidRequest(fmt.Errorf("payment [%d] is %s already", payment.ID, payment.Status))
+			}
+			invoice, err = pu.markCaptured(c, tx, nil, payment)
+			return err
+		case entity.PaymentStatusFailed:
+			switch payment.Status {
+			case entity.PaymentStatusPending, entity.PaymentStatusAuthorized, entity.PaymentStatusCapturing:
+			default:
+				return nil
+			}
+			before := payment.Snapshot()
+			payment.Status = entity.PaymentStatusFailed
+			payment.FailureReason = event.FailureReason
+			return pu.updatePayment(c, tx, nil, payment, before)
+		default:
+			return payload.ErrInvalidRequest(fmt.Errorf("unknown payment status [%s]", event.Status))
+		}
+	})
+	if err != nil {
+		pu.p.Logger.Error("PAYMENT_CALLBACK: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	pu.deliverInvoice(c, invoice)
+
+	pu.p.Logger.Info("PAYMENT_CALLBACK: SUCCESSFULLY", map[string]interface{}{"payment": payment})
+	return payment, nil
+}
+
+func (pu paymentUsecase) GetPaymentsByOrderID(c *gin.Context, requester entity.Requester, orderID int64) ([]entity.Payment, error) {
+	span := pu.p.Logger.Start(c, "GET_PAYMENTS_BY_ORDER_ID: USECASES", pu.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	order, err := orders.NewOrderRepository(c, pu.p, pu.p.GormDB).GetOrderByID(orderID)
+	if err != nil {
+		pu.p.Logger.Error("GET_PAYMENTS_BY_ORDER_ID: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	if err := authorizeOrder(requester, order); err != nil {
+		pu.p.Logger.Error("GET_PAYMENTS_BY_ORDER_ID: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	listPayments, err := payments.NewPaymentRepository(c, pu.p, pu.p.GormDB).GetPaymentsByOrderID(orderID)
+	if err != nil {
+		pu.p.Logger.Error("GET_PAYMENTS_BY_ORDER_ID: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return listPayments, nil
+}
+
+// markCaptured saves the payment as captured and moves its order to paid, which issues the invoice of the order. The
+// order must still be able to get paid
+func (pu paymentUsecase) markCaptured(c *gin.Context, tx *gorm.DB, actorID *uint, payment *entity.Payment) (*entity.Invoice, error) {
+	before := payment.Snapshot()
+	now := time.Now()
+	payment.Status = entity.PaymentStatusCaptured
+	payment.CapturedAt = &now
+	if err := pu.updatePayment(c, tx, actorID, payment, before); err != nil {
+		return nil, err
+	}
+
+	order, err := orders.NewOrderRepository(c, pu.p, tx).GetOrderByID(int64(payment.OrderID))
+	if err != nil {
+		return nil, err
+	}
+	return transitionOrder(c, pu.p, tx, actorID, order, entity.OrderStatusPaid)
+}
+
+// paymentsToVoid returns the authorized payments of an order about to be cancelled, voidPayments releases them once
+// the cancellation is committed. The order must be locked, a payment being captured keeps it from being cancelled
+func paymentsToVoid(c *gin.Context, p *base.Persistence, tx *gorm.DB, orderID int64) ([]entity.Payment, error) {
+	orderPayments, err := payments.NewPaymentRepository(c, p, tx).GetPaymentsByOrderID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	authorized := make([]entity.Payment, 0)
+	for _, payment := range orderPayments {
+		switch payment.Status {
+		case entity.PaymentStatusCapturing:
+			return nil, payload.ErrInvalidRequest(fmt.Errorf("the payment [%d] of order [%d] is being captured", payment.ID, orderID))
+		case entity.PaymentStatusAuthorized:
+			authorized = append(authorized, payment)
+		}
+	}
+	return authorized, nil
+}
+
+// voidPayments releases on their gateway the authorized payments of an order that got cancelled. It runs once the
+// cancellation is committed, a payment the gateway could not void stays authorized with the reason and expires on
+// the gateway
+func voidPayments(c *gin.Context, p *base.Persistence, actorID *uint, authorized []entity.Payment) {
+	pu := paymentUsecase{p, paymentConfig(), invoiceConfig()}
+	for i := range authorized {
+		payment := &authorized[i]
+		before := payment.Snapshot()
+		gateway, err := payments.NewPaymentGateway(payment.Provider, pu.cfg)
+		var result *paymentRepo.GatewayResult
+		if err == nil {
+			result, err = gateway.Void(payment)
+		}
+		switch {
+		case err != nil:
+			payment.FailureReason = err.Error()
+		case result.Status != entity.PaymentStatusVoided:
+			payment.FailureReason = result.FailureReason
+		default:
+			payment.Status = entity.PaymentStatusVoided
+			payment.FailureReason = ""
+		}
+		if payment.Status != entity.PaymentStatusVoided {
+			p.Logger.Error("VOID_PAYMENT: ERROR", map[string]interface{}{"payment_id": payment.ID, "error": payment.FailureReason})
+		}
+		if err := pu.updatePayment(c, p.GormDB, actorID, payment, before); err != nil {
+			p.Logger.Error("VOID_PAYMENT: ERROR", map[string]interface{}{"payment_id": payment.ID, "error": err.Error()})
+		}
+	}
+}
+
+// deliverInvoice sends the invoice issued when a payment got captured, a failed delivery does not fail the payment
+func (pu paymentUsecase) deliverInvoice(c *gin.Context, invoice *entity.Invoice) {
+	if invoice == nil {
+		return
+	}
+	if err := deliverInvoice(c, pu.p, pu.invoiceCfg, invoice); err != nil {
+		pu.p.Logger.Error("DELIVER_INVOICE: ERROR", map[string]interface{}{"number": invoice.Number, "error": err.Error()})
+	}
+}
+
+// updatePayment saves the payment and records how it changed from before in the history of its order, a nil
+// actor stands for the payment gateway
+func (pu paymentUsecase) updatePayment(c *gin.Context, db *gorm.DB, actorID *uint, payment *entity.Payment, before entity.Snapshot) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := payments.NewPaymentRepository(c, pu.p, tx).Update(payment); err != nil {
+			return err
+		}
+		return recordOrderEvent(c, pu.p, tx, payment.OrderID, actorID, entity.OrderEventPaymentUpdated, before, payment.Snapshot())
+	})
+}
\ No newline at end of file
@@ -1,17 +1,23 @@
 package application
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 	"math"
 	"pm/domain/entity"
+	"pm/infrastructure/config"
 	"pm/infrastructure/controllers/payload"
 	"pm/infrastructure/implementations/files"
 	"pm/infrastructure/implementations/products"
+	"pm/infrastructure/implementations/suggestions"
+	"pm/infrastructure/jobs"
 	"pm/infrastructure/mapper"
 	"pm/infrastructure/persistences/base"
 	"pm/utils"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -19,14 +25,20 @@ import (
 const (
 	entityName   string = "products"
 	redisHashKey        = "products"
+
+	defaultSuggestionLimit = 10
+	maxSuggestionLimit     = 50
 )
 
 type ProductUsecase interface {
-	CreateProduct(*gin.Context, *payload.CreateProductRequest) error
+	CreateProduct(*gin.Context, entity.Requester, *payload.CreateProductRequest) error
 	GetAllProducts(*gin.Context, *entity.ProductFilter, *entity.Pagination) ([]entity.Product, error)
+	GetProductFacets(*gin.Context, *entity.ProductFilter) (*entity.ProductFacets, error)
+	SearchProducts(*gin.Context, *entity.ProductSearch, *entity.Pagination) ([]entity.Product, error)
+	SuggestProducts(*gin.Context, *entity.SuggestionQuery) ([]entity.Suggestion, error)
 	GetProductByID(*gin.Context, int64) (*entity.Product, error)
 	DeleteProductByID(*gin.Context, int64) error
-	UpdateProductByID(*gin.Context, int64, *payload.UpdateProductRequest) (*entity.Product, error)
+	UpdateProductByID(*gin.Context, int64, entity.Requester, *payload.UpdateProductRequest) (*entity.Product, error)
 	Report() error
 }
 type productUsecase struct {
@@ -37,7 +49,8 @@ func NewProductUsecase(p *base.Persistence) ProductUsecase {
 	return productUsecase{p}
 }
 
-func (p productUsecase) CreateProduct(c *gin.Context, reqPayload *payload.CreateProductRequest) error {
+// CreateProduct creates the product and records the stock it starts with as its opening stock movement
+func (p productUsecase) CreateProduct(c *gin.Context, requester entity.Requester, reqPayload *payload.CreateProductRequest) error {
 	span := p.p.Logger.Start(c, "CREATE_PRODUCT: USECASES", p.p.Logger.SetContextWithSpanFunc())
 	defer span.End()
 	p.p.Logger.Info("STARTING: CREATE_PRODUCT", map[string]interface{}{"data": reqPayload})
@@ -46,15 +59,25 @@ func (p productUsecase) CreateProduct(c *gin.Context, reqPayload *payload.Create
 		p.p.Logger.Error("CREATE_PRODUCT: ERROR VALIDATE REQUEST DATA", map[string]interface{}{"error": err.Error()})
 		return payload.ErrValidateFailed(err)
 	}
+	if err := checkTaxClass(c, p.p, reqPayload.TaxClassID); err != nil {
+		p.p.Logger.Error("CREATE_PRODUCT: ERROR VALIDATE REQUEST DATA", map[string]interface{}{"error": err.Error()})
+		return err
+	}
 
 	prod := mapper.PayloadToProduct(reqPayload)
 	productRepo := products.NewProductRepository(c, p.p, p.p.GormDB)
-	err := productRepo.Create(span, prod)
+	err := p.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		if err := products.NewProductRepository(c, p.p, tx).Create(span, prod); err != nil {
+			return err
+		}
+		return recordOpeningStock(c, p.p, tx, requester.ActorID(), prod.ID, nil, prod.Stock)
+	})
 	if err != nil {
 		p.p.Logger.Error("CREATE_PRODUCT: FAILED", map[string]interface{}{"error": err.Error()})
 		return err
 	}
 
+	go indexSuggestion(p.p, entity.ProductSuggestion(prod))
 	go func(prod *entity.Product) {
 		logger, err := zap.NewProduction()
 		if err != nil {
@@ -95,22 +118,25 @@ func (p productUsecase) GetAllProducts(c *gin.Context, filter *entity.ProductFil
 
 	productRepo := products.NewProductRepository(c, p.p, p.p.GormDB)
 	prods := make([]entity.Product, 0)
-	if filter.IsNil() == true {
+	// the cached products are only ever listed by page number in the default order, by id
+	if filter.IsNil() == true && pagination.Sort == "" && !pagination.IsCursor() {
 		productsMap := make(map[string]entity.Product)
 		utils.GetAllHashGeneric(redisHashKey, &productsMap)
-		prods := make([]entity.Product, 0)
 		if len(productsMap) != 0 {
 			prods = prodsMapToArray(productsMap)
-			_prods := prods[:pagination.GetOffset()-1]
-			_prods = _prods[:pagination.GetLimit()-1]
-			pagination = &entity.Pagination{
-				Limit:      pagination.GetLimit(),
-				Page:       pagination.GetPage(),
-				Sort:       "",
-				TotalRows:  int64(len(prods)),
-				TotalPages: int(math.Ceil(float64(len(prods) * 1.0 / pagination.GetLimit()))),
+			sort.Slice(prods, func(i, j int) bool {
+				return prods[i].ID < prods[j].ID
+			})
+			pagination.TotalRows = int64(len(prods))
+			pagination.TotalPages = int(math.Ceil(float64(len(prods)) / float64(pagination.GetLimit())))
+			start := min(max(pagination.GetOffset(), 0), len(prods))
+			end := min(start+max(pagination.GetLimit(), 0), len(prods))
+			prods = prods[start:end]
+			if err := utils.SetCursors(p.p.GormDB, pagination, entity.ProductSortColumns, &prods); err != nil {
+				p.p.Logger.Error("GET_ALL_PRODUCTS: ERROR", map[string]interface{}{"error": err.Error()})
+				return nil, payload.ErrInternal(err)
 			}
-			p.p.Logger.Info("GET_ALL_PRODUCTS: SUCCESSFULLY", map[string]interface{}{"data": _prods})
+			p.p.Logger.Info("GET_ALL_PRODUCTS: SUCCESSFULLY", map[string]interface{}{"data": prods})
 			return prods, nil
 		}
 	}
@@ -124,6 +150,83 @@ func (p productUsecase) GetAllProducts(c *gin.Context, filter *entity.ProductFil
 	return prods, nil
 }
 
+// GetProductFacets counts the products the filter could be narrowed to by every category, configured price bucket,
+// option value and by stock. It always goes to the database, the cached products are not filtered
+func (p productUsecase) GetProductFacets(c *gin.Context, filter *entity.ProductFilter) (*entity.ProductFacets, error) {
+	span := p.p.Logger.Start(c, "GET_PRODUCT_FACETS: USECASES", p.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	p.p.Logger.Info("STARTING: GET_PRODUCT_FACETS", map[string]interface{}{"filter": filter})
+
+	var bounds []float64
+	if config.Configs != nil {
+		bounds = config.Configs.CatalogConfig.PriceBuckets
+	}
+	facets, err := products.NewProductRepository(c, p.p, p.p.GormDB).GetProductFacets(span, filter, entity.PriceBuckets(bounds))
+	if err != nil {
+		p.p.Logger.Error("GET_PRODUCT_FACETS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	p.p.Logger.Info("GET_PRODUCT_FACETS: SUCCESSFULLY", map[string]interface{}{"facets": facets})
+	return facets, nil
+}
+
+// SearchProducts returns the products matching the words of the search, the most relevant first. It always goes to the
+// database, the cached products cannot be ranked. Results are paged by page number only, a cursor cannot hold the
+// rank of a row as the rank depends on the search
+func (p productUsecase) SearchProducts(c *gin.Context, search *entity.ProductSearch, pagination *entity.Pagination) ([]entity.Product, error) {
+	span := p.p.Logger.Start(c, "SEARCH_PRODUCTS: USECASES", p.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	p.p.Logger.Info("STARTING: SEARCH_PRODUCTS", map[string]interface{}{"search": search, "pagination": pagination})
+
+	if utils.NormalizeSearchText(search.Query) == "" {
+		err := errors.New("param [q] is required")
+		p.p.Logger.Error("SEARCH_PRODUCTS: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrParamRequired(err)
+	}
+	if pagination.IsCursor() {
+		err := errors.New("the search is paged by page number, it has no cursors")
+		p.p.Logger.Error("SEARCH_PRODUCTS: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInvalidRequest(err)
+	}
+	prods, err := products.NewProductRepository(c, p.p, p.p.GormDB).SearchProducts(span, search, pagination)
+	if err != nil {
+		p.p.Logger.Error("SEARCH_PRODUCTS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	p.p.Logger.Info("SEARCH_PRODUCTS: SUCCESSFULLY", map[string]interface{}{"products": len(prods)})
+	return prods, nil
+}
+
+// SuggestProducts returns the products and categories to offer while the search is typed, typos included. It reads
+// the suggestion index on redis only, the index is kept up to date as products and categories change
+func (p productUsecase) SuggestProducts(c *gin.Context, query *entity.SuggestionQuery) ([]entity.Suggestion, error) {
+	span := p.p.Logger.Start(c, "SUGGEST_PRODUCTS: USECASES", p.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	p.p.Logger.Info("STARTING: SUGGEST_PRODUCTS", map[string]interface{}{"query": query})
+
+	if utils.NormalizeSearchText(query.Query) == "" {
+		err := errors.New("param [q] is required")
+		p.p.Logger.Error("SUGGEST_PRODUCTS: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrParamRequired(err)
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultSuggestionLimit
+	}
+	limit = min(limit, maxSuggestionLimit)
+
+	list, err := suggestions.NewSuggestionRepository(p.p.Redis.RedisDB, p.p.Ctx).Suggest(query.Query, limit)
+	if err != nil {
+		p.p.Logger.Error("SUGGEST_PRODUCTS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInternal(err)
+	}
+
+	p.p.Logger.Info("SUGGEST_PRODUCTS: SUCCESSFULLY", map[string]interface{}{"suggestions": len(list)})
+	return list, nil
+}
+
 func (p productUsecase) GetProductByID(c *gin.Context, id int64) (*entity.Product, error) {
 	span := p.p.Logger.Start(c, "GET_PRODUCT_BY_ID: USECASES", p.p.Logger.SetContextWithSpanFunc())
 	defer span.End()
@@ -175,12 +278,15 @@ func (p productUsecase) DeleteProductByID(c *gin.Context, id int64) error {
 		p.p.Logger.Info("DELETE_PRODUCT: ERROR", map[string]interface{}{"error": err.Error()})
 		return err
 	}
+	go removeSuggestion(p.p, entity.SuggestionKindProduct, prod.ID)
 
 	p.p.Logger.Info("DELETE_PRODUCT: SUCCESSFULLY", map[string]interface{}{})
 	return nil
 }
 
-func (p productUsecase) UpdateProductByID(c *gin.Context, id int64, updatePayload *payload.UpdateProductRequest) (*entity.Product, error) {
+// UpdateProductByID saves the product, a change of its stock is recorded as a manual adjustment. The stock of a
+// product with variants lives on its variants, so it is left as it is
+func (p productUsecase) UpdateProductByID(c *gin.Context, id int64, requester entity.Requester, updatePayload *payload.UpdateProductRequest) (*entity.Product, error) {
 	span := p.p.Logger.Start(c, "UPDATE_PRODUCT: USECASES", p.p.Logger.SetContextWithSpanFunc())
 	defer span.End()
 	p.p.Logger.Info("STARTING: UPDATE_PRODUCT", map[string]interface{}{"data": struct {
@@ -194,6 +300,10 @@ func (p productUsecase) UpdateProductByID(c *gin.Context, id int64, updatePayloa
 		p.p.Logger.Error("UPDATE_PRODUCT: ERROR VALIDATE REQUEST DATA", map[string]interface{}{"error": err.Error()})
 		return nil, payload.ErrInvalidRequest(err)
 	}
+	if err := checkTaxClass(c, p.p, updatePayload.TaxClassID); err != nil {
+		p.p.Logger.Error("UPDATE_PRODUCT: ERROR VALIDATE REQUEST DATA", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
 
 	productRepo := products.NewProductRepository(c, p.p, p.p.GormDB)
 	prod, err := productRepo.GetProductByID(span, id)
@@ -203,16 +313,39 @@ func (p productUsecase) UpdateProductByID(c *gin.Context, id int64, updatePayloa
 	}
 	updatePayload.ID = id
 	mapper.UpdateProduct(prod, updatePayload)
-	_, err = productRepo.Update(span, prod)
+	err = p.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		txProductRepo := products.NewProductRepository(c, p.p, tx)
+		// the stock read above may be behind orders placed since, the delta is taken from the locked row so the
+		// product ends up with the stock asked for and its ledger records the move that really happened
+		locked, err := txProductRepo.GetProductByIDForUpdate(span, id)
+		if err != nil {
+			return err
+		}
+		prod.Stock = locked.Stock
+		delta := updatePayload.Stock - locked.Stock
+		if locked.HasVariants() {
+			delta = 0
+		}
+		if _, err := txProductRepo.Update(span, prod); err != nil {
+			return payload.ErrCannotUpdateEntity(entityName, err)
+		}
+		cause := entity.StockCause{Reason: entity.StockReasonAdjustment, Reference: entity.StockReferenceManual, ActorID: requester.ActorID()}
+		adjusted, err := adjustStock(c, p.p, tx, span, cause, nil, entity.OrderItem{ProductID: prod.ID}, delta)
+		if adjusted != nil {
+			prod.Stock = adjusted.Stock
+		}
+		return err
+	})
 	if err != nil {
 		p.p.Logger.Error("UPDATE_PRODUCT: ERROR", map[string]interface{}{"error": err.Error()})
-		return nil, payload.ErrCannotUpdateEntity(entityName, err)
+		return nil, err
 	}
 
 	err = utils.RedisSetHashGenericKey(redisHashKey, strconv.FormatInt(int64(prod.ID), 10), prod, p.p.Redis.KeyExpirationTime)
 	if err != nil {
 		fmt.Printf("error updating product: ID: %v - error: %v", id, err)
 	}
+	go indexSuggestion(p.p, entity.ProductSuggestion(prod))
 
 	p.p.Logger.Error("UPDATE_PRODUCT: SUCCESSFULLY", map[string]interface{}{"product": prod})
 	return prod, nil
@@ -229,4 +362,30 @@ func prodsMapToArray(prodsMap map[string]entity.Product) []entity.Product {
 		arrProds = append(arrProds, v)
 	}
 	return arrProds
+}
+
+// cacheProducts writes the given products to the products hash on redis, reloading the whole hash when a write fails
+func cacheProducts(p *base.Persistence, prods []entity.Product) {
+	for _, prod := range prods {
+		err := utils.RedisSetHashGenericKey(redisHashKey, strconv.FormatInt(int64(prod.ID), 10), prod, p.Redis.KeyExpirationTime)
+		if err != nil {
+			fmt.Printf("error updating product on redis: ID: %v - error: %v", prod.ID, err)
+			go jobs.LoadProductToRedis(p)
+			return
+		}
+	}
+}
+
+// indexSuggestion and removeSuggestion keep the suggestion index up to date, a failure only leaves it stale until the
+// next rebuild
+func indexSuggestion(p *base.Persistence, s entity.Suggestion) {
+	if err := suggestions.NewSuggestionRepository(p.Redis.RedisDB, p.Ctx).Index(s); err != nil {
+		p.Logger.Error("INDEX_SUGGESTION: ERROR", map[string]interface{}{"kind": s.Kind, "id": s.ID, "error": err.Error()})
+	}
+}
+
+func removeSuggestion(p *base.Persistence, kind string, id uint) {
+	if err := suggestions.NewSuggestionRepository(p.Redis.RedisDB, p.Ctx).Remove(kind, id); err != nil {
+		p.Logger.Error("REMOVE_SUGGESTION: ERROR", map[string]interface{}{"kind": kind, "id": id, "error": err.Error()})
+	}
 }
\ No newline at end of file
@@ -0,0 +1,202 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/implementations/products"
+	"pm/infrastructure/mapper"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+)
+
+const variantEntity string = "product variants"
+
+type ProductVariantUsecase interface {
+	GetVariants(c *gin.Context, productID int64) (*entity.Product, error)
+	CreateVariant(c *gin.Context, requester entity.Requester, productID int64, reqPayload *payload.ProductVariantRequest) (*entity.Product, *entity.ProductVariant, error)
+	UpdateVariant(c *gin.Context, requester entity.Requester, productID int64, variantID int64, reqPayload *payload.ProductVariantRequest) (*entity.Product, *entity.ProductVariant, error)
+	DeleteVariant(c *gin.Context, productID int64, variantID int64) error
+}
+
+type productVariantUsecase struct {
+	p *base.Persistence
+}
+
+func NewProductVariantUsecase(p *base.Persistence) ProductVariantUsecase {
+	return productVariantUsecase{p}
+}
+
+// GetVariants returns the product with its variants
+func (pv productVariantUsecase) GetVariants(c *gin.Context, productID int64) (*entity.Product, error) {
+	span := pv.p.Logger.Start(c, "GET_VARIANTS: USECASES", pv.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	product, err := products.NewProductRepository(c, pv.p, pv.p.GormDB).GetProductByID(span, productID)
+	if err != nil {
+		pv.p.Logger.Error("GET_VARIANTS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return product, nil
+}
+
+// CreateVariant adds a variant to the product and records the stock it starts with as its opening stock movement.
+// Once a product has variants it is only sold through them
+func (pv productVariantUsecase) CreateVariant(c *gin.Context, requester entity.Requester, productID int64, reqPayload *payload.ProductVariantRequest) (*entity.Product, *entity.ProductVariant, error) {
+	span := pv.p.Logger.Start(c, "CREATE_VARIANT: USECASES", pv.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	pv.p.Logger.Info("STARTING: CREATE_VARIANT", map[string]interface{}{"product_id": productID, "data": reqPayload})
+
+	productRepo := products.NewProductRepository(c, pv.p, pv.p.GormDB)
+	product, err := productRepo.GetProductByID(span, productID)
+	if err != nil {
+		pv.p.Logger.Error("CREATE_VARIANT: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, nil, err
+	}
+
+	variant := entity.ProductVariant{ProductID: product.ID}
+	mapper.VariantPayloadToVariant(reqPayload, &variant)
+	if err := pv.validateVariant(c, reqPayload, product, &variant); err != nil {
+		pv.p.Logger.Error("CREATE_VARIANT: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, nil, err
+	}
+	err = pv.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		if err := products.NewProductRepository(c, pv.p, tx).CreateVariant(span, &variant); err != nil {
+			return err
+		}
+		return recordOpeningStock(c, pv.p, tx, requester.ActorID(), variant.ProductID, &variant.ID, variant.Stock)
+	})
+	if err != nil {
+		pv.p.Logger.Error("CREATE_VARIANT: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, nil, err
+	}
+
+	product = pv.refreshProduct(c, product)
+	pv.p.Logger.Info("CREATE_VARIANT: SUCCESSFULLY", map[string]interface{}{"variant": variant})
+	return product, &variant, nil
+}
+
+// UpdateVariant replaces the variant with the request, its options included, a change of its stock is recorded as a
+// manual adjustment. Orders already placed keep the price they got
+func (pv productVariantUsecase) UpdateVariant(c *gin.Context, requester entity.Requester, productID int64, variantID int64, reqPayload *payload.ProductVariantRequest) (*entity.Product, *entity.ProductVariant, error) {
+	span := pv.p.Logger.Start(c, "UPDATE_VARIANT: USECASES", pv.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	pv.p.Logger.Info("STARTING: UPDATE_VARIANT", map[string]interface{}{"product_id": productID, "variant_id": variantID, "data": reqPayload})
+
+	productRepo := products.NewProductRepository(c, pv.p, pv.p.GormDB)
+	product, err := productRepo.GetProductByID(span, productID)
+	if err != nil {
+		pv.p.Logger.Error("UPDATE_VARIANT: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, nil, err
+	}
+	variant, err := productRepo.GetVariantByID(span, productID, variantID)
+	if err != nil {
+		pv.p.Logger.Error("UPDATE_VARIANT: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, nil, err
+	}
+
+	stock := variant.Stock
+	mapper.VariantPayloadToVariant(reqPayload, variant)
+	variant.Stock = stock
+	if err := pv.validateVariant(c, reqPayload, product, variant); err != nil {
+		pv.p.Logger.Error("UPDATE_VARIANT: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, nil, err
+	}
+	err = pv.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		if err := products.NewProductRepository(c, pv.p, tx).UpdateVariant(span, variant); err != nil {
+			return err
+		}
+		cause := entity.StockCause{Reason: entity.StockReasonAdjustment, Reference: entity.StockReferenceManual, ActorID: requester.ActorID()}
+		item := entity.OrderItem{ProductID: variant.ProductID, VariantID: &variant.ID}
+		adjusted, err := adjustStock(c, pv.p, tx, span, cause, nil, item, reqPayload.Stock-stock)
+		if adjusted != nil {
+			variant.Stock = adjusted.StockOf(&variant.ID)
+		}
+		return err
+	})
+	if err != nil {
+		pv.p.Logger.Error("UPDATE_VARIANT: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, nil, err
+	}
+
+	product = pv.refreshProduct(c, product)
+	pv.p.Logger.Info("UPDATE_VARIANT: SUCCESSFULLY", map[string]interface{}{"variant": variant})
+	return product, variant, nil
+}
+
+func (pv productVariantUsecase) DeleteVariant(c *gin.Context, productID int64, variantID int64) error {
+	span := pv.p.Logger.Start(c, "DELETE_VARIANT: USECASES", pv.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	pv.p.Logger.Info("STARTING: DELETE_VARIANT", map[string]interface{}{"product_id": productID, "variant_id": variantID})
+
+	productRepo := products.NewProductRepository(c, pv.p, pv.p.GormDB)
+	variant, err := productRepo.GetVariantByID(span, productID, variantID)
+	if err != nil {
+		pv.p.Logger.Error("DELETE_VARIANT: ERROR", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+	if err := productRepo.DeleteVariant(span, variant); err != nil {
+		pv.p.Logger.Error("DELETE_VARIANT: ERROR", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+
+	pv.refreshProduct(c, &entity.Product{Model: gorm.Model{ID: variant.ProductID}})
+	pv.p.Logger.Info("DELETE_VARIANT: SUCCESSFULLY", map[string]interface{}{"deleted_id": variantID})
+	return nil
+}
+
+// validateVariant checks the SKU of the variant is not used by another variant, it has each option once and no
+// other variant of the product has the same option values
+func (pv productVariantUsecase) validateVariant(c *gin.Context, reqPayload *payload.ProductVariantRequest, product *entity.Product, variant *entity.ProductVariant) error {
+	span := pv.p.Logger.Start(c, "VALIDATE_VARIANT", pv.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	if err := utils.ValidateReqPayload(reqPayload); err != nil {
+		return payload.ErrInvalidRequest(err)
+	}
+	if variant.SKU == "" {
+		return payload.ErrInvalidRequest(errors.New("sku must not be blank"))
+	}
+	names := make(map[string]bool)
+	for _, o := range variant.Options {
+		if o.Name == "" || o.Value == "" {
+			return payload.ErrInvalidRequest(errors.New("option names and values must not be blank"))
+		}
+		if names[o.Name] {
+			return payload.ErrInvalidRequest(fmt.Errorf("option [%s] is given more than once", o.Name))
+		}
+		names[o.Name] = true
+	}
+	for _, other := range product.Variants {
+		if other.ID != variant.ID && other.OptionsKey() == variant.OptionsKey() {
+			return payload.ErrEntityExisted(variantEntity, fmt.Errorf("variant [%d] of product [%d] already has these options", other.ID, product.ID))
+		}
+	}
+
+	existing, err := products.NewProductRepository(c, pv.p, pv.p.GormDB).GetVariantBySKU(span, variant.SKU)
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	if err == nil && existing.ID != variant.ID {
+		return payload.ErrEntityExisted(variantEntity, fmt.Errorf("sku [%s] already exists", variant.SKU))
+	}
+	return nil
+}
+
+// refreshProduct reloads the product with its variants and caches it, the given product is returned when the
+// reload fails
+func (pv productVariantUsecase) refreshProduct(c *gin.Context, product *entity.Product) *entity.Product {
+	span := pv.p.Logger.Start(c, "REFRESH_PRODUCT", pv.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	reloaded, err := products.NewProductRepository(c, pv.p, pv.p.GormDB).GetProductByID(span, int64(product.ID))
+	if err != nil {
+		pv.p.Logger.Error("REFRESH_PRODUCT: ERROR", map[string]interface{}{"error": err.Error()})
+		return product
+	}
+	cacheProducts(pv.p, []entity.Product{*reloaded})
+	return reloaded
+}
\ No newline at end of file
@@ -0,0 +1,241 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/implementations/promotions"
+	"pm/infrastructure/mapper"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+	"time"
+)
+
+const promotionEntity string = "promotions"
+
+type PromotionUsecase interface {
+	CreatePromotion(*gin.Context, *payload.PromotionRequest) (*entity.Promotion, error)
+	GetAllPromotions(*gin.Context, *entity.Pagination) ([]entity.Promotion, error)
+	GetPromotionByID(*gin.Context, int64) (*entity.Promotion, error)
+	UpdatePromotion(c *gin.Context, id int64, reqPayload *payload.PromotionRequest) (*entity.Promotion, error)
+	DeletePromotion(*gin.Context, int64) error
+}
+
+type promotionUsecase struct {
+	p *base.Persistence
+}
+
+func NewPromotionUsecase(p *base.Persistence) PromotionUsecase {
+	return promotionUsecase{p}
+}
+
+func (pu promotionUsecase) CreatePromotion(c *gin.Context, reqPayload *payload.PromotionRequest) (*entity.Promotion, error) {
+	span := pu.p.Logger.Start(c, "CREATE_PROMOTION: USECASES", pu.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	pu.p.Logger.Info("STARTING: CREATE_PROMOTION", map[string]interface{}{"data": reqPayload})
+
+	var promotion entity.Promotion
+	mapper.PromotionPayloadToPromotion(reqPayload, &promotion)
+	if err := pu.validatePromotion(c, reqPayload, &promotion); err != nil {
+		pu.p.Logger.Error("CREATE_PROMOTION: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	if err := promotions.NewPromotionRepository(c, pu.p, pu.p.GormDB).Create(&promotion); err != nil {
+		pu.p.Logger.Error("CREATE_PROMOTION: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	pu.p.Logger.Info("CREATE_PROMOTION: SUCCESSFULLY", map[string]interface{}{"promotion": promotion})
+	return &promotion, nil
+}
+
+func (pu promotionUsecase) GetAllPromotions(c *gin.Context, pagination *entity.Pagination) ([]entity.Promotion, error) {
+	span := pu.p.Logger.Start(c, "GET_ALL_PROMOTIONS: USECASES", pu.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	listPromotions, err := promotions.NewPromotionRepository(c, pu.p, pu.p.GormDB).GetAllPromotions(pagination)
+	if err != nil {
+		pu.p.Logger.Error("GET_ALL_PROMOTIONS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return listPromotions, nil
+}
+
+func (pu promotionUsecase) GetPromotionByID(c *gin.Context, id int64) (*entity.Promotion, error) {
+	span := pu.p.Logger.Start(c, "GET_PROMOTION_BY_ID: USECASES", pu.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	promotion, err := promotions.NewPromotionRepository(c, pu.p, pu.p.GormDB).GetPromotionByID(id)
+	if err != nil {
+		pu.p.Logger.Error("GET_PROMOTION_BY_ID: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return promotion, nil
+}
+
+// UpdatePromotion replaces the settings of the promotion. Orders that already used it keep the discount they got,
+// the usage count is left as it is
+func (pu promotionUsecase) UpdatePromotion(c *gin.Context, id int64, reqPayload *payload.PromotionRequest) (*entity.Promotion, error) {
+	span := pu.p.Logger.Start(c, "UPDATE_PROMOTION: USECASES", pu.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	pu.p.Logger.Info("STARTING: UPDATE_PROMOTION", map[string]interface{}{"id": id, "data": reqPayload})
+
+	promotionRepo := promotions.NewPromotionRepository(c, pu.p, pu.p.GormDB)
+	promotion, err := promotionRepo.GetPromotionByID(id)
+	if err != nil {
+		pu.p.Logger.Error("UPDATE_PROMOTION: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	mapper.PromotionPayloadToPromotion(reqPayload, promotion)
+	if err := pu.validatePromotion(c, reqPayload, promotion); err != nil {
+		pu.p.Logger.Error("UPDATE_PROMOTION: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	if err := promotionRepo.Update(promotion); err != nil {
+		pu.p.Logger.Error("UPDATE_PROMOTION: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	pu.p.Logger.Info("UPDATE_PROMOTION: SUCCESSFULLY", map[string]interface{}{"promotion": promotion})
+	return promotion, nil
+}
+
+func (pu promotionUsecase) DeletePromotion(c *gin.Context, id int64) error {
+	span := pu.p.Logger.Start(c, "DELETE_PROMOTION: USECASES", pu.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	pu.p.Logger.Info("STARTING: DELETE_PROMOTION", map[string]interface{}{"id": id})
+
+	promotionRepo := promotions.NewPromotionRepository(c, pu.p, pu.p.GormDB)
+	promotion, err := promotionRepo.GetPromotionByID(id)
+	if err != nil {
+		pu.p.Logger.Error("DELETE_PROMOTION: ERROR", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+	if err := promotionRepo.DeletePromotion(promotion); err != nil {
+		pu.p.Logger.Error("DELETE_PROMOTION: ERROR", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+
+	pu.p.Logger.Info("DELETE_PROMOTION: SUCCESSFULLY", map[string]interface{}{"deleted_id": id})
+	return nil
+}
+
+// validatePromotion checks the settings of the promotion make sense for its type and that no other promotion
+// uses its code
+func (pu promotionUsecase) validatePromotion(c *gin.Context, reqPayload *payload.PromotionRequest, promotion *entity.Promotion) error {
+	if err := utils.ValidateReqPayload(reqPayload); err != nil {
+		return payload.ErrInvalidRequest(err)
+	}
+
+	var err error
+	switch promotion.Type {
+	case entity.PromotionTypePercentage:
+		if promotion.Value <= 0 || promotion.Value > 100 {
+			err = errors.New("the value of a percentage promotion must be greater than 0 and at most 100")
+		}
+	case entity.PromotionTypeFixedAmount:
+		if promotion.Value <= 0 {
+			err = errors.New("the value of a fixed amount promotion must be greater than 0")
+		}
+	case entity.PromotionTypeFreeShipping:
+	case entity.PromotionTypeBuyXGetY:
+		if promotion.BuyQuantity <= 0 || promotion.GetQuantity <= 0 {
+			err = errors.New("buyQuantity and getQuantity of a buy X get Y promotion must be greater than 0")
+		} else if promotion.ProductID != nil && promotion.CategoryID != nil {
+			err = errors.New("a buy X get Y promotion targets either a product or a category, not both")
+		}
+	default:
+		err = fmt.Errorf("unknown promotion type [%s]", promotion.Type)
+	}
+	if err == nil && promotion.StartsAt != nil && promotion.EndsAt != nil && !promotion.EndsAt.After(*promotion.StartsAt) {
+		err = errors.New("endsAt must be after startsAt")
+	}
+	if err != nil {
+		return payload.ErrInvalidRequest(err)
+	}
+
+	existing, err := promotions.NewPromotionRepository(c, pu.p, pu.p.GormDB).GetPromotionByCode(promotion.Code)
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	if err == nil && existing.ID != promotion.ID {
+		return payload.ErrEntityExisted(promotionEntity, fmt.Errorf("promotion code [%s] already exists", promotion.Code))
+	}
+	return nil
+}
+
+// applyPromotion takes the discount of the code off the order, whose items must already be priced. The promotion
+// stays locked until the transaction ends, so its usage limits are checked and counted by one order at a time.
+// The returned promotion still has to be used once the order is saved
+func applyPromotion(c *gin.Context, p *base.Persistence, db *gorm.DB, requester entity.Requester, order *entity.Order, code string, categories map[uint]int64) (*entity.Promotion, error) {
+	promotionRepo := promotions.NewPromotionRepository(c, p, db)
+	promotion, err := promotionRepo.LockPromotionByCode(code)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, payload.ErrInvalidRequest(fmt.Errorf("%w: code %s does not exist", entity.ErrPromotionNotApplicable, entity.NormalizePromotionCode(code)))
+		}
+		return nil, err
+	}
+	if promotion.UsageLimit > 0 && promotion.UsedCount >= promotion.UsageLimit {
+		return nil, payload.ErrInvalidRequest(fmt.Errorf("%w: code %s has reached its usage limit", entity.ErrPromotionNotApplicable, promotion.Code))
+	}
+	if promotion.PerUserLimit > 0 {
+		used, err := promotionRepo.CountUsagesByUser(promotion.ID, uint(requester.UserID))
+		if err != nil {
+			return nil, err
+		}
+		if used >= int64(promotion.PerUserLimit) {
+			return nil, payload.ErrInvalidRequest(fmt.Errorf("%w: code %s was already used %d times", entity.ErrPromotionNotApplicable, promotion.Code, used))
+		}
+	}
+
+	if err := discountOrder(promotion, order, categories, time.Now()); err != nil {
+		return nil, payload.ErrInvalidRequest(err)
+	}
+	order.PromotionID = &promotion.ID
+	order.PromotionCode = promotion.Code
+	return promotion, nil
+}
+
+// repriceOrderPromotion works the discount of the promotion of the order out again after its items changed, as it
+// was when the order was placed. A promotion the order no longer qualifies for gives no discount
+func repriceOrderPromotion(c *gin.Context, p *base.Persistence, db *gorm.DB, order *entity.Order, categories map[uint]int64) error {
+	if order.PromotionID == nil {
+		order.CalculateTotals()
+		return nil
+	}
+	promotion, err := promotions.NewPromotionRepository(c, p, db).GetPromotionByID(int64(*order.PromotionID))
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	if err != nil || discountOrder(promotion, order, categories, order.CreatedAt) != nil {
+		order.DiscountTotal = 0
+		order.CalculateTotals()
+	}
+	return nil
+}
+
+func discountOrder(promotion *entity.Promotion, order *entity.Order, categories map[uint]int64, at time.Time) error {
+	order.CalculateTotals()
+	discount, err := promotion.Discount(order, categories, at)
+	if err != nil {
+		return err
+	}
+	order.DiscountTotal = discount
+	order.CalculateTotals()
+	return nil
+}
+
+// productCategories maps every product to its category, for promotions that target a category
+func productCategories(prods []entity.Product) map[uint]int64 {
+	categories := make(map[uint]int64)
+	for _, v := range prods {
+		categories[v.ID] = v.CategoryID
+	}
+	return categories
+}
\ No newline at end of file
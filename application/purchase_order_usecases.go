@@ -0,0 +1,332 @@
+package application
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/implementations/mailer"
+	"pm/infrastructure/implementations/products"
+	purchaseOrders "pm/infrastructure/implementations/purchase_orders"
+	"pm/infrastructure/implementations/suppliers"
+	"pm/infrastructure/implementations/warehouses"
+	"pm/infrastructure/mapper"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+	"strings"
+	"time"
+)
+
+const purchaseOrderEntity string = "purchase_orders"
+
+type PurchaseOrderUsecase interface {
+	GetAllPurchaseOrders(c *gin.Context, filter *entity.PurchaseOrderFilter, pagination *entity.Pagination) ([]entity.PurchaseOrder, error)
+	GetPurchaseOrderByID(c *gin.Context, id int64) (*entity.PurchaseOrder, error)
+	CreatePurchaseOrder(c *gin.Context, requester entity.Requester, reqPayload *payload.PurchaseOrderRequest) (*entity.PurchaseOrder, error)
+	UpdatePurchaseOrder(c *gin.Context, id int64, reqPayload *payload.PurchaseOrderRequest) (*entity.PurchaseOrder, error)
+	SendPurchaseOrder(c *gin.Context, id int64) (*entity.PurchaseOrder, error)
+	CancelPurchaseOrder(c *gin.Context, id int64) (*entity.PurchaseOrder, error)
+	ReceiveGoods(c *gin.Context, requester entity.Requester, id int64, reqPayload *payload.ReceiveGoodsRequest) (*entity.PurchaseOrder, error)
+}
+
+type purchaseOrderUsecase struct {
+	p *base.Persistence
+}
+
+func NewPurchaseOrderUsecase(p *base.Persistence) PurchaseOrderUsecase {
+	return purchaseOrderUsecase{p}
+}
+
+func (po purchaseOrderUsecase) GetAllPurchaseOrders(c *gin.Context, filter *entity.PurchaseOrderFilter, pagination *entity.Pagination) ([]entity.PurchaseOrder, error) {
+	span := po.p.Logger.Start(c, "GET_ALL_PURCHASE_ORDERS: USECASES", po.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	filter.Status = strings.ToUpper(strings.TrimSpace(filter.Status))
+	if filter.Status != "" && !entity.IsValidPurchaseOrderStatus(filter.Status) {
+		err := fmt.Errorf("status [%s] is not valid", filter.Status)
+		po.p.Logger.Error("GET_ALL_PURCHASE_ORDERS: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInvalidRequest(err)
+	}
+	listPurchaseOrders, err := purchaseOrders.NewPurchaseOrderRepository(c, po.p, po.p.GormDB).GetAllPurchaseOrders(filter, pagination)
+	if err != nil {
+		po.p.Logger.Error("GET_ALL_PURCHASE_ORDERS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return listPurchaseOrders, nil
+}
+
+func (po purchaseOrderUsecase) GetPurchaseOrderByID(c *gin.Context, id int64) (*entity.PurchaseOrder, error) {
+	span := po.p.Logger.Start(c, "GET_PURCHASE_ORDER: USECASES", po.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	purchaseOrder, err := purchaseOrders.NewPurchaseOrderRepository(c, po.p, po.p.GormDB).GetPurchaseOrderByID(id)
+	if err != nil {
+		po.p.Logger.Error("GET_PURCHASE_ORDER: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return purchaseOrder, nil
+}
+
+// CreatePurchaseOrder drafts a purchase order, it can be changed until it is sent to the supplier
+func (po purchaseOrderUsecase) CreatePurchaseOrder(c *gin.Context, requester entity.Requester, reqPayload *payload.PurchaseOrderRequest) (*entity.PurchaseOrder, error) {
+	span := po.p.Logger.Start(c, "CREATE_PURCHASE_ORDER: USECASES", po.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	po.p.Logger.Info("STARTING: CREATE_PURCHASE_ORDER", map[string]interface{}{"data": reqPayload})
+
+	if err := po.validatePurchaseOrder(c, span, reqPayload); err != nil {
+		po.p.Logger.Error("CREATE_PURCHASE_ORDER: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	purchaseOrder := entity.PurchaseOrder{
+		Status:    entity.PurchaseOrderStatusDraft,
+		CreatedBy: requester.ActorID(),
+		Lines:     mapper.PurchaseOrderLinePayloadsToPurchaseOrderLines(reqPayload.Lines),
+	}
+	mapper.PurchaseOrderPayloadToPurchaseOrder(reqPayload, &purchaseOrder)
+	purchaseOrderRepo := purchaseOrders.NewPurchaseOrderRepository(c, po.p, po.p.GormDB)
+	if err := purchaseOrderRepo.Create(&purchaseOrder); err != nil {
+		po.p.Logger.Error("CREATE_PURCHASE_ORDER: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	po.p.Logger.Info("CREATE_PURCHASE_ORDER: SUCCESSFULLY", map[string]interface{}{"purchase_order": purchaseOrder.ID})
+	return purchaseOrderRepo.GetPurchaseOrderByID(int64(purchaseOrder.ID))
+}
+
+// UpdatePurchaseOrder changes a purchase order that is still a draft, the lines of the request replace its lines
+func (po purchaseOrderUsecase) UpdatePurchaseOrder(c *gin.Context, id int64, reqPayload *payload.PurchaseOrderRequest) (*entity.PurchaseOrder, error) {
+	span := po.p.Logger.Start(c, "UPDATE_PURCHASE_ORDER: USECASES", po.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	po.p.Logger.Info("STARTING: UPDATE_PURCHASE_ORDER", map[string]interface{}{"id": id, "data": reqPayload})
+
+	if err := po.validatePurchaseOrder(c, span, reqPayload); err != nil {
+		po.p.Logger.Error("UPDATE_PURCHASE_ORDER: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	err := po.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		purchaseOrderRepo := purchaseOrders.NewPurchaseOrderRepository(c, po.p, tx)
+		purchaseOrder, err := purchaseOrderRepo.LockPurchaseOrder(id)
+		if err != nil {
+			return err
+		}
+		if purchaseOrder.Status != entity.PurchaseOrderStatusDraft {
+			return payload.ErrInvalidRequest(fmt.Errorf("the purchase order [%d] is %s, only a draft can be changed", id, purchaseOrder.Status))
+		}
+		mapper.PurchaseOrderPayloadToPurchaseOrder(reqPayload, purchaseOrder)
+		if err := purchaseOrderRepo.Update(purchaseOrder); err != nil {
+			return err
+		}
+		return purchaseOrderRepo.ReplaceLines(purchaseOrder, mapper.PurchaseOrderLinePayloadsToPurchaseOrderLines(reqPayload.Lines))
+	})
+	if err != nil {
+		po.p.Logger.Error("UPDATE_PURCHASE_ORDER: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	po.p.Logger.Info("UPDATE_PURCHASE_ORDER: SUCCESSFULLY", map[string]interface{}{"purchase_order": id})
+	return purchaseOrders.NewPurchaseOrderRepository(c, po.p, po.p.GormDB).GetPurchaseOrderByID(id)
+}
+
+// SendPurchaseOrder marks the purchase order sent and emails it to the supplier. The purchase order stays sent when
+// the email fails, it can be forwarded by hand
+func (po purchaseOrderUsecase) SendPurchaseOrder(c *gin.Context, id int64) (*entity.PurchaseOrder, error) {
+	span := po.p.Logger.Start(c, "SEND_PURCHASE_ORDER: USECASES", po.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	purchaseOrder, err := po.transition(c, id, entity.PurchaseOrderStatusSent)
+	if err != nil {
+		po.p.Logger.Error("SEND_PURCHASE_ORDER: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	if err := mailPurchaseOrder(c, po.p, span, purchaseOrder); err != nil {
+		po.p.Logger.Error("SEND_PURCHASE_ORDER: ERROR SENDING EMAIL", map[string]interface{}{"error": err.Error()})
+	}
+
+	po.p.Logger.Info("SEND_PURCHASE_ORDER: SUCCESSFULLY", map[string]interface{}{"purchase_order": id})
+	return purchaseOrder, nil
+}
+
+// CancelPurchaseOrder cancels a purchase order nothing was received for yet
+func (po purchaseOrderUsecase) CancelPurchaseOrder(c *gin.Context, id int64) (*entity.PurchaseOrder, error) {
+	span := po.p.Logger.Start(c, "CANCEL_PURCHASE_ORDER: USECASES", po.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	purchaseOrder, err := po.transition(c, id, entity.PurchaseOrderStatusCancelled)
+	if err != nil {
+		po.p.Logger.Error("CANCEL_PURCHASE_ORDER: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	po.p.Logger.Info("CANCEL_PURCHASE_ORDER: SUCCESSFULLY", map[string]interface{}{"purchase_order": id})
+	return purchaseOrder, nil
+}
+
+// ReceiveGoods puts the goods that arrived for a sent purchase order in stock, as restock movements of the ledger,
+// and averages their unit cost into the cost of goods of their products. The purchase order is received once all of
+// its lines are, partially received until then
+func (po purchaseOrderUsecase) ReceiveGoods(c *gin.Context, requester entity.Requester, id int64, reqPayload *payload.ReceiveGoodsRequest) (*entity.PurchaseOrder, error) {
+	span := po.p.Logger.Start(c, "RECEIVE_GOODS: USECASES", po.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	po.p.Logger.Info("STARTING: RECEIVE_GOODS", map[string]interface{}{"id": id, "data": reqPayload})
+
+	if err := utils.ValidateReqPayload(reqPayload); err != nil {
+		po.p.Logger.Error("RECEIVE_GOODS: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInvalidRequest(err)
+	}
+
+	var purchaseOrder *entity.PurchaseOrder
+	received := make([]entity.Product, 0)
+	err := po.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		purchaseOrderRepo := purchaseOrders.NewPurchaseOrderRepository(c, po.p, tx)
+		if purchaseOrder, err = purchaseOrderRepo.LockPurchaseOrder(id); err != nil {
+			return err
+		}
+		if !purchaseOrder.IsReceivable() {
+			return payload.ErrInvalidRequest(fmt.Errorf("the purchase order [%d] is %s, goods can only be received once it is sent", id, purchaseOrder.Status))
+		}
+
+		warehouseID := reqPayload.WarehouseID
+		if warehouseID == nil {
+			warehouseID = purchaseOrder.WarehouseID
+		}
+		cause := entity.StockCause{
+			Reason:    entity.StockReasonRestock,
+			Reference: entity.PurchaseOrderStockReference(purchaseOrder.ID),
+			ActorID:   requester.ActorID(),
+		}
+		productRepo := products.NewProductRepository(c, po.p, tx)
+		for _, r := range reqPayload.Lines {
+			line := purchaseOrder.Line(r.LineID)
+			if line == nil {
+				return payload.ErrEntityNotFound(purchaseOrderEntity, fmt.Errorf("line [%d] of purchase order [%d] not found", r.LineID, id))
+			}
+			if err := purchaseOrderRepo.ReceiveLine(line, r.Quantity); err != nil {
+				return err
+			}
+			item := entity.OrderItem{ProductID: line.ProductID, VariantID: line.VariantID}
+			product, err := adjustStock(c, po.p, tx, span, cause, warehouseID, item, r.Quantity)
+			if err != nil {
+				return err
+			}
+			// the stock of the product is locked by the adjustment, what it held before is what it holds now less the goods
+			cost := entity.AverageCost(product.CostPrice, product.TotalStock()-r.Quantity, line.UnitCost, r.Quantity)
+			if err := productRepo.UpdateCostPrice(span, product, cost); err != nil {
+				return err
+			}
+			received = append(received, *product)
+		}
+
+		purchaseOrder.Status = entity.PurchaseOrderStatusPartiallyReceived
+		if purchaseOrder.IsFullyReceived() {
+			now := time.Now()
+			purchaseOrder.Status = entity.PurchaseOrderStatusReceived
+			purchaseOrder.ReceivedAt = &now
+		}
+		return purchaseOrderRepo.UpdateStatus(purchaseOrder)
+	})
+	if err != nil {
+		po.p.Logger.Error("RECEIVE_GOODS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	cacheProducts(po.p, received)
+	po.p.Logger.Info("RECEIVE_GOODS: SUCCESSFULLY", map[string]interface{}{"purchase_order": id, "status": purchaseOrder.Status})
+	return purchaseOrder, nil
+}
+
+// transition moves the purchase order to the given status when its current status allows it
+func (po purchaseOrderUsecase) transition(c *gin.Context, id int64, status string) (*entity.PurchaseOrder, error) {
+	var purchaseOrder *entity.PurchaseOrder
+	err := po.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		purchaseOrderRepo := purchaseOrders.NewPurchaseOrderRepository(c, po.p, tx)
+		if purchaseOrder, err = purchaseOrderRepo.LockPurchaseOrder(id); err != nil {
+			return err
+		}
+		if !purchaseOrder.CanTransitionTo(status) {
+			return payload.ErrInvalidRequest(fmt.Errorf("the purchase order [%d] cannot move from %s to %s", id, purchaseOrder.Status, status))
+		}
+		purchaseOrder.Status = status
+		if status == entity.PurchaseOrderStatusSent {
+			now := time.Now()
+			purchaseOrder.SentAt = &now
+		}
+		return purchaseOrderRepo.UpdateStatus(purchaseOrder)
+	})
+	return purchaseOrder, err
+}
+
+func (po purchaseOrderUsecase) validatePurchaseOrder(c *gin.Context, span trace.Span, reqPayload *payload.PurchaseOrderRequest) error {
+	if err := utils.ValidateReqPayload(reqPayload); err != nil {
+		return payload.ErrInvalidRequest(err)
+	}
+	if _, err := suppliers.NewSupplierRepository(c, po.p, po.p.GormDB).GetSupplierByID(int64(reqPayload.SupplierID)); err != nil {
+		return err
+	}
+	if reqPayload.WarehouseID != nil {
+		if _, err := warehouses.NewWarehouseRepository(c, po.p, po.p.GormDB).GetWarehouseByID(int64(*reqPayload.WarehouseID)); err != nil {
+			return err
+		}
+	}
+
+	items := make([]entity.OrderItem, 0)
+	for _, line := range reqPayload.Lines {
+		item := entity.OrderItem{ProductID: line.ProductID, VariantID: line.VariantID}
+		for _, other := range items {
+			if item.SameStock(other) {
+				return payload.ErrInvalidRequest(fmt.Errorf("the product %v is ordered on more than one line", line.ProductID))
+			}
+		}
+		if err := checkStockItem(c, po.p, po.p.GormDB, span, item); err != nil {
+			return err
+		}
+		items = append(items, item)
+	}
+	return nil
+}
+
+// mailPurchaseOrder emails the lines of the purchase order to its supplier, nothing is sent to a supplier without an
+// email address
+func mailPurchaseOrder(c *gin.Context, p *base.Persistence, span trace.Span, purchaseOrder *entity.PurchaseOrder) error {
+	if purchaseOrder.Supplier.Email == "" {
+		return nil
+	}
+	ids := make([]uint, 0)
+	for _, line := range purchaseOrder.Lines {
+		ids = append(ids, line.ProductID)
+	}
+	prods, err := products.NewProductRepository(c, p, p.GormDB).GetProductsByIDs(span, ids...)
+	if err != nil {
+		return err
+	}
+	names := make(map[uint]*entity.Product)
+	for i := range prods {
+		names[prods[i].ID] = &prods[i]
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Dear %s,\n\nplease supply the goods of our purchase order #%d:\n\n", purchaseOrder.Supplier.Name, purchaseOrder.ID)
+	for _, line := range purchaseOrder.Lines {
+		name := fmt.Sprintf("product %d", line.ProductID)
+		if product, ok := names[line.ProductID]; ok {
+			name = product.Name
+			if line.VariantID != nil {
+				if v := product.Variant(*line.VariantID); v != nil {
+					name += " (" + v.SKU + ")"
+				}
+			}
+		}
+		fmt.Fprintf(&body, "- %s: %d x %.2f\n", name, line.Quantity, line.UnitCost)
+	}
+	fmt.Fprintf(&body, "\nTotal: %.2f\n", purchaseOrder.Total())
+	if purchaseOrder.Note != "" {
+		fmt.Fprintf(&body, "\n%s\n", purchaseOrder.Note)
+	}
+	subject := fmt.Sprintf("Purchase order #%d", purchaseOrder.ID)
+	return mailer.NewMailerRepository(p).SendEmailWithPlainText(body.String(), subject, []string{purchaseOrder.Supplier.Email}, nil)
+}
\ No newline at end of file
@@ -0,0 +1,348 @@
+package application
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"pm/domain/entity"
+	paymentRepo "pm/domain/repository/payments"
+	"pm/infrastructure/config"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/implementations/orders"
+	"pm/infrastructure/implementations/payments"
+	"pm/infrastructure/implementations/products"
+	"pm/infrastructure/implementations/promotions"
+	"pm/infrastructure/implementations/refunds"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+)
+
+type RefundUsecase interface {
+	CreateRefund(c *gin.Context, requester entity.Requester, orderID int64, reqPayload *payload.CreateRefundRequest) (*entity.Refund, error)
+	GetRefundsByOrderID(c *gin.Context, requester entity.Requester, orderID int64) ([]entity.Refund, error)
+}
+
+type refundUsecase struct {
+	p   *base.Persistence
+	cfg config.PaymentConfig
+}
+
+func NewRefundUsecase(p *base.Persistence) RefundUsecase {
+	return refundUsecase{p, paymentConfig()}
+}
+
+// CreateRefund gives money back from the captured payment of the order. Without items everything that has not been
+// refunded yet is refunded, otherwise only the given quantities, priced with the share of discount and tax of the
+// order. The refund is saved as pending before the gateway is asked for the money and completed afterwards, with
+// the stock and the status of the order, so the money never leaves without a record. Pending refunds count against
+// the payment, so concurrent refunds can never exceed what was captured
+func (r refundUsecase) CreateRefund(c *gin.Context, requester entity.Requester, orderID int64, reqPayload *payload.CreateRefundRequest) (*entity.Refund, error) {
+	span := r.p.Logger.Start(c, "CREATE_REFUND: USECASES", r.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	r.p.Logger.Info("STARTING: CREATE_REFUND", map[string]interface{}{"order_id": orderID, "data": reqPayload})
+
+	if err := utils.ValidateReqPayload(reqPayload); err != nil {
+		r.p.Logger.Error("CREATE_REFUND: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInvalidRequest(err)
+	}
+
+	var refund *entity.Refund
+	var payment *entity.Payment
+	err := r.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		orderRepo := orders.NewOrderRepository(c, r.p, tx)
+		if err := orderRepo.LockOrder(orderID); err != nil {
+			return err
+		}
+		order, err := orderRepo.GetOrderByID(orderID)
+		if err != nil {
+			return err
+		}
+		if !order.CanTransitionTo(entity.OrderStatusPartiallyRefunded) {
+			return payload.ErrInvalidOrderTransition(order.Status, entity.OrderStatusPartiallyRefunded)
+		}
+
+		paymentRepo := payments.NewPaymentRepository(c, r.p, tx)
+		if payment, err = capturedPaymentOf(paymentRepo, orderID); err != nil {
+			return err
+		}
+		if payment, err = paymentRepo.GetPaymentByID(int64(payment.ID)); err != nil {
+			return err
+		}
+
+		refundRepo := refunds.NewRefundRepository(c, r.p, tx)
+		refunded, err := refundRepo.GetRefundedAmountByPaymentID(int64(payment.ID), entity.RefundHoldingStatuses...)
+		if err != nil {
+			return err
+		}
+		refundedQuantities, err := refundRepo.GetRefundedQuantitiesByOrderID(orderID, entity.RefundHoldingStatuses...)
+		if err != nil {
+			return err
+		}
+		remaining := entity.RoundMoney(payment.Amount - refunded)
+		freeShipping, err := hasFreeShipping(c, r.p, tx, order)
+		if err != nil {
+			return err
+		}
+
+		refund, err = buildRefund(order, reqPayload, refundedQuantities, remaining, order.ItemsTotal(freeShipping))
+		if err != nil {
+			return err
+		}
+		refund.PaymentID = payment.ID
+		refund.CreatedBy = uint(requester.UserID)
+		refund.Status = entity.RefundStatusPending
+		if err := refundRepo.Create(refund); err != nil {
+			return err
+		}
+		return recordOrderEvent(c, r.p, tx, order.ID, requester.ActorID(), entity.OrderEventRefundCreated, nil, refund.Snapshot())
+	})
+	if err != nil {
+		r.p.Logger.Error("CREATE_REFUND: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	// the gateway is called outside of the transaction so a slow gateway never holds the lock on the order
+	before := refund.Snapshot()
+	result, err := r.refundOnGateway(payment, refund.Amount)
+	if err != nil {
+		// the gateway may still have refunded the money, the refund stays pending so its amount is never refunded twice
+		refund.FailureReason = err.Error()
+		if errU := r.updateRefund(c, r.p.GormDB, requester.ActorID(), refund, before); errU != nil {
+			r.p.Logger.Error("CREATE_REFUND: ERROR", map[string]interface{}{"error": errU.Error()})
+		}
+		r.p.Logger.Error("CREATE_REFUND: ERROR GATEWAY", map[string]interface{}{"error": err.Error(), "refund_id": refund.ID})
+		return nil, payload.ErrInternal(err)
+	}
+	if result.Status != entity.RefundStatusSucceeded {
+		refund.Status = entity.RefundStatusFailed
+		refund.FailureReason = result.FailureReason
+		if errU := r.updateRefund(c, r.p.GormDB, requester.ActorID(), refund, before); errU != nil {
+			r.p.Logger.Error("CREATE_REFUND: ERROR", map[string]interface{}{"error": errU.Error()})
+		}
+		err := payload.ErrInvalidRequest(fmt.Errorf("the gateway refused the refund: %s", result.FailureReason))
+		r.p.Logger.Error("CREATE_REFUND: ERROR GATEWAY", map[string]interface{}{"error": err.Error(), "refund_id": refund.ID})
+		return nil, err
+	}
+
+	restocked := make([]entity.Product, 0)
+	err = r.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		orderRepo := orders.NewOrderRepository(c, r.p, tx)
+		if err := orderRepo.LockOrder(orderID); err != nil {
+			return err
+		}
+		order, err := orderRepo.GetOrderByID(orderID)
+		if err != nil {
+			return err
+		}
+
+		refund.Status = entity.RefundStatusSucceeded
+		refund.ProviderRef = result.ProviderRef
+		if err := r.updateRefund(c, tx, requester.ActorID(), refund, before); err != nil {
+			return err
+		}
+		if refund.Restock {
+			if restocked, err = r.restock(c, tx, span, requester, refund); err != nil {
+				return err
+			}
+		}
+
+		refunded, err := refunds.NewRefundRepository(c, r.p, tx).GetRefundedAmountByPaymentID(int64(payment.ID), entity.RefundStatusSucceeded)
+		if err != nil {
+			return err
+		}
+		status := entity.OrderStatusPartiallyRefunded
+		if entity.RoundMoney(payment.Amount-refunded) <= 0 {
+			status = entity.OrderStatusRefunded
+		}
+		if !order.CanTransitionTo(status) {
+			return payload.ErrInvalidOrderTransition(order.Status, status)
+		}
+		orderBefore := order.Snapshot()
+		if err := orderRepo.UpdateStatus(order, status); err != nil {
+			return err
+		}
+		return recordOrderEvent(c, r.p, tx, order.ID, requester.ActorID(), entity.OrderEventStatusChanged, orderBefore, order.Snapshot())
+	})
+	if err != nil {
+		// the money is back with the customer, the refund stays pending with the reference of the gateway in the logs
+		r.p.Logger.Error("CREATE_REFUND: ERROR", map[string]interface{}{"error": err.Error(), "refund_id": refund.ID, "provider_ref": result.ProviderRef})
+		return nil, err
+	}
+
+	cacheProducts(r.p, restocked)
+	r.p.Logger.Info("CREATE_REFUND: SUCCESSFULLY", map[string]interface{}{"refund": refund})
+	return refund, nil
+}
+
+func (r refundUsecase) GetRefundsByOrderID(c *gin.Context, requester entity.Requester, orderID int64) ([]entity.Refund, error) {
+	span := r.p.Logger.Start(c, "GET_REFUNDS_BY_ORDER_ID: USECASES", r.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	order, err := orders.NewOrderRepository(c, r.p, r.p.GormDB).GetOrderByID(orderID)
+	if err != nil {
+		r.p.Logger.Error("GET_REFUNDS_BY_ORDER_ID: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	if err := authorizeOrder(requester, order); err != nil {
+		r.p.Logger.Error("GET_REFUNDS_BY_ORDER_ID: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	listRefunds, err := refunds.NewRefundRepository(c, r.p, r.p.GormDB).GetRefundsByOrderID(orderID)
+	if err != nil {
+		r.p.Logger.Error("GET_REFUNDS_BY_ORDER_ID: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return listRefunds, nil
+}
+
+func (r refundUsecase) refundOnGateway(payment *entity.Payment, amount float64) (*paymentRepo.GatewayResult, error) {
+	gateway, err := payments.NewPaymentGateway(payment.Provider, r.cfg)
+	if err != nil {
+		return nil, err
+	}
+	return gateway.Refund(payment, amount)
+}
+
+// restock gives the refunded quantities back to the stock they were taken from
+func (r refundUsecase) restock(c *gin.Context, tx *gorm.DB, span trace.Span, requester entity.Requester, refund *entity.Refund) ([]entity.Product, error) {
+	items := make([]entity.OrderItem, 0)
+	for _, item := range refund.RefundItems {
+		items = append(items, entity.OrderItem{Model: gorm.Model{ID: item.OrderItemID}, ProductID: item.ProductID, VariantID: item.VariantID, Quantity: item.Quantity})
+	}
+	restocked, err := products.NewProductRepository(c, r.p, tx).IncreaseStock(span, items...)
+	if err != nil {
+		return nil, err
+	}
+	cause := entity.StockCause{Reason: entity.StockReasonReturn, Reference: entity.RefundStockReference(refund.ID), ActorID: requester.ActorID()}
+	if err := recordStockMovements(c, r.p, tx, cause, 1, items, restocked); err != nil {
+		return nil, err
+	}
+	if err := returnStock(c, r.p, tx, items); err != nil {
+		return nil, err
+	}
+	return restocked, nil
+}
+
+// updateRefund saves the outcome of the refund and records how it changed from before in the history of its order
+func (r refundUsecase) updateRefund(c *gin.Context, db *gorm.DB, actorID *uint, refund *entity.Refund, before entity.Snapshot) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := refunds.NewRefundRepository(c, r.p, tx).Update(refund); err != nil {
+			return err
+		}
+		return recordOrderEvent(c, r.p, tx, refund.OrderID, actorID, entity.OrderEventRefundUpdated, before, refund.Snapshot())
+	})
+}
+
+// hasFreeShipping reports whether the discount of the order comes from a free shipping promotion, which only
+// discounts the shipping fee
+func hasFreeShipping(c *gin.Context, p *base.Persistence, db *gorm.DB, order *entity.Order) (bool, error) {
+	if order.PromotionID == nil {
+		return false, nil
+	}
+	promotion, err := promotions.NewPromotionRepository(c, p, db).GetPromotionByID(int64(*order.PromotionID))
+	if err != nil {
+		if isNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return promotion.Type == entity.PromotionTypeFreeShipping, nil
+}
+
+// capturedPaymentOf finds the payment the money of the order was taken with
+func capturedPaymentOf(repo paymentRepo.PaymentRepository, orderID int64) (*entity.Payment, error) {
+	listPayments, err := repo.GetPaymentsByOrderID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range listPayments {
+		if v.Status == entity.PaymentStatusCaptured {
+			return &v, nil
+		}
+	}
+	return nil, payload.ErrInvalidRequest(fmt.Errorf("order [%d] has no captured payment to refund", orderID))
+}
+
+// buildRefund works out the items and the amount of a refund. remaining is what is left of the captured payment,
+// the refund never goes above it. itemsTotal is what the items and their tax cost once discounted, the refund of
+// items is worked out of it so it never gives back any of the shipping fee
+func buildRefund(order *entity.Order, reqPayload *payload.CreateRefundRequest, refundedQuantities map[uint]int, remaining, itemsTotal float64) (*entity.Refund, error) {
+	refund := &entity.Refund{
+		OrderID:     order.ID,
+		Reason:      reqPayload.Reason,
+		Restock:     reqPayload.Restock,
+		RefundItems: make([]entity.RefundItem, 0),
+	}
+
+	orderItems := make(map[uint]entity.OrderItem)
+	left := make(map[uint]int)
+	for _, item := range order.OrderItems {
+		orderItems[item.ID] = item
+		left[item.ID] = item.Quantity - refundedQuantities[item.ID]
+	}
+
+	// the share of the items total every item is worth once discount and tax are applied
+	ratio := 1.0
+	if order.Subtotal > 0 {
+		ratio = itemsTotal / order.Subtotal
+	}
+
+	if len(reqPayload.Items) == 0 {
+		for _, item := range order.OrderItems {
+			if left[item.ID] <= 0 {
+				continue
+			}
+			refund.RefundItems = append(refund.RefundItems, entity.RefundItem{
+				OrderItemID: item.ID,
+				ProductID:   item.ProductID,
+				VariantID:   item.VariantID,
+				Quantity:    left[item.ID],
+				Amount:      entity.RoundMoney(item.Price * float64(left[item.ID]) * ratio),
+			})
+		}
+		refund.Amount = remaining
+	} else {
+		for _, v := range reqPayload.Items {
+			item, ok := orderItems[v.OrderItemID]
+			if !ok {
+				return nil, payload.ErrInvalidRequest(fmt.Errorf("order item [%d] does not belong to order [%d]", v.OrderItemID, order.ID))
+			}
+			if v.Quantity > left[item.ID] {
+				return nil, payload.ErrInvalidRequest(fmt.Errorf("only %d of order item [%d] can still be refunded", left[item.ID], item.ID))
+			}
+			left[item.ID] -= v.Quantity
+
+			amount := entity.RoundMoney(item.Price * float64(v.Quantity) * ratio)
+			refund.RefundItems = append(refund.RefundItems, entity.RefundItem{
+				OrderItemID: item.ID,
+				ProductID:   item.ProductID,
+				VariantID:   item.VariantID,
+				Quantity:    v.Quantity,
+				Amount:      amount,
+			})
+			refund.Amount += amount
+		}
+		refund.Amount = entity.RoundMoney(refund.Amount)
+
+		// once every quantity is refunded the rest of the items total goes with it, so rounding leaves nothing behind
+		nothingLeft := true
+		for _, quantity := range left {
+			if quantity > 0 {
+				nothingLeft = false
+			}
+		}
+		if itemsRemaining := entity.RoundMoney(remaining - (order.GrandTotal - itemsTotal)); nothingLeft && itemsRemaining > 0 {
+			refund.Amount = itemsRemaining
+		}
+	}
+
+	if refund.Amount <= 0 {
+		return nil, payload.ErrInvalidRequest(fmt.Errorf("there is nothing left to refund on order [%d]", order.ID))
+	}
+	if refund.Amount > remaining {
+		return nil, payload.ErrInvalidRequest(fmt.Errorf("the refund of %v is more than the %v left of the captured payment", refund.Amount, remaining))
+	}
+	return refund, nil
+}
\ No newline at end of file
@@ -0,0 +1,152 @@
+package application
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"net/http"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+	"testing"
+)
+
+func TestBuildRefund(t *testing.T) {
+	item := func(id uint, quantity int, price float64) entity.OrderItem {
+		return entity.OrderItem{Model: gorm.Model{ID: id}, ProductID: id, Quantity: quantity, Price: price}
+	}
+	// 100 of items, 10 off, 9 of tax and 5 of shipping, the items are worth 99 of the 104 paid
+	order := &entity.Order{
+		Model:         gorm.Model{ID: 1},
+		OrderItems:    []entity.OrderItem{item(1, 2, 30), item(2, 1, 40)},
+		Subtotal:      100,
+		DiscountTotal: 10,
+		TaxTotal:      9,
+		ShippingFee:   5,
+		GrandTotal:    104,
+	}
+	const itemsTotal = 99.0
+	// three units of 10 worth 20 once discounted, each unit is worth a third of it
+	thirds := &entity.Order{
+		Model:         gorm.Model{ID: 2},
+		OrderItems:    []entity.OrderItem{item(3, 3, 10)},
+		Subtotal:      30,
+		DiscountTotal: 10,
+		ShippingFee:   5,
+		GrandTotal:    25,
+	}
+
+	tests := []struct {
+		name        string
+		order       *entity.Order
+		items       []payload.RefundItemRequest
+		refunded    map[uint]int
+		remaining   float64
+		itemsTotal  float64
+		wantAmount  float64
+		wantAmounts []float64
+		wantErr     bool
+	}{
+		{
+			name:        "the whole order gives back what is left of the payment",
+			order:       order,
+			remaining:   104,
+			itemsTotal:  itemsTotal,
+			wantAmount:  104,
+			wantAmounts: []float64{59.4, 39.6},
+		},
+		{
+			name:        "the whole order only refunds the quantities left",
+			order:       order,
+			refunded:    map[uint]int{1: 1},
+			remaining:   74.3,
+			itemsTotal:  itemsTotal,
+			wantAmount:  74.3,
+			wantAmounts: []float64{29.7, 39.6},
+		},
+		{
+			name:        "an item is refunded its share of the discount and the tax",
+			order:       order,
+			items:       []payload.RefundItemRequest{{OrderItemID: 1, Quantity: 1}},
+			remaining:   104,
+			itemsTotal:  itemsTotal,
+			wantAmount:  29.7,
+			wantAmounts: []float64{29.7},
+		},
+		{
+			name:        "every item refunded leaves the shipping fee out",
+			order:       order,
+			items:       []payload.RefundItemRequest{{OrderItemID: 1, Quantity: 2}, {OrderItemID: 2, Quantity: 1}},
+			remaining:   104,
+			itemsTotal:  itemsTotal,
+			wantAmount:  99,
+			wantAmounts: []float64{59.4, 39.6},
+		},
+		{
+			name:        "the last unit takes what rounding left of the items",
+			order:       thirds,
+			items:       []payload.RefundItemRequest{{OrderItemID: 3, Quantity: 1}},
+			refunded:    map[uint]int{3: 2},
+			remaining:   11.66,
+			itemsTotal:  20,
+			wantAmount:  6.66,
+			wantAmounts: []float64{6.67},
+		},
+		{
+			name:       "an item of another order",
+			order:      order,
+			items:      []payload.RefundItemRequest{{OrderItemID: 3, Quantity: 1}},
+			remaining:  104,
+			itemsTotal: itemsTotal,
+			wantErr:    true,
+		},
+		{
+			name:       "more than is left of an item",
+			order:      order,
+			items:      []payload.RefundItemRequest{{OrderItemID: 1, Quantity: 2}},
+			refunded:   map[uint]int{1: 1},
+			remaining:  74.3,
+			itemsTotal: itemsTotal,
+			wantErr:    true,
+		},
+		{
+			name:       "more than is left of the payment",
+			order:      order,
+			items:      []payload.RefundItemRequest{{OrderItemID: 2, Quantity: 1}},
+			remaining:  20,
+			itemsTotal: itemsTotal,
+			wantErr:    true,
+		},
+		{
+			name:       "nothing left to refund",
+			order:      order,
+			refunded:   map[uint]int{1: 2, 2: 1},
+			itemsTotal: itemsTotal,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refund, err := buildRefund(tt.order, &payload.CreateRefundRequest{Items: tt.items}, tt.refunded, tt.remaining, tt.itemsTotal)
+			if tt.wantErr {
+				var appErr *payload.AppError
+				if !errors.As(err, &appErr) || appErr.StatusCode != http.StatusBadRequest {
+					t.Errorf("expected the refund to be rejected, got %+v, %v", refund, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if refund.Amount != tt.wantAmount {
+				t.Errorf("expected a refund of %v, got %v", tt.wantAmount, refund.Amount)
+			}
+			if len(refund.RefundItems) != len(tt.wantAmounts) {
+				t.Fatalf("expected %d refunded items, got %d", len(tt.wantAmounts), len(refund.RefundItems))
+			}
+			for i, ri := range refund.RefundItems {
+				if ri.Amount != tt.wantAmounts[i] {
+					t.Errorf("expected item [%d] to be refunded %v, got %v", ri.OrderItemID, tt.wantAmounts[i], ri.Amount)
+				}
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -0,0 +1,166 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/implementations/products"
+	stockMovements "pm/infrastructure/implementations/stock_movements"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+	"strings"
+)
+
+type StockUsecase interface {
+	GetStockHistory(c *gin.Context, productID int64, variantID *uint, pagination *entity.Pagination) (*entity.StockHistory, error)
+	AdjustStock(c *gin.Context, requester entity.Requester, productID int64, reqPayload *payload.StockAdjustmentRequest) (*entity.Product, error)
+}
+
+type stockUsecase struct {
+	p *base.Persistence
+}
+
+func NewStockUsecase(p *base.Persistence) StockUsecase {
+	return stockUsecase{p}
+}
+
+// GetStockHistory returns the stock of the product, or of one of its variants, with the sum of its movements and a
+// page of the movements. Both numbers are the same unless the stock was changed without going through the ledger
+func (s stockUsecase) GetStockHistory(c *gin.Context, productID int64, variantID *uint, pagination *entity.Pagination) (*entity.StockHistory, error) {
+	span := s.p.Logger.Start(c, "GET_STOCK_HISTORY: USECASES", s.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	s.p.Logger.Info("STARTING: GET_STOCK_HISTORY", map[string]interface{}{"product_id": productID, "variant_id": variantID})
+
+	product, err := products.NewProductRepository(c, s.p, s.p.GormDB).GetProductByID(span, productID)
+	if err != nil {
+		s.p.Logger.Error("GET_STOCK_HISTORY: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	if variantID != nil && product.Variant(*variantID) == nil {
+		err := payload.ErrEntityNotFound(variantEntity, fmt.Errorf("variant [%d] of product [%d] not found", *variantID, productID))
+		s.p.Logger.Error("GET_STOCK_HISTORY: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	movementRepo := stockMovements.NewStockMovementRepository(c, s.p, s.p.GormDB)
+	ledger, err := movementRepo.SumMovements(productID, variantID)
+	if err != nil {
+		s.p.Logger.Error("GET_STOCK_HISTORY: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	movements, err := movementRepo.GetStockHistory(productID, variantID, pagination)
+	if err != nil {
+		s.p.Logger.Error("GET_STOCK_HISTORY: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	s.p.Logger.Info("GET_STOCK_HISTORY: SUCCESSFULLY", map[string]interface{}{"movements": len(movements)})
+	return &entity.StockHistory{
+		ProductID: product.ID,
+		VariantID: variantID,
+		Stock:     product.StockOf(variantID),
+		Ledger:    ledger,
+		Movements: movements,
+	}, nil
+}
+
+// AdjustStock moves the stock of the product, or of one of its variants, by the signed quantity of the request, like
+// after a stock count or for damaged goods, and records it as a manual adjustment
+func (s stockUsecase) AdjustStock(c *gin.Context, requester entity.Requester, productID int64, reqPayload *payload.StockAdjustmentRequest) (*entity.Product, error) {
+	span := s.p.Logger.Start(c, "ADJUST_STOCK: USECASES", s.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	s.p.Logger.Info("STARTING: ADJUST_STOCK", map[string]interface{}{"product_id": productID, "data": reqPayload})
+
+	if err := utils.ValidateReqPayload(reqPayload); err != nil {
+		s.p.Logger.Error("ADJUST_STOCK: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInvalidRequest(err)
+	}
+	if reqPayload.Quantity == 0 {
+		err := errors.New("quantity must not be 0")
+		s.p.Logger.Error("ADJUST_STOCK: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInvalidRequest(err)
+	}
+	reference := strings.TrimSpace(reqPayload.Reference)
+	if reference == "" {
+		reference = entity.StockReferenceManual
+	}
+
+	var product *entity.Product
+	err := s.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		item := entity.OrderItem{ProductID: uint(productID), VariantID: reqPayload.VariantID}
+		cause := entity.StockCause{Reason: entity.StockReasonAdjustment, Reference: reference, ActorID: requester.ActorID()}
+		product, err = adjustStock(c, s.p, tx, span, cause, reqPayload.WarehouseID, item, reqPayload.Quantity)
+		return err
+	})
+	if err != nil {
+		s.p.Logger.Error("ADJUST_STOCK: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	cacheProducts(s.p, []entity.Product{*product})
+	s.p.Logger.Info("ADJUST_STOCK: SUCCESSFULLY", map[string]interface{}{"product": product.ID})
+	return product, nil
+}
+
+// adjustStock moves the stock of the item's product, or of its variant, by delta, in the given warehouse or the first
+// one, and records the movement. It has to run in a transaction and returns nil when there is nothing to move
+func adjustStock(c *gin.Context, p *base.Persistence, db *gorm.DB, span trace.Span, cause entity.StockCause, warehouseID *uint, item entity.OrderItem, delta int64) (*entity.Product, error) {
+	if delta == 0 {
+		return nil, nil
+	}
+	product, err := products.NewProductRepository(c, p, db).AdjustStock(span, item, delta)
+	if err != nil {
+		return nil, err
+	}
+	if err := moveWarehouseStock(c, p, db, warehouseID, item, delta); err != nil {
+		return nil, err
+	}
+	item.Quantity = int(delta)
+	return product, recordStockMovements(c, p, db, cause, 1, []entity.OrderItem{item}, []entity.Product{*product})
+}
+
+// recordStockMovements appends to the stock ledger how the stock of every item moved, out of the stock when sign is
+// -1 and back in when it is 1. prods[i] is the product of items[i] as it was right after its stock moved, so every
+// movement gets the balance it left. Like recordOrderEvent it has to run in the transaction of the move itself
+func recordStockMovements(c *gin.Context, p *base.Persistence, db *gorm.DB, cause entity.StockCause, sign int64, items []entity.OrderItem, prods []entity.Product) error {
+	movements := make([]entity.StockMovement, 0)
+	for i, item := range items {
+		reference := cause.Reference
+		if reference == "" {
+			reference = entity.OrderStockReference(item.OrderID)
+		}
+		movements = append(movements, entity.StockMovement{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  sign * int64(item.Quantity),
+			Balance:   prods[i].StockOf(item.VariantID),
+			Reason:    cause.Reason,
+			Reference: reference,
+			ActorID:   cause.ActorID,
+		})
+	}
+	return stockMovements.NewStockMovementRepository(c, p, db).Create(movements...)
+}
+
+// recordOpeningStock records the stock a product or a variant is created with as its first movement, so its ledger
+// adds up to its stock from the start, and puts that stock in the first warehouse
+func recordOpeningStock(c *gin.Context, p *base.Persistence, db *gorm.DB, actorID *uint, productID uint, variantID *uint, stock int64) error {
+	item := entity.OrderItem{ProductID: productID, VariantID: variantID}
+	if err := moveWarehouseStock(c, p, db, nil, item, stock); err != nil {
+		return err
+	}
+	return stockMovements.NewStockMovementRepository(c, p, db).Create(entity.StockMovement{
+		ProductID: productID,
+		VariantID: variantID,
+		Quantity:  stock,
+		Balance:   stock,
+		Reason:    entity.StockReasonOpening,
+		Reference: entity.StockReferenceManual,
+		ActorID:   actorID,
+	})
+}
\ No newline at end of file
@@ -0,0 +1,119 @@
+package application
+
+import (
+	"github.com/gin-gonic/gin"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/implementations/suppliers"
+	"pm/infrastructure/mapper"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+)
+
+type SupplierUsecase interface {
+	GetAllSuppliers(c *gin.Context, pagination *entity.Pagination) ([]entity.Supplier, error)
+	GetSupplierByID(c *gin.Context, id int64) (*entity.Supplier, error)
+	CreateSupplier(c *gin.Context, reqPayload *payload.SupplierRequest) (*entity.Supplier, error)
+	UpdateSupplier(c *gin.Context, id int64, reqPayload *payload.SupplierRequest) (*entity.Supplier, error)
+	DeleteSupplier(c *gin.Context, id int64) error
+}
+
+type supplierUsecase struct {
+	p *base.Persistence
+}
+
+func NewSupplierUsecase(p *base.Persistence) SupplierUsecase {
+	return supplierUsecase{p}
+}
+
+func (s supplierUsecase) GetAllSuppliers(c *gin.Context, pagination *entity.Pagination) ([]entity.Supplier, error) {
+	span := s.p.Logger.Start(c, "GET_ALL_SUPPLIERS: USECASES", s.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	listSuppliers, err := suppliers.NewSupplierRepository(c, s.p, s.p.GormDB).GetAllSuppliers(pagination)
+	if err != nil {
+		s.p.Logger.Error("GET_ALL_SUPPLIERS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return listSuppliers, nil
+}
+
+func (s supplierUsecase) GetSupplierByID(c *gin.Context, id int64) (*entity.Supplier, error) {
+	span := s.p.Logger.Start(c, "GET_SUPPLIER: USECASES", s.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	supplier, err := suppliers.NewSupplierRepository(c, s.p, s.p.GormDB).GetSupplierByID(id)
+	if err != nil {
+		s.p.Logger.Error("GET_SUPPLIER: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return supplier, nil
+}
+
+func (s supplierUsecase) CreateSupplier(c *gin.Context, reqPayload *payload.SupplierRequest) (*entity.Supplier, error) {
+	span := s.p.Logger.Start(c, "CREATE_SUPPLIER: USECASES", s.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	s.p.Logger.Info("STARTING: CREATE_SUPPLIER", map[string]interface{}{"data": reqPayload})
+
+	if err := utils.ValidateReqPayload(reqPayload); err != nil {
+		s.p.Logger.Error("CREATE_SUPPLIER: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInvalidRequest(err)
+	}
+
+	var supplier entity.Supplier
+	mapper.SupplierPayloadToSupplier(reqPayload, &supplier)
+	if err := suppliers.NewSupplierRepository(c, s.p, s.p.GormDB).Create(&supplier); err != nil {
+		s.p.Logger.Error("CREATE_SUPPLIER: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	s.p.Logger.Info("CREATE_SUPPLIER: SUCCESSFULLY", map[string]interface{}{"supplier": supplier})
+	return &supplier, nil
+}
+
+func (s supplierUsecase) UpdateSupplier(c *gin.Context, id int64, reqPayload *payload.SupplierRequest) (*entity.Supplier, error) {
+	span := s.p.Logger.Start(c, "UPDATE_SUPPLIER: USECASES", s.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	s.p.Logger.Info("STARTING: UPDATE_SUPPLIER", map[string]interface{}{"id": id, "data": reqPayload})
+
+	if err := utils.ValidateReqPayload(reqPayload); err != nil {
+		s.p.Logger.Error("UPDATE_SUPPLIER: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInvalidRequest(err)
+	}
+
+	supplierRepo := suppliers.NewSupplierRepository(c, s.p, s.p.GormDB)
+	supplier, err := supplierRepo.GetSupplierByID(id)
+	if err != nil {
+		s.p.Logger.Error("UPDATE_SUPPLIER: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	mapper.SupplierPayloadToSupplier(reqPayload, supplier)
+	if err := supplierRepo.Update(supplier); err != nil {
+		s.p.Logger.Error("UPDATE_SUPPLIER: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	s.p.Logger.Info("UPDATE_SUPPLIER: SUCCESSFULLY", map[string]interface{}{"supplier": supplier})
+	return supplier, nil
+}
+
+// DeleteSupplier deletes a supplier that has no purchase orders waiting for goods anymore, the purchase orders it
+// had keep showing it
+func (s supplierUsecase) DeleteSupplier(c *gin.Context, id int64) error {
+	span := s.p.Logger.Start(c, "DELETE_SUPPLIER: USECASES", s.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	supplierRepo := suppliers.NewSupplierRepository(c, s.p, s.p.GormDB)
+	supplier, err := supplierRepo.GetSupplierByID(id)
+	if err != nil {
+		s.p.Logger.Error("DELETE_SUPPLIER: ERROR", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+	if err := supplierRepo.DeleteSupplier(supplier); err != nil {
+		s.p.Logger.Error("DELETE_SUPPLIER: ERROR", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+
+	s.p.Logger.Info("DELETE_SUPPLIER: SUCCESSFULLY", map[string]interface{}{"deleted_id": id})
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,327 @@
+package application
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/implementations/categories"
+	"pm/infrastructure/implementations/taxes"
+	"pm/infrastructure/mapper"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+	"strings"
+)
+
+const (
+	taxClassEntity string = "tax_classes"
+	taxRateEntity  string = "tax_rates"
+)
+
+type TaxUsecase interface {
+	GetAllTaxClasses(*gin.Context) ([]entity.TaxClass, error)
+	GetTaxClassByID(*gin.Context, int64) (*entity.TaxClass, error)
+	CreateTaxClass(*gin.Context, *payload.TaxClassRequest) (*entity.TaxClass, error)
+	UpdateTaxClass(c *gin.Context, id int64, reqPayload *payload.TaxClassRequest) (*entity.TaxClass, error)
+	DeleteTaxClass(*gin.Context, int64) error
+	GetTaxRatesByClassID(*gin.Context, int64) ([]entity.TaxRate, error)
+	CreateTaxRate(c *gin.Context, taxClassID int64, reqPayload *payload.TaxRateRequest) (*entity.TaxRate, error)
+	UpdateTaxRate(c *gin.Context, id int64, reqPayload *payload.TaxRateRequest) (*entity.TaxRate, error)
+	DeleteTaxRate(*gin.Context, int64) error
+}
+
+type taxUsecase struct {
+	p *base.Persistence
+}
+
+func NewTaxUsecase(p *base.Persistence) TaxUsecase {
+	return taxUsecase{p}
+}
+
+func (t taxUsecase) GetAllTaxClasses(c *gin.Context) ([]entity.TaxClass, error) {
+	span := t.p.Logger.Start(c, "GET_ALL_TAX_CLASSES: USECASES", t.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	listTaxClasses, err := taxes.NewTaxRepository(c, t.p, t.p.GormDB).GetAllTaxClasses()
+	if err != nil {
+		t.p.Logger.Error("GET_ALL_TAX_CLASSES: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return listTaxClasses, nil
+}
+
+func (t taxUsecase) GetTaxClassByID(c *gin.Context, id int64) (*entity.TaxClass, error) {
+	span := t.p.Logger.Start(c, "GET_TAX_CLASS_BY_ID: USECASES", t.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	taxClass, err := taxes.NewTaxRepository(c, t.p, t.p.GormDB).GetTaxClassByID(id)
+	if err != nil {
+		t.p.Logger.Error("GET_TAX_CLASS_BY_ID: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return taxClass, nil
+}
+
+func (t taxUsecase) CreateTaxClass(c *gin.Context, reqPayload *payload.TaxClassRequest) (*entity.TaxClass, error) {
+	span := t.p.Logger.Start(c, "CREATE_TAX_CLASS: USECASES", t.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	t.p.Logger.Info("STARTING: CREATE_TAX_CLASS", map[string]interface{}{"data": reqPayload})
+
+	var taxClass entity.TaxClass
+	mapper.TaxClassPayloadToTaxClass(reqPayload, &taxClass)
+	if err := t.validateTaxClass(c, reqPayload, &taxClass); err != nil {
+		t.p.Logger.Error("CREATE_TAX_CLASS: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	if err := taxes.NewTaxRepository(c, t.p, t.p.GormDB).CreateTaxClass(&taxClass); err != nil {
+		t.p.Logger.Error("CREATE_TAX_CLASS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	t.p.Logger.Info("CREATE_TAX_CLASS: SUCCESSFULLY", map[string]interface{}{"tax_class": taxClass})
+	return &taxClass, nil
+}
+
+func (t taxUsecase) UpdateTaxClass(c *gin.Context, id int64, reqPayload *payload.TaxClassRequest) (*entity.TaxClass, error) {
+	span := t.p.Logger.Start(c, "UPDATE_TAX_CLASS: USECASES", t.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	t.p.Logger.Info("STARTING: UPDATE_TAX_CLASS", map[string]interface{}{"id": id, "data": reqPayload})
+
+	taxRepo := taxes.NewTaxRepository(c, t.p, t.p.GormDB)
+	taxClass, err := taxRepo.GetTaxClassByID(id)
+	if err != nil {
+		t.p.Logger.Error("UPDATE_TAX_CLASS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	mapper.TaxClassPayloadToTaxClass(reqPayload, taxClass)
+	if err := t.validateTaxClass(c, reqPayload, taxClass); err != nil {
+		t.p.Logger.Error("UPDATE_TAX_CLASS: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	if err := taxRepo.UpdateTaxClass(taxClass); err != nil {
+		t.p.Logger.Error("UPDATE_TAX_CLASS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	t.p.Logger.Info("UPDATE_TAX_CLASS: SUCCESSFULLY", map[string]interface{}{"tax_class": taxClass})
+	return taxClass, nil
+}
+
+// DeleteTaxClass deletes the tax class with its rates, products of the class are not taxed anymore. Orders already
+// placed keep the tax they were placed with
+func (t taxUsecase) DeleteTaxClass(c *gin.Context, id int64) error {
+	span := t.p.Logger.Start(c, "DELETE_TAX_CLASS: USECASES", t.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	t.p.Logger.Info("STARTING: DELETE_TAX_CLASS", map[string]interface{}{"id": id})
+
+	taxRepo := taxes.NewTaxRepository(c, t.p, t.p.GormDB)
+	taxClass, err := taxRepo.GetTaxClassByID(id)
+	if err != nil {
+		t.p.Logger.Error("DELETE_TAX_CLASS: ERROR", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+	if err := taxRepo.DeleteTaxClass(taxClass); err != nil {
+		t.p.Logger.Error("DELETE_TAX_CLASS: ERROR", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+
+	t.p.Logger.Info("DELETE_TAX_CLASS: SUCCESSFULLY", map[string]interface{}{"deleted_id": id})
+	return nil
+}
+
+func (t taxUsecase) GetTaxRatesByClassID(c *gin.Context, taxClassID int64) ([]entity.TaxRate, error) {
+	span := t.p.Logger.Start(c, "GET_TAX_RATES_BY_CLASS_ID: USECASES", t.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	taxRepo := taxes.NewTaxRepository(c, t.p, t.p.GormDB)
+	if _, err := taxRepo.GetTaxClassByID(taxClassID); err != nil {
+		t.p.Logger.Error("GET_TAX_RATES_BY_CLASS_ID: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	listTaxRates, err := taxRepo.GetTaxRatesByClassID(taxClassID)
+	if err != nil {
+		t.p.Logger.Error("GET_TAX_RATES_BY_CLASS_ID: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return listTaxRates, nil
+}
+
+func (t taxUsecase) CreateTaxRate(c *gin.Context, taxClassID int64, reqPayload *payload.TaxRateRequest) (*entity.TaxRate, error) {
+	span := t.p.Logger.Start(c, "CREATE_TAX_RATE: USECASES", t.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	t.p.Logger.Info("STARTING: CREATE_TAX_RATE", map[string]interface{}{"tax_class_id": taxClassID, "data": reqPayload})
+
+	taxRepo := taxes.NewTaxRepository(c, t.p, t.p.GormDB)
+	taxClass, err := taxRepo.GetTaxClassByID(taxClassID)
+	if err != nil {
+		t.p.Logger.Error("CREATE_TAX_RATE: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	taxRate := entity.TaxRate{TaxClassID: taxClass.ID}
+	mapper.TaxRatePayloadToTaxRate(reqPayload, &taxRate)
+	if err := t.validateTaxRate(c, reqPayload, &taxRate); err != nil {
+		t.p.Logger.Error("CREATE_TAX_RATE: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	if err := taxRepo.CreateTaxRate(&taxRate); err != nil {
+		t.p.Logger.Error("CREATE_TAX_RATE: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	t.p.Logger.Info("CREATE_TAX_RATE: SUCCESSFULLY", map[string]interface{}{"tax_rate": taxRate})
+	return &taxRate, nil
+}
+
+// UpdateTaxRate changes the rate for the orders placed from now on, orders already placed keep the tax they were
+// placed with
+func (t taxUsecase) UpdateTaxRate(c *gin.Context, id int64, reqPayload *payload.TaxRateRequest) (*entity.TaxRate, error) {
+	span := t.p.Logger.Start(c, "UPDATE_TAX_RATE: USECASES", t.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	t.p.Logger.Info("STARTING: UPDATE_TAX_RATE", map[string]interface{}{"id": id, "data": reqPayload})
+
+	taxRepo := taxes.NewTaxRepository(c, t.p, t.p.GormDB)
+	taxRate, err := taxRepo.GetTaxRateByID(id)
+	if err != nil {
+		t.p.Logger.Error("UPDATE_TAX_RATE: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	mapper.TaxRatePayloadToTaxRate(reqPayload, taxRate)
+	if err := t.validateTaxRate(c, reqPayload, taxRate); err != nil {
+		t.p.Logger.Error("UPDATE_TAX_RATE: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	if err := taxRepo.UpdateTaxRate(taxRate); err != nil {
+		t.p.Logger.Error("UPDATE_TAX_RATE: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	t.p.Logger.Info("UPDATE_TAX_RATE: SUCCESSFULLY", map[string]interface{}{"tax_rate": taxRate})
+	return taxRate, nil
+}
+
+func (t taxUsecase) DeleteTaxRate(c *gin.Context, id int64) error {
+	span := t.p.Logger.Start(c, "DELETE_TAX_RATE: USECASES", t.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	t.p.Logger.Info("STARTING: DELETE_TAX_RATE", map[string]interface{}{"id": id})
+
+	taxRepo := taxes.NewTaxRepository(c, t.p, t.p.GormDB)
+	taxRate, err := taxRepo.GetTaxRateByID(id)
+	if err != nil {
+		t.p.Logger.Error("DELETE_TAX_RATE: ERROR", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+	if err := taxRepo.DeleteTaxRate(taxRate); err != nil {
+		t.p.Logger.Error("DELETE_TAX_RATE: ERROR", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+
+	t.p.Logger.Info("DELETE_TAX_RATE: SUCCESSFULLY", map[string]interface{}{"deleted_id": id})
+	return nil
+}
+
+// validateTaxClass makes sure no other tax class has the name of the tax class
+func (t taxUsecase) validateTaxClass(c *gin.Context, reqPayload *payload.TaxClassRequest, taxClass *entity.TaxClass) error {
+	if err := utils.ValidateReqPayload(reqPayload); err != nil {
+		return payload.ErrInvalidRequest(err)
+	}
+
+	listTaxClasses, err := taxes.NewTaxRepository(c, t.p, t.p.GormDB).GetAllTaxClasses()
+	if err != nil {
+		return err
+	}
+	for _, v := range listTaxClasses {
+		if v.Name == taxClass.Name && v.ID != taxClass.ID {
+			return payload.ErrEntityExisted(taxClassEntity, fmt.Errorf("tax class [%s] already exists", taxClass.Name))
+		}
+	}
+	return nil
+}
+
+// validateTaxRate makes sure the tax class has no other rate for the region of the rate
+func (t taxUsecase) validateTaxRate(c *gin.Context, reqPayload *payload.TaxRateRequest, taxRate *entity.TaxRate) error {
+	if err := utils.ValidateReqPayload(reqPayload); err != nil {
+		return payload.ErrInvalidRequest(err)
+	}
+
+	listTaxRates, err := taxes.NewTaxRepository(c, t.p, t.p.GormDB).GetTaxRatesByClassID(int64(taxRate.TaxClassID))
+	if err != nil {
+		return err
+	}
+	for _, v := range listTaxRates {
+		if v.Country == taxRate.Country && strings.EqualFold(v.Region, taxRate.Region) && v.ID != taxRate.ID {
+			return payload.ErrEntityExisted(taxRateEntity, fmt.Errorf("tax class [%d] already has a rate in %s %s", taxRate.TaxClassID, taxRate.Country, taxRate.Region))
+		}
+	}
+	return nil
+}
+
+// checkTaxClass makes sure the tax class a product or a category is given exists, no tax class is fine
+func checkTaxClass(c *gin.Context, p *base.Persistence, taxClassID *uint) error {
+	if taxClassID == nil {
+		return nil
+	}
+	_, err := taxes.NewTaxRepository(c, p, p.GormDB).GetTaxClassByID(int64(*taxClassID))
+	if isNotFound(err) {
+		return payload.ErrInvalidRequest(fmt.Errorf("tax class [%d] does not exist", *taxClassID))
+	}
+	return err
+}
+
+// taxOrderItems taxes every item of the order with the rate of its tax class in the region the order is shipped to,
+// the city of the shipping address. prods must hold the product of every item. An item whose product has no tax class
+// or whose class has no rate in the region is not taxed
+func taxOrderItems(c *gin.Context, p *base.Persistence, db *gorm.DB, span trace.Span, order *entity.Order, prods []entity.Product) error {
+	taxRepo := taxes.NewTaxRepository(c, p, db)
+	categoryRepo := categories.NewCategoryRepository(c, p, db)
+
+	productsByID := make(map[uint]entity.Product)
+	for _, v := range prods {
+		productsByID[v.ID] = v
+	}
+	categoryClasses := make(map[int64]*uint)
+	rates := make(map[uint]float64)
+
+	for i := range order.OrderItems {
+		product := productsByID[order.OrderItems[i].ProductID]
+		taxClassID := product.TaxClassID
+		if taxClassID == nil {
+			classID, ok := categoryClasses[product.CategoryID]
+			if !ok {
+				category, err := categoryRepo.GetCategoryByID(span, product.CategoryID)
+				if err != nil && !isNotFound(err) {
+					return err
+				}
+				if category != nil {
+					classID = category.TaxClassID
+				}
+				categoryClasses[product.CategoryID] = classID
+			}
+			taxClassID = classID
+		}
+		if taxClassID == nil {
+			order.OrderItems[i].ApplyTaxRate(0)
+			continue
+		}
+
+		rate, ok := rates[*taxClassID]
+		if !ok {
+			taxRate, err := taxRepo.GetTaxRateForRegion(*taxClassID, order.ShippingAddress.Country, order.ShippingAddress.City)
+			if err != nil && !isNotFound(err) {
+				return err
+			}
+			if taxRate != nil {
+				rate = taxRate.Rate
+			}
+			rates[*taxClassID] = rate
+		}
+		order.OrderItems[i].ApplyTaxRate(rate)
+	}
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,440 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"pm/domain/entity"
+	"pm/infrastructure/config"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/implementations/orders"
+	"pm/infrastructure/implementations/products"
+	"pm/infrastructure/implementations/warehouses"
+	"pm/infrastructure/mapper"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+)
+
+const warehouseEntity string = "warehouses"
+
+type WarehouseUsecase interface {
+	GetAllWarehouses(c *gin.Context) ([]entity.Warehouse, error)
+	CreateWarehouse(c *gin.Context, reqPayload *payload.WarehouseRequest) (*entity.Warehouse, error)
+	UpdateWarehouse(c *gin.Context, id int64, reqPayload *payload.WarehouseRequest) (*entity.Warehouse, error)
+	DeleteWarehouse(c *gin.Context, id int64) error
+	GetStockLevels(c *gin.Context, id int64) ([]entity.WarehouseStock, error)
+	TransferStock(c *gin.Context, requester entity.Requester, reqPayload *payload.StockTransferRequest) (*entity.StockTransfer, error)
+	GetPickingLists(c *gin.Context, orderID int64) ([]entity.PickingList, error)
+}
+
+type warehouseUsecase struct {
+	p *base.Persistence
+}
+
+func NewWarehouseUsecase(p *base.Persistence) WarehouseUsecase {
+	return warehouseUsecase{p}
+}
+
+func (w warehouseUsecase) GetAllWarehouses(c *gin.Context) ([]entity.Warehouse, error) {
+	span := w.p.Logger.Start(c, "GET_ALL_WAREHOUSES: USECASES", w.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	listWarehouses, err := warehouses.NewWarehouseRepository(c, w.p, w.p.GormDB).GetAllWarehouses()
+	if err != nil {
+		w.p.Logger.Error("GET_ALL_WAREHOUSES: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return listWarehouses, nil
+}
+
+// CreateWarehouse adds a warehouse. The first one takes over all the stock there is, so from then on the stock
+// counters are the sum of the stock of the warehouses. Warehouses are created one at a time, or two created together
+// could both count none and take over the stock twice
+func (w warehouseUsecase) CreateWarehouse(c *gin.Context, reqPayload *payload.WarehouseRequest) (*entity.Warehouse, error) {
+	span := w.p.Logger.Start(c, "CREATE_WAREHOUSE: USECASES", w.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	w.p.Logger.Info("STARTING: CREATE_WAREHOUSE", map[string]interface{}{"data": reqPayload})
+
+	var warehouse entity.Warehouse
+	mapper.WarehousePayloadToWarehouse(reqPayload, &warehouse)
+	if err := w.validateWarehouse(c, reqPayload, &warehouse); err != nil {
+		w.p.Logger.Error("CREATE_WAREHOUSE: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	err := w.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		warehouseRepo := warehouses.NewWarehouseRepository(c, w.p, tx)
+		if err := warehouseRepo.LockWarehouses(); err != nil {
+			return err
+		}
+		count, err := warehouseRepo.CountWarehouses()
+		if err != nil {
+			return err
+		}
+		if err := warehouseRepo.Create(&warehouse); err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		return warehouseRepo.TakeOverStock(warehouse.ID)
+	})
+	if err != nil {
+		w.p.Logger.Error("CREATE_WAREHOUSE: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	w.p.Logger.Info("CREATE_WAREHOUSE: SUCCESSFULLY", map[string]interface{}{"warehouse": warehouse})
+	return &warehouse, nil
+}
+
+func (w warehouseUsecase) UpdateWarehouse(c *gin.Context, id int64, reqPayload *payload.WarehouseRequest) (*entity.Warehouse, error) {
+	span := w.p.Logger.Start(c, "UPDATE_WAREHOUSE: USECASES", w.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	w.p.Logger.Info("STARTING: UPDATE_WAREHOUSE", map[string]interface{}{"id": id, "data": reqPayload})
+
+	warehouseRepo := warehouses.NewWarehouseRepository(c, w.p, w.p.GormDB)
+	warehouse, err := warehouseRepo.GetWarehouseByID(id)
+	if err != nil {
+		w.p.Logger.Error("UPDATE_WAREHOUSE: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	mapper.WarehousePayloadToWarehouse(reqPayload, warehouse)
+	if err := w.validateWarehouse(c, reqPayload, warehouse); err != nil {
+		w.p.Logger.Error("UPDATE_WAREHOUSE: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	if err := warehouseRepo.Update(warehouse); err != nil {
+		w.p.Logger.Error("UPDATE_WAREHOUSE: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	w.p.Logger.Info("UPDATE_WAREHOUSE: SUCCESSFULLY", map[string]interface{}{"warehouse": warehouse})
+	return warehouse, nil
+}
+
+// DeleteWarehouse deletes a warehouse that does not hold any stock anymore
+func (w warehouseUsecase) DeleteWarehouse(c *gin.Context, id int64) error {
+	span := w.p.Logger.Start(c, "DELETE_WAREHOUSE: USECASES", w.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	warehouseRepo := warehouses.NewWarehouseRepository(c, w.p, w.p.GormDB)
+	warehouse, err := warehouseRepo.GetWarehouseByID(id)
+	if err != nil {
+		w.p.Logger.Error("DELETE_WAREHOUSE: ERROR", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+	if err := warehouseRepo.DeleteWarehouse(warehouse); err != nil {
+		w.p.Logger.Error("DELETE_WAREHOUSE: ERROR", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+
+	w.p.Logger.Info("DELETE_WAREHOUSE: SUCCESSFULLY", map[string]interface{}{"deleted_id": id})
+	return nil
+}
+
+func (w warehouseUsecase) GetStockLevels(c *gin.Context, id int64) ([]entity.WarehouseStock, error) {
+	span := w.p.Logger.Start(c, "GET_STOCK_LEVELS: USECASES", w.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	warehouseRepo := warehouses.NewWarehouseRepository(c, w.p, w.p.GormDB)
+	if _, err := warehouseRepo.GetWarehouseByID(id); err != nil {
+		w.p.Logger.Error("GET_STOCK_LEVELS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	stocks, err := warehouseRepo.GetStockLevels(id)
+	if err != nil {
+		w.p.Logger.Error("GET_STOCK_LEVELS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	return stocks, nil
+}
+
+// TransferStock moves stock of a product, or of one of its variants, from a warehouse to another. The stock counter
+// of the product stays the same, so the ledger does not record it
+func (w warehouseUsecase) TransferStock(c *gin.Context, requester entity.Requester, reqPayload *payload.StockTransferRequest) (*entity.StockTransfer, error) {
+	span := w.p.Logger.Start(c, "TRANSFER_STOCK: USECASES", w.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+	w.p.Logger.Info("STARTING: TRANSFER_STOCK", map[string]interface{}{"data": reqPayload})
+
+	if err := utils.ValidateReqPayload(reqPayload); err != nil {
+		w.p.Logger.Error("TRANSFER_STOCK: ERROR INVALID DATA", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInvalidRequest(err)
+	}
+
+	transfer := entity.StockTransfer{
+		FromWarehouseID: reqPayload.FromWarehouseID,
+		ToWarehouseID:   reqPayload.ToWarehouseID,
+		ProductID:       reqPayload.ProductID,
+		VariantID:       reqPayload.VariantID,
+		Quantity:        reqPayload.Quantity,
+		ActorID:         requester.ActorID(),
+	}
+	err := w.p.GormDB.Transaction(func(tx *gorm.DB) error {
+		warehouseRepo := warehouses.NewWarehouseRepository(c, w.p, tx)
+		for _, id := range []uint{transfer.FromWarehouseID, transfer.ToWarehouseID} {
+			if _, err := warehouseRepo.GetWarehouseByID(int64(id)); err != nil {
+				return err
+			}
+		}
+		item := entity.OrderItem{ProductID: transfer.ProductID, VariantID: transfer.VariantID}
+		if err := checkStockItem(c, w.p, tx, span, item); err != nil {
+			return err
+		}
+		if err := warehouseRepo.MoveStock(transfer.FromWarehouseID, item, -transfer.Quantity); err != nil {
+			return err
+		}
+		if err := warehouseRepo.MoveStock(transfer.ToWarehouseID, item, transfer.Quantity); err != nil {
+			return err
+		}
+		return warehouseRepo.CreateTransfer(&transfer)
+	})
+	if err != nil {
+		w.p.Logger.Error("TRANSFER_STOCK: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	w.p.Logger.Info("TRANSFER_STOCK: SUCCESSFULLY", map[string]interface{}{"transfer": transfer})
+	return &transfer, nil
+}
+
+// GetPickingLists returns, for every warehouse the order is allocated from, what has to be picked there. Items of
+// orders placed before there were warehouses are not allocated and are left out
+func (w warehouseUsecase) GetPickingLists(c *gin.Context, orderID int64) ([]entity.PickingList, error) {
+	span := w.p.Logger.Start(c, "GET_PICKING_LISTS: USECASES", w.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	order, err := orders.NewOrderRepository(c, w.p, w.p.GormDB).GetOrderByID(orderID)
+	if err != nil {
+		w.p.Logger.Error("GET_PICKING_LISTS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	items := make(map[uint]entity.OrderItem)
+	itemIDs := make([]uint, 0)
+	productIDs := make([]uint, 0)
+	for _, item := range order.OrderItems {
+		items[item.ID] = item
+		itemIDs = append(itemIDs, item.ID)
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	warehouseRepo := warehouses.NewWarehouseRepository(c, w.p, w.p.GormDB)
+	allocations, err := warehouseRepo.GetAllocations(itemIDs...)
+	if err != nil {
+		w.p.Logger.Error("GET_PICKING_LISTS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	listWarehouses, err := warehouseRepo.GetAllWarehouses()
+	if err != nil {
+		w.p.Logger.Error("GET_PICKING_LISTS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	prods, err := products.NewProductRepository(c, w.p, w.p.GormDB).GetProductsByIDs(span, productIDs...)
+	if err != nil {
+		w.p.Logger.Error("GET_PICKING_LISTS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+	prodsByID := make(map[uint]entity.Product)
+	for _, prod := range prods {
+		prodsByID[prod.ID] = prod
+	}
+
+	lists := make([]entity.PickingList, 0)
+	for _, warehouse := range listWarehouses {
+		list := entity.PickingList{Warehouse: warehouse, Lines: make([]entity.PickingLine, 0)}
+		for _, a := range allocations {
+			if a.WarehouseID != warehouse.ID {
+				continue
+			}
+			item := items[a.OrderItemID]
+			line := entity.PickingLine{OrderItemID: item.ID, ProductID: item.ProductID, VariantID: item.VariantID, Quantity: a.Quantity}
+			prod := prodsByID[item.ProductID]
+			line.Name = prod.Name
+			if item.VariantID != nil {
+				if v := prod.Variant(*item.VariantID); v != nil {
+					line.Name = prod.Name + " - " + v.Describe()
+					line.SKU = v.SKU
+				}
+			}
+			list.Lines = append(list.Lines, line)
+		}
+		if len(list.Lines) > 0 {
+			lists = append(lists, list)
+		}
+	}
+
+	w.p.Logger.Info("GET_PICKING_LISTS: SUCCESSFULLY", map[string]interface{}{"warehouses": len(lists)})
+	return lists, nil
+}
+
+// validateWarehouse makes sure no other warehouse has the code of the warehouse
+func (w warehouseUsecase) validateWarehouse(c *gin.Context, reqPayload *payload.WarehouseRequest, warehouse *entity.Warehouse) error {
+	if err := utils.ValidateReqPayload(reqPayload); err != nil {
+		return payload.ErrInvalidRequest(err)
+	}
+	if warehouse.Code == "" {
+		return payload.ErrInvalidRequest(errors.New("code must not be blank"))
+	}
+
+	existing, err := warehouses.NewWarehouseRepository(c, w.p, w.p.GormDB).GetWarehouseByCode(warehouse.Code)
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != warehouse.ID {
+		return payload.ErrEntityExisted(warehouseEntity, fmt.Errorf("warehouse [%s] already exists", warehouse.Code))
+	}
+	return nil
+}
+
+// checkStockItem makes sure the product of the item exists and that the item names one of its variants when, and
+// only when, the product has some, as its stock lives on them
+func checkStockItem(c *gin.Context, p *base.Persistence, db *gorm.DB, span trace.Span, item entity.OrderItem) error {
+	product, err := products.NewProductRepository(c, p, db).GetProductByID(span, int64(item.ProductID))
+	if err != nil {
+		return err
+	}
+	if item.VariantID != nil {
+		if product.Variant(*item.VariantID) == nil {
+			return payload.ErrEntityNotFound(variantEntity, fmt.Errorf("variant [%d] of product [%d] not found", *item.VariantID, item.ProductID))
+		}
+		return nil
+	}
+	if product.HasVariants() {
+		return payload.ErrInvalidRequest(fmt.Errorf("the product %v keeps its stock on its variants, one of them must be given", item.ProductID))
+	}
+	return nil
+}
+
+func allocationStrategy() string {
+	if config.Configs != nil && entity.IsValidAllocationStrategy(config.Configs.StockConfig.AllocationStrategy) {
+		return config.Configs.StockConfig.AllocationStrategy
+	}
+	return entity.AllocationStrategyPriority
+}
+
+// allocateStock takes the saved items from the warehouses with the configured strategy and stores how much of every
+// item is taken from which warehouse. It has to run in the transaction that takes the stock of the items, nothing
+// happens while there are no warehouses
+func allocateStock(c *gin.Context, p *base.Persistence, db *gorm.DB, items []entity.OrderItem) error {
+	warehouseRepo := warehouses.NewWarehouseRepository(c, p, db)
+	listWarehouses, err := warehouseRepo.GetAllWarehouses()
+	if err != nil || len(listWarehouses) == 0 || len(items) == 0 {
+		return err
+	}
+	stocks, err := warehouseRepo.LockStockLevels(items...)
+	if err != nil {
+		return err
+	}
+	allocations, err := entity.Allocate(allocationStrategy(), listWarehouses, entity.NewStockLevels(stocks), items)
+	if err != nil {
+		return payload.ErrInvalidRequest(err)
+	}
+
+	itemsByID := make(map[uint]entity.OrderItem)
+	for _, item := range items {
+		itemsByID[item.ID] = item
+	}
+	for _, a := range allocations {
+		if err := warehouseRepo.MoveStock(a.WarehouseID, itemsByID[a.OrderItemID], -int64(a.Quantity)); err != nil {
+			return err
+		}
+	}
+	return warehouseRepo.CreateAllocations(allocations...)
+}
+
+// releaseStock gives the quantity of every item back to the warehouses it was allocated from, the latest allocation
+// first. What was never allocated, like items of orders placed before there were warehouses, goes to the first one
+func releaseStock(c *gin.Context, p *base.Persistence, db *gorm.DB, items []entity.OrderItem) error {
+	warehouseRepo := warehouses.NewWarehouseRepository(c, p, db)
+	listWarehouses, err := warehouseRepo.GetAllWarehouses()
+	if err != nil || len(listWarehouses) == 0 || len(items) == 0 {
+		return err
+	}
+	itemIDs := make([]uint, 0)
+	for _, item := range items {
+		itemIDs = append(itemIDs, item.ID)
+	}
+	allocations, err := warehouseRepo.GetAllocations(itemIDs...)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		remaining := item.Quantity
+		for i := len(allocations) - 1; i >= 0 && remaining > 0; i-- {
+			a := &allocations[i]
+			if a.OrderItemID != item.ID || a.Quantity <= 0 {
+				continue
+			}
+			released := min(a.Quantity, remaining)
+			if err := warehouseRepo.MoveStock(a.WarehouseID, item, int64(released)); err != nil {
+				return err
+			}
+			if err := warehouseRepo.ReduceAllocation(a, released); err != nil {
+				return err
+			}
+			remaining -= released
+		}
+		if remaining > 0 {
+			if err := warehouseRepo.MoveStock(listWarehouses[0].ID, item, int64(remaining)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// returnStock puts returned items back in the warehouse they were first taken from, or in the first warehouse when
+// they were never allocated. Their allocations stay, they still tell where the items were shipped from
+func returnStock(c *gin.Context, p *base.Persistence, db *gorm.DB, items []entity.OrderItem) error {
+	warehouseRepo := warehouses.NewWarehouseRepository(c, p, db)
+	listWarehouses, err := warehouseRepo.GetAllWarehouses()
+	if err != nil || len(listWarehouses) == 0 || len(items) == 0 {
+		return err
+	}
+	itemIDs := make([]uint, 0)
+	for _, item := range items {
+		itemIDs = append(itemIDs, item.ID)
+	}
+	allocations, err := warehouseRepo.GetAllocations(itemIDs...)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		warehouseID := listWarehouses[0].ID
+		for _, a := range allocations {
+			if a.OrderItemID == item.ID {
+				warehouseID = a.WarehouseID
+				break
+			}
+		}
+		if err := warehouseRepo.MoveStock(warehouseID, item, int64(item.Quantity)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveWarehouseStock moves the stock of the item in the warehouse by delta, in the first warehouse when none is
+// given. Nothing happens while there are no warehouses
+func moveWarehouseStock(c *gin.Context, p *base.Persistence, db *gorm.DB, warehouseID *uint, item entity.OrderItem, delta int64) error {
+	warehouseRepo := warehouses.NewWarehouseRepository(c, p, db)
+	if warehouseID != nil {
+		if _, err := warehouseRepo.GetWarehouseByID(int64(*warehouseID)); err != nil {
+			return err
+		}
+		return warehouseRepo.MoveStock(*warehouseID, item, delta)
+	}
+	listWarehouses, err := warehouseRepo.GetAllWarehouses()
+	if err != nil || len(listWarehouses) == 0 || delta == 0 {
+		return err
+	}
+	return warehouseRepo.MoveStock(listWarehouses[0].ID, item, delta)
+}
\ No newline at end of file
@@ -0,0 +1,47 @@
+package entity
+
+import "gorm.io/gorm"
+
+// Address is an entry of the address book of a user, a user has at most one default address
+type Address struct {
+	gorm.Model
+	UserID        uint   `gorm:"index;uniqueIndex:idx_addresses_user_default,where:is_default = true AND deleted_at IS NULL"`
+	RecipientName string `gorm:"type:varchar(150)"`
+	Phone         string `gorm:"type:varchar(20)"`
+	Line1         string `gorm:"type:varchar(255)"`
+	Line2         string `gorm:"type:varchar(255)"`
+	Ward          string `gorm:"type:varchar(100)"`
+	District      string `gorm:"type:varchar(100)"`
+	City          string `gorm:"type:varchar(100)"`
+	Country       string `gorm:"type:varchar(2)"`
+	PostalCode    string `gorm:"type:varchar(20)"`
+	IsDefault     bool
+}
+
+// ShippingAddress is the copy of an address kept on an order. It is taken when the order is created and never
+// changes afterwards, whatever happens to the address book
+type ShippingAddress struct {
+	RecipientName string `gorm:"type:varchar(150)" json:"recipientName"`
+	Phone         string `gorm:"type:varchar(20)" json:"phone"`
+	Line1         string `gorm:"type:varchar(255)" json:"line1"`
+	Line2         string `gorm:"type:varchar(255)" json:"line2"`
+	Ward          string `gorm:"type:varchar(100)" json:"ward"`
+	District      string `gorm:"type:varchar(100)" json:"district"`
+	City          string `gorm:"type:varchar(100)" json:"city"`
+	Country       string `gorm:"type:varchar(2)" json:"country"`
+	PostalCode    string `gorm:"type:varchar(20)" json:"postalCode"`
+}
+
+func (a *Address) ToShippingAddress() ShippingAddress {
+	return ShippingAddress{
+		RecipientName: a.RecipientName,
+		Phone:         a.Phone,
+		Line1:         a.Line1,
+		Line2:         a.Line2,
+		Ward:          a.Ward,
+		District:      a.District,
+		City:          a.City,
+		Country:       a.Country,
+		PostalCode:    a.PostalCode,
+	}
+}
\ No newline at end of file
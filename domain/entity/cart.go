@@ -0,0 +1,92 @@
+package entity
+
+import "gorm.io/gorm"
+
+// CartItem is one line of the cart of a user, a user has at most one line per product, or per variant of a product
+// that has variants. Carts live on redis, the table keeps them when redis is down or loses them
+type CartItem struct {
+	gorm.Model
+	UserID    uint  `gorm:"uniqueIndex:idx_cart_items_user_product,where:variant_id IS NULL;uniqueIndex:idx_cart_items_user_variant"`
+	ProductID uint  `gorm:"uniqueIndex:idx_cart_items_user_product,where:variant_id IS NULL;uniqueIndex:idx_cart_items_user_variant"`
+	VariantID *uint `gorm:"uniqueIndex:idx_cart_items_user_variant"`
+	Quantity  int
+}
+
+// IsLineOf reports whether the item is the line of the cart for the product, or for the given variant of it
+func (i CartItem) IsLineOf(productID uint, variantID *uint) bool {
+	if i.ProductID != productID {
+		return false
+	}
+	if i.VariantID == nil || variantID == nil {
+		return i.VariantID == nil && variantID == nil
+	}
+	return *i.VariantID == *variantID
+}
+
+// Cart is the cart of a user with every line priced at the current price of its product
+type Cart struct {
+	UserID uint
+	Lines  []CartLine
+}
+
+// CartLine is a line of a cart with its product as it is now, Product is nil once the product is gone
+type CartLine struct {
+	ProductID uint
+	VariantID *uint
+	Quantity  int
+	Product   *Product
+}
+
+// Variant is the variant of the product the line is for, nil when the line is for the product itself or the
+// variant is gone
+func (l CartLine) Variant() *ProductVariant {
+	if l.Product == nil || l.VariantID == nil {
+		return nil
+	}
+	return l.Product.Variant(*l.VariantID)
+}
+
+// IsAvailable reports whether the line can still be ordered as it is, its product, or its variant, must still exist
+// and a product that has variants is only sold through them
+func (l CartLine) IsAvailable() bool {
+	if l.Product == nil {
+		return false
+	}
+	if l.VariantID != nil {
+		return l.Variant() != nil
+	}
+	return !l.Product.HasVariants()
+}
+
+// IsInStock reports whether the product, or its variant, has enough stock left for the whole quantity of the line
+func (l CartLine) IsInStock() bool {
+	return l.IsAvailable() && l.Product.StockOf(l.VariantID) >= int64(l.Quantity)
+}
+
+func (l CartLine) Total() float64 {
+	if !l.IsAvailable() {
+		return 0
+	}
+	return RoundMoney(l.Product.UnitPrice(l.VariantID) * float64(l.Quantity))
+}
+
+func (c *Cart) Subtotal() float64 {
+	var subtotal float64
+	for _, line := range c.Lines {
+		subtotal += line.Total()
+	}
+	return RoundMoney(subtotal)
+}
+
+// CanCheckout reports whether the cart has lines and every one of them can be ordered right now
+func (c *Cart) CanCheckout() bool {
+	if len(c.Lines) == 0 {
+		return false
+	}
+	for _, line := range c.Lines {
+		if !line.IsInStock() {
+			return false
+		}
+	}
+	return true
+}
\ No newline at end of file
@@ -4,6 +4,7 @@ import "gorm.io/gorm"
 
 type Category struct {
 	gorm.Model
-	Name     string    `gorm:"type:varchar(255)"`
-	Products []Product `gorm:"foreignKey:CategoryID"`
+	Name       string    `gorm:"type:varchar(255)"`
+	TaxClassID *uint     `gorm:"index"`
+	Products   []Product `gorm:"foreignKey:CategoryID"`
 }
\ No newline at end of file
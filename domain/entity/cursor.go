@@ -0,0 +1,82 @@
+package entity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// Cursor is a position in a sorted list, right after or right before the row it was taken at when Backward is set.
+// It keeps the sort of the list and the values the row has for its columns, so the next page starts from the row
+// whatever was added or removed since. The id is always among them, the sort ends with it
+type Cursor struct {
+	Fields   []CursorField     `json:"f"`
+	Values   []json.RawMessage `json:"v"`
+	Backward bool              `json:"b,omitempty"`
+}
+
+type CursorField struct {
+	Column string `json:"c"`
+	Desc   bool   `json:"d,omitempty"`
+}
+
+// Sort is the sort of the list the cursor was taken from
+func (c *Cursor) Sort() []SortField {
+	fields := make([]SortField, 0, len(c.Fields))
+	for _, f := range c.Fields {
+		fields = append(fields, SortField{Column: f.Column, Desc: f.Desc})
+	}
+	return fields
+}
+
+// NewCursor takes a cursor at the row with the given values for the columns of the sort
+func NewCursor(sort []SortField, values []interface{}, backward bool) (*Cursor, error) {
+	if len(sort) != len(values) {
+		return nil, errors.New("a cursor needs a value for every column of the sort")
+	}
+	cursor := &Cursor{Backward: backward}
+	for i, f := range sort {
+		value, err := json.Marshal(values[i])
+		if err != nil {
+			return nil, err
+		}
+		cursor.Fields = append(cursor.Fields, CursorField{Column: f.Column, Desc: f.Desc})
+		cursor.Values = append(cursor.Values, value)
+	}
+	return cursor, nil
+}
+
+// Encode writes the cursor as the opaque string handed out to the clients
+func (c *Cursor) Encode() (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor reads a cursor handed out by a list sorted by the sortable columns. The cursor comes from the client,
+// a column outside of them means it was made up or taken from another list
+func DecodeCursor(encoded string, sortable SortableColumns) (*Cursor, error) {
+	invalid := errors.New("the cursor is not valid for this list")
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, invalid
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, invalid
+	}
+	if len(cursor.Fields) == 0 || len(cursor.Fields) != len(cursor.Values) {
+		return nil, invalid
+	}
+	for _, f := range cursor.Fields {
+		if !sortable.HasColumn(f.Column) {
+			return nil, invalid
+		}
+	}
+	if id, ok := sortable["id"]; !ok || cursor.Fields[len(cursor.Fields)-1].Column != id {
+		return nil, invalid
+	}
+	return &cursor, nil
+}
\ No newline at end of file
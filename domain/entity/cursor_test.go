@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"encoding/base64"
+	"slices"
+	"testing"
+)
+
+func TestDecodeCursor(t *testing.T) {
+	sortable := SortableColumns{"id": "things.id", "name": "things.name"}
+
+	encode := func(t *testing.T, sort []SortField, values []interface{}, backward bool) string {
+		t.Helper()
+		cursor, err := NewCursor(sort, values, backward)
+		if err != nil {
+			t.Fatalf("error creating cursor: %v", err)
+		}
+		encoded, err := cursor.Encode()
+		if err != nil {
+			t.Fatalf("error encoding cursor: %v", err)
+		}
+		return encoded
+	}
+	raw := func(data string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(data))
+	}
+
+	valid := []SortField{{Column: "things.name", Desc: true}, {Column: "things.id", Desc: true}}
+	tests := []struct {
+		name     string
+		encoded  string
+		backward bool
+		wantErr  bool
+	}{
+		{name: "a cursor of the list", encoded: encode(t, valid, []interface{}{"chair", 7}, false)},
+		{name: "a backward cursor of the list", encoded: encode(t, valid, []interface{}{"chair", 7}, true), backward: true},
+		{name: "not base64", encoded: "%%%", wantErr: true},
+		{name: "not json", encoded: raw("not json"), wantErr: true},
+		{name: "no fields", encoded: raw(`{"f":[],"v":[]}`), wantErr: true},
+		{name: "fewer values than fields", encoded: raw(`{"f":[{"c":"things.name"},{"c":"things.id"}],"v":["chair"]}`), wantErr: true},
+		{
+			name:    "a column of another list",
+			encoded: encode(t, []SortField{{Column: "users.password"}, {Column: "things.id"}}, []interface{}{"x", 1}, false),
+			wantErr: true,
+		},
+		{
+			name:    "a sort that does not end with the id",
+			encoded: encode(t, []SortField{{Column: "things.id"}, {Column: "things.name"}}, []interface{}{1, "chair"}, false),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := DecodeCursor(tt.encoded, sortable)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected the cursor to be refused, got %+v", cursor)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(cursor.Sort(), valid) {
+				t.Errorf("expected the sort %v, got %v", valid, cursor.Sort())
+			}
+			if cursor.Backward != tt.backward {
+				t.Errorf("expected backward to be %v, got %v", tt.backward, cursor.Backward)
+			}
+		})
+	}
+
+	// an id column only counts when the list sorts by an id at all
+	encoded := encode(t, []SortField{{Column: "things.name"}}, []interface{}{"chair"}, false)
+	if _, err := DecodeCursor(encoded, SortableColumns{"name": "things.name"}); err == nil {
+		t.Errorf("expected a cursor of a list without an id to be refused")
+	}
+}
\ No newline at end of file
@@ -0,0 +1,85 @@
+package entity
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FacetCount is how many products a filter value leaves, under the other active filters. Label is how the value is
+// shown when it is not shown as it is, like the name of a category
+type FacetCount struct {
+	Value string
+	Label string
+	Count int64
+}
+
+// AttributeFacet counts the products by the values their variants have for one option, like every color
+type AttributeFacet struct {
+	Name   string
+	Values []FacetCount
+}
+
+// ProductFacets counts the products a list of products could be narrowed to by every filter value. The counts of a
+// facet leave its own filter out, so selecting one more value of it is counted as widening the list and not as
+// narrowing it down to nothing
+type ProductFacets struct {
+	Categories   []FacetCount
+	PriceBuckets []FacetCount
+	Attributes   []AttributeFacet
+	InStock      int64
+}
+
+// PriceBucket is a range of prices from From included to To excluded, the last bucket has no To
+type PriceBucket struct {
+	From float64
+	To   float64
+}
+
+// PriceBuckets splits the prices at the given bounds, from 0 to the first bound up to above the last one
+func PriceBuckets(bounds []float64) []PriceBucket {
+	sorted := make([]float64, 0, len(bounds))
+	for _, bound := range bounds {
+		if bound > 0 && !slices.Contains(sorted, bound) {
+			sorted = append(sorted, bound)
+		}
+	}
+	slices.Sort(sorted)
+
+	buckets := make([]PriceBucket, 0, len(sorted)+1)
+	from := 0.0
+	for _, bound := range sorted {
+		buckets = append(buckets, PriceBucket{From: from, To: bound})
+		from = bound
+	}
+	return append(buckets, PriceBucket{From: from})
+}
+
+// Key writes the bucket as from-to, the way it is filtered by, the last bucket as from-
+func (b PriceBucket) Key() string {
+	key := strconv.FormatFloat(b.From, 'f', -1, 64) + "-"
+	if b.To > 0 {
+		key += strconv.FormatFloat(b.To, 'f', -1, 64)
+	}
+	return key
+}
+
+// ParsePriceBucket reads a bucket written as from-to or from-
+func ParsePriceBucket(key string) (PriceBucket, bool) {
+	from, to, ok := strings.Cut(strings.TrimSpace(key), "-")
+	if !ok {
+		return PriceBucket{}, false
+	}
+	bucket := PriceBucket{}
+	var err error
+	if bucket.From, err = strconv.ParseFloat(from, 64); err != nil || bucket.From < 0 {
+		return PriceBucket{}, false
+	}
+	if to == "" {
+		return bucket, true
+	}
+	if bucket.To, err = strconv.ParseFloat(to, 64); err != nil || bucket.To <= bucket.From {
+		return PriceBucket{}, false
+	}
+	return bucket, true
+}
\ No newline at end of file
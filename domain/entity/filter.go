@@ -1,6 +1,9 @@
 package entity
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 type ProductFilter struct {
 	Keyword       string     `form:"keyword"`
@@ -10,6 +13,11 @@ type ProductFilter struct {
 	PriceTo       float64    `form:"priceTo"`
 	Description   string     `form:"description"`
 	CategoryID    int64      `form:"categoryId"`
+	CategoryIDs   []int64    `form:"categoryIds"`
+	InStock       bool       `form:"inStock"`
+	PriceBuckets  []string   `form:"priceBucket"`
+	SKU           string     `form:"sku"`
+	Options       []string   `form:"option"`
 	CreatedAtFrom *time.Time `form:"createdAtFrom"`
 	CreatedAtTo   *time.Time `form:"createdAtTo"`
 	UpdatedAtFrom *time.Time `form:"updatedAtFrom"`
@@ -24,12 +32,35 @@ func (f *ProductFilter) IsNil() bool {
 		f.PriceFrom == 0 &&
 		f.PriceTo == 0 &&
 		f.Description == "" &&
-		f.CategoryID == 0 &&
+		f.CategoryID == 0 && len(f.CategoryIDs) == 0 &&
+		f.InStock == false && len(f.PriceBuckets) == 0 &&
+		f.SKU == "" && len(f.Options) == 0 &&
 		f.CreatedAtTo == nil && f.CreatedAtFrom == nil &&
 		f.UpdatedAtTo == nil && f.UpdatedAtFrom == nil &&
 		f.Deleted == false
 }
 
+// Categories is every category selected, the products of any of them are kept
+func (f *ProductFilter) Categories() []int64 {
+	ids := make([]int64, 0, len(f.CategoryIDs)+1)
+	if f.CategoryID != 0 {
+		ids = append(ids, f.CategoryID)
+	}
+	for _, id := range f.CategoryIDs {
+		if id != 0 && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// ProductSearch is a search of products by words, Query is matched against their name, description, category name and
+// tags whatever the case and the accents
+type ProductSearch struct {
+	Query      string `form:"q"`
+	CategoryID int64  `form:"categoryId"`
+}
+
 type CategoryFilter struct {
 	Keyword       string     `form:"keyword"`
 	ID            int64      `form:"id"`
@@ -42,4 +73,11 @@ type CategoryFilter struct {
 }
 
 type OrderFilter struct {
+	Status        string     `form:"status"`
+	UserID        int64      `form:"userId"`
+	ProductID     int64      `form:"productId"`
+	TotalFrom     float64    `form:"totalFrom"`
+	TotalTo       float64    `form:"totalTo"`
+	CreatedAtFrom *time.Time `form:"createdAtFrom"`
+	CreatedAtTo   *time.Time `form:"createdAtTo"`
 }
\ No newline at end of file
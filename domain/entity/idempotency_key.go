@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	IdempotencyStatusInProgress = "IN_PROGRESS"
+	IdempotencyStatusCompleted  = "COMPLETED"
+)
+
+// IdempotencyKey remembers the response a user got for a request sent with an Idempotency-Key header,
+// so a retry of the same request gets the same response instead of running the request twice
+type IdempotencyKey struct {
+	gorm.Model
+	UserID       uint   `gorm:"uniqueIndex:idx_idempotency_keys_user_key"`
+	Key          string `gorm:"type:varchar(255);uniqueIndex:idx_idempotency_keys_user_key"`
+	RequestHash  string `gorm:"type:varchar(64)"`
+	Status       string `gorm:"type:varchar(20)"`
+	ResponseCode int
+	ContentType  string `gorm:"type:varchar(100)"`
+	ResponseBody string `gorm:"type:text"`
+	ExpiresAt    time.Time
+}
+
+func (k *IdempotencyKey) IsExpired() bool {
+	return time.Now().After(k.ExpiresAt)
+}
\ No newline at end of file
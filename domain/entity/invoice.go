@@ -0,0 +1,112 @@
+package entity
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"time"
+)
+
+// InvoiceSequence holds the last number given in a series of invoice numbers. Numbers are taken with the row locked
+// in the transaction that issues the invoice, so a rolled back invoice gives its number back and the series has no gaps
+type InvoiceSequence struct {
+	Series     string `gorm:"type:varchar(30);primaryKey"`
+	LastNumber int64
+}
+
+// Invoice is issued once the order is paid. It keeps a copy of everything it shows, so the document rendered from it
+// never changes afterwards
+type Invoice struct {
+	gorm.Model
+	OrderID        uint   `gorm:"uniqueIndex"`
+	UserID         uint   `gorm:"index"`
+	Number         string `gorm:"type:varchar(30);uniqueIndex"`
+	IssuedAt       time.Time
+	Currency       string          `gorm:"type:varchar(3)"`
+	SellerName     string          `gorm:"type:varchar(255)"`
+	SellerAddress  string          `gorm:"type:varchar(255)"`
+	SellerTaxCode  string          `gorm:"type:varchar(50)"`
+	BillingAddress ShippingAddress `gorm:"embedded;embeddedPrefix:billing_"`
+	PromotionCode  string          `gorm:"type:varchar(50)"`
+	Subtotal       float64         `gorm:"type:double precision"`
+	ShippingFee    float64         `gorm:"type:double precision"`
+	DiscountTotal  float64         `gorm:"type:double precision"`
+	TaxTotal       float64         `gorm:"type:double precision"`
+	GrandTotal     float64         `gorm:"type:double precision"`
+	FileURL        string          `gorm:"type:text"`
+	EmailedAt      *time.Time
+	Lines          []InvoiceLine `gorm:"foreignKey:InvoiceID"`
+}
+
+type InvoiceLine struct {
+	gorm.Model
+	InvoiceID   uint `gorm:"index"`
+	OrderItemID uint
+	ProductID   uint
+	VariantID   *uint
+	SKU         string `gorm:"type:varchar(64)"`
+	Description string `gorm:"type:varchar(255)"`
+	Quantity    int
+	UnitPrice   float64 `gorm:"type:double precision"`
+	TaxRate     float64 `gorm:"type:double precision"`
+	TaxAmount   float64 `gorm:"type:double precision"`
+	NetAmount   float64 `gorm:"type:double precision"`
+	GrossAmount float64 `gorm:"type:double precision"`
+}
+
+// InvoiceSeries is the series the invoices issued at the given time are numbered in, numbering starts again every year
+func InvoiceSeries(prefix string, issuedAt time.Time) string {
+	return fmt.Sprintf("%s-%d", prefix, issuedAt.Year())
+}
+
+func FormatInvoiceNumber(series string, number int64) string {
+	return fmt.Sprintf("%s-%06d", series, number)
+}
+
+// NewInvoice copies the lines and the totals of the order into an invoice, the lines are described with the products
+// of the items and their variants
+func NewInvoice(order *Order, prods map[uint]Product, issuedAt time.Time) *Invoice {
+	invoice := &Invoice{
+		OrderID:        order.ID,
+		UserID:         order.UserID,
+		IssuedAt:       issuedAt,
+		BillingAddress: order.ShippingAddress,
+		PromotionCode:  order.PromotionCode,
+		Subtotal:       order.Subtotal,
+		ShippingFee:    order.ShippingFee,
+		DiscountTotal:  order.DiscountTotal,
+		TaxTotal:       order.TaxTotal,
+		GrandTotal:     order.GrandTotal,
+		Lines:          make([]InvoiceLine, 0),
+	}
+	for _, item := range order.OrderItems {
+		net := RoundMoney(item.Price * float64(item.Quantity))
+		product := prods[item.ProductID]
+		line := InvoiceLine{
+			OrderItemID: item.ID,
+			ProductID:   item.ProductID,
+			VariantID:   item.VariantID,
+			Description: product.Name,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.Price,
+			TaxRate:     item.TaxRate,
+			TaxAmount:   item.TaxAmount,
+			NetAmount:   net,
+			GrossAmount: RoundMoney(net + item.TaxAmount),
+		}
+		if item.VariantID != nil {
+			if v := product.Variant(*item.VariantID); v != nil {
+				line.SKU = v.SKU
+				if options := v.Describe(); options != "" {
+					line.Description += " (" + options + ")"
+				}
+			}
+		}
+		invoice.Lines = append(invoice.Lines, line)
+	}
+	return invoice
+}
+
+// FileName is the name the rendered invoice is stored, downloaded and attached under
+func (i *Invoice) FileName() string {
+	return i.Number + ".html"
+}
\ No newline at end of file
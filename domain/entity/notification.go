@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+const NotificationTypeLowStock = "low_stock"
+
+// Notification is an entry of the notification feed of the admins, ReadAt is set once one of them has read it
+type Notification struct {
+	ID        uint   `gorm:"primarykey"`
+	Type      string `gorm:"type:varchar(50);index"`
+	Title     string `gorm:"type:varchar(255)"`
+	Body      string `gorm:"type:text"`
+	ReadAt    *time.Time
+	CreatedAt time.Time `gorm:"index"`
+}
+
+type NotificationFilter struct {
+	Unread bool `form:"unread"`
+}
\ No newline at end of file
@@ -1,19 +1,128 @@
 package entity
 
-import "gorm.io/gorm"
+import (
+	"gorm.io/gorm"
+	"math"
+	"slices"
+	"time"
+)
+
+const (
+	OrderStatusPending   = "PENDING"
+	OrderStatusConfirmed = "CONFIRMED"
+	OrderStatusPaid      = "PAID"
+	OrderStatusShipped   = "SHIPPED"
+	OrderStatusDelivered = "DELIVERED"
+	OrderStatusCancelled = "CANCELLED"
+	OrderStatusRefunded  = "REFUNDED"
+
+	OrderStatusPartiallyRefunded = "PARTIALLY_REFUNDED"
+)
+
+// orderTransitions lists, for every order status, the statuses the order is allowed to move to.
+// A partially refunded order keeps being fulfilled and can be refunded again until nothing is left, CanTransitionTo
+// only lets its fulfilment go forward from where it was when it got refunded
+var orderTransitions = map[string][]string{
+	OrderStatusPending:           {OrderStatusConfirmed, OrderStatusCancelled},
+	OrderStatusConfirmed:         {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid:              {OrderStatusShipped, OrderStatusRefunded, OrderStatusPartiallyRefunded},
+	OrderStatusShipped:           {OrderStatusDelivered, OrderStatusRefunded, OrderStatusPartiallyRefunded},
+	OrderStatusDelivered:         {OrderStatusRefunded, OrderStatusPartiallyRefunded},
+	OrderStatusPartiallyRefunded: {OrderStatusShipped, OrderStatusDelivered, OrderStatusRefunded, OrderStatusPartiallyRefunded},
+	OrderStatusCancelled:         {},
+	OrderStatusRefunded:          {},
+}
 
-// PaymentID int64
 type Order struct {
 	gorm.Model
-	UserID     uint
-	Status     string      `gorm:"type:varchar(50)"`
-	OrderItems []OrderItem `gorm:"foreignKey:OrderID"`
+	UserID          uint
+	Status          string      `gorm:"type:varchar(50)"`
+	OrderItems      []OrderItem `gorm:"foreignKey:OrderID"`
+	Payments        []Payment   `gorm:"foreignKey:OrderID"`
+	CancelledBy     uint
+	CancelReason    string `gorm:"type:varchar(255)"`
+	CancelledAt     *time.Time
+	Subtotal        float64         `gorm:"type:double precision"`
+	DiscountTotal   float64         `gorm:"type:double precision"`
+	TaxTotal        float64         `gorm:"type:double precision"`
+	GrandTotal      float64         `gorm:"type:double precision"`
+	ShippingFee     float64         `gorm:"type:double precision"`
+	PromotionID     *uint           `gorm:"index"`
+	PromotionCode   string          `gorm:"type:varchar(50)"`
+	ShippingAddress ShippingAddress `gorm:"embedded;embeddedPrefix:shipping_"`
+	ShippingMethod  string          `gorm:"type:varchar(50)"`
+	TrackingNumber  string          `gorm:"type:varchar(100)"`
+	ShippedAt       *time.Time
+	DeliveredAt     *time.Time
 }
 
 type OrderItem struct {
 	gorm.Model
 	OrderID   uint    `json:"orderId" validate:"required"`
 	ProductID uint    `json:"productId" validate:"required"`
-	Quantity  int     `json:"quantity" validate:"required"`
+	VariantID *uint   `gorm:"index" json:"variantId"`
+	Quantity  int     `json:"quantity" validate:"required,gt=0"`
 	Price     float64 `gorm:"type:double precision"`
+	TaxRate   float64 `gorm:"type:double precision"`
+	TaxAmount float64 `gorm:"type:double precision"`
+}
+
+func IsValidOrderStatus(status string) bool {
+	_, ok := orderTransitions[status]
+	return ok
+}
+
+// IsRefundStatus reports whether the status can only be reached by refunding the payment of the order
+func IsRefundStatus(status string) bool {
+	return status == OrderStatusRefunded || status == OrderStatusPartiallyRefunded
+}
+
+// CanTransitionTo reports whether the order is allowed to move from its current status to the given one
+func (o *Order) CanTransitionTo(status string) bool {
+	next, ok := orderTransitions[o.Status]
+	if !ok || !slices.Contains(next, status) {
+		return false
+	}
+	// a partially refunded order no longer shows how far it was fulfilled, its shipping and delivery times do
+	if o.Status == OrderStatusPartiallyRefunded {
+		switch status {
+		case OrderStatusShipped:
+			return o.ShippedAt == nil
+		case OrderStatusDelivered:
+			return o.ShippedAt != nil && o.DeliveredAt == nil
+		}
+	}
+	return true
+}
+
+// CalculateTotals sums the net price snapshots of the order items into the subtotal and their tax into the tax total,
+// then derives the grand total from them and the shipping fee. The discount can never take the grand total below the
+// tax, which is owed on the net prices whatever the discount
+func (o *Order) CalculateTotals() {
+	var subtotal, taxTotal float64
+	for _, item := range o.OrderItems {
+		subtotal += item.Price * float64(item.Quantity)
+		taxTotal += item.TaxAmount
+	}
+	o.Subtotal = RoundMoney(subtotal)
+	o.ShippingFee = RoundMoney(o.ShippingFee)
+	o.DiscountTotal = RoundMoney(math.Min(o.DiscountTotal, o.Subtotal+o.ShippingFee))
+	o.TaxTotal = RoundMoney(taxTotal)
+	o.GrandTotal = RoundMoney(o.Subtotal + o.ShippingFee - o.DiscountTotal + o.TaxTotal)
+}
+
+// ItemsTotal is the part of the grand total paid for the items and their tax, the shipping fee left out. A free
+// shipping discount only ever comes off the shipping fee, any other discount comes off the items first and only
+// reaches the shipping fee once it is larger than the subtotal
+func (o *Order) ItemsTotal(freeShipping bool) float64 {
+	var itemsDiscount float64
+	if !freeShipping {
+		itemsDiscount = math.Min(o.DiscountTotal, o.Subtotal)
+	}
+	return RoundMoney(o.Subtotal - itemsDiscount + o.TaxTotal)
+}
+
+// RoundMoney rounds an amount of money to cents
+func RoundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
 }
\ No newline at end of file
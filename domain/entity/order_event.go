@@ -0,0 +1,124 @@
+package entity
+
+import (
+	"reflect"
+	"time"
+)
+
+const (
+	OrderEventCreated          = "ORDER_CREATED"
+	OrderEventStatusChanged    = "STATUS_CHANGED"
+	OrderEventCancelled        = "ORDER_CANCELLED"
+	OrderEventDeleted          = "ORDER_DELETED"
+	OrderEventItemsAdded       = "ITEMS_ADDED"
+	OrderEventItemsUpdated     = "ITEMS_UPDATED"
+	OrderEventItemRemoved      = "ITEM_REMOVED"
+	OrderEventPaymentInitiated = "PAYMENT_INITIATED"
+	OrderEventPaymentUpdated   = "PAYMENT_UPDATED"
+	OrderEventRefundCreated    = "REFUND_CREATED"
+	OrderEventRefundUpdated    = "REFUND_UPDATED"
+)
+
+// OrderEvent is one change made to an order. Events are only ever appended, they are never updated or deleted,
+// even when their order is. ActorID is nil when the change was not made by a user, like a payment gateway callback
+type OrderEvent struct {
+	ID        uint `gorm:"primarykey"`
+	OrderID   uint `gorm:"index"`
+	ActorID   *uint
+	Type      string    `gorm:"type:varchar(50)"`
+	Diff      string    `gorm:"type:jsonb"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+// Snapshot is the state of an order, or of something attached to it, at the moment of an event
+type Snapshot map[string]interface{}
+
+// FieldChange is the value of a field before and after an event
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// DiffSnapshots keeps only the fields that changed between both snapshots, a nil snapshot stands for something
+// that did not exist yet or does not exist anymore
+func DiffSnapshots(before, after Snapshot) map[string]FieldChange {
+	diff := make(map[string]FieldChange)
+	for k, v := range after {
+		if old, ok := before[k]; !ok || !reflect.DeepEqual(old, v) {
+			diff[k] = FieldChange{Before: before[k], After: v}
+		}
+	}
+	for k, v := range before {
+		if _, ok := after[k]; !ok {
+			diff[k] = FieldChange{Before: v, After: nil}
+		}
+	}
+	return diff
+}
+
+func (o *Order) Snapshot() Snapshot {
+	items := make([]Snapshot, 0)
+	for _, item := range o.OrderItems {
+		items = append(items, item.Snapshot())
+	}
+	return Snapshot{
+		"status":        o.Status,
+		"subtotal":      o.Subtotal,
+		"discountTotal": o.DiscountTotal,
+		"taxTotal":      o.TaxTotal,
+		"grandTotal":    o.GrandTotal,
+		"shippingFee":   o.ShippingFee,
+		"promotionCode": o.PromotionCode,
+		"cancelledBy":   o.CancelledBy,
+		"cancelReason":  o.CancelReason,
+		"orderItems":    items,
+
+		"shippingAddress": o.ShippingAddress,
+		"shippingMethod":  o.ShippingMethod,
+		"trackingNumber":  o.TrackingNumber,
+	}
+}
+
+func (oi *OrderItem) Snapshot() Snapshot {
+	return Snapshot{
+		"id":        oi.ID,
+		"productId": oi.ProductID,
+		"variantId": oi.VariantID,
+		"quantity":  oi.Quantity,
+		"price":     oi.Price,
+		"taxAmount": oi.TaxAmount,
+	}
+}
+
+func (p *Payment) Snapshot() Snapshot {
+	return Snapshot{
+		"paymentId":     p.ID,
+		"provider":      p.Provider,
+		"providerRef":   p.ProviderRef,
+		"amount":        p.Amount,
+		"status":        p.Status,
+		"failureReason": p.FailureReason,
+	}
+}
+
+func (r *Refund) Snapshot() Snapshot {
+	items := make([]Snapshot, 0)
+	for _, item := range r.RefundItems {
+		items = append(items, Snapshot{
+			"orderItemId": item.OrderItemID,
+			"quantity":    item.Quantity,
+			"amount":      item.Amount,
+		})
+	}
+	return Snapshot{
+		"refundId":      r.ID,
+		"paymentId":     r.PaymentID,
+		"amount":        r.Amount,
+		"reason":        r.Reason,
+		"restock":       r.Restock,
+		"status":        r.Status,
+		"providerRef":   r.ProviderRef,
+		"failureReason": r.FailureReason,
+		"refundItems":   items,
+	}
+}
\ No newline at end of file
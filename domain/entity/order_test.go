@@ -0,0 +1,90 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOrderCanTransitionTo(t *testing.T) {
+	shippedAt := time.Now()
+	deliveredAt := shippedAt.Add(48 * time.Hour)
+
+	tests := []struct {
+		name  string
+		order Order
+		to    string
+		want  bool
+	}{
+		{name: "pending to confirmed", order: Order{Status: OrderStatusPending}, to: OrderStatusConfirmed, want: true},
+		{name: "pending to cancelled", order: Order{Status: OrderStatusPending}, to: OrderStatusCancelled, want: true},
+		{name: "pending cannot be paid", order: Order{Status: OrderStatusPending}, to: OrderStatusPaid},
+		{name: "pending cannot be shipped", order: Order{Status: OrderStatusPending}, to: OrderStatusShipped},
+		{name: "confirmed to paid", order: Order{Status: OrderStatusConfirmed}, to: OrderStatusPaid, want: true},
+		{name: "confirmed to cancelled", order: Order{Status: OrderStatusConfirmed}, to: OrderStatusCancelled, want: true},
+		{name: "confirmed cannot be refunded", order: Order{Status: OrderStatusConfirmed}, to: OrderStatusRefunded},
+		{name: "paid to shipped", order: Order{Status: OrderStatusPaid}, to: OrderStatusShipped, want: true},
+		{name: "paid to partially refunded", order: Order{Status: OrderStatusPaid}, to: OrderStatusPartiallyRefunded, want: true},
+		{name: "paid cannot be cancelled", order: Order{Status: OrderStatusPaid}, to: OrderStatusCancelled},
+		{name: "paid cannot go back to pending", order: Order{Status: OrderStatusPaid}, to: OrderStatusPending},
+		{name: "shipped to delivered", order: Order{Status: OrderStatusShipped, ShippedAt: &shippedAt}, to: OrderStatusDelivered, want: true},
+		{name: "shipped to refunded", order: Order{Status: OrderStatusShipped, ShippedAt: &shippedAt}, to: OrderStatusRefunded, want: true},
+		{name: "shipped cannot be shipped again", order: Order{Status: OrderStatusShipped, ShippedAt: &shippedAt}, to: OrderStatusShipped},
+		{name: "delivered to refunded", order: Order{Status: OrderStatusDelivered}, to: OrderStatusRefunded, want: true},
+		{name: "delivered cannot be shipped", order: Order{Status: OrderStatusDelivered}, to: OrderStatusShipped},
+		{name: "cancelled is final", order: Order{Status: OrderStatusCancelled}, to: OrderStatusPending},
+		{name: "refunded is final", order: Order{Status: OrderStatusRefunded}, to: OrderStatusPartiallyRefunded},
+		{name: "unknown status", order: Order{Status: "LOST"}, to: OrderStatusPending},
+		{name: "to an unknown status", order: Order{Status: OrderStatusPending}, to: "LOST"},
+		{
+			name:  "partially refunded before shipping can be shipped",
+			order: Order{Status: OrderStatusPartiallyRefunded},
+			to:    OrderStatusShipped,
+			want:  true,
+		},
+		{
+			name:  "partially refunded before shipping cannot be delivered",
+			order: Order{Status: OrderStatusPartiallyRefunded},
+			to:    OrderStatusDelivered,
+		},
+		{
+			name:  "partially refunded after shipping cannot be shipped again",
+			order: Order{Status: OrderStatusPartiallyRefunded, ShippedAt: &shippedAt},
+			to:    OrderStatusShipped,
+		},
+		{
+			name:  "partially refunded after shipping can be delivered",
+			order: Order{Status: OrderStatusPartiallyRefunded, ShippedAt: &shippedAt},
+			to:    OrderStatusDelivered,
+			want:  true,
+		},
+		{
+			name:  "partially refunded after delivery cannot be delivered again",
+			order: Order{Status: OrderStatusPartiallyRefunded, ShippedAt: &shippedAt, DeliveredAt: &deliveredAt},
+			to:    OrderStatusDelivered,
+		},
+		{
+			name:  "partially refunded after delivery can still be refunded",
+			order: Order{Status: OrderStatusPartiallyRefunded, ShippedAt: &shippedAt, DeliveredAt: &deliveredAt},
+			to:    OrderStatusRefunded,
+			want:  true,
+		},
+		{
+			name:  "partially refunded can be partially refunded again",
+			order: Order{Status: OrderStatusPartiallyRefunded},
+			to:    OrderStatusPartiallyRefunded,
+			want:  true,
+		},
+		{
+			name:  "partially refunded cannot be cancelled",
+			order: Order{Status: OrderStatusPartiallyRefunded},
+			to:    OrderStatusCancelled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.order.CanTransitionTo(tt.to); got != tt.want {
+				t.Errorf("expected %s -> %s to be %v, got %v", tt.order.Status, tt.to, tt.want, got)
+			}
+		})
+	}
+}
\ No newline at end of file
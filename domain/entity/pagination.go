@@ -1,11 +1,19 @@
 package entity
 
+import "errors"
+
+// Pagination pages a list by page number, or from a cursor when there is one. A list paged from a cursor is not
+// counted, its sort is the sort of the cursor. NextCursor and PrevCursor are the cursors to the pages around the
+// page read, whichever way it was read
 type Pagination struct {
 	Limit      int    `form:"limit"`
 	Page       int    `form:"page"`
 	Sort       string `form:"sort"`
+	Cursor     string `form:"cursor"`
 	TotalRows  int64  `form:"-"`
 	TotalPages int    `form:"-"`
+	NextCursor string `form:"-"`
+	PrevCursor string `form:"-"`
 }
 
 func InitPaginate() *Pagination {
@@ -33,9 +41,30 @@ func (p *Pagination) GetPage() int {
 	return p.Page
 }
 
-func (p *Pagination) GetSort() string {
-	if p.Sort == "" {
-		p.Sort = "Id asc"
+// ValidateSort checks the sort only uses the columns the list can be sorted by
+func (p *Pagination) ValidateSort(sortable SortableColumns) error {
+	_, err := ParseSort(p.Sort, sortable)
+	return err
+}
+
+// IsCursor reports whether the list is paged from a cursor rather than by page number
+func (p *Pagination) IsCursor() bool {
+	return p.Cursor != ""
+}
+
+// ValidateNoCursor refuses a cursor on a list that is only paged by page number, it hands out no cursors
+func (p *Pagination) ValidateNoCursor() error {
+	if p.IsCursor() {
+		return errors.New("this list is paged by page number, it has no cursors")
+	}
+	return nil
+}
+
+// ValidateCursor checks the cursor was handed out by a list sorted by the sortable columns
+func (p *Pagination) ValidateCursor(sortable SortableColumns) error {
+	if !p.IsCursor() {
+		return nil
 	}
-	return p.Sort
+	_, err := DecodeCursor(p.Cursor, sortable)
+	return err
 }
\ No newline at end of file
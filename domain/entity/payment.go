@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+var (
+	ErrInvalidPaymentSignature = errors.New("invalid payment callback signature")
+)
+
+const (
+	PaymentStatusPending    = "PENDING"
+	PaymentStatusAuthorized = "AUTHORIZED"
+	PaymentStatusCapturing  = "CAPTURING"
+	PaymentStatusCaptured   = "CAPTURED"
+	PaymentStatusFailed     = "FAILED"
+	PaymentStatusVoided     = "VOIDED"
+)
+
+type Payment struct {
+	gorm.Model
+	OrderID       uint    `gorm:"index"`
+	Provider      string  `gorm:"type:varchar(50);index:idx_payments_provider_ref"`
+	ProviderRef   string  `gorm:"type:varchar(255);index:idx_payments_provider_ref"`
+	Amount        float64 `gorm:"type:double precision"`
+	Currency      string  `gorm:"type:varchar(3)"`
+	Status        string  `gorm:"type:varchar(20)"`
+	FailureReason string  `gorm:"type:varchar(255)"`
+	CapturedAt    *time.Time
+}
+
+// IsActive reports whether the payment still holds or has taken the money of its order. A capturing payment is
+// active, the gateway may have taken the money already
+func (p *Payment) IsActive() bool {
+	switch p.Status {
+	case PaymentStatusPending, PaymentStatusAuthorized, PaymentStatusCapturing, PaymentStatusCaptured:
+		return true
+	}
+	return false
+}
\ No newline at end of file
@@ -1,17 +1,52 @@
 package entity
 
-import "gorm.io/gorm"
+import (
+	"gorm.io/gorm"
+	"slices"
+	"strings"
+)
 
+// Product is reported as low on stock once its stock, or the stock of any of its variants, is at or below its
+// ReorderThreshold. A product without a threshold is never reported. CostPrice is the cost of goods of its stock,
+// averaged over the goods received from suppliers, it is shared by all of its variants. SearchName and SearchText are
+// the normalized name and the normalized name, description, category name and tags the product is searched by, the
+// repository keeps them in line with the product
 type Product struct {
 	gorm.Model
-	Name        string `gorm:"type:varchar(255)"`
-	Description string
-	Price       float64 `gorm:"type:double precision"`
-	CategoryID  int64
-	Stock       int64
-	Image       string `gorm:"type:text"`
+	Name             string `gorm:"type:varchar(255)"`
+	Description      string
+	Price            float64 `gorm:"type:double precision"`
+	CategoryID       int64
+	TaxClassID       *uint `gorm:"index"`
+	Stock            int64
+	ReorderThreshold *int64
+	CostPrice        float64          `gorm:"type:double precision"`
+	Tags             string           `gorm:"type:text"`
+	SearchName       string           `gorm:"type:text"`
+	SearchText       string           `gorm:"type:text"`
+	Image            string           `gorm:"type:text"`
+	Variants         []ProductVariant `gorm:"foreignKey:ProductID"`
 }
 
 func GetID(p Product) int64 {
 	return int64(uint64(p.ID))
+}
+
+// NormalizeTags keeps the tags lower case, trimmed and once each, in the comma separated form products store them in
+func NormalizeTags(tags []string) string {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(tag, ",", " ")))
+		if tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return strings.Join(normalized, ",")
+}
+
+func (p *Product) TagList() []string {
+	if p.Tags == "" {
+		return []string{}
+	}
+	return strings.Split(p.Tags, ",")
 }
\ No newline at end of file
@@ -0,0 +1,151 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	PromotionTypePercentage   = "PERCENTAGE"
+	PromotionTypeFixedAmount  = "FIXED_AMOUNT"
+	PromotionTypeFreeShipping = "FREE_SHIPPING"
+	PromotionTypeBuyXGetY     = "BUY_X_GET_Y"
+)
+
+var (
+	ErrPromotionNotApplicable = errors.New("promotion is not applicable")
+)
+
+// Promotion is a discount code. A zero UsageLimit, PerUserLimit or MinOrderValue means no limit, and a nil StartsAt or
+// EndsAt leaves the validity window open on that side. Buy X get Y promotions target either a product or a category
+type Promotion struct {
+	gorm.Model
+	Code          string  `gorm:"type:varchar(50);uniqueIndex:idx_promotions_code,where:deleted_at IS NULL"`
+	Description   string  `gorm:"type:varchar(255)"`
+	Type          string  `gorm:"type:varchar(20)"`
+	Value         float64 `gorm:"type:double precision"`
+	MaxDiscount   float64 `gorm:"type:double precision"`
+	MinOrderValue float64 `gorm:"type:double precision"`
+	BuyQuantity   int
+	GetQuantity   int
+	ProductID     *uint
+	CategoryID    *int64
+	StartsAt      *time.Time
+	EndsAt        *time.Time
+	UsageLimit    int
+	PerUserLimit  int
+	UsedCount     int
+	Active        bool
+}
+
+// PromotionUsage is one use of a promotion by an order, it is deleted when the order is cancelled so the use
+// goes back to the promotion
+type PromotionUsage struct {
+	gorm.Model
+	PromotionID uint `gorm:"index"`
+	UserID      uint `gorm:"index"`
+	OrderID     uint `gorm:"index"`
+}
+
+func IsValidPromotionType(promotionType string) bool {
+	switch promotionType {
+	case PromotionTypePercentage, PromotionTypeFixedAmount, PromotionTypeFreeShipping, PromotionTypeBuyXGetY:
+		return true
+	}
+	return false
+}
+
+// NormalizePromotionCode makes codes case insensitive, they are stored and looked up in upper case
+func NormalizePromotionCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// IsUsableAt reports whether the promotion is active and inside its validity window at the given time
+func (p *Promotion) IsUsableAt(now time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && now.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && now.After(*p.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// Discount works out how much the promotion takes off the order, whose subtotal and shipping fee must already be
+// set. categories maps the product of every item to its category, it is only read by buy X get Y promotions
+// on a category. Usage limits are not checked here, they need the lock the usecase takes on the promotion
+func (p *Promotion) Discount(order *Order, categories map[uint]int64, now time.Time) (float64, error) {
+	if !p.IsUsableAt(now) {
+		return 0, fmt.Errorf("%w: code %s is not active", ErrPromotionNotApplicable, p.Code)
+	}
+	if p.MinOrderValue > 0 && order.Subtotal < p.MinOrderValue {
+		return 0, fmt.Errorf("%w: code %s needs an order of at least %v", ErrPromotionNotApplicable, p.Code, p.MinOrderValue)
+	}
+
+	var discount float64
+	switch p.Type {
+	case PromotionTypePercentage:
+		discount = order.Subtotal * p.Value / 100
+		if p.MaxDiscount > 0 {
+			discount = math.Min(discount, p.MaxDiscount)
+		}
+	case PromotionTypeFixedAmount:
+		discount = math.Min(p.Value, order.Subtotal)
+	case PromotionTypeFreeShipping:
+		discount = order.ShippingFee
+	case PromotionTypeBuyXGetY:
+		discount = p.buyXGetYDiscount(order, categories)
+	default:
+		return 0, fmt.Errorf("%w: unknown promotion type %s", ErrPromotionNotApplicable, p.Type)
+	}
+
+	if discount <= 0 {
+		return 0, fmt.Errorf("%w: code %s gives no discount on this order", ErrPromotionNotApplicable, p.Code)
+	}
+	return RoundMoney(discount), nil
+}
+
+// buyXGetYDiscount gives GetQuantity units free for every BuyQuantity + GetQuantity units of the targeted items,
+// the cheapest units are the free ones
+func (p *Promotion) buyXGetYDiscount(order *Order, categories map[uint]int64) float64 {
+	if p.BuyQuantity <= 0 || p.GetQuantity <= 0 {
+		return 0
+	}
+
+	prices := make([]float64, 0)
+	for _, item := range order.OrderItems {
+		if !p.targets(item, categories) {
+			continue
+		}
+		for i := 0; i < item.Quantity; i++ {
+			prices = append(prices, item.Price)
+		}
+	}
+	free := len(prices) / (p.BuyQuantity + p.GetQuantity) * p.GetQuantity
+
+	sort.Float64s(prices)
+	var discount float64
+	for _, price := range prices[:free] {
+		discount += price
+	}
+	return discount
+}
+
+func (p *Promotion) targets(item OrderItem, categories map[uint]int64) bool {
+	if p.ProductID != nil {
+		return item.ProductID == *p.ProductID
+	}
+	if p.CategoryID != nil {
+		category, ok := categories[item.ProductID]
+		return ok && category == *p.CategoryID
+	}
+	return true
+}
\ No newline at end of file
@@ -0,0 +1,93 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPromotionDiscount(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	yesterday := now.Add(-24 * time.Hour)
+	tomorrow := now.Add(24 * time.Hour)
+	productID := uint(1)
+	categoryID := int64(10)
+
+	// three units of product 1 at 10, one of product 2 at 4 and two of product 3 at 25
+	order := &Order{
+		Subtotal:    84,
+		ShippingFee: 5,
+		OrderItems: []OrderItem{
+			{ProductID: 1, Quantity: 3, Price: 10},
+			{ProductID: 2, Quantity: 1, Price: 4},
+			{ProductID: 3, Quantity: 2, Price: 25},
+		},
+	}
+	categories := map[uint]int64{1: categoryID, 2: categoryID, 3: 20}
+
+	tests := []struct {
+		name      string
+		promotion Promotion
+		inactive  bool
+		want      float64
+		wantErr   bool
+	}{
+		{name: "percentage", promotion: Promotion{Type: PromotionTypePercentage, Value: 10}, want: 8.4},
+		{name: "percentage capped", promotion: Promotion{Type: PromotionTypePercentage, Value: 50, MaxDiscount: 20}, want: 20},
+		{name: "fixed amount", promotion: Promotion{Type: PromotionTypeFixedAmount, Value: 15}, want: 15},
+		{name: "fixed amount above the subtotal", promotion: Promotion{Type: PromotionTypeFixedAmount, Value: 100}, want: 84},
+		{name: "free shipping", promotion: Promotion{Type: PromotionTypeFreeShipping}, want: 5},
+		{
+			name:      "buy two get one of a product",
+			promotion: Promotion{Type: PromotionTypeBuyXGetY, BuyQuantity: 2, GetQuantity: 1, ProductID: &productID},
+			want:      10,
+		},
+		{
+			name:      "buy one get one of a category gives the cheapest units",
+			promotion: Promotion{Type: PromotionTypeBuyXGetY, BuyQuantity: 1, GetQuantity: 1, CategoryID: &categoryID},
+			want:      14,
+		},
+		{
+			name:      "buy one get one of everything",
+			promotion: Promotion{Type: PromotionTypeBuyXGetY, BuyQuantity: 1, GetQuantity: 1},
+			want:      24,
+		},
+		{
+			name:      "not enough units for a free one",
+			promotion: Promotion{Type: PromotionTypeBuyXGetY, BuyQuantity: 3, GetQuantity: 1, ProductID: &productID},
+			wantErr:   true,
+		},
+		{
+			name:      "buy x get y without quantities",
+			promotion: Promotion{Type: PromotionTypeBuyXGetY, ProductID: &productID},
+			wantErr:   true,
+		},
+		{name: "minimum order value reached", promotion: Promotion{Type: PromotionTypeFixedAmount, Value: 5, MinOrderValue: 84}, want: 5},
+		{name: "minimum order value not reached", promotion: Promotion{Type: PromotionTypeFixedAmount, Value: 5, MinOrderValue: 100}, wantErr: true},
+		{name: "inactive", promotion: Promotion{Type: PromotionTypeFixedAmount, Value: 5}, inactive: true, wantErr: true},
+		{name: "not started", promotion: Promotion{Type: PromotionTypeFixedAmount, Value: 5, StartsAt: &tomorrow}, wantErr: true},
+		{name: "ended", promotion: Promotion{Type: PromotionTypeFixedAmount, Value: 5, EndsAt: &yesterday}, wantErr: true},
+		{name: "inside its window", promotion: Promotion{Type: PromotionTypeFixedAmount, Value: 5, StartsAt: &yesterday, EndsAt: &tomorrow}, want: 5},
+		{name: "unknown type", promotion: Promotion{Type: "MYSTERY", Value: 5}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.promotion.Code = "TEST"
+			tt.promotion.Active = !tt.inactive
+
+			got, err := tt.promotion.Discount(order, categories, now)
+			if tt.wantErr {
+				if !errors.Is(err, ErrPromotionNotApplicable) {
+					t.Errorf("expected the promotion not to apply, got %v, %v", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected a discount of %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -0,0 +1,127 @@
+package entity
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"slices"
+	"time"
+)
+
+const (
+	PurchaseOrderStatusDraft             = "DRAFT"
+	PurchaseOrderStatusSent              = "SENT"
+	PurchaseOrderStatusPartiallyReceived = "PARTIALLY_RECEIVED"
+	PurchaseOrderStatusReceived          = "RECEIVED"
+	PurchaseOrderStatusCancelled         = "CANCELLED"
+)
+
+// purchaseOrderTransitions lists, for every purchase order status, the statuses the purchase order is allowed to move
+// to. Once goods are received the purchase order can only be received in full, it cannot be cancelled anymore
+var purchaseOrderTransitions = map[string][]string{
+	PurchaseOrderStatusDraft:             {PurchaseOrderStatusSent, PurchaseOrderStatusCancelled},
+	PurchaseOrderStatusSent:              {PurchaseOrderStatusPartiallyReceived, PurchaseOrderStatusReceived, PurchaseOrderStatusCancelled},
+	PurchaseOrderStatusPartiallyReceived: {PurchaseOrderStatusPartiallyReceived, PurchaseOrderStatusReceived},
+	PurchaseOrderStatusReceived:          {},
+	PurchaseOrderStatusCancelled:         {},
+}
+
+type Supplier struct {
+	gorm.Model
+	Name    string `gorm:"type:varchar(255)"`
+	Email   string `gorm:"type:varchar(255)"`
+	Phone   string `gorm:"type:varchar(50)"`
+	Address string
+}
+
+// PurchaseOrder is an order of goods to a supplier. The goods are received into WarehouseID, or into the first
+// warehouse when it is nil
+type PurchaseOrder struct {
+	gorm.Model
+	SupplierID  uint   `gorm:"index"`
+	Status      string `gorm:"type:varchar(30);index"`
+	WarehouseID *uint
+	Note        string
+	CreatedBy   *uint
+	SentAt      *time.Time
+	ReceivedAt  *time.Time
+	Supplier    Supplier            `gorm:"foreignKey:SupplierID"`
+	Lines       []PurchaseOrderLine `gorm:"foreignKey:PurchaseOrderID"`
+}
+
+type PurchaseOrderLine struct {
+	gorm.Model
+	PurchaseOrderID  uint `gorm:"index"`
+	ProductID        uint `gorm:"index"`
+	VariantID        *uint
+	Quantity         int64
+	ReceivedQuantity int64
+	UnitCost         float64 `gorm:"type:double precision"`
+}
+
+type PurchaseOrderFilter struct {
+	Status     string `form:"status"`
+	SupplierID uint   `form:"supplierId"`
+}
+
+func IsValidPurchaseOrderStatus(status string) bool {
+	_, ok := purchaseOrderTransitions[status]
+	return ok
+}
+
+// CanTransitionTo reports whether the purchase order is allowed to move from its current status to the given one
+func (po *PurchaseOrder) CanTransitionTo(status string) bool {
+	return slices.Contains(purchaseOrderTransitions[po.Status], status)
+}
+
+// IsReceivable reports whether goods can be received for the purchase order, once it was sent to the supplier
+func (po *PurchaseOrder) IsReceivable() bool {
+	return po.CanTransitionTo(PurchaseOrderStatusReceived)
+}
+
+// Line returns the line of the purchase order with the given id, nil when it has none
+func (po *PurchaseOrder) Line(id uint) *PurchaseOrderLine {
+	for i := range po.Lines {
+		if po.Lines[i].ID == id {
+			return &po.Lines[i]
+		}
+	}
+	return nil
+}
+
+// IsFullyReceived reports whether every line of the purchase order was received in full
+func (po *PurchaseOrder) IsFullyReceived() bool {
+	for _, line := range po.Lines {
+		if line.Remaining() > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Total is what the purchase order costs, at the unit costs agreed with the supplier
+func (po *PurchaseOrder) Total() float64 {
+	var total float64
+	for _, line := range po.Lines {
+		total += line.UnitCost * float64(line.Quantity)
+	}
+	return RoundMoney(total)
+}
+
+// Remaining is how many of the line are still to be received
+func (l *PurchaseOrderLine) Remaining() int64 {
+	return max(l.Quantity-l.ReceivedQuantity, 0)
+}
+
+func PurchaseOrderStockReference(purchaseOrderID uint) string {
+	return fmt.Sprintf("purchase_order:%d", purchaseOrderID)
+}
+
+// AverageCost is the cost of goods of a stock after receiving quantity more of it at unitCost, the average of the
+// cost of the stock held and of the goods received weighted by their quantities. A stock that was empty, or below
+// zero, takes the cost of the goods received
+func AverageCost(cost float64, stock int64, unitCost float64, quantity int64) float64 {
+	if stock <= 0 || stock+quantity <= 0 {
+		return RoundMoney(unitCost)
+	}
+	return RoundMoney((cost*float64(stock) + unitCost*float64(quantity)) / float64(stock+quantity))
+}
\ No newline at end of file
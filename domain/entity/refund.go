@@ -0,0 +1,40 @@
+package entity
+
+import "gorm.io/gorm"
+
+const (
+	RefundStatusPending   = "PENDING"
+	RefundStatusSucceeded = "SUCCEEDED"
+	RefundStatusFailed    = "FAILED"
+)
+
+// RefundHoldingStatuses are the statuses of the refunds that count against their payment. A pending refund may still
+// go through on the gateway, so what it refunds cannot be refunded again until it fails
+var RefundHoldingStatuses = []string{RefundStatusPending, RefundStatusSucceeded}
+
+// Refund is money given back from a captured payment, for the whole order or for some quantities of its items.
+// It is saved as pending before the gateway is asked for the money, so a refund the gateway made is never left
+// without a record
+type Refund struct {
+	gorm.Model
+	OrderID       uint    `gorm:"index"`
+	PaymentID     uint    `gorm:"index"`
+	Amount        float64 `gorm:"type:double precision"`
+	Reason        string  `gorm:"type:varchar(255)"`
+	Restock       bool
+	Status        string `gorm:"type:varchar(20)"`
+	ProviderRef   string `gorm:"type:varchar(255)"`
+	FailureReason string `gorm:"type:varchar(255)"`
+	CreatedBy     uint
+	RefundItems   []RefundItem `gorm:"foreignKey:RefundID"`
+}
+
+type RefundItem struct {
+	gorm.Model
+	RefundID    uint `gorm:"index"`
+	OrderItemID uint `gorm:"index"`
+	ProductID   uint
+	VariantID   *uint
+	Quantity    int
+	Amount      float64 `gorm:"type:double precision"`
+}
\ No newline at end of file
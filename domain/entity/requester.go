@@ -0,0 +1,22 @@
+package entity
+
+// Requester is the authenticated user on whose behalf a usecase runs
+type Requester struct {
+	UserID int64
+	RoleID int64
+}
+
+func (r Requester) IsAdmin() bool {
+	return r.RoleID == RoleAdmin
+}
+
+// CanAccessOrder reports whether the requester may see or act on the order, admins can access every order
+func (r Requester) CanAccessOrder(order *Order) bool {
+	return r.IsAdmin() || int64(order.UserID) == r.UserID
+}
+
+// ActorID is the id recorded as the author of the changes the requester makes
+func (r Requester) ActorID() *uint {
+	id := uint(r.UserID)
+	return &id
+}
\ No newline at end of file
@@ -0,0 +1,139 @@
+package entity
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// SortField is one column a list is sorted by
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// SortableColumns maps the fields a list can be sorted by, as they are written in the sort param, to the columns
+// they sort. Only these ever reach the ORDER BY of a query
+type SortableColumns map[string]string
+
+var (
+	ProductSortColumns = SortableColumns{
+		"id":         "products.id",
+		"name":       "products.name",
+		"price":      "products.price",
+		"stock":      "products.stock",
+		"categoryId": "products.category_id",
+		"createdAt":  "products.created_at",
+		"updatedAt":  "products.updated_at",
+	}
+	CategorySortColumns = SortableColumns{
+		"id":        "categories.id",
+		"name":      "categories.name",
+		"createdAt": "categories.created_at",
+		"updatedAt": "categories.updated_at",
+	}
+	OrderSortColumns = SortableColumns{
+		"id":         "orders.id",
+		"status":     "orders.status",
+		"grandTotal": "orders.grand_total",
+		"createdAt":  "orders.created_at",
+		"updatedAt":  "orders.updated_at",
+	}
+	PromotionSortColumns = SortableColumns{
+		"id":        "promotions.id",
+		"code":      "promotions.code",
+		"value":     "promotions.value",
+		"startsAt":  "promotions.starts_at",
+		"endsAt":    "promotions.ends_at",
+		"usedCount": "promotions.used_count",
+		"createdAt": "promotions.created_at",
+	}
+	SupplierSortColumns = SortableColumns{
+		"id":        "suppliers.id",
+		"name":      "suppliers.name",
+		"createdAt": "suppliers.created_at",
+	}
+	PurchaseOrderSortColumns = SortableColumns{
+		"id":         "purchase_orders.id",
+		"status":     "purchase_orders.status",
+		"sentAt":     "purchase_orders.sent_at",
+		"receivedAt": "purchase_orders.received_at",
+		"createdAt":  "purchase_orders.created_at",
+	}
+	NotificationSortColumns = SortableColumns{
+		"id":        "notifications.id",
+		"createdAt": "notifications.created_at",
+	}
+	OrderItemSortColumns = SortableColumns{
+		"id":        "order_items.id",
+		"orderId":   "order_items.order_id",
+		"productId": "order_items.product_id",
+		"quantity":  "order_items.quantity",
+		"price":     "order_items.price",
+		"createdAt": "order_items.created_at",
+	}
+	StockMovementSortColumns = SortableColumns{
+		"id":        "stock_movements.id",
+		"quantity":  "stock_movements.quantity",
+		"createdAt": "stock_movements.created_at",
+	}
+)
+
+// Fields lists the fields the columns can be sorted by, in order
+func (s SortableColumns) Fields() []string {
+	fields := make([]string, 0, len(s))
+	for field := range s {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func (s SortableColumns) HasColumn(column string) bool {
+	for _, c := range s {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSort reads a sort written as comma separated fields, a field sorted in descending order starting with a -,
+// like -price,name. The list is sorted by defaults when there is no sort. The id is always sorted by last, in the
+// direction of the last field, so that rows sorting the same keep their order from a page to the next
+func ParseSort(spec string, sortable SortableColumns, defaults ...SortField) ([]SortField, error) {
+	fields := make([]SortField, 0)
+	seen := make([]string, 0)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, desc := strings.CutPrefix(part, "-")
+		column, ok := sortable[name]
+		if !ok {
+			return nil, fmt.Errorf("cannot sort by [%s], the sortable fields are %s", name, strings.Join(sortable.Fields(), ", "))
+		}
+		if slices.Contains(seen, column) {
+			return nil, fmt.Errorf("cannot sort by [%s] twice", name)
+		}
+		seen = append(seen, column)
+		fields = append(fields, SortField{Column: column, Desc: desc})
+	}
+	if len(fields) == 0 {
+		fields = append(fields, defaults...)
+	}
+
+	id, ok := sortable["id"]
+	if !ok {
+		return fields, nil
+	}
+	for _, f := range fields {
+		if f.Column == id {
+			return fields, nil
+		}
+	}
+	desc := len(fields) > 0 && fields[len(fields)-1].Desc
+	return append(fields, SortField{Column: id, Desc: desc}), nil
+}
\ No newline at end of file
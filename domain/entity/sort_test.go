@@ -0,0 +1,99 @@
+package entity
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSort(t *testing.T) {
+	sortable := SortableColumns{"id": "things.id", "name": "things.name", "price": "things.price"}
+	byName := SortField{Column: "things.name"}
+
+	tests := []struct {
+		name     string
+		spec     string
+		sortable SortableColumns
+		defaults []SortField
+		want     []SortField
+		wantErr  bool
+	}{
+		{
+			name:     "ascending and descending fields end with the id in the direction of the last one",
+			spec:     "-price,name",
+			sortable: sortable,
+			want:     []SortField{{Column: "things.price", Desc: true}, {Column: "things.name"}, {Column: "things.id"}},
+		},
+		{
+			name:     "a descending last field sorts the id descending",
+			spec:     "name,-price",
+			sortable: sortable,
+			want:     []SortField{{Column: "things.name"}, {Column: "things.price", Desc: true}, {Column: "things.id", Desc: true}},
+		},
+		{
+			name:     "the id is not added twice",
+			spec:     "-id,name",
+			sortable: sortable,
+			want:     []SortField{{Column: "things.id", Desc: true}, {Column: "things.name"}},
+		},
+		{
+			name:     "blanks and empty fields are skipped",
+			spec:     " price , ,",
+			sortable: sortable,
+			want:     []SortField{{Column: "things.price"}, {Column: "things.id"}},
+		},
+		{
+			name:     "no sort falls back to the defaults",
+			spec:     "",
+			sortable: sortable,
+			defaults: []SortField{byName},
+			want:     []SortField{byName, {Column: "things.id"}},
+		},
+		{
+			name:     "no sort and no defaults sorts by the id",
+			spec:     "",
+			sortable: sortable,
+			want:     []SortField{{Column: "things.id"}},
+		},
+		{
+			name:     "columns without an id are left as they are",
+			spec:     "name",
+			sortable: SortableColumns{"name": "things.name"},
+			want:     []SortField{byName},
+		},
+		{
+			name:     "an unknown field is refused",
+			spec:     "password",
+			sortable: sortable,
+			wantErr:  true,
+		},
+		{
+			name:     "a column given as the field is refused",
+			spec:     "things.name",
+			sortable: sortable,
+			wantErr:  true,
+		},
+		{
+			name:     "a field sorted twice is refused",
+			spec:     "name,-name",
+			sortable: sortable,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSort(tt.spec, tt.sortable, tt.defaults...)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected sort %q to be refused, got %v", tt.spec, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
\ No newline at end of file
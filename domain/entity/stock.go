@@ -0,0 +1,108 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	StockReasonOpening      = "opening"
+	StockReasonSale         = "sale"
+	StockReasonCancellation = "cancellation"
+	StockReasonAdjustment   = "adjustment"
+	StockReasonRestock      = "restock"
+	StockReasonReturn       = "return"
+
+	// StockReferenceManual is the reference of a stock change an admin makes without giving one
+	StockReferenceManual = "manual"
+	// StockReferenceBackfill is the reference of the opening movement the reconciliation gives stock that was there
+	// before the ledger
+	StockReferenceBackfill = "backfill"
+)
+
+// StockMovement is one signed change of the stock of a product, or of one of its variants, with what caused it.
+// Movements are only ever appended in the transaction of the change itself, so the stock counter always equals the
+// sum of the movements of its product or variant. ActorID is nil when no user made the change
+type StockMovement struct {
+	ID        uint  `gorm:"primarykey"`
+	ProductID uint  `gorm:"index"`
+	VariantID *uint `gorm:"index"`
+	Quantity  int64
+	Balance   int64
+	Reason    string `gorm:"type:varchar(30)"`
+	Reference string `gorm:"type:varchar(100)"`
+	ActorID   *uint
+	CreatedAt time.Time `gorm:"index"`
+}
+
+// StockDrift is a stock counter the reconciliation found out of line with the sum of its movements, which means
+// the stock was changed without going through the ledger
+type StockDrift struct {
+	ID         uint  `gorm:"primarykey"`
+	ProductID  uint  `gorm:"index"`
+	VariantID  *uint `gorm:"index"`
+	Counter    int64
+	Ledger     int64
+	Difference int64
+	DetectedAt time.Time `gorm:"index"`
+}
+
+// LowStockAlert is a product, or one of its variants, reported at or below the reorder threshold of the product. It
+// stays open until the stock is back above the threshold, so a shortage is only reported once
+type LowStockAlert struct {
+	ID         uint  `gorm:"primarykey"`
+	ProductID  uint  `gorm:"index"`
+	VariantID  *uint `gorm:"index"`
+	Stock      int64
+	Threshold  int64
+	CreatedAt  time.Time
+	ResolvedAt *time.Time `gorm:"index"`
+}
+
+// StockCause is why stock moves, every movement it causes is recorded with it. An empty reference stands for
+// the order of each moved item
+type StockCause struct {
+	Reason    string
+	Reference string
+	ActorID   *uint
+}
+
+func OrderStockReference(orderID uint) string {
+	return fmt.Sprintf("order:%d", orderID)
+}
+
+func RefundStockReference(refundID uint) string {
+	return fmt.Sprintf("refund:%d", refundID)
+}
+
+// StockHistory is the stock counter of a product, or of one of its variants, next to what its ledger gives, with
+// the movements that led to it
+type StockHistory struct {
+	ProductID uint
+	VariantID *uint
+	Stock     int64
+	Ledger    int64
+	Movements []StockMovement
+}
+
+// StockOf is the stock of the product, or of the given variant of it
+func (p *Product) StockOf(variantID *uint) int64 {
+	if variantID != nil {
+		if v := p.Variant(*variantID); v != nil {
+			return v.Stock
+		}
+	}
+	return p.Stock
+}
+
+// TotalStock is the stock of the product, the sum of the stock of its variants when it has any
+func (p *Product) TotalStock() int64 {
+	if !p.HasVariants() {
+		return p.Stock
+	}
+	var total int64
+	for _, v := range p.Variants {
+		total += v.Stock
+	}
+	return total
+}
\ No newline at end of file
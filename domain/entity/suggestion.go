@@ -0,0 +1,26 @@
+package entity
+
+const (
+	SuggestionKindProduct  = "product"
+	SuggestionKindCategory = "category"
+)
+
+// Suggestion is a product or a category offered while a search is typed, Label is its name as it is shown
+type Suggestion struct {
+	Kind  string
+	ID    uint
+	Label string
+}
+
+type SuggestionQuery struct {
+	Query string `form:"q"`
+	Limit int    `form:"limit"`
+}
+
+func ProductSuggestion(p *Product) Suggestion {
+	return Suggestion{Kind: SuggestionKindProduct, ID: p.ID, Label: p.Name}
+}
+
+func CategorySuggestion(c *Category) Suggestion {
+	return Suggestion{Kind: SuggestionKindCategory, ID: c.ID, Label: c.Name}
+}
\ No newline at end of file
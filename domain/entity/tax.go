@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+	"strings"
+)
+
+// TaxClass groups products taxed the same way. A product without a tax class of its own is taxed with the tax
+// class of its category, and a product with neither is not taxed
+type TaxClass struct {
+	gorm.Model
+	Name        string `gorm:"type:varchar(100);uniqueIndex:idx_tax_classes_name,where:deleted_at IS NULL"`
+	Description string `gorm:"type:varchar(255)"`
+}
+
+// TaxRate is the percentage a tax class is taxed with in a region. A rate with an empty region applies to the whole
+// country, a rate of the region the order is shipped to wins over it
+type TaxRate struct {
+	gorm.Model
+	TaxClassID uint    `gorm:"uniqueIndex:idx_tax_rates_region,where:deleted_at IS NULL"`
+	Country    string  `gorm:"type:varchar(2);uniqueIndex:idx_tax_rates_region,where:deleted_at IS NULL"`
+	Region     string  `gorm:"type:varchar(100);uniqueIndex:idx_tax_rates_region,where:deleted_at IS NULL"`
+	Name       string  `gorm:"type:varchar(100)"`
+	Rate       float64 `gorm:"type:double precision"`
+}
+
+// NormalizeTaxRegion makes the country and region of a rate comparable with the shipping address of an order
+func NormalizeTaxRegion(country, region string) (string, string) {
+	return strings.ToUpper(strings.TrimSpace(country)), strings.TrimSpace(region)
+}
+
+// ApplyTaxRate taxes the line of the item, its price is the net price and the tax comes on top of it
+func (oi *OrderItem) ApplyTaxRate(rate float64) {
+	oi.TaxRate = rate
+	oi.TaxAmount = RoundMoney(oi.Price * float64(oi.Quantity) * rate / 100)
+}
\ No newline at end of file
@@ -0,0 +1,108 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+	"sort"
+	"strings"
+)
+
+// ProductVariant is one version of a product, such as one size and color of a T-shirt. A product with variants is
+// sold and stocked through them, the stock of the product itself is not used
+type ProductVariant struct {
+	gorm.Model
+	ProductID uint     `gorm:"index"`
+	SKU       string   `gorm:"type:varchar(64);uniqueIndex:idx_product_variants_sku,where:deleted_at IS NULL"`
+	Price     *float64 `gorm:"type:double precision"`
+	Stock     int64
+	Image     string                 `gorm:"type:text"`
+	Options   []ProductVariantOption `gorm:"foreignKey:VariantID"`
+}
+
+// ProductVariantOption is the value a variant has for one option of its product, a variant has one value per option
+type ProductVariantOption struct {
+	ID        uint   `gorm:"primaryKey"`
+	VariantID uint   `gorm:"uniqueIndex:idx_product_variant_options_name"`
+	Name      string `gorm:"type:varchar(50);uniqueIndex:idx_product_variant_options_name"`
+	Value     string `gorm:"type:varchar(100)"`
+}
+
+func NormalizeSKU(sku string) string {
+	return strings.ToUpper(strings.TrimSpace(sku))
+}
+
+func NormalizeVariantOptionName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// ParseVariantOption splits an option filter written as name:value
+func ParseVariantOption(option string) (string, string, bool) {
+	name, value, ok := strings.Cut(option, ":")
+	name = NormalizeVariantOptionName(name)
+	value = strings.TrimSpace(value)
+	if !ok || name == "" || value == "" {
+		return "", "", false
+	}
+	return name, value, true
+}
+
+// PriceOf is the price the variant sells at, its own price or the price of its product when it has none
+func (v *ProductVariant) PriceOf(product *Product) float64 {
+	if v.Price != nil {
+		return *v.Price
+	}
+	return product.Price
+}
+
+// Describe names the variant by its option values, like "size: M, color: red"
+func (v *ProductVariant) Describe() string {
+	values := make([]string, 0, len(v.Options))
+	for _, o := range v.Options {
+		values = append(values, o.Name+": "+o.Value)
+	}
+	return strings.Join(values, ", ")
+}
+
+// OptionsKey identifies the combination of option values of the variant whatever their order and case, two variants
+// of a product with the same key could not be told apart
+func (v *ProductVariant) OptionsKey() string {
+	values := make([]string, 0, len(v.Options))
+	for _, o := range v.Options {
+		values = append(values, NormalizeVariantOptionName(o.Name)+"="+strings.ToLower(o.Value))
+	}
+	sort.Strings(values)
+	return strings.Join(values, ";")
+}
+
+func (p *Product) HasVariants() bool {
+	return len(p.Variants) > 0
+}
+
+func (p *Product) Variant(id uint) *ProductVariant {
+	for i := range p.Variants {
+		if p.Variants[i].ID == id {
+			return &p.Variants[i]
+		}
+	}
+	return nil
+}
+
+// UnitPrice is the price of the product, or of the given variant of it
+func (p *Product) UnitPrice(variantID *uint) float64 {
+	if variantID != nil {
+		if v := p.Variant(*variantID); v != nil {
+			return v.PriceOf(p)
+		}
+	}
+	return p.Price
+}
+
+// SameStock reports whether both items take their stock from the same product or variant
+func (oi OrderItem) SameStock(other OrderItem) bool {
+	if oi.ProductID != other.ProductID {
+		return false
+	}
+	if oi.VariantID == nil || other.VariantID == nil {
+		return oi.VariantID == nil && other.VariantID == nil
+	}
+	return *oi.VariantID == *other.VariantID
+}
\ No newline at end of file
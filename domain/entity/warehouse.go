@@ -0,0 +1,176 @@
+package entity
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+const (
+	// AllocationStrategyPriority takes every item from the warehouses in their priority order, as much as each has
+	AllocationStrategyPriority = "priority"
+	// AllocationStrategySingle takes the whole order from the first warehouse, by priority, that has all of it and
+	// splits it by priority when no warehouse has
+	AllocationStrategySingle = "single"
+)
+
+type Warehouse struct {
+	gorm.Model
+	Code     string `gorm:"type:varchar(30);uniqueIndex:idx_warehouses_code,where:deleted_at IS NULL"`
+	Name     string
+	Address  string
+	Priority int `gorm:"index"`
+}
+
+// WarehouseStock is how many of a product, or of one of its variants, a warehouse holds. Once there are warehouses
+// the stock counter of every product and variant is the sum of its stock in all of them
+type WarehouseStock struct {
+	ID          uint  `gorm:"primarykey"`
+	WarehouseID uint  `gorm:"uniqueIndex:idx_warehouse_stocks_product,where:variant_id IS NULL;uniqueIndex:idx_warehouse_stocks_variant"`
+	ProductID   uint  `gorm:"uniqueIndex:idx_warehouse_stocks_product,where:variant_id IS NULL;index"`
+	VariantID   *uint `gorm:"uniqueIndex:idx_warehouse_stocks_variant"`
+	Quantity    int64
+	UpdatedAt   time.Time
+}
+
+// StockTransfer records stock moved from a warehouse to another, it leaves the stock counters as they are
+type StockTransfer struct {
+	ID              uint `gorm:"primarykey"`
+	FromWarehouseID uint `gorm:"index"`
+	ToWarehouseID   uint `gorm:"index"`
+	ProductID       uint `gorm:"index"`
+	VariantID       *uint
+	Quantity        int64
+	ActorID         *uint
+	CreatedAt       time.Time
+}
+
+// OrderItemAllocation is how many of an order item are taken from a warehouse, an item may be split over several
+type OrderItemAllocation struct {
+	ID          uint `gorm:"primarykey"`
+	OrderItemID uint `gorm:"index"`
+	WarehouseID uint `gorm:"index"`
+	Quantity    int
+	CreatedAt   time.Time
+}
+
+// PickingList is what has to be picked in a warehouse for an order
+type PickingList struct {
+	Warehouse Warehouse
+	Lines     []PickingLine
+}
+
+type PickingLine struct {
+	OrderItemID uint
+	ProductID   uint
+	VariantID   *uint
+	Name        string
+	SKU         string
+	Quantity    int
+}
+
+func NormalizeWarehouseCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func IsValidAllocationStrategy(strategy string) bool {
+	return strategy == AllocationStrategyPriority || strategy == AllocationStrategySingle
+}
+
+type stockKey struct {
+	warehouseID uint
+	productID   uint
+	variantID   uint
+}
+
+func stockKeyOf(warehouseID uint, productID uint, variantID *uint) stockKey {
+	key := stockKey{warehouseID: warehouseID, productID: productID}
+	if variantID != nil {
+		key.variantID = *variantID
+	}
+	return key
+}
+
+// StockLevels is the stock every warehouse holds of every product and variant
+type StockLevels map[stockKey]int64
+
+func NewStockLevels(stocks []WarehouseStock) StockLevels {
+	levels := make(StockLevels)
+	for _, s := range stocks {
+		levels[stockKeyOf(s.WarehouseID, s.ProductID, s.VariantID)] += s.Quantity
+	}
+	return levels
+}
+
+func (l StockLevels) Of(warehouseID uint, item OrderItem) int64 {
+	return l[stockKeyOf(warehouseID, item.ProductID, item.VariantID)]
+}
+
+func (l StockLevels) take(warehouseID uint, item OrderItem, quantity int64) {
+	l[stockKeyOf(warehouseID, item.ProductID, item.VariantID)] -= quantity
+}
+
+// Allocate decides which warehouses the items are taken from with the strategy, the warehouses being in their
+// priority order. The levels are left with what remains after the allocations
+func Allocate(strategy string, warehouses []Warehouse, levels StockLevels, items []OrderItem) ([]OrderItemAllocation, error) {
+	if strategy == AllocationStrategySingle {
+		if allocations, ok := allocateFromSingleWarehouse(warehouses, levels, items); ok {
+			return allocations, nil
+		}
+	}
+	return allocateByPriority(warehouses, levels, items)
+}
+
+func allocateFromSingleWarehouse(warehouses []Warehouse, levels StockLevels, items []OrderItem) ([]OrderItemAllocation, bool) {
+	for _, w := range warehouses {
+		needed := make(StockLevels)
+		for _, item := range items {
+			needed[stockKeyOf(w.ID, item.ProductID, item.VariantID)] += int64(item.Quantity)
+		}
+		fulfills := true
+		for key, quantity := range needed {
+			if levels[key] < quantity {
+				fulfills = false
+				break
+			}
+		}
+		if !fulfills {
+			continue
+		}
+
+		allocations := make([]OrderItemAllocation, 0)
+		for _, item := range items {
+			levels.take(w.ID, item, int64(item.Quantity))
+			allocations = append(allocations, OrderItemAllocation{OrderItemID: item.ID, WarehouseID: w.ID, Quantity: item.Quantity})
+		}
+		return allocations, true
+	}
+	return nil, false
+}
+
+func allocateByPriority(warehouses []Warehouse, levels StockLevels, items []OrderItem) ([]OrderItemAllocation, error) {
+	allocations := make([]OrderItemAllocation, 0)
+	for _, item := range items {
+		remaining := int64(item.Quantity)
+		for _, w := range warehouses {
+			if remaining == 0 {
+				break
+			}
+			taken := min(levels.Of(w.ID, item), remaining)
+			if taken <= 0 {
+				continue
+			}
+			levels.take(w.ID, item, taken)
+			remaining -= taken
+			allocations = append(allocations, OrderItemAllocation{OrderItemID: item.ID, WarehouseID: w.ID, Quantity: int(taken)})
+		}
+		if remaining > 0 {
+			if item.VariantID != nil {
+				return nil, fmt.Errorf("the warehouses do not have enough stock of the variant %v of product %v", *item.VariantID, item.ProductID)
+			}
+			return nil, fmt.Errorf("the warehouses do not have enough stock of the product %v", item.ProductID)
+		}
+	}
+	return allocations, nil
+}
\ No newline at end of file
@@ -0,0 +1,128 @@
+package entity
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestAllocate(t *testing.T) {
+	variantID := uint(7)
+	// the warehouses are given in their priority order
+	warehouses := []Warehouse{{Priority: 1}, {Priority: 2}, {Priority: 3}}
+	for i := range warehouses {
+		warehouses[i].ID = uint(i + 1)
+	}
+	stocks := []WarehouseStock{
+		{WarehouseID: 1, ProductID: 1, Quantity: 2},
+		{WarehouseID: 2, ProductID: 1, Quantity: 5},
+		{WarehouseID: 3, ProductID: 1, Quantity: 10},
+		{WarehouseID: 2, ProductID: 2, Quantity: 3},
+		{WarehouseID: 3, ProductID: 2, Quantity: 3},
+		{WarehouseID: 1, ProductID: 2, VariantID: &variantID, Quantity: 4},
+	}
+	item := func(id uint, productID uint, variantID *uint, quantity int) OrderItem {
+		i := OrderItem{ProductID: productID, VariantID: variantID, Quantity: quantity}
+		i.ID = id
+		return i
+	}
+
+	tests := []struct {
+		name     string
+		strategy string
+		items    []OrderItem
+		want     []OrderItemAllocation
+		wantErr  bool
+	}{
+		{
+			name:     "priority takes from the first warehouses as much as they have",
+			strategy: AllocationStrategyPriority,
+			items:    []OrderItem{item(1, 1, nil, 4)},
+			want:     []OrderItemAllocation{{OrderItemID: 1, WarehouseID: 1, Quantity: 2}, {OrderItemID: 1, WarehouseID: 2, Quantity: 2}},
+		},
+		{
+			name:     "priority skips the warehouses without the item",
+			strategy: AllocationStrategyPriority,
+			items:    []OrderItem{item(1, 2, nil, 2)},
+			want:     []OrderItemAllocation{{OrderItemID: 1, WarehouseID: 2, Quantity: 2}},
+		},
+		{
+			name:     "priority keeps variants apart from their product",
+			strategy: AllocationStrategyPriority,
+			items:    []OrderItem{item(1, 2, &variantID, 3), item(2, 2, nil, 1)},
+			want:     []OrderItemAllocation{{OrderItemID: 1, WarehouseID: 1, Quantity: 3}, {OrderItemID: 2, WarehouseID: 2, Quantity: 1}},
+		},
+		{
+			name:     "priority takes what the earlier items left",
+			strategy: AllocationStrategyPriority,
+			items:    []OrderItem{item(1, 1, nil, 6), item(2, 1, nil, 3)},
+			want: []OrderItemAllocation{
+				{OrderItemID: 1, WarehouseID: 1, Quantity: 2}, {OrderItemID: 1, WarehouseID: 2, Quantity: 4},
+				{OrderItemID: 2, WarehouseID: 2, Quantity: 1}, {OrderItemID: 2, WarehouseID: 3, Quantity: 2},
+			},
+		},
+		{
+			name:     "single takes the whole order from the first warehouse that has all of it",
+			strategy: AllocationStrategySingle,
+			items:    []OrderItem{item(1, 1, nil, 4), item(2, 2, nil, 3)},
+			want:     []OrderItemAllocation{{OrderItemID: 1, WarehouseID: 2, Quantity: 4}, {OrderItemID: 2, WarehouseID: 2, Quantity: 3}},
+		},
+		{
+			name:     "single counts every item of the same product together",
+			strategy: AllocationStrategySingle,
+			items:    []OrderItem{item(1, 1, nil, 3), item(2, 1, nil, 3)},
+			want:     []OrderItemAllocation{{OrderItemID: 1, WarehouseID: 3, Quantity: 3}, {OrderItemID: 2, WarehouseID: 3, Quantity: 3}},
+		},
+		{
+			name:     "single splits by priority when no warehouse has all of it",
+			strategy: AllocationStrategySingle,
+			items:    []OrderItem{item(1, 1, nil, 1), item(2, 2, &variantID, 1), item(3, 2, nil, 1)},
+			want: []OrderItemAllocation{
+				{OrderItemID: 1, WarehouseID: 1, Quantity: 1}, {OrderItemID: 2, WarehouseID: 1, Quantity: 1}, {OrderItemID: 3, WarehouseID: 2, Quantity: 1},
+			},
+		},
+		{
+			name:     "priority refuses more than all the warehouses have",
+			strategy: AllocationStrategyPriority,
+			items:    []OrderItem{item(1, 1, nil, 18)},
+			wantErr:  true,
+		},
+		{
+			name:     "single refuses more than all the warehouses have",
+			strategy: AllocationStrategySingle,
+			items:    []OrderItem{item(1, 2, &variantID, 5)},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			levels := NewStockLevels(stocks)
+			got, err := Allocate(tt.strategy, warehouses, levels, tt.items)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected the allocation to be refused, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+
+			// the levels are left with what the allocations did not take
+			want := NewStockLevels(stocks)
+			for _, a := range got {
+				for _, i := range tt.items {
+					if i.ID == a.OrderItemID {
+						want.take(a.WarehouseID, i, int64(a.Quantity))
+					}
+				}
+			}
+			if !maps.Equal(levels, want) {
+				t.Errorf("expected the levels %v to be left, got %v", want, levels)
+			}
+		})
+	}
+}
\ No newline at end of file
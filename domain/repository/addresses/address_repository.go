@@ -0,0 +1,13 @@
+package addresses
+
+import "pm/domain/entity"
+
+type AddressRepository interface {
+	Create(*entity.Address) error
+	Update(*entity.Address) error
+	GetAddressByID(id int64) (*entity.Address, error)
+	GetAddressesByUserID(userID int64) ([]entity.Address, error)
+	GetDefaultAddress(userID int64) (*entity.Address, error)
+	DeleteAddress(*entity.Address) error
+	SetDefault(*entity.Address) error
+}
\ No newline at end of file
@@ -4,7 +4,9 @@ import "time"
 
 type RedisCacheRepository interface {
 	GetHash(key string, property string, src interface{})
+	GetAllHash(key string) (map[string]string, error)
 	DeleteHash(key string, property string) error
 	SetHashObject(key string, property string, object interface{}) error
 	SetExpireKey(key string, expiration time.Duration) error
+	DeleteKey(key string) error
 }
\ No newline at end of file
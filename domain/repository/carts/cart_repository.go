@@ -0,0 +1,10 @@
+package carts
+
+import "pm/domain/entity"
+
+type CartRepository interface {
+	GetCartItems(userID int64) ([]entity.CartItem, error)
+	SetCartItem(*entity.CartItem) error
+	RemoveCartItem(userID int64, productID int64, variantID *uint) error
+	ClearCart(userID int64) error
+}
\ No newline at end of file
@@ -0,0 +1,16 @@
+package idempotency
+
+import (
+	"go.opentelemetry.io/otel/trace"
+	"pm/domain/entity"
+	"time"
+)
+
+type IdempotencyRepository interface {
+	GetByKey(span trace.Span, userID int64, key string) (*entity.IdempotencyKey, error)
+	Reserve(trace.Span, *entity.IdempotencyKey) (bool, error)
+	Complete(trace.Span, *entity.IdempotencyKey) error
+	Release(trace.Span, *entity.IdempotencyKey) error
+	Extend(trace.Span, *entity.IdempotencyKey) error
+	ReleaseStale(span trace.Span, record *entity.IdempotencyKey, staleBefore time.Time) (bool, error)
+}
\ No newline at end of file
@@ -0,0 +1,10 @@
+package invoices
+
+import "pm/domain/entity"
+
+type InvoiceRepository interface {
+	NextNumber(series string) (int64, error)
+	Create(*entity.Invoice) error
+	GetInvoiceByOrderID(orderID int64) (*entity.Invoice, error)
+	UpdateDelivery(*entity.Invoice) error
+}
\ No newline at end of file
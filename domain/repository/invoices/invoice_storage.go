@@ -0,0 +1,6 @@
+package invoices
+
+// InvoiceStorage keeps the rendered invoices, Store returns where the stored document can be found
+type InvoiceStorage interface {
+	Store(fileName string, content []byte) (string, error)
+}
\ No newline at end of file
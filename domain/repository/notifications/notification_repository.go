@@ -0,0 +1,9 @@
+package notifications
+
+import "pm/domain/entity"
+
+type NotificationRepository interface {
+	GetAllNotifications(filter *entity.NotificationFilter, pagination *entity.Pagination) ([]entity.Notification, error)
+	GetNotificationByID(id int64) (*entity.Notification, error)
+	MarkRead(*entity.Notification) error
+}
\ No newline at end of file
@@ -0,0 +1,9 @@
+package order_events
+
+import "pm/domain/entity"
+
+// OrderEventRepository has no update nor delete on purpose, the history of an order can only grow
+type OrderEventRepository interface {
+	Create(*entity.OrderEvent) error
+	GetEventsByOrderID(orderID int64) ([]entity.OrderEvent, error)
+}
\ No newline at end of file
@@ -6,6 +6,6 @@ type OrderItemRepository interface {
 	CreateNewOrderItems([]entity.OrderItem) error
 	UpdateOrderItems([]entity.OrderItem) ([]entity.OrderItem, error)
 	GetOrderItemByID(int64) (*entity.OrderItem, error)
-	GetAllOrderItems() ([]entity.OrderItem, error)
+	GetAllOrderItems(*entity.Pagination) ([]entity.OrderItem, error)
 	DeleteOrderItemByID(int64) error
 }
\ No newline at end of file
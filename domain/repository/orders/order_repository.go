@@ -9,8 +9,13 @@ import (
 type OrderRepository interface {
 	Create(*entity.Order) error
 	Update(*entity.Order) (*entity.Order, error)
+	UpdateStatus(order *entity.Order, status string) error
+	UpdateTotals(*entity.Order) error
+	Cancel(order *entity.Order, cancelledBy int64, reason string) error
+	Ship(order *entity.Order, shippingMethod string, trackingNumber string) error
 	GetOrderByID(id int64) (*entity.Order, error)
-	GetAllOrders(pagination *entity.Pagination) ([]entity.Order, error)
+	LockOrder(id int64) error
+	GetAllOrders(filter *entity.OrderFilter, pagination *entity.Pagination) ([]entity.Order, error)
 	DeleteOrder(*entity.Order) error
 	IsAvailableStockByOrderItems(*base.Persistence, *gin.Context, ...entity.OrderItem) ([]entity.Product, error)
 	//GetOrdersByUserID(userID int64) ([]entity.Order, error)
@@ -0,0 +1,29 @@
+package payments
+
+import "pm/domain/entity"
+
+// GatewayResult is the answer of a payment gateway about one payment
+type GatewayResult struct {
+	ProviderRef   string
+	Status        string
+	RedirectURL   string
+	FailureReason string
+}
+
+// CallbackEvent is a notification a payment gateway sent about one of its payments, it is only returned once
+// its signature has been verified
+type CallbackEvent struct {
+	ProviderRef   string  `json:"providerRef"`
+	Status        string  `json:"status"`
+	Amount        float64 `json:"amount"`
+	FailureReason string  `json:"failureReason"`
+}
+
+type PaymentGateway interface {
+	Name() string
+	Initiate(*entity.Payment) (*GatewayResult, error)
+	Capture(*entity.Payment) (*GatewayResult, error)
+	Void(*entity.Payment) (*GatewayResult, error)
+	Refund(payment *entity.Payment, amount float64) (*GatewayResult, error)
+	ParseCallback(body []byte, signature string) (*CallbackEvent, error)
+}
\ No newline at end of file
@@ -0,0 +1,11 @@
+package payments
+
+import "pm/domain/entity"
+
+type PaymentRepository interface {
+	Create(*entity.Payment) error
+	Update(*entity.Payment) error
+	GetPaymentByID(id int64) (*entity.Payment, error)
+	GetPaymentByProviderRef(provider string, providerRef string) (*entity.Payment, error)
+	GetPaymentsByOrderID(orderID int64) ([]entity.Payment, error)
+}
\ No newline at end of file
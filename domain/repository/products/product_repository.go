@@ -9,9 +9,22 @@ type ProductRepository interface {
 	Create(trace.Span, *entity.Product) error
 	Update(trace.Span, *entity.Product) (*entity.Product, error)
 	GetProductByID(trace.Span, int64) (*entity.Product, error)
+	GetProductByIDForUpdate(trace.Span, int64) (*entity.Product, error)
 	GetAllProducts(trace.Span, *entity.ProductFilter, *entity.Pagination) ([]entity.Product, error)
+	SearchProducts(trace.Span, *entity.ProductSearch, *entity.Pagination) ([]entity.Product, error)
+	GetProductFacets(trace.Span, *entity.ProductFilter, []entity.PriceBucket) (*entity.ProductFacets, error)
+	ReindexCategory(trace.Span, *entity.Category) error
 	DeleteProduct(trace.Span, *entity.Product) error
 	GetProductByOrderItem(trace.Span, ...entity.OrderItem) ([]entity.Product, error)
-	UpdateMultiProduct(trace.Span, ...entity.Product) ([]entity.Product, error)
+	GetProductsByIDs(trace.Span, ...uint) ([]entity.Product, error)
 	IsAvailableStockByOrderItems(trace.Span, ...entity.OrderItem) ([]entity.Product, error)
+	IncreaseStock(trace.Span, ...entity.OrderItem) ([]entity.Product, error)
+	DecreaseStock(trace.Span, ...entity.OrderItem) ([]entity.Product, error)
+	AdjustStock(span trace.Span, item entity.OrderItem, delta int64) (*entity.Product, error)
+	UpdateCostPrice(span trace.Span, product *entity.Product, cost float64) error
+	CreateVariant(trace.Span, *entity.ProductVariant) error
+	UpdateVariant(trace.Span, *entity.ProductVariant) error
+	GetVariantByID(span trace.Span, productID int64, variantID int64) (*entity.ProductVariant, error)
+	GetVariantBySKU(trace.Span, string) (*entity.ProductVariant, error)
+	DeleteVariant(trace.Span, *entity.ProductVariant) error
 }
\ No newline at end of file
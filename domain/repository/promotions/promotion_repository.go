@@ -0,0 +1,16 @@
+package promotions
+
+import "pm/domain/entity"
+
+type PromotionRepository interface {
+	Create(*entity.Promotion) error
+	Update(*entity.Promotion) error
+	GetPromotionByID(id int64) (*entity.Promotion, error)
+	GetPromotionByCode(code string) (*entity.Promotion, error)
+	GetAllPromotions(pagination *entity.Pagination) ([]entity.Promotion, error)
+	DeletePromotion(*entity.Promotion) error
+	LockPromotionByCode(code string) (*entity.Promotion, error)
+	CountUsagesByUser(promotionID uint, userID uint) (int64, error)
+	Use(promotion *entity.Promotion, userID uint, orderID uint) error
+	Release(orderID uint) error
+}
\ No newline at end of file
@@ -0,0 +1,14 @@
+package purchase_orders
+
+import "pm/domain/entity"
+
+type PurchaseOrderRepository interface {
+	Create(*entity.PurchaseOrder) error
+	Update(*entity.PurchaseOrder) error
+	ReplaceLines(purchaseOrder *entity.PurchaseOrder, lines []entity.PurchaseOrderLine) error
+	GetPurchaseOrderByID(id int64) (*entity.PurchaseOrder, error)
+	LockPurchaseOrder(id int64) (*entity.PurchaseOrder, error)
+	GetAllPurchaseOrders(filter *entity.PurchaseOrderFilter, pagination *entity.Pagination) ([]entity.PurchaseOrder, error)
+	UpdateStatus(*entity.PurchaseOrder) error
+	ReceiveLine(line *entity.PurchaseOrderLine, quantity int64) error
+}
\ No newline at end of file
@@ -0,0 +1,11 @@
+package refunds
+
+import "pm/domain/entity"
+
+type RefundRepository interface {
+	Create(*entity.Refund) error
+	Update(*entity.Refund) error
+	GetRefundsByOrderID(orderID int64) ([]entity.Refund, error)
+	GetRefundedAmountByPaymentID(paymentID int64, statuses ...string) (float64, error)
+	GetRefundedQuantitiesByOrderID(orderID int64, statuses ...string) (map[uint]int, error)
+}
\ No newline at end of file
@@ -0,0 +1,10 @@
+package stock_movements
+
+import "pm/domain/entity"
+
+// StockMovementRepository has no update nor delete on purpose, the stock ledger can only grow
+type StockMovementRepository interface {
+	Create(...entity.StockMovement) error
+	GetStockHistory(productID int64, variantID *uint, pagination *entity.Pagination) ([]entity.StockMovement, error)
+	SumMovements(productID int64, variantID *uint) (int64, error)
+}
\ No newline at end of file
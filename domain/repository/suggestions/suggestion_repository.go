@@ -0,0 +1,10 @@
+package suggestions
+
+import "pm/domain/entity"
+
+type SuggestionRepository interface {
+	Rebuild(suggestions []entity.Suggestion) error
+	Index(suggestion entity.Suggestion) error
+	Remove(kind string, id uint) error
+	Suggest(query string, limit int) ([]entity.Suggestion, error)
+}
\ No newline at end of file
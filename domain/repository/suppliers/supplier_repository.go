@@ -0,0 +1,11 @@
+package suppliers
+
+import "pm/domain/entity"
+
+type SupplierRepository interface {
+	Create(*entity.Supplier) error
+	Update(*entity.Supplier) error
+	GetSupplierByID(id int64) (*entity.Supplier, error)
+	GetAllSuppliers(pagination *entity.Pagination) ([]entity.Supplier, error)
+	DeleteSupplier(*entity.Supplier) error
+}
\ No newline at end of file
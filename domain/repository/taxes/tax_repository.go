@@ -0,0 +1,17 @@
+package taxes
+
+import "pm/domain/entity"
+
+type TaxRepository interface {
+	CreateTaxClass(*entity.TaxClass) error
+	UpdateTaxClass(*entity.TaxClass) error
+	GetTaxClassByID(id int64) (*entity.TaxClass, error)
+	GetAllTaxClasses() ([]entity.TaxClass, error)
+	DeleteTaxClass(*entity.TaxClass) error
+	CreateTaxRate(*entity.TaxRate) error
+	UpdateTaxRate(*entity.TaxRate) error
+	GetTaxRateByID(id int64) (*entity.TaxRate, error)
+	GetTaxRatesByClassID(taxClassID int64) ([]entity.TaxRate, error)
+	DeleteTaxRate(*entity.TaxRate) error
+	GetTaxRateForRegion(taxClassID uint, country string, region string) (*entity.TaxRate, error)
+}
\ No newline at end of file
@@ -0,0 +1,22 @@
+package warehouses
+
+import "pm/domain/entity"
+
+type WarehouseRepository interface {
+	Create(*entity.Warehouse) error
+	Update(*entity.Warehouse) error
+	GetWarehouseByID(id int64) (*entity.Warehouse, error)
+	GetWarehouseByCode(code string) (*entity.Warehouse, error)
+	GetAllWarehouses() ([]entity.Warehouse, error)
+	CountWarehouses() (int64, error)
+	LockWarehouses() error
+	DeleteWarehouse(*entity.Warehouse) error
+	GetStockLevels(warehouseID int64) ([]entity.WarehouseStock, error)
+	LockStockLevels(items ...entity.OrderItem) ([]entity.WarehouseStock, error)
+	MoveStock(warehouseID uint, item entity.OrderItem, delta int64) error
+	TakeOverStock(warehouseID uint) error
+	CreateTransfer(*entity.StockTransfer) error
+	CreateAllocations(...entity.OrderItemAllocation) error
+	GetAllocations(orderItemIDs ...uint) ([]entity.OrderItemAllocation, error)
+	ReduceAllocation(allocation *entity.OrderItemAllocation, quantity int) error
+}
\ No newline at end of file
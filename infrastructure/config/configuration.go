@@ -4,6 +4,7 @@ import (
 	"github.com/joho/godotenv"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -68,6 +69,38 @@ type MailConfig struct {
 	Password string
 }
 
+// PaymentConfig holds the one gateway payments go through and its secrets, payments are disabled while Gateway is empty
+type PaymentConfig struct {
+	Gateway    string
+	Currency   string
+	MockSecret string
+}
+
+type ShippingConfig struct {
+	Fee float64
+}
+
+type StockConfig struct {
+	ReconcileSchedule  string
+	AllocationStrategy string
+	LowStockSchedule   string
+}
+
+// CatalogConfig holds the prices the product list splits into price buckets
+type CatalogConfig struct {
+	PriceBuckets []float64
+}
+
+type InvoiceConfig struct {
+	Prefix        string
+	Storage       string
+	Dir           string
+	Bucket        string
+	SellerName    string
+	SellerAddress string
+	SellerTaxCode string
+}
+
 type AppConfig struct {
 	DatabaseConfig        DatabaseConfig
 	RedisConfig           RedisConfig
@@ -75,6 +108,11 @@ type AppConfig struct {
 	SupabaseStorageConfig SupabaseStorage
 	JwtConfig             JwtConfig
 	MailConfig            MailConfig
+	PaymentConfig         PaymentConfig
+	ShippingConfig        ShippingConfig
+	InvoiceConfig         InvoiceConfig
+	StockConfig           StockConfig
+	CatalogConfig         CatalogConfig
 }
 
 var Configs, _ = LoadConfig()
@@ -112,6 +150,31 @@ func LoadConfig() (*AppConfig, error) {
 			SecretKey:       GetEnv("JWT_SECRET_KEY", "https://drqbnazyxxjvqapzcmkz.supabase.co/storage/v1"),
 			TokenExpiration: GetEnvAsDuration("JWT_TOKEN_EXPIRATION", 1*time.Minute),
 		},
+		PaymentConfig: PaymentConfig{
+			Gateway:    GetEnv("PAYMENT_GATEWAY", ""),
+			Currency:   GetEnv("PAYMENT_CURRENCY", "VND"),
+			MockSecret: GetEnv("PAYMENT_MOCK_SECRET", ""),
+		},
+		ShippingConfig: ShippingConfig{
+			Fee: GetEnvAsFloat("SHIPPING_FEE", 0),
+		},
+		InvoiceConfig: InvoiceConfig{
+			Prefix:        GetEnv("INVOICE_PREFIX", "INV"),
+			Storage:       GetEnv("INVOICE_STORAGE", "local"),
+			Dir:           GetEnv("INVOICE_DIR", "invoices"),
+			Bucket:        GetEnv("INVOICE_BUCKET", "invoices"),
+			SellerName:    GetEnv("INVOICE_SELLER_NAME", "PM Store"),
+			SellerAddress: GetEnv("INVOICE_SELLER_ADDRESS", ""),
+			SellerTaxCode: GetEnv("INVOICE_SELLER_TAX_CODE", ""),
+		},
+		StockConfig: StockConfig{
+			ReconcileSchedule:  GetEnv("STOCK_RECONCILE_SCHEDULE", "@daily"),
+			AllocationStrategy: GetEnv("STOCK_ALLOCATION_STRATEGY", "priority"),
+			LowStockSchedule:   GetEnv("STOCK_LOW_STOCK_SCHEDULE", "@every 1h"),
+		},
+		CatalogConfig: CatalogConfig{
+			PriceBuckets: GetEnvAsFloats("CATALOG_PRICE_BUCKETS", []float64{100000, 200000, 500000, 1000000, 2000000, 5000000}),
+		},
 	}
 
 	//file, err := os.Open("./infrastructure/config/application.yml")
@@ -157,4 +220,31 @@ func GetEnvAsDuration(key string, fallback time.Duration) time.Duration {
 		return i
 	}
 	return fallback
+}
+
+func GetEnvAsFloat(key string, fallback float64) float64 {
+	if value, ok := os.LookupEnv(key); ok {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fallback
+		}
+		return f
+	}
+	return fallback
+}
+
+// GetEnvAsFloats reads a comma separated list of numbers, falling back when any of them is not a number
+func GetEnvAsFloats(key string, fallback []float64) []float64 {
+	if value, ok := os.LookupEnv(key); ok {
+		values := make([]float64, 0)
+		for _, v := range strings.Split(value, ",") {
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return fallback
+			}
+			values = append(values, f)
+		}
+		return values
+	}
+	return fallback
 }
\ No newline at end of file
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"pm/application"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/mapper"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+	"strconv"
+)
+
+type AddressHandler struct {
+	p       *base.Persistence
+	usecase application.AddressUsecase
+}
+
+func NewAddressHandler(p *base.Persistence) *AddressHandler {
+	usecase := application.NewAddressUsecase(p)
+	return &AddressHandler{p, usecase}
+}
+
+// HandleGetAddresses GetAddresses godoc
+//
+//	@Summary		Get the address book
+//	@Description	get every address of the authenticated user, the default address first
+//	@Tags			Address
+//	@Accept			json
+//	@Produce		json
+//	@Success		200			{object}	payload.AppResponse
+//	@Failure		400			{object}	payload.AppError
+//	@Failure		500			{object}	payload.AppError
+//	@Router			/addresses 	[get]
+func (h *AddressHandler) HandleGetAddresses(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleGetAddresses", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("GET_ADDRESSES_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	listAddresses, err := h.usecase.GetAddresses(c, requester)
+	if err != nil {
+		h.p.Logger.Error("GET_ADDRESSES_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.AddressesToAddressResponses(listAddresses), "")
+}
+
+// HandleGetAddressByID GetAddressByID godoc
+//
+//	@Summary		Get an address by id
+//	@Description	get an address of the address book of the authenticated user
+//	@Tags			Address
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path		int	true	"the id of the address"
+//	@Success		200				{object}	payload.AppResponse
+//	@Failure		400				{object}	payload.AppError
+//	@Failure		404				{object}	payload.AppError
+//	@Failure		500				{object}	payload.AppError
+//	@Router			/addresses/:id 	[get]
+func (h *AddressHandler) HandleGetAddressByID(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleGetAddressByID", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	addressId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if addressId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("GET_ADDRESS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("GET_ADDRESS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	address, err := h.usecase.GetAddressByID(c, requester, addressId)
+	if err != nil {
+		h.p.Logger.Error("GET_ADDRESS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.AddressToAddressResponse(address), "")
+}
+
+// HandleCreateAddress CreateAddress godoc
+//
+//	@Summary		Add an address
+//	@Description	add an address to the address book, the first address becomes the default one
+//	@Tags			Address
+//	@Accept			json
+//	@Produce		json
+//	@Param			CreateAddressRequest	body		payload.CreateAddressRequest	true	"the new address"
+//	@Success		200						{object}	payload.AppResponse
+//	@Failure		400						{object}	payload.AppError
+//	@Failure		500						{object}	payload.AppError
+//	@Router			/addresses 				[post]
+func (h *AddressHandler) HandleCreateAddress(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleCreateAddress", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	var createRequest payload.CreateAddressRequest
+	if err := c.ShouldBindJSON(&createRequest); err != nil {
+		h.p.Logger.Error("CREATE_ADDRESS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("CREATE_ADDRESS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	address, err := h.usecase.CreateAddress(c, requester, &createRequest)
+	if err != nil {
+		h.p.Logger.Error("CREATE_ADDRESS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.AddressToAddressResponse(address), "")
+}
+
+// HandleUpdateAddress UpdateAddress godoc
+//
+//	@Summary		Update an address
+//	@Description	update an address of the address book, orders already placed keep the address they were placed with
+//	@Tags			Address
+//	@Accept			json
+//	@Produce		json
+//	@Param			id						path		int								true	"the id of the address"
+//	@Param			UpdateAddressRequest	body		payload.UpdateAddressRequest	true	"the address"
+//	@Success		200						{object}	payload.AppResponse
+//	@Failure		400						{object}	payload.AppError
+//	@Failure		404						{object}	payload.AppError
+//	@Failure		500						{object}	payload.AppError
+//	@Router			/addresses/:id 			[put]
+func (h *AddressHandler) HandleUpdateAddress(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleUpdateAddress", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	addressId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if addressId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("UPDATE_ADDRESS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	var updateRequest payload.UpdateAddressRequest
+	if err := c.ShouldBindJSON(&updateRequest); err != nil {
+		h.p.Logger.Error("UPDATE_ADDRESS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("UPDATE_ADDRESS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	address, err := h.usecase.UpdateAddress(c, requester, addressId, &updateRequest)
+	if err != nil {
+		h.p.Logger.Error("UPDATE_ADDRESS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.AddressToAddressResponse(address), "")
+}
+
+// HandleDeleteAddress DeleteAddress godoc
+//
+//	@Summary		Delete an address
+//	@Description	remove an address from the address book, the oldest address left becomes the default one
+//	@Tags			Address
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path		int	true	"the id of the address"
+//	@Success		200				{object}	payload.AppResponse
+//	@Failure		400				{object}	payload.AppError
+//	@Failure		404				{object}	payload.AppError
+//	@Failure		500				{object}	payload.AppError
+//	@Router			/addresses/:id 	[delete]
+func (h *AddressHandler) HandleDeleteAddress(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleDeleteAddress", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	addressId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if addressId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("DELETE_ADDRESS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("DELETE_ADDRESS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	if err := h.usecase.DeleteAddress(c, requester, addressId); err != nil {
+		h.p.Logger.Error("DELETE_ADDRESS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, nil, "address deleted")
+}
+
+// HandleSetDefaultAddress SetDefaultAddress godoc
+//
+//	@Summary		Set the default address
+//	@Description	make an address the default one, orders without an address are shipped to it
+//	@Tags			Address
+//	@Accept			json
+//	@Produce		json
+//	@Param			id						path		int	true	"the id of the address"
+//	@Success		200						{object}	payload.AppResponse
+//	@Failure		400						{object}	payload.AppError
+//	@Failure		404						{object}	payload.AppError
+//	@Failure		500						{object}	payload.AppError
+//	@Router			/addresses/:id/default 	[post]
+func (h *AddressHandler) HandleSetDefaultAddress(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleSetDefaultAddress", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	addressId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if addressId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("SET_DEFAULT_ADDRESS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("SET_DEFAULT_ADDRESS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	address, err := h.usecase.SetDefaultAddress(c, requester, addressId)
+	if err != nil {
+		h.p.Logger.Error("SET_DEFAULT_ADDRESS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.AddressToAddressResponse(address), "")
+}
\ No newline at end of file
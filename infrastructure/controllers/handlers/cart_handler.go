@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"pm/application"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/mapper"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+	"strconv"
+)
+
+type CartHandler struct {
+	p       *base.Persistence
+	usecase application.CartUsecase
+}
+
+func NewCartHandler(p *base.Persistence) *CartHandler {
+	usecase := application.NewCartUsecase(p)
+	return &CartHandler{p, usecase}
+}
+
+// HandleGetCart GetCart godoc
+//
+//	@Summary		Get the cart
+//	@Description	get the cart of the authenticated user with the current price and stock of every product
+//	@Tags			Cart
+//	@Accept			json
+//	@Produce		json
+//	@Success		200		{object}	payload.AppResponse
+//	@Failure		400		{object}	payload.AppError
+//	@Failure		500		{object}	payload.AppError
+//	@Router			/cart 	[get]
+func (h *CartHandler) HandleGetCart(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleGetCart", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("GET_CART_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	cart, err := h.usecase.GetCart(c, requester)
+	if err != nil {
+		h.p.Logger.Error("GET_CART_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.CartToCartResponse(cart), "")
+}
+
+// HandleAddCartItem AddCartItem godoc
+//
+//	@Summary		Add a product to the cart
+//	@Description	add a product, or one of its variants, to the cart, the quantity is added to the line the cart already has for it. A product that has variants is added by one of them
+//	@Tags			Cart
+//	@Accept			json
+//	@Produce		json
+//	@Param			AddCartItemRequest	body		payload.AddCartItemRequest	true	"the product and the quantity to add"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		404					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/cart/items 		[post]
+func (h *CartHandler) HandleAddCartItem(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleAddCartItem", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	var addRequest payload.AddCartItemRequest
+	if err := c.ShouldBindJSON(&addRequest); err != nil {
+		h.p.Logger.Error("ADD_CART_ITEM_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("ADD_CART_ITEM_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	cart, err := h.usecase.AddCartItem(c, requester, &addRequest)
+	if err != nil {
+		h.p.Logger.Error("ADD_CART_ITEM_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.CartToCartResponse(cart), "")
+}
+
+// HandleUpdateCartItem UpdateCartItem godoc
+//
+//	@Summary		Update a line of the cart
+//	@Description	replace the quantity of a product, or of one of its variants, in the cart
+//	@Tags			Cart
+//	@Accept			json
+//	@Produce		json
+//	@Param			productId				path		int								true	"the id of the product"
+//	@Param			variantId				query		int								false	"the id of the variant"
+//	@Param			UpdateCartItemRequest	body		payload.UpdateCartItemRequest	true	"the new quantity"
+//	@Success		200						{object}	payload.AppResponse
+//	@Failure		400						{object}	payload.AppError
+//	@Failure		404						{object}	payload.AppError
+//	@Failure		500						{object}	payload.AppError
+//	@Router			/cart/items/:productId 	[put]
+func (h *CartHandler) HandleUpdateCartItem(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleUpdateCartItem", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	productId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("productId")), 10, 64)
+	if productId == 0 {
+		err := errors.New("param [productId] is required")
+		h.p.Logger.Error("UPDATE_CART_ITEM_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+	variantID, err := variantIDFromQuery(c)
+	if err != nil {
+		h.p.Logger.Error("UPDATE_CART_ITEM_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	var updateRequest payload.UpdateCartItemRequest
+	if err := c.ShouldBindJSON(&updateRequest); err != nil {
+		h.p.Logger.Error("UPDATE_CART_ITEM_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("UPDATE_CART_ITEM_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	cart, err := h.usecase.UpdateCartItem(c, requester, productId, variantID, &updateRequest)
+	if err != nil {
+		h.p.Logger.Error("UPDATE_CART_ITEM_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.CartToCartResponse(cart), "")
+}
+
+// HandleRemoveCartItem RemoveCartItem godoc
+//
+//	@Summary		Remove a line of the cart
+//	@Description	remove a product, or one of its variants, from the cart
+//	@Tags			Cart
+//	@Accept			json
+//	@Produce		json
+//	@Param			productId				path		int	true	"the id of the product"
+//	@Param			variantId				query		int	false	"the id of the variant"
+//	@Success		200						{object}	payload.AppResponse
+//	@Failure		400						{object}	payload.AppError
+//	@Failure		500						{object}	payload.AppError
+//	@Router			/cart/items/:productId 	[delete]
+func (h *CartHandler) HandleRemoveCartItem(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleRemoveCartItem", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	productId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("productId")), 10, 64)
+	if productId == 0 {
+		err := errors.New("param [productId] is required")
+		h.p.Logger.Error("REMOVE_CART_ITEM_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+	variantID, err := variantIDFromQuery(c)
+	if err != nil {
+		h.p.Logger.Error("REMOVE_CART_ITEM_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("REMOVE_CART_ITEM_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	cart, err := h.usecase.RemoveCartItem(c, requester, productId, variantID)
+	if err != nil {
+		h.p.Logger.Error("REMOVE_CART_ITEM_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.CartToCartResponse(cart), "")
+}
+
+// HandleClearCart ClearCart godoc
+//
+//	@Summary		Clear the cart
+//	@Description	remove every line of the cart
+//	@Tags			Cart
+//	@Accept			json
+//	@Produce		json
+//	@Success		200		{object}	payload.AppResponse
+//	@Failure		400		{object}	payload.AppError
+//	@Failure		500		{object}	payload.AppError
+//	@Router			/cart 	[delete]
+func (h *CartHandler) HandleClearCart(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleClearCart", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("CLEAR_CART_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	if err := h.usecase.ClearCart(c, requester); err != nil {
+		h.p.Logger.Error("CLEAR_CART_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, nil, "cart cleared")
+}
+
+// HandleCheckout Checkout godoc
+//
+//	@Summary		Check out the cart
+//	@Description	place an order with every line of the cart, the cart is emptied once the order is placed
+//	@Tags			Cart
+//	@Accept			json
+//	@Produce		json
+//	@Param			CheckoutRequest		body		payload.CheckoutRequest	false	"the address to ship to, the default address when empty"
+//	@Param			Idempotency-Key		header		string					false	"retries with the same key replay the first response"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		404					{object}	payload.AppError
+//	@Failure		409					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/cart/checkout 		[post]
+func (h *CartHandler) HandleCheckout(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleCheckout", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	var checkoutRequest payload.CheckoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&checkoutRequest); err != nil {
+			h.p.Logger.Error("CHECKOUT_FAILED", map[string]interface{}{"message": err.Error()})
+			c.Error(payload.ErrInvalidRequest(err))
+			return
+		}
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("CHECKOUT_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	order, err := h.usecase.Checkout(c, requester, &checkoutRequest)
+	if err != nil {
+		h.p.Logger.Error("CHECKOUT_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	orderResponse := mapper.OrderToOrderResponse(order)
+	h.p.Logger.Info("CHECKOUT_SUCCESSFULLY", map[string]interface{}{"order_response": orderResponse})
+	utils.HttpSuccessResponse(c, orderResponse, "")
+}
+
+// variantIDFromQuery reads the optional variantId query, a line of the cart is for a product or for one of its variants
+func variantIDFromQuery(c *gin.Context) (*uint, error) {
+	v := c.Query("variantId")
+	if v == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(v, 10, 64)
+	if err != nil || id == 0 {
+		return nil, payload.ErrInvalidRequest(errors.New("query [variantId] must be a positive number"))
+	}
+	variantID := uint(id)
+	return &variantID, nil
+}
\ No newline at end of file
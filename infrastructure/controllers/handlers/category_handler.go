@@ -65,6 +65,8 @@ func (h CategoryHandler) HandleCreateCategory(c *gin.Context) {
 //	@Produce		json
 //	@Param			limit		query		int						false	"the limit perpage"
 //	@Param			page		query		int						false	"the page nummber"
+//	@Param			sort		query		string						false	"the fields to sort by separated by commas, descending when starting with -, like -createdAt,name"
+//	@Param			cursor		query		string						false	"the nextCursor or prevCursor of a previous page, the page and the sort are then ignored"
 //	@Param			filter		query		entity.CategoryFilter	false	"filtering the data"
 //	@Success		200			{object}	payload.AppResponse
 //	@Failure		400			{object}	payload.AppError
@@ -88,6 +90,16 @@ func (h CategoryHandler) HandleGetAllCategories(c *gin.Context) {
 		h.p.Logger.Error("GET_ALL_CATEGORIES_FAILED", map[string]interface{}{"message": err.Error()})
 		return
 	}
+	if err := pagination.ValidateSort(entity.CategorySortColumns); err != nil {
+		c.Error(payload.ErrInvalidSort(err))
+		h.p.Logger.Error("GET_ALL_CATEGORIES_FAILED", map[string]interface{}{"message": err.Error()})
+		return
+	}
+	if err := pagination.ValidateCursor(entity.CategorySortColumns); err != nil {
+		c.Error(payload.ErrInvalidCursor(err))
+		h.p.Logger.Error("GET_ALL_CATEGORIES_FAILED", map[string]interface{}{"message": err.Error()})
+		return
+	}
 
 	categories, err := h.categoryUsecase.GetAllCategories(c, &categoryFilter, &pagination)
 	if err != nil {
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"pm/application"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/implementations/invoices"
+	"pm/infrastructure/mapper"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+	"strconv"
+)
+
+type InvoiceHandler struct {
+	p       *base.Persistence
+	usecase application.InvoiceUsecase
+}
+
+func NewInvoiceHandler(p *base.Persistence) *InvoiceHandler {
+	usecase := application.NewInvoiceUsecase(p)
+	return &InvoiceHandler{p, usecase}
+}
+
+// HandleDownloadInvoice DownloadInvoice godoc
+//
+//	@Summary		Download the invoice of an order
+//	@Description	download the invoice issued when the order was paid
+//	@Tags			Invoice
+//	@Produce		html
+//	@Param			id					path		int	true	"the id of the order"
+//	@Success		200					{file}		file
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		404					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/orders/:id/invoice [get]
+func (h *InvoiceHandler) HandleDownloadInvoice(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleDownloadInvoice", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	orderId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if orderId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("DOWNLOAD_INVOICE_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("DOWNLOAD_INVOICE_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	invoice, content, err := h.usecase.GetInvoiceDocument(c, requester, orderId)
+	if err != nil {
+		h.p.Logger.Error("DOWNLOAD_INVOICE_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", invoice.FileName()))
+	c.Data(http.StatusOK, invoices.ContentType, content)
+}
+
+// HandleEmailInvoice EmailInvoice godoc
+//
+//	@Summary		Email the invoice of an order
+//	@Description	send the invoice of the order to its owner again
+//	@Tags			Invoice
+//	@Accept			json
+//	@Produce		json
+//	@Param			id							path		int	true	"the id of the order"
+//	@Success		200							{object}	payload.AppResponse
+//	@Failure		400							{object}	payload.AppError
+//	@Failure		404							{object}	payload.AppError
+//	@Failure		500							{object}	payload.AppError
+//	@Router			/orders/:id/invoice/email 	[post]
+func (h *InvoiceHandler) HandleEmailInvoice(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleEmailInvoice", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	orderId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if orderId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("EMAIL_INVOICE_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("EMAIL_INVOICE_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	invoice, err := h.usecase.EmailInvoice(c, requester, orderId)
+	if err != nil {
+		h.p.Logger.Error("EMAIL_INVOICE_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.InvoiceToInvoiceResponse(invoice), "invoice sent")
+}
\ No newline at end of file
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"pm/application"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/mapper"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+	"strconv"
+)
+
+type NotificationHandler struct {
+	p       *base.Persistence
+	usecase application.NotificationUsecase
+}
+
+func NewNotificationHandler(p *base.Persistence) *NotificationHandler {
+	usecase := application.NewNotificationUsecase(p)
+	return &NotificationHandler{p, usecase}
+}
+
+// HandleGetAllNotifications GetAllNotifications godoc
+//
+//	@Summary		Get all notifications
+//	@Description	get the notifications raised for the admins, like low stock digests, newest first
+//	@Tags			Notification
+//	@Accept			json
+//	@Produce		json
+//	@Param			unread				query		bool	false	"only the notifications nobody has read"
+//	@Param			limit				query		int		false	"the limit perpage"
+//	@Param			page				query		int		false	"the page nummber"
+//	@Param			sort				query		string		false	"the fields to sort by separated by commas, descending when starting with -, like -createdAt,name"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/notifications 		[get]
+func (h *NotificationHandler) HandleGetAllNotifications(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleGetAllNotifications", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	var filter entity.NotificationFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		h.p.Logger.Error("GET_ALL_NOTIFICATIONS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+	var pagination entity.Pagination
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		h.p.Logger.Error("GET_ALL_NOTIFICATIONS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+	if err := pagination.ValidateSort(entity.NotificationSortColumns); err != nil {
+		h.p.Logger.Error("GET_ALL_NOTIFICATIONS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidSort(err))
+		return
+	}
+	if err := pagination.ValidateNoCursor(); err != nil {
+		h.p.Logger.Error("GET_ALL_NOTIFICATIONS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidCursor(err))
+		return
+	}
+
+	listNotifications, err := h.usecase.GetAllNotifications(c, &filter, &pagination)
+	if err != nil {
+		h.p.Logger.Error("GET_ALL_NOTIFICATIONS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.NotificationsToListNotificationResponses(listNotifications, &pagination), "")
+}
+
+// HandleMarkNotificationRead MarkNotificationRead godoc
+//
+//	@Summary		Mark a notification read
+//	@Description	mark a notification read, it keeps the time it was first read
+//	@Tags			Notification
+//	@Accept			json
+//	@Produce		json
+//	@Param			id							path		int	true	"the id of the notification"
+//	@Success		200							{object}	payload.AppResponse
+//	@Failure		400							{object}	payload.AppError
+//	@Failure		404							{object}	payload.AppError
+//	@Failure		500							{object}	payload.AppError
+//	@Router			/notifications/:id/read 	[put]
+func (h *NotificationHandler) HandleMarkNotificationRead(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleMarkNotificationRead", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	notificationId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if notificationId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("MARK_NOTIFICATION_READ_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	notification, err := h.usecase.MarkNotificationRead(c, notificationId)
+	if err != nil {
+		h.p.Logger.Error("MARK_NOTIFICATION_READ_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.NotificationToNotificationResponse(notification), "")
+}
\ No newline at end of file
@@ -4,9 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"math"
 	"net/http"
 	"pm/application"
+	"pm/domain/entity"
 	"pm/infrastructure/controllers/payload"
 	"pm/infrastructure/mapper"
 	"pm/infrastructure/persistences/base"
@@ -16,6 +16,7 @@ import (
 
 const (
 	userContextKey = "user"
+	roleContextKey = "role"
 )
 
 type OrderHandler struct {
@@ -36,8 +37,11 @@ func NewOrderHandler(p *base.Persistence) *OrderHandler {
 //	@Accept			json
 //	@Produce		json
 //	@Param			CreateOrderRequest	body		payload.CreateOrderRequest	true	"create a new order"
+//	@Param			Idempotency-Key		header		string						false	"retries with the same key replay the first response"
 //	@Success		200				{object}	payload.AppResponse
 //	@Failure		400				{object}	payload.AppError
+//	@Failure		409				{object}	payload.AppError
+//	@Failure		422				{object}	payload.AppError
 //	@Failure		500				{object}	payload.AppError
 //	@Router			/orders 				[post]
 func (h *OrderHandler) HandleCreateOrder(c *gin.Context) {
@@ -51,53 +55,136 @@ func (h *OrderHandler) HandleCreateOrder(c *gin.Context) {
 		return
 	}
 
-	//userId := c.Value(userContextKey)
-	//if exists == false {
-	//	c.Error(payload.ErrInvalidRequest(errors.New("unauthorized because cannot find user from context")))
-	//}
-	idValue := c.Value(userContextKey)
-	var id int64
-	var err error
-
-	switch v := idValue.(type) {
-	case int64:
-		id = v
-	case int:
-		id = int64(v)
-	case int32:
-		id = int64(v)
-	case float64:
-		id = int64(v)
-	case string:
-		id, err = strconv.ParseInt(v, 10, 64)
-		if err != nil {
-			h.p.Logger.Error("CREATE_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
-			c.Error(payload.ErrInternal(fmt.Errorf("error cast from any to int64")))
-			return
-		}
-	default:
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
 		h.p.Logger.Error("CREATE_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
-		c.Error(payload.ErrInternal(fmt.Errorf("error cast from any to int64")))
+		c.Error(err)
 		return
 	}
 
-	requestPayload.UserID = uint(id)
-	requestPayload.Status = "WAITING_FOR_PAYMENT"
-	if err := h.usecase.CreateOrder(c, &requestPayload); err != nil {
+	order, err := h.usecase.CreateOrder(c, requester, &requestPayload)
+	if err != nil {
 		h.p.Logger.Error("CREATE_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
 		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, payload.SuccessResponse(nil, ""))
-	h.p.Logger.Info("CREATE_ORDER_SUCCESSFULLY", map[string]interface{}{})
+
+	orderResponse := mapper.OrderToOrderResponse(order)
+	h.p.Logger.Info("CREATE_ORDER_SUCCESSFULLY", map[string]interface{}{"order_response": orderResponse})
+	c.JSON(http.StatusOK, payload.SuccessResponse(orderResponse, ""))
 }
 
+// HandleUpdateOrderByID UpdateOrderByID godoc
+//
+//	@Summary		Update order by id
+//	@Description	move the order to another status, cancelling it restores the stock of its items. Orders are paid by capturing their payment
+//	@Tags			Order
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		int							true	"the id of the order to update"
+//	@Param			UpdateOrderRequest	body		payload.UpdateOrderRequest	true	"update order with update order request"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		404					{object}	payload.AppError
+//	@Failure		409					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/orders/:id 				[put]
 func (h *OrderHandler) HandleUpdateOrderByID(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleUpdateOrderByID", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	orderId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if orderId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("UPDATE_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	var updatePayload payload.UpdateOrderRequest
+	if err := c.ShouldBindJSON(&updatePayload); err != nil {
+		h.p.Logger.Error("UPDATE_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("UPDATE_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
 
+	order, err := h.usecase.UpdateOrderByID(c, orderId, requester, updatePayload)
+	if err != nil {
+		h.p.Logger.Error("UPDATE_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	orderResponse := mapper.OrderToOrderResponse(order)
+	h.p.Logger.Info("UPDATE_ORDER_SUCCESSFULLY", map[string]interface{}{"order_response": orderResponse})
+	utils.HttpSuccessResponse(c, orderResponse, "")
 }
 
+// HandleGetAllOrders GetAllOrders godoc
+//
+//	@Summary		Get all orders
+//	@Description	Get all orders which are not deleted, filtered by status, user, date range, total range and contained product
+//	@Tags			Order
+//	@Accept			json
+//	@Produce		json
+//	@Param			limit		query		int					false	"the limit perpage"
+//	@Param			page		query		int					false	"the page nummber"
+//	@Param			sort		query		string					false	"the fields to sort by separated by commas, descending when starting with -, like -createdAt,name"
+//	@Param			cursor		query		string					false	"the nextCursor or prevCursor of a previous page, the page and the sort are then ignored"
+//	@Param			filter		query		entity.OrderFilter	false	"filtering the data"
+//	@Success		200			{object}	payload.AppResponse
+//	@Failure		400			{object}	payload.AppError
+//	@Failure		500			{object}	payload.AppError
+//	@Router			/orders 				[get]
 func (h *OrderHandler) HandleGetAllOrders(c *gin.Context) {
+	var orderFilter entity.OrderFilter
+	var pagination entity.Pagination
+
+	if err := c.ShouldBindQuery(&orderFilter); err != nil {
+		h.p.Logger.Error("GET_ALL_ORDERS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		h.p.Logger.Error("GET_ALL_ORDERS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+	if err := pagination.ValidateSort(entity.OrderSortColumns); err != nil {
+		h.p.Logger.Error("GET_ALL_ORDERS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidSort(err))
+		return
+	}
+	if err := pagination.ValidateCursor(entity.OrderSortColumns); err != nil {
+		h.p.Logger.Error("GET_ALL_ORDERS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidCursor(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("GET_ALL_ORDERS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	listOrders, err := h.usecase.GetAllOrders(c, requester, &orderFilter, &pagination)
+	if err != nil {
+		h.p.Logger.Error("GET_ALL_ORDERS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
 
+	listOrderResponses := mapper.OrdersToListOrderResponses(listOrders, &pagination)
+	utils.HttpSuccessResponse(c, listOrderResponses, "")
 }
 
 // HandleGetOrderByID GetOrderByID godoc
@@ -119,25 +206,319 @@ func (h *OrderHandler) HandleGetOrderByID(c *gin.Context) {
 		c.Error(payload.ErrParamRequired(errors.New("param [id] is required")))
 		return
 	}
-	order, err := h.usecase.GetOrderByID(c, orderId)
+	requester, err := getRequesterFromContext(c)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	orderResponse := mapper.OrderToOrderResponse(order)
-	var totalPrice float64 = 0
-	for _, v := range order.OrderItems {
-		totalPrice += v.Price * float64(v.Quantity)
+	order, err := h.usecase.GetOrderByID(c, requester, orderId)
+	if err != nil {
+		c.Error(err)
+		return
 	}
-	orderResponse.Total = math.Round(totalPrice*100) / 100
+
+	orderResponse := mapper.OrderToOrderResponse(order)
 	utils.HttpSuccessResponse(c, orderResponse, "")
 }
 
+// HandleGetOrderHistory GetOrderHistory godoc
+//
+//	@Summary		Get the history of an order
+//	@Description	get every change made to an order, oldest first, with who made it and what changed
+//	@Tags			Order
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path		int	true	"the id of the order"
+//	@Success		200				{object}	payload.AppResponse
+//	@Failure		400				{object}	payload.AppError
+//	@Failure		404				{object}	payload.AppError
+//	@Failure		500				{object}	payload.AppError
+//	@Router			/orders/:id/history 		[get]
+func (h *OrderHandler) HandleGetOrderHistory(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleGetOrderHistory", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	orderId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if orderId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("GET_ORDER_HISTORY_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("GET_ORDER_HISTORY_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	events, err := h.usecase.GetOrderHistory(c, orderId, requester)
+	if err != nil {
+		h.p.Logger.Error("GET_ORDER_HISTORY_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.OrderEventsToOrderEventResponses(events), "")
+}
+
+// HandleDeleteOrderByID DeleteOrderByID godoc
+//
+//	@Summary		Delete order by id
+//	@Description	delete an order, an order which is not cancelled yet is cancelled and restocked first
+//	@Tags			Order
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path		int	true	"the id of order to delete"
+//	@Success		200				{object}	payload.AppResponse
+//	@Failure		400				{object}	payload.AppError
+//	@Failure		404				{object}	payload.AppError
+//	@Failure		409				{object}	payload.AppError
+//	@Failure		500				{object}	payload.AppError
+//	@Router			/orders/:id 				[delete]
 func (h *OrderHandler) HandleDeleteOrderByID(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleDeleteOrderByID", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	orderId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if orderId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("DELETE_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
 
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("DELETE_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	if err := h.usecase.DeleteOrderByID(c, orderId, requester); err != nil {
+		h.p.Logger.Error("DELETE_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	h.p.Logger.Info("DELETE_ORDER_SUCCESSFULLY", map[string]interface{}{"id": orderId})
+	c.JSON(http.StatusOK, payload.SuccessResponse(nil, ""))
 }
 
 func (h *OrderHandler) HandleGetOrdersByUserID(c *gin.Context) {
 
+}
+
+// HandleConfirmOrder ConfirmOrder godoc
+//
+//	@Summary		Confirm an order
+//	@Description	move a pending order to confirmed
+//	@Tags			Order
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path		int	true	"the id of the order"
+//	@Success		200				{object}	payload.AppResponse
+//	@Failure		400				{object}	payload.AppError
+//	@Failure		404				{object}	payload.AppError
+//	@Failure		409				{object}	payload.AppError
+//	@Failure		500				{object}	payload.AppError
+//	@Router			/orders/:id/confirm 				[post]
+func (h *OrderHandler) HandleConfirmOrder(c *gin.Context) {
+	h.handleOrderTransition(c, entity.OrderStatusConfirmed)
+}
+
+// HandleShipOrder ShipOrder godoc
+//
+//	@Summary		Ship an order
+//	@Description	move a paid order to shipped with its shipping method and tracking number
+//	@Tags			Order
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		int							true	"the id of the order"
+//	@Param			ShipOrderRequest	body		payload.ShipOrderRequest	false	"how the order is shipped"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		404					{object}	payload.AppError
+//	@Failure		409					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/orders/:id/ship 				[post]
+func (h *OrderHandler) HandleShipOrder(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleShipOrder", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	orderId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if orderId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("SHIP_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	var shipRequest payload.ShipOrderRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&shipRequest); err != nil {
+			h.p.Logger.Error("SHIP_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+			c.Error(payload.ErrInvalidRequest(err))
+			return
+		}
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("SHIP_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	order, err := h.usecase.ShipOrder(c, orderId, requester, &shipRequest)
+	if err != nil {
+		h.p.Logger.Error("SHIP_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	orderResponse := mapper.OrderToOrderResponse(order)
+	h.p.Logger.Info("SHIP_ORDER_SUCCESSFULLY", map[string]interface{}{"order_response": orderResponse})
+	utils.HttpSuccessResponse(c, orderResponse, "")
+}
+
+// HandleDeliverOrder DeliverOrder godoc
+//
+//	@Summary		Deliver an order
+//	@Description	move a shipped order to delivered
+//	@Tags			Order
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path		int	true	"the id of the order"
+//	@Success		200				{object}	payload.AppResponse
+//	@Failure		400				{object}	payload.AppError
+//	@Failure		404				{object}	payload.AppError
+//	@Failure		409				{object}	payload.AppError
+//	@Failure		500				{object}	payload.AppError
+//	@Router			/orders/:id/deliver 				[post]
+func (h *OrderHandler) HandleDeliverOrder(c *gin.Context) {
+	h.handleOrderTransition(c, entity.OrderStatusDelivered)
+}
+
+// HandleCancelOrder CancelOrder godoc
+//
+//	@Summary		Cancel an order
+//	@Description	cancel a pending or confirmed order and give the stock of its items back
+//	@Tags			Order
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		int							true	"the id of the order"
+//	@Param			CancelOrderRequest	body		payload.CancelOrderRequest	false	"the reason of the cancellation"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		404					{object}	payload.AppError
+//	@Failure		409					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/orders/:id/cancel 				[post]
+func (h *OrderHandler) HandleCancelOrder(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleCancelOrder", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	orderId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if orderId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("CANCEL_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	var cancelRequest payload.CancelOrderRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&cancelRequest); err != nil {
+			h.p.Logger.Error("CANCEL_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+			c.Error(payload.ErrInvalidRequest(err))
+			return
+		}
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("CANCEL_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	order, err := h.usecase.CancelOrder(c, orderId, requester, cancelRequest.Reason)
+	if err != nil {
+		h.p.Logger.Error("CANCEL_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	orderResponse := mapper.OrderToOrderResponse(order)
+	h.p.Logger.Info("CANCEL_ORDER_SUCCESSFULLY", map[string]interface{}{"order_response": orderResponse})
+	utils.HttpSuccessResponse(c, orderResponse, "")
+}
+
+func (h *OrderHandler) handleOrderTransition(c *gin.Context, status string) {
+	span := h.p.Logger.Start(c, "handlers/HandleOrderTransition", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	orderId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if orderId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("UPDATE_ORDER_STATUS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("UPDATE_ORDER_STATUS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	order, err := h.usecase.UpdateOrderStatus(c, orderId, requester, status)
+	if err != nil {
+		h.p.Logger.Error("UPDATE_ORDER_STATUS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	orderResponse := mapper.OrderToOrderResponse(order)
+	h.p.Logger.Info("UPDATE_ORDER_STATUS_SUCCESSFULLY", map[string]interface{}{"order_response": orderResponse})
+	utils.HttpSuccessResponse(c, orderResponse, "")
+}
+
+// getUserIDFromContext reads the id of the authenticated user that AuthMiddleware put into the context
+func getUserIDFromContext(c *gin.Context) (int64, error) {
+	switch v := c.Value(userContextKey).(type) {
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case float64:
+		return int64(v), nil
+	case string:
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, payload.ErrInternal(fmt.Errorf("error cast from any to int64"))
+		}
+		return id, nil
+	default:
+		return 0, payload.ErrInternal(fmt.Errorf("error cast from any to int64"))
+	}
+}
+
+// getRequesterFromContext builds the requester from the user id and role the auth middleware put into the context
+func getRequesterFromContext(c *gin.Context) (entity.Requester, error) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		return entity.Requester{}, err
+	}
+	roleID, ok := c.Value(roleContextKey).(int64)
+	if !ok {
+		return entity.Requester{}, payload.ErrInternal(fmt.Errorf("error cast role from any to int64"))
+	}
+	return entity.Requester{UserID: userID, RoleID: roleID}, nil
 }
\ No newline at end of file
@@ -5,6 +5,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"pm/application"
+	"pm/domain/entity"
 	"pm/infrastructure/controllers/payload"
 	"pm/infrastructure/persistences/base"
 	"strconv"
@@ -27,9 +28,12 @@ func NewOrderItemHandler(p *base.Persistence) *OrderItemHandler {
 //	@Tags			OrderItem
 //	@Accept			json
 //	@Produce		json
-//	@Param			orderItems	body		[]entity.OrderItem	true	"Order items to create"
+//	@Param			orderItems		body		[]entity.OrderItem	true	"Order items to create"
+//	@Param			Idempotency-Key	header		string				false	"retries with the same key replay the first response"
 //	@Success		200			{object}	payload.AppResponse
 //	@Failure		400			{object}	payload.AppError
+//	@Failure		409			{object}	payload.AppError
+//	@Failure		422			{object}	payload.AppError
 //	@Failure		500			{object}	payload.AppError
 //	@Router			/order-items	[post]
 func (oi *OrderItemHandler) HandleCreateNewOrderItem(c *gin.Context) {
@@ -43,7 +47,14 @@ func (oi *OrderItemHandler) HandleCreateNewOrderItem(c *gin.Context) {
 		return
 	}
 
-	if err := oi.orderItemUsecase.CreateNewOrderItem(c, orderItemRequest.OrderItems); err != nil {
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		oi.p.Logger.Error("CREATING_ORDER_ITEM: ERROR", map[string]interface{}{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	if err := oi.orderItemUsecase.CreateNewOrderItem(c, requester, orderItemRequest.OrderItems); err != nil {
 		oi.p.Logger.Error("CREATING_ORDER_ITEM: ERROR", map[string]interface{}{"error": err.Error()})
 		c.Error(err)
 		return
@@ -84,7 +95,14 @@ func (oi *OrderItemHandler) HandleGetOrderItemByID(c *gin.Context) {
 		return
 	}
 
-	orderItem, err := oi.orderItemUsecase.GetOrderItemByID(c, id)
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		oi.p.Logger.Error("GET_ORDER_ITEM: ERROR", map[string]interface{}{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	orderItem, err := oi.orderItemUsecase.GetOrderItemByID(c, requester, id)
 	if err != nil {
 		oi.p.Logger.Error("GET_ORDER_ITEM: ERROR", map[string]interface{}{"error": err.Error()})
 		c.Error(err)
@@ -133,7 +151,14 @@ func (oi *OrderItemHandler) HandleUpdateOrderItemByID(c *gin.Context) {
 		c.Error(payload.ErrInvalidRequest(err))
 		return
 	}
-	orderItemUpdated, err := oi.orderItemUsecase.UpdateOrderItem(c, orderItemRequest.OrderItems)
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		oi.p.Logger.Error("UPDATE_ORDER_ITEM: ERROR", map[string]interface{}{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	orderItemUpdated, err := oi.orderItemUsecase.UpdateOrderItem(c, requester, orderItemRequest.OrderItems)
 	if err != nil {
 		oi.p.Logger.Error("UPDATE_ORDER_ITEM: ERROR", map[string]interface{}{"error": err.Error()})
 		c.Error(err)
@@ -175,13 +200,19 @@ func (oi *OrderItemHandler) HandleDeleteOrderItemByID(c *gin.Context) {
 		return
 	}
 
-	err := oi.orderItemUsecase.DeleteOrderItemByID(c, id)
+	requester, err := getRequesterFromContext(c)
 	if err != nil {
 		oi.p.Logger.Error("DELETE_ORDER_ITEM: ERROR", map[string]interface{}{"error": err.Error()})
 		c.Error(err)
 		return
 	}
 
+	if err := oi.orderItemUsecase.DeleteOrderItemByID(c, requester, id); err != nil {
+		oi.p.Logger.Error("DELETE_ORDER_ITEM: ERROR", map[string]interface{}{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
 	oi.p.Logger.Info("DELETE_ORDER_ITEM: SUCCESSFULLY", map[string]interface{}{"id_order_item_deleted": id})
 	c.JSON(http.StatusOK, payload.SuccessResponse(nil, ""))
 }
@@ -189,19 +220,40 @@ func (oi *OrderItemHandler) HandleDeleteOrderItemByID(c *gin.Context) {
 // HandleGetAllOrderItems godoc
 //
 //	@Summary		Get all order items
-//	@Description	Get a list of all order items
+//	@Description	Get a list of all order items, by page number or from the cursor of a previous page
 //	@Tags			OrderItem
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	payload.AppResponse
-//	@Failure		400	{object}	payload.AppError
-//	@Failure		500	{object}	payload.AppError
+//	@Param			limit			query		int		false	"the limit perpage"
+//	@Param			page			query		int		false	"the page nummber"
+//	@Param			sort			query		string	false	"the fields to sort by separated by commas, descending when starting with -, like -createdAt,name"
+//	@Param			cursor			query		string	false	"the nextCursor or prevCursor of a previous page, the page and the sort are then ignored"
+//	@Success		200				{object}	payload.AppResponse
+//	@Failure		400				{object}	payload.AppError
+//	@Failure		500				{object}	payload.AppError
 //	@Router			/order-items	[get]
 func (oi *OrderItemHandler) HandleGetAllOrderItems(c *gin.Context) {
 	span := oi.p.Logger.Start(c, "handlers/HandleGetAllOrderItems", oi.p.Logger.SetContextWithSpanFunc())
 	defer span.End()
 
-	orderItems, err := oi.orderItemUsecase.GetAllOrderItems(c)
+	var pagination entity.Pagination
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		oi.p.Logger.Error("GET_ALL_ORDER_ITEMS: ERROR", map[string]interface{}{"error": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+	if err := pagination.ValidateSort(entity.OrderItemSortColumns); err != nil {
+		oi.p.Logger.Error("GET_ALL_ORDER_ITEMS: ERROR", map[string]interface{}{"error": err.Error()})
+		c.Error(payload.ErrInvalidSort(err))
+		return
+	}
+	if err := pagination.ValidateCursor(entity.OrderItemSortColumns); err != nil {
+		oi.p.Logger.Error("GET_ALL_ORDER_ITEMS: ERROR", map[string]interface{}{"error": err.Error()})
+		c.Error(payload.ErrInvalidCursor(err))
+		return
+	}
+
+	orderItems, err := oi.orderItemUsecase.GetAllOrderItems(c, &pagination)
 	if err != nil {
 		oi.p.Logger.Error("GET_ALL_ORDER_ITEMS: ERROR", map[string]interface{}{"error": err.Error()})
 		c.Error(err)
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"pm/application"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/mapper"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+	"strconv"
+)
+
+const (
+	paymentSignatureHeader = "X-Signature"
+)
+
+type PaymentHandler struct {
+	p       *base.Persistence
+	usecase application.PaymentUsecase
+}
+
+func NewPaymentHandler(p *base.Persistence) *PaymentHandler {
+	usecase := application.NewPaymentUsecase(p)
+	return &PaymentHandler{p, usecase}
+}
+
+// HandleInitiatePayment InitiatePayment godoc
+//
+//	@Summary		Initiate a payment
+//	@Description	open a payment for the grand total of a confirmed order on a payment gateway
+//	@Tags			Payment
+//	@Accept			json
+//	@Produce		json
+//	@Param			id						path		int								true	"the id of the order"
+//	@Param			InitiatePaymentRequest	body		payload.InitiatePaymentRequest	false	"the gateway to pay with, the default gateway when empty"
+//	@Param			Idempotency-Key			header		string							false	"retries with the same key replay the first response"
+//	@Success		200						{object}	payload.AppResponse
+//	@Failure		400						{object}	payload.AppError
+//	@Failure		404						{object}	payload.AppError
+//	@Failure		409						{object}	payload.AppError
+//	@Failure		500						{object}	payload.AppError
+//	@Router			/orders/:id/payments 	[post]
+func (h *PaymentHandler) HandleInitiatePayment(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleInitiatePayment", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	orderId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if orderId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("INITIATE_PAYMENT_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	var initiateRequest payload.InitiatePaymentRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&initiateRequest); err != nil {
+			h.p.Logger.Error("INITIATE_PAYMENT_FAILED", map[string]interface{}{"message": err.Error()})
+			c.Error(payload.ErrInvalidRequest(err))
+			return
+		}
+	}
+	if err := utils.ValidateReqPayload(initiateRequest); err != nil {
+		h.p.Logger.Error("INITIATE_PAYMENT_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("INITIATE_PAYMENT_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	payment, result, err := h.usecase.InitiatePayment(c, requester, orderId, initiateRequest.Provider)
+	if err != nil {
+		h.p.Logger.Error("INITIATE_PAYMENT_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	paymentResponse := mapper.PaymentToPaymentResponse(payment)
+	paymentResponse.RedirectURL = result.RedirectURL
+	h.p.Logger.Info("INITIATE_PAYMENT_SUCCESSFULLY", map[string]interface{}{"payment_response": paymentResponse})
+	utils.HttpSuccessResponse(c, paymentResponse, "")
+}
+
+// HandleCapturePayment CapturePayment godoc
+//
+//	@Summary		Capture a payment
+//	@Description	capture an authorized payment, the order moves to paid
+//	@Tags			Payment
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		int	true	"the id of the order"
+//	@Param			paymentId	path		int	true	"the id of the payment"
+//	@Success		200			{object}	payload.AppResponse
+//	@Failure		400			{object}	payload.AppError
+//	@Failure		404			{object}	payload.AppError
+//	@Failure		409			{object}	payload.AppError
+//	@Failure		500			{object}	payload.AppError
+//	@Router			/orders/:id/payments/:paymentId/capture 	[post]
+func (h *PaymentHandler) HandleCapturePayment(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleCapturePayment", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	orderId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	paymentId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("paymentId")), 10, 64)
+	if orderId == 0 || paymentId == 0 {
+		err := errors.New("params [id] and [paymentId] are required")
+		h.p.Logger.Error("CAPTURE_PAYMENT_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("CAPTURE_PAYMENT_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	payment, err := h.usecase.CapturePayment(c, requester, orderId, paymentId)
+	if err != nil {
+		h.p.Logger.Error("CAPTURE_PAYMENT_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	paymentResponse := mapper.PaymentToPaymentResponse(payment)
+	h.p.Logger.Info("CAPTURE_PAYMENT_SUCCESSFULLY", map[string]interface{}{"payment_response": paymentResponse})
+	utils.HttpSuccessResponse(c, paymentResponse, "")
+}
+
+// HandleGetPaymentsByOrderID GetPaymentsByOrderID godoc
+//
+//	@Summary		Get the payments of an order
+//	@Description	get every payment attempt of an order
+//	@Tags			Payment
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int	true	"the id of the order"
+//	@Success		200		{object}	payload.AppResponse
+//	@Failure		400		{object}	payload.AppError
+//	@Failure		404		{object}	payload.AppError
+//	@Failure		500		{object}	payload.AppError
+//	@Router			/orders/:id/payments 	[get]
+func (h *PaymentHandler) HandleGetPaymentsByOrderID(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleGetPaymentsByOrderID", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	orderId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if orderId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("GET_PAYMENTS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("GET_PAYMENTS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	listPayments, err := h.usecase.GetPaymentsByOrderID(c, requester, orderId)
+	if err != nil {
+		h.p.Logger.Error("GET_PAYMENTS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.PaymentsToPaymentResponses(listPayments), "")
+}
+
+// HandlePaymentCallback PaymentCallback godoc
+//
+//	@Summary		Payment gateway callback
+//	@Description	receive a signed notification of a payment gateway about one of its payments
+//	@Tags			Payment
+//	@Accept			json
+//	@Produce		json
+//	@Param			provider		path		string	true	"the name of the payment gateway"
+//	@Param			X-Signature		header		string	true	"the signature of the raw body"
+//	@Success		200				{object}	payload.AppResponse
+//	@Failure		400				{object}	payload.AppError
+//	@Failure		401				{object}	payload.AppError
+//	@Failure		404				{object}	payload.AppError
+//	@Failure		500				{object}	payload.AppError
+//	@Router			/payments/callback/:provider 	[post]
+func (h *PaymentHandler) HandlePaymentCallback(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandlePaymentCallback", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	// the signature covers the raw body, so it is read as it is instead of being bound
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		h.p.Logger.Error("PAYMENT_CALLBACK_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	payment, err := h.usecase.HandleCallback(c, removeSlashFromParam(c.Param("provider")), body, c.GetHeader(paymentSignatureHeader))
+	if err != nil {
+		h.p.Logger.Error("PAYMENT_CALLBACK_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	paymentResponse := mapper.PaymentToPaymentResponse(payment)
+	h.p.Logger.Info("PAYMENT_CALLBACK_SUCCESSFULLY", map[string]interface{}{"payment_response": paymentResponse})
+	c.JSON(http.StatusOK, payload.SuccessResponse(paymentResponse, ""))
+}
\ No newline at end of file
@@ -51,7 +51,14 @@ func (handler *ProductHandler) HandleCreateProduct(c *gin.Context) {
 		return
 	}
 
-	if err := handler.usecase.CreateProduct(c, &createProdReq); err != nil {
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		c.Error(err)
+		handler.p.Logger.Error("CREATE_PRODUCT: FAILED", map[string]interface{}{"message": err.Error()})
+		return
+	}
+
+	if err := handler.usecase.CreateProduct(c, requester, &createProdReq); err != nil {
 		c.Error(err)
 		handler.p.Logger.Error("CREATE_PRODUCT: FAILED", map[string]interface{}{"message": err.Error()})
 		return
@@ -64,18 +71,19 @@ func (handler *ProductHandler) HandleCreateProduct(c *gin.Context) {
 // HandleGetAllProducts GetAllProducts godoc
 //
 //	@Summary		Get all products
-//	@Description	Get all products which is not deleted
+//	@Description	Get all products which is not deleted, with how many products every category, price bucket, option value and the stock filter would leave
 //	@Tags			Product
 //	@Accept			json
 //	@Produce		json
 //	@Param			limit		query		int						false	"the limit perpage"
 //	@Param			page		query		int						false	"the page nummber"
-//	@Param			filter		query		entity.ProductFilter	false	"filtering the data"
+//	@Param			sort		query		string						false	"the fields to sort by separated by commas, descending when starting with -, like -createdAt,name"
+//	@Param			cursor		query		string						false	"the nextCursor or prevCursor of a previous page, the page and the sort are then ignored"
+//	@Param			filter		query		entity.ProductFilter	false	"filtering the data, categoryIds, priceBucket (from-to or from-) and option (name:value) can be repeated"
 //	@Success		200			{object}	payload.AppResponse
 //	@Failure		400			{object}	payload.AppError
 //	@Failure		500			{object}	payload.AppError
 //	@Router			/products 				[get]
-//	@Router			/products/search 				[get]
 func (handler *ProductHandler) HandleGetAllProducts(c *gin.Context) {
 	span := handler.p.Logger.Start(c, "handlers/HandleGetAllProducts", handler.p.Logger.SetContextWithSpanFunc())
 	defer span.End()
@@ -93,6 +101,16 @@ func (handler *ProductHandler) HandleGetAllProducts(c *gin.Context) {
 		handler.p.Logger.Error("GET_ALL_PRODUCTS_FAILED", map[string]interface{}{"message": err.Error()})
 		return
 	}
+	if err := pagination.ValidateSort(entity.ProductSortColumns); err != nil {
+		c.Error(payload.ErrInvalidSort(err))
+		handler.p.Logger.Error("GET_ALL_PRODUCTS_FAILED", map[string]interface{}{"message": err.Error()})
+		return
+	}
+	if err := pagination.ValidateCursor(entity.ProductSortColumns); err != nil {
+		c.Error(payload.ErrInvalidCursor(err))
+		handler.p.Logger.Error("GET_ALL_PRODUCTS_FAILED", map[string]interface{}{"message": err.Error()})
+		return
+	}
 
 	prods, err := handler.usecase.GetAllProducts(c, &filter, pagination)
 	if err != nil {
@@ -100,12 +118,104 @@ func (handler *ProductHandler) HandleGetAllProducts(c *gin.Context) {
 		handler.p.Logger.Error("GET_ALL_PRODUCTS_FAILED", map[string]interface{}{"message": err.Error()})
 		return
 	}
+	facets, err := handler.usecase.GetProductFacets(c, &filter)
+	if err != nil {
+		c.Error(err)
+		handler.p.Logger.Error("GET_ALL_PRODUCTS_FAILED", map[string]interface{}{"message": err.Error()})
+		return
+	}
 
 	listProdResponse := mapper.ProdsToListProdsResponse(prods, pagination)
+	listProdResponse.Facets = mapper.FacetsToProductFacetsResponse(facets)
 	handler.p.Logger.Info("GET_ALL_PRODUCTS_SUCCESSFULLY", map[string]interface{}{"list_products_response": listProdResponse})
 	c.JSON(http.StatusOK, payload.SuccessResponse(listProdResponse, ""))
 }
 
+// HandleSearchProducts SearchProducts godoc
+//
+//	@Summary		Search products
+//	@Description	search products by words in their name, description, category name and tags, whatever the case and the accents, the most relevant first
+//	@Tags			Product
+//	@Accept			json
+//	@Produce		json
+//	@Param			q					query		string	true	"the words to search"
+//	@Param			categoryId			query		int		false	"only the products of the category"
+//	@Param			limit				query		int		false	"the limit perpage"
+//	@Param			page				query		int		false	"the page nummber"
+//	@Param			sort				query		string		false	"the fields to sort by separated by commas, descending when starting with -, like -createdAt,name"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/products/search 	[get]
+func (handler *ProductHandler) HandleSearchProducts(c *gin.Context) {
+	span := handler.p.Logger.Start(c, "handlers/HandleSearchProducts", handler.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	var search entity.ProductSearch
+	if err := c.ShouldBindQuery(&search); err != nil {
+		c.Error(payload.ErrInvalidRequest(err))
+		handler.p.Logger.Error("SEARCH_PRODUCTS_FAILED", map[string]interface{}{"message": err.Error()})
+		return
+	}
+
+	pagination := entity.InitPaginate()
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		c.Error(payload.ErrInvalidRequest(err))
+		handler.p.Logger.Error("SEARCH_PRODUCTS_FAILED", map[string]interface{}{"message": err.Error()})
+		return
+	}
+	if err := pagination.ValidateSort(entity.ProductSortColumns); err != nil {
+		c.Error(payload.ErrInvalidSort(err))
+		handler.p.Logger.Error("SEARCH_PRODUCTS_FAILED", map[string]interface{}{"message": err.Error()})
+		return
+	}
+	prods, err := handler.usecase.SearchProducts(c, &search, pagination)
+	if err != nil {
+		c.Error(err)
+		handler.p.Logger.Error("SEARCH_PRODUCTS_FAILED", map[string]interface{}{"message": err.Error()})
+		return
+	}
+
+	listProdResponse := mapper.ProdsToListProdsResponse(prods, pagination)
+	handler.p.Logger.Info("SEARCH_PRODUCTS_SUCCESSFULLY", map[string]interface{}{"products": len(prods)})
+	c.JSON(http.StatusOK, payload.SuccessResponse(listProdResponse, ""))
+}
+
+// HandleSuggestProducts SuggestProducts godoc
+//
+//	@Summary		Suggest products
+//	@Description	suggest the products and categories whose name has a word starting like the search being typed, forgiving a typo or two
+//	@Tags			Product
+//	@Accept			json
+//	@Produce		json
+//	@Param			q					query		string	true	"the search typed so far"
+//	@Param			limit				query		int		false	"the number of suggestions, 10 by default and 50 at most"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/products/suggest 	[get]
+func (handler *ProductHandler) HandleSuggestProducts(c *gin.Context) {
+	span := handler.p.Logger.Start(c, "handlers/HandleSuggestProducts", handler.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	var query entity.SuggestionQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(payload.ErrInvalidRequest(err))
+		handler.p.Logger.Error("SUGGEST_PRODUCTS_FAILED", map[string]interface{}{"message": err.Error()})
+		return
+	}
+
+	list, err := handler.usecase.SuggestProducts(c, &query)
+	if err != nil {
+		c.Error(err)
+		handler.p.Logger.Error("SUGGEST_PRODUCTS_FAILED", map[string]interface{}{"message": err.Error()})
+		return
+	}
+
+	handler.p.Logger.Info("SUGGEST_PRODUCTS_SUCCESSFULLY", map[string]interface{}{"suggestions": len(list)})
+	c.JSON(http.StatusOK, payload.SuccessResponse(mapper.SuggestionsToSuggestionResponses(list), ""))
+}
+
 // HandleGetProductByID GetProductByID godoc
 //
 //	@Summary		Get product by id
@@ -210,7 +320,14 @@ func (handler *ProductHandler) HandleUpdateProductByID(c *gin.Context) {
 		return
 	}
 
-	prodUpdated, err := handler.usecase.UpdateProductByID(c, id, &updateProductReq)
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		c.Error(err)
+		handler.p.Logger.Error("UPDATE_PRODUCT_FAILED", map[string]interface{}{"message": err.Error()})
+		return
+	}
+
+	prodUpdated, err := handler.usecase.UpdateProductByID(c, id, requester, &updateProductReq)
 	if err != nil {
 		c.Error(err)
 		handler.p.Logger.Error("UPDATE_PRODUCT_FAILED", map[string]interface{}{"message": err.Error()})
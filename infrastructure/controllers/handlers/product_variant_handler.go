@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"pm/application"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/mapper"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+	"strconv"
+)
+
+type ProductVariantHandler struct {
+	p       *base.Persistence
+	usecase application.ProductVariantUsecase
+}
+
+func NewProductVariantHandler(p *base.Persistence) *ProductVariantHandler {
+	usecase := application.NewProductVariantUsecase(p)
+	return &ProductVariantHandler{p, usecase}
+}
+
+// HandleGetVariants GetVariants godoc
+//
+//	@Summary		Get the variants of a product
+//	@Description	get every variant of a product with its SKU, options, price and stock
+//	@Tags			Product
+//	@Accept			json
+//	@Produce		json
+//	@Param			id							path		int	true	"the id of the product"
+//	@Success		200							{object}	payload.AppResponse
+//	@Failure		400							{object}	payload.AppError
+//	@Failure		404							{object}	payload.AppError
+//	@Failure		500							{object}	payload.AppError
+//	@Router			/products/:id/variants 		[get]
+func (h *ProductVariantHandler) HandleGetVariants(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleGetVariants", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	productId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if productId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("GET_VARIANTS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	product, err := h.usecase.GetVariants(c, productId)
+	if err != nil {
+		h.p.Logger.Error("GET_VARIANTS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.VariantsToVariantResponses(product, product.Variants), "")
+}
+
+// HandleCreateVariant CreateVariant godoc
+//
+//	@Summary		Create a variant of a product
+//	@Description	add a variant with its own SKU, options, price and stock, a product with variants is only sold through them
+//	@Tags			Product
+//	@Accept			json
+//	@Produce		json
+//	@Param			id							path		int								true	"the id of the product"
+//	@Param			ProductVariantRequest		body		payload.ProductVariantRequest	true	"the new variant"
+//	@Success		200							{object}	payload.AppResponse
+//	@Failure		400							{object}	payload.AppError
+//	@Failure		404							{object}	payload.AppError
+//	@Failure		500							{object}	payload.AppError
+//	@Router			/products/:id/variants 		[post]
+func (h *ProductVariantHandler) HandleCreateVariant(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleCreateVariant", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	productId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if productId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("CREATE_VARIANT_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	var createRequest payload.ProductVariantRequest
+	if err := c.ShouldBindJSON(&createRequest); err != nil {
+		h.p.Logger.Error("CREATE_VARIANT_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("CREATE_VARIANT_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	product, variant, err := h.usecase.CreateVariant(c, requester, productId, &createRequest)
+	if err != nil {
+		h.p.Logger.Error("CREATE_VARIANT_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.VariantToVariantResponse(product, variant), "")
+}
+
+// HandleUpdateVariant UpdateVariant godoc
+//
+//	@Summary		Update a variant of a product
+//	@Description	replace a variant and its options, orders already placed keep the price they got
+//	@Tags			Product
+//	@Accept			json
+//	@Produce		json
+//	@Param			id									path		int								true	"the id of the product"
+//	@Param			variantId							path		int								true	"the id of the variant"
+//	@Param			ProductVariantRequest				body		payload.ProductVariantRequest	true	"the variant"
+//	@Success		200									{object}	payload.AppResponse
+//	@Failure		400									{object}	payload.AppError
+//	@Failure		404									{object}	payload.AppError
+//	@Failure		500									{object}	payload.AppError
+//	@Router			/products/:id/variants/:variantId 	[put]
+func (h *ProductVariantHandler) HandleUpdateVariant(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleUpdateVariant", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	productId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	variantId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("variantId")), 10, 64)
+	if productId == 0 || variantId == 0 {
+		err := errors.New("params [id] and [variantId] are required")
+		h.p.Logger.Error("UPDATE_VARIANT_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	var updateRequest payload.ProductVariantRequest
+	if err := c.ShouldBindJSON(&updateRequest); err != nil {
+		h.p.Logger.Error("UPDATE_VARIANT_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("UPDATE_VARIANT_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	product, variant, err := h.usecase.UpdateVariant(c, requester, productId, variantId, &updateRequest)
+	if err != nil {
+		h.p.Logger.Error("UPDATE_VARIANT_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.VariantToVariantResponse(product, variant), "")
+}
+
+// HandleDeleteVariant DeleteVariant godoc
+//
+//	@Summary		Delete a variant of a product
+//	@Description	delete a variant, it cannot be ordered anymore
+//	@Tags			Product
+//	@Accept			json
+//	@Produce		json
+//	@Param			id									path		int	true	"the id of the product"
+//	@Param			variantId							path		int	true	"the id of the variant"
+//	@Success		200									{object}	payload.AppResponse
+//	@Failure		400									{object}	payload.AppError
+//	@Failure		404									{object}	payload.AppError
+//	@Failure		500									{object}	payload.AppError
+//	@Router			/products/:id/variants/:variantId 	[delete]
+func (h *ProductVariantHandler) HandleDeleteVariant(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleDeleteVariant", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	productId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	variantId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("variantId")), 10, 64)
+	if productId == 0 || variantId == 0 {
+		err := errors.New("params [id] and [variantId] are required")
+		h.p.Logger.Error("DELETE_VARIANT_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	if err := h.usecase.DeleteVariant(c, productId, variantId); err != nil {
+		h.p.Logger.Error("DELETE_VARIANT_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, nil, "variant deleted")
+}
\ No newline at end of file
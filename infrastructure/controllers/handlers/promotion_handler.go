@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"pm/application"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/mapper"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+	"strconv"
+)
+
+type PromotionHandler struct {
+	p       *base.Persistence
+	usecase application.PromotionUsecase
+}
+
+func NewPromotionHandler(p *base.Persistence) *PromotionHandler {
+	usecase := application.NewPromotionUsecase(p)
+	return &PromotionHandler{p, usecase}
+}
+
+// HandleGetAllPromotions GetAllPromotions godoc
+//
+//	@Summary		Get all promotions
+//	@Description	get every promotion with how many times it was used
+//	@Tags			Promotion
+//	@Accept			json
+//	@Produce		json
+//	@Param			limit			query		int		false	"the limit perpage"
+//	@Param			page			query		int		false	"the page nummber"
+//	@Param			sort			query		string	false	"the fields to sort by separated by commas, descending when starting with -, like -createdAt,name"
+//	@Success		200				{object}	payload.AppResponse
+//	@Failure		400				{object}	payload.AppError
+//	@Failure		500				{object}	payload.AppError
+//	@Router			/promotions 	[get]
+func (h *PromotionHandler) HandleGetAllPromotions(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleGetAllPromotions", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	var pagination entity.Pagination
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		h.p.Logger.Error("GET_ALL_PROMOTIONS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+	if err := pagination.ValidateSort(entity.PromotionSortColumns); err != nil {
+		h.p.Logger.Error("GET_ALL_PROMOTIONS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidSort(err))
+		return
+	}
+	if err := pagination.ValidateNoCursor(); err != nil {
+		h.p.Logger.Error("GET_ALL_PROMOTIONS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidCursor(err))
+		return
+	}
+
+	listPromotions, err := h.usecase.GetAllPromotions(c, &pagination)
+	if err != nil {
+		h.p.Logger.Error("GET_ALL_PROMOTIONS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.PromotionsToListPromotionResponses(listPromotions, &pagination), "")
+}
+
+// HandleGetPromotionByID GetPromotionByID godoc
+//
+//	@Summary		Get a promotion by id
+//	@Description	get a promotion with how many times it was used
+//	@Tags			Promotion
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		int	true	"the id of the promotion"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		404					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/promotions/:id 	[get]
+func (h *PromotionHandler) HandleGetPromotionByID(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleGetPromotionByID", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	promotionId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if promotionId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("GET_PROMOTION_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	promotion, err := h.usecase.GetPromotionByID(c, promotionId)
+	if err != nil {
+		h.p.Logger.Error("GET_PROMOTION_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.PromotionToPromotionResponse(promotion), "")
+}
+
+// HandleCreatePromotion CreatePromotion godoc
+//
+//	@Summary		Create a promotion
+//	@Description	create a promotion code, percentage off, fixed amount off, free shipping or buy X get Y
+//	@Tags			Promotion
+//	@Accept			json
+//	@Produce		json
+//	@Param			PromotionRequest	body		payload.PromotionRequest	true	"the new promotion"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/promotions 		[post]
+func (h *PromotionHandler) HandleCreatePromotion(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleCreatePromotion", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	var createRequest payload.PromotionRequest
+	if err := c.ShouldBindJSON(&createRequest); err != nil {
+		h.p.Logger.Error("CREATE_PROMOTION_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	promotion, err := h.usecase.CreatePromotion(c, &createRequest)
+	if err != nil {
+		h.p.Logger.Error("CREATE_PROMOTION_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.PromotionToPromotionResponse(promotion), "")
+}
+
+// HandleUpdatePromotion UpdatePromotion godoc
+//
+//	@Summary		Update a promotion
+//	@Description	update a promotion, orders already placed keep the discount they got
+//	@Tags			Promotion
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		int							true	"the id of the promotion"
+//	@Param			PromotionRequest	body		payload.PromotionRequest	true	"the promotion"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		404					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/promotions/:id 	[put]
+func (h *PromotionHandler) HandleUpdatePromotion(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleUpdatePromotion", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	promotionId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if promotionId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("UPDATE_PROMOTION_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	var updateRequest payload.PromotionRequest
+	if err := c.ShouldBindJSON(&updateRequest); err != nil {
+		h.p.Logger.Error("UPDATE_PROMOTION_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	promotion, err := h.usecase.UpdatePromotion(c, promotionId, &updateRequest)
+	if err != nil {
+		h.p.Logger.Error("UPDATE_PROMOTION_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.PromotionToPromotionResponse(promotion), "")
+}
+
+// HandleDeletePromotion DeletePromotion godoc
+//
+//	@Summary		Delete a promotion
+//	@Description	delete a promotion, its code cannot be applied anymore
+//	@Tags			Promotion
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		int	true	"the id of the promotion"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		404					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/promotions/:id 	[delete]
+func (h *PromotionHandler) HandleDeletePromotion(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleDeletePromotion", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	promotionId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if promotionId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("DELETE_PROMOTION_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	if err := h.usecase.DeletePromotion(c, promotionId); err != nil {
+		h.p.Logger.Error("DELETE_PROMOTION_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, nil, "promotion deleted")
+}
\ No newline at end of file
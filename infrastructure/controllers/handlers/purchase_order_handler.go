@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"pm/application"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/mapper"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+	"strconv"
+)
+
+type PurchaseOrderHandler struct {
+	p       *base.Persistence
+	usecase application.PurchaseOrderUsecase
+}
+
+func NewPurchaseOrderHandler(p *base.Persistence) *PurchaseOrderHandler {
+	usecase := application.NewPurchaseOrderUsecase(p)
+	return &PurchaseOrderHandler{p, usecase}
+}
+
+// HandleGetAllPurchaseOrders GetAllPurchaseOrders godoc
+//
+//	@Summary		Get all purchase orders
+//	@Description	get the purchase orders, filtered by status and supplier
+//	@Tags			PurchaseOrder
+//	@Accept			json
+//	@Produce		json
+//	@Param			status					query		string	false	"DRAFT, SENT, PARTIALLY_RECEIVED, RECEIVED or CANCELLED"
+//	@Param			supplierId				query		int		false	"the id of the supplier"
+//	@Param			limit					query		int		false	"the limit perpage"
+//	@Param			page					query		int		false	"the page nummber"
+//	@Param			sort					query		string	false	"the fields to sort by separated by commas, descending when starting with -, like -createdAt,name"
+//	@Success		200						{object}	payload.AppResponse
+//	@Failure		400						{object}	payload.AppError
+//	@Failure		500						{object}	payload.AppError
+//	@Router			/purchase-orders 		[get]
+func (h *PurchaseOrderHandler) HandleGetAllPurchaseOrders(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleGetAllPurchaseOrders", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	var filter entity.PurchaseOrderFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		h.p.Logger.Error("GET_ALL_PURCHASE_ORDERS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+	var pagination entity.Pagination
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		h.p.Logger.Error("GET_ALL_PURCHASE_ORDERS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+	if err := pagination.ValidateSort(entity.PurchaseOrderSortColumns); err != nil {
+		h.p.Logger.Error("GET_ALL_PURCHASE_ORDERS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidSort(err))
+		return
+	}
+	if err := pagination.ValidateNoCursor(); err != nil {
+		h.p.Logger.Error("GET_ALL_PURCHASE_ORDERS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidCursor(err))
+		return
+	}
+
+	listPurchaseOrders, err := h.usecase.GetAllPurchaseOrders(c, &filter, &pagination)
+	if err != nil {
+		h.p.Logger.Error("GET_ALL_PURCHASE_ORDERS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.PurchaseOrdersToListPurchaseOrderResponses(listPurchaseOrders, &pagination), "")
+}
+
+// HandleGetPurchaseOrderByID GetPurchaseOrderByID godoc
+//
+//	@Summary		Get a purchase order
+//	@Description	get a purchase order with its lines and how much of them was received
+//	@Tags			PurchaseOrder
+//	@Accept			json
+//	@Produce		json
+//	@Param			id							path		int	true	"the id of the purchase order"
+//	@Success		200							{object}	payload.AppResponse
+//	@Failure		400							{object}	payload.AppError
+//	@Failure		404							{object}	payload.AppError
+//	@Failure		500							{object}	payload.AppError
+//	@Router			/purchase-orders/:id 		[get]
+func (h *PurchaseOrderHandler) HandleGetPurchaseOrderByID(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleGetPurchaseOrderByID", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	purchaseOrderId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if purchaseOrderId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("GET_PURCHASE_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	purchaseOrder, err := h.usecase.GetPurchaseOrderByID(c, purchaseOrderId)
+	if err != nil {
+		h.p.Logger.Error("GET_PURCHASE_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.PurchaseOrderToPurchaseOrderResponse(purchaseOrder), "")
+}
+
+// HandleCreatePurchaseOrder CreatePurchaseOrder godoc
+//
+//	@Summary		Create a purchase order
+//	@Description	draft a purchase order to a supplier, it can be changed until it is sent
+//	@Tags			PurchaseOrder
+//	@Accept			json
+//	@Produce		json
+//	@Param			PurchaseOrderRequest	body		payload.PurchaseOrderRequest	true	"the new purchase order"
+//	@Success		200						{object}	payload.AppResponse
+//	@Failure		400						{object}	payload.AppError
+//	@Failure		404						{object}	payload.AppError
+//	@Failure		500						{object}	payload.AppError
+//	@Router			/purchase-orders 		[post]
+func (h *PurchaseOrderHandler) HandleCreatePurchaseOrder(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleCreatePurchaseOrder", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	var createRequest payload.PurchaseOrderRequest
+	if err := c.ShouldBindJSON(&createRequest); err != nil {
+		h.p.Logger.Error("CREATE_PURCHASE_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("CREATE_PURCHASE_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	purchaseOrder, err := h.usecase.CreatePurchaseOrder(c, requester, &createRequest)
+	if err != nil {
+		h.p.Logger.Error("CREATE_PURCHASE_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.PurchaseOrderToPurchaseOrderResponse(purchaseOrder), "")
+}
+
+// HandleUpdatePurchaseOrder UpdatePurchaseOrder godoc
+//
+//	@Summary		Update a purchase order
+//	@Description	change a purchase order that is still a draft, the lines given replace its lines
+//	@Tags			PurchaseOrder
+//	@Accept			json
+//	@Produce		json
+//	@Param			id						path		int								true	"the id of the purchase order"
+//	@Param			PurchaseOrderRequest	body		payload.PurchaseOrderRequest	true	"the purchase order"
+//	@Success		200						{object}	payload.AppResponse
+//	@Failure		400						{object}	payload.AppError
+//	@Failure		404						{object}	payload.AppError
+//	@Failure		500						{object}	payload.AppError
+//	@Router			/purchase-orders/:id 	[put]
+func (h *PurchaseOrderHandler) HandleUpdatePurchaseOrder(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleUpdatePurchaseOrder", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	purchaseOrderId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if purchaseOrderId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("UPDATE_PURCHASE_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	var updateRequest payload.PurchaseOrderRequest
+	if err := c.ShouldBindJSON(&updateRequest); err != nil {
+		h.p.Logger.Error("UPDATE_PURCHASE_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	purchaseOrder, err := h.usecase.UpdatePurchaseOrder(c, purchaseOrderId, &updateRequest)
+	if err != nil {
+		h.p.Logger.Error("UPDATE_PURCHASE_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.PurchaseOrderToPurchaseOrderResponse(purchaseOrder), "")
+}
+
+// HandleSendPurchaseOrder SendPurchaseOrder godoc
+//
+//	@Summary		Send a purchase order
+//	@Description	mark a draft purchase order sent and email it to its supplier
+//	@Tags			PurchaseOrder
+//	@Accept			json
+//	@Produce		json
+//	@Param			id								path		int	true	"the id of the purchase order"
+//	@Success		200								{object}	payload.AppResponse
+//	@Failure		400								{object}	payload.AppError
+//	@Failure		404								{object}	payload.AppError
+//	@Failure		500								{object}	payload.AppError
+//	@Router			/purchase-orders/:id/send 		[post]
+func (h *PurchaseOrderHandler) HandleSendPurchaseOrder(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleSendPurchaseOrder", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	purchaseOrderId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if purchaseOrderId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("SEND_PURCHASE_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	purchaseOrder, err := h.usecase.SendPurchaseOrder(c, purchaseOrderId)
+	if err != nil {
+		h.p.Logger.Error("SEND_PURCHASE_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.PurchaseOrderToPurchaseOrderResponse(purchaseOrder), "")
+}
+
+// HandleCancelPurchaseOrder CancelPurchaseOrder godoc
+//
+//	@Summary		Cancel a purchase order
+//	@Description	cancel a purchase order nothing was received for yet
+//	@Tags			PurchaseOrder
+//	@Accept			json
+//	@Produce		json
+//	@Param			id								path		int	true	"the id of the purchase order"
+//	@Success		200								{object}	payload.AppResponse
+//	@Failure		400								{object}	payload.AppError
+//	@Failure		404								{object}	payload.AppError
+//	@Failure		500								{object}	payload.AppError
+//	@Router			/purchase-orders/:id/cancel 	[post]
+func (h *PurchaseOrderHandler) HandleCancelPurchaseOrder(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleCancelPurchaseOrder", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	purchaseOrderId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if purchaseOrderId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("CANCEL_PURCHASE_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	purchaseOrder, err := h.usecase.CancelPurchaseOrder(c, purchaseOrderId)
+	if err != nil {
+		h.p.Logger.Error("CANCEL_PURCHASE_ORDER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.PurchaseOrderToPurchaseOrderResponse(purchaseOrder), "")
+}
+
+// HandleReceiveGoods ReceiveGoods godoc
+//
+//	@Summary		Receive goods of a purchase order
+//	@Description	put the goods that arrived for a sent purchase order in stock and update the cost of goods of their products
+//	@Tags			PurchaseOrder
+//	@Accept			json
+//	@Produce		json
+//	@Param			id								path		int							true	"the id of the purchase order"
+//	@Param			ReceiveGoodsRequest				body		payload.ReceiveGoodsRequest	true	"the goods received"
+//	@Success		200								{object}	payload.AppResponse
+//	@Failure		400								{object}	payload.AppError
+//	@Failure		404								{object}	payload.AppError
+//	@Failure		500								{object}	payload.AppError
+//	@Router			/purchase-orders/:id/receipts 	[post]
+func (h *PurchaseOrderHandler) HandleReceiveGoods(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleReceiveGoods", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	purchaseOrderId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if purchaseOrderId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("RECEIVE_GOODS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	var receiveRequest payload.ReceiveGoodsRequest
+	if err := c.ShouldBindJSON(&receiveRequest); err != nil {
+		h.p.Logger.Error("RECEIVE_GOODS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("RECEIVE_GOODS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	purchaseOrder, err := h.usecase.ReceiveGoods(c, requester, purchaseOrderId, &receiveRequest)
+	if err != nil {
+		h.p.Logger.Error("RECEIVE_GOODS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.PurchaseOrderToPurchaseOrderResponse(purchaseOrder), "")
+}
\ No newline at end of file
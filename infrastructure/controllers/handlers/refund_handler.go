@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"pm/application"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/mapper"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+	"strconv"
+)
+
+type RefundHandler struct {
+	p       *base.Persistence
+	usecase application.RefundUsecase
+}
+
+func NewRefundHandler(p *base.Persistence) *RefundHandler {
+	usecase := application.NewRefundUsecase(p)
+	return &RefundHandler{p, usecase}
+}
+
+// HandleCreateRefund CreateRefund godoc
+//
+//	@Summary		Refund an order
+//	@Description	refund the captured payment of an order, fully without items or partially for the given quantities
+//	@Tags			Refund
+//	@Accept			json
+//	@Produce		json
+//	@Param			id						path		int							true	"the id of the order"
+//	@Param			CreateRefundRequest		body		payload.CreateRefundRequest	false	"the items to refund, everything left when empty"
+//	@Success		200						{object}	payload.AppResponse
+//	@Failure		400						{object}	payload.AppError
+//	@Failure		404						{object}	payload.AppError
+//	@Failure		409						{object}	payload.AppError
+//	@Failure		500						{object}	payload.AppError
+//	@Router			/orders/:id/refunds 	[post]
+func (h *RefundHandler) HandleCreateRefund(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleCreateRefund", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	orderId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if orderId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("CREATE_REFUND_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	var refundRequest payload.CreateRefundRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&refundRequest); err != nil {
+			h.p.Logger.Error("CREATE_REFUND_FAILED", map[string]interface{}{"message": err.Error()})
+			c.Error(payload.ErrInvalidRequest(err))
+			return
+		}
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("CREATE_REFUND_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	refund, err := h.usecase.CreateRefund(c, requester, orderId, &refundRequest)
+	if err != nil {
+		h.p.Logger.Error("CREATE_REFUND_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	refundResponse := mapper.RefundToRefundResponse(refund)
+	h.p.Logger.Info("CREATE_REFUND_SUCCESSFULLY", map[string]interface{}{"refund_response": refundResponse})
+	utils.HttpSuccessResponse(c, refundResponse, "")
+}
+
+// HandleGetRefundsByOrderID GetRefundsByOrderID godoc
+//
+//	@Summary		Get the refunds of an order
+//	@Description	get every refund of an order with its items
+//	@Tags			Refund
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int	true	"the id of the order"
+//	@Success		200		{object}	payload.AppResponse
+//	@Failure		400		{object}	payload.AppError
+//	@Failure		404		{object}	payload.AppError
+//	@Failure		500		{object}	payload.AppError
+//	@Router			/orders/:id/refunds 	[get]
+func (h *RefundHandler) HandleGetRefundsByOrderID(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleGetRefundsByOrderID", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	orderId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if orderId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("GET_REFUNDS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("GET_REFUNDS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	listRefunds, err := h.usecase.GetRefundsByOrderID(c, requester, orderId)
+	if err != nil {
+		h.p.Logger.Error("GET_REFUNDS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.RefundsToRefundResponses(listRefunds), "")
+}
\ No newline at end of file
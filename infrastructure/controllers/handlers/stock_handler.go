@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"pm/application"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/mapper"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+	"strconv"
+)
+
+type StockHandler struct {
+	p       *base.Persistence
+	usecase application.StockUsecase
+}
+
+func NewStockHandler(p *base.Persistence) *StockHandler {
+	usecase := application.NewStockUsecase(p)
+	return &StockHandler{p, usecase}
+}
+
+// HandleGetStockHistory GetStockHistory godoc
+//
+//	@Summary		Get the stock history of a product
+//	@Description	get the stock of a product, or of one of its variants, next to the sum of its stock movements, with the movements newest first
+//	@Tags			Stock
+//	@Accept			json
+//	@Produce		json
+//	@Param			id									path		int		true	"the id of the product"
+//	@Param			variantId							query		int		false	"the id of the variant"
+//	@Param			limit								query		int		false	"the limit perpage"
+//	@Param			page								query		int		false	"the page nummber"
+//	@Param			sort								query		string		false	"the fields to sort by separated by commas, descending when starting with -, like -createdAt,name"
+//	@Success		200									{object}	payload.AppResponse
+//	@Failure		400									{object}	payload.AppError
+//	@Failure		404									{object}	payload.AppError
+//	@Failure		500									{object}	payload.AppError
+//	@Router			/products/:id/stock-history 		[get]
+func (h *StockHandler) HandleGetStockHistory(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleGetStockHistory", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	productId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if productId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("GET_STOCK_HISTORY_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	var variantID *uint
+	if v := c.Query("variantId"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || id == 0 {
+			err := errors.New("query [variantId] must be a positive number")
+			h.p.Logger.Error("GET_STOCK_HISTORY_FAILED", map[string]interface{}{"message": err.Error()})
+			c.Error(payload.ErrInvalidRequest(err))
+			return
+		}
+		variant := uint(id)
+		variantID = &variant
+	}
+
+	var pagination entity.Pagination
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		h.p.Logger.Error("GET_STOCK_HISTORY_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+	if err := pagination.ValidateSort(entity.StockMovementSortColumns); err != nil {
+		h.p.Logger.Error("GET_STOCK_HISTORY_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidSort(err))
+		return
+	}
+	if err := pagination.ValidateNoCursor(); err != nil {
+		h.p.Logger.Error("GET_STOCK_HISTORY_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidCursor(err))
+		return
+	}
+
+	history, err := h.usecase.GetStockHistory(c, productId, variantID, &pagination)
+	if err != nil {
+		h.p.Logger.Error("GET_STOCK_HISTORY_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.StockHistoryToStockHistoryResponse(history, &pagination), "")
+}
+
+// HandleAdjustStock AdjustStock godoc
+//
+//	@Summary		Adjust the stock of a product
+//	@Description	move the stock of a product, or of one of its variants, by a signed quantity and record it as a manual adjustment
+//	@Tags			Stock
+//	@Accept			json
+//	@Produce		json
+//	@Param			id									path		int								true	"the id of the product"
+//	@Param			StockAdjustmentRequest				body		payload.StockAdjustmentRequest	true	"the adjustment"
+//	@Success		200									{object}	payload.AppResponse
+//	@Failure		400									{object}	payload.AppError
+//	@Failure		404									{object}	payload.AppError
+//	@Failure		500									{object}	payload.AppError
+//	@Router			/products/:id/stock-adjustments 	[post]
+func (h *StockHandler) HandleAdjustStock(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleAdjustStock", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	productId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if productId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("ADJUST_STOCK_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	var adjustmentRequest payload.StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&adjustmentRequest); err != nil {
+		h.p.Logger.Error("ADJUST_STOCK_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("ADJUST_STOCK_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	product, err := h.usecase.AdjustStock(c, requester, productId, &adjustmentRequest)
+	if err != nil {
+		h.p.Logger.Error("ADJUST_STOCK_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.ProductToProductResponse(product), "")
+}
\ No newline at end of file
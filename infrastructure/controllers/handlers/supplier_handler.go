@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"pm/application"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/mapper"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+	"strconv"
+)
+
+type SupplierHandler struct {
+	p       *base.Persistence
+	usecase application.SupplierUsecase
+}
+
+func NewSupplierHandler(p *base.Persistence) *SupplierHandler {
+	usecase := application.NewSupplierUsecase(p)
+	return &SupplierHandler{p, usecase}
+}
+
+// HandleGetAllSuppliers GetAllSuppliers godoc
+//
+//	@Summary		Get all suppliers
+//	@Description	get the suppliers goods are ordered from
+//	@Tags			Supplier
+//	@Accept			json
+//	@Produce		json
+//	@Param			limit			query		int		false	"the limit perpage"
+//	@Param			page			query		int		false	"the page nummber"
+//	@Param			sort			query		string	false	"the fields to sort by separated by commas, descending when starting with -, like -createdAt,name"
+//	@Success		200				{object}	payload.AppResponse
+//	@Failure		400				{object}	payload.AppError
+//	@Failure		500				{object}	payload.AppError
+//	@Router			/suppliers 		[get]
+func (h *SupplierHandler) HandleGetAllSuppliers(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleGetAllSuppliers", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	var pagination entity.Pagination
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		h.p.Logger.Error("GET_ALL_SUPPLIERS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+	if err := pagination.ValidateSort(entity.SupplierSortColumns); err != nil {
+		h.p.Logger.Error("GET_ALL_SUPPLIERS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidSort(err))
+		return
+	}
+	if err := pagination.ValidateNoCursor(); err != nil {
+		h.p.Logger.Error("GET_ALL_SUPPLIERS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidCursor(err))
+		return
+	}
+
+	listSuppliers, err := h.usecase.GetAllSuppliers(c, &pagination)
+	if err != nil {
+		h.p.Logger.Error("GET_ALL_SUPPLIERS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.SuppliersToListSupplierResponses(listSuppliers, &pagination), "")
+}
+
+// HandleGetSupplierByID GetSupplierByID godoc
+//
+//	@Summary		Get a supplier
+//	@Description	get a supplier by its id
+//	@Tags			Supplier
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		int	true	"the id of the supplier"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		404					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/suppliers/:id 		[get]
+func (h *SupplierHandler) HandleGetSupplierByID(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleGetSupplierByID", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	supplierId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if supplierId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("GET_SUPPLIER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	supplier, err := h.usecase.GetSupplierByID(c, supplierId)
+	if err != nil {
+		h.p.Logger.Error("GET_SUPPLIER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.SupplierToSupplierResponse(supplier), "")
+}
+
+// HandleCreateSupplier CreateSupplier godoc
+//
+//	@Summary		Create a supplier
+//	@Description	create a supplier to order goods from
+//	@Tags			Supplier
+//	@Accept			json
+//	@Produce		json
+//	@Param			SupplierRequest		body		payload.SupplierRequest	true	"the new supplier"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/suppliers 			[post]
+func (h *SupplierHandler) HandleCreateSupplier(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleCreateSupplier", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	var createRequest payload.SupplierRequest
+	if err := c.ShouldBindJSON(&createRequest); err != nil {
+		h.p.Logger.Error("CREATE_SUPPLIER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	supplier, err := h.usecase.CreateSupplier(c, &createRequest)
+	if err != nil {
+		h.p.Logger.Error("CREATE_SUPPLIER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.SupplierToSupplierResponse(supplier), "")
+}
+
+// HandleUpdateSupplier UpdateSupplier godoc
+//
+//	@Summary		Update a supplier
+//	@Description	update the name and the contact details of a supplier
+//	@Tags			Supplier
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		int						true	"the id of the supplier"
+//	@Param			SupplierRequest		body		payload.SupplierRequest	true	"the supplier"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		404					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/suppliers/:id 		[put]
+func (h *SupplierHandler) HandleUpdateSupplier(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleUpdateSupplier", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	supplierId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if supplierId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("UPDATE_SUPPLIER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	var updateRequest payload.SupplierRequest
+	if err := c.ShouldBindJSON(&updateRequest); err != nil {
+		h.p.Logger.Error("UPDATE_SUPPLIER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	supplier, err := h.usecase.UpdateSupplier(c, supplierId, &updateRequest)
+	if err != nil {
+		h.p.Logger.Error("UPDATE_SUPPLIER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.SupplierToSupplierResponse(supplier), "")
+}
+
+// HandleDeleteSupplier DeleteSupplier godoc
+//
+//	@Summary		Delete a supplier
+//	@Description	delete a supplier, its purchase orders have to be received or cancelled first
+//	@Tags			Supplier
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		int	true	"the id of the supplier"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		404					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/suppliers/:id 		[delete]
+func (h *SupplierHandler) HandleDeleteSupplier(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleDeleteSupplier", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	supplierId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if supplierId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("DELETE_SUPPLIER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	if err := h.usecase.DeleteSupplier(c, supplierId); err != nil {
+		h.p.Logger.Error("DELETE_SUPPLIER_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, nil, "supplier deleted")
+}
\ No newline at end of file
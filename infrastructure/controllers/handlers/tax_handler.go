@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"pm/application"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/mapper"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+	"strconv"
+)
+
+type TaxHandler struct {
+	p       *base.Persistence
+	usecase application.TaxUsecase
+}
+
+func NewTaxHandler(p *base.Persistence) *TaxHandler {
+	usecase := application.NewTaxUsecase(p)
+	return &TaxHandler{p, usecase}
+}
+
+// HandleGetAllTaxClasses GetAllTaxClasses godoc
+//
+//	@Summary		Get all tax classes
+//	@Description	get every tax class products and categories can be taxed with
+//	@Tags			Tax
+//	@Accept			json
+//	@Produce		json
+//	@Success		200				{object}	payload.AppResponse
+//	@Failure		500				{object}	payload.AppError
+//	@Router			/tax-classes 	[get]
+func (h *TaxHandler) HandleGetAllTaxClasses(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleGetAllTaxClasses", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	listTaxClasses, err := h.usecase.GetAllTaxClasses(c)
+	if err != nil {
+		h.p.Logger.Error("GET_ALL_TAX_CLASSES_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.TaxClassesToTaxClassResponses(listTaxClasses), "")
+}
+
+// HandleGetTaxClassByID GetTaxClassByID godoc
+//
+//	@Summary		Get a tax class by id
+//	@Description	get a tax class by id
+//	@Tags			Tax
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		int	true	"the id of the tax class"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		404					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/tax-classes/:id 	[get]
+func (h *TaxHandler) HandleGetTaxClassByID(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleGetTaxClassByID", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	taxClassId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if taxClassId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("GET_TAX_CLASS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	taxClass, err := h.usecase.GetTaxClassByID(c, taxClassId)
+	if err != nil {
+		h.p.Logger.Error("GET_TAX_CLASS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.TaxClassToTaxClassResponse(taxClass), "")
+}
+
+// HandleCreateTaxClass CreateTaxClass godoc
+//
+//	@Summary		Create a tax class
+//	@Description	create a tax class, it is taxed with its rates once products or categories are given it
+//	@Tags			Tax
+//	@Accept			json
+//	@Produce		json
+//	@Param			TaxClassRequest	body		payload.TaxClassRequest	true	"the new tax class"
+//	@Success		200				{object}	payload.AppResponse
+//	@Failure		400				{object}	payload.AppError
+//	@Failure		500				{object}	payload.AppError
+//	@Router			/tax-classes 	[post]
+func (h *TaxHandler) HandleCreateTaxClass(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleCreateTaxClass", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	var createRequest payload.TaxClassRequest
+	if err := c.ShouldBindJSON(&createRequest); err != nil {
+		h.p.Logger.Error("CREATE_TAX_CLASS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	taxClass, err := h.usecase.CreateTaxClass(c, &createRequest)
+	if err != nil {
+		h.p.Logger.Error("CREATE_TAX_CLASS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.TaxClassToTaxClassResponse(taxClass), "")
+}
+
+// HandleUpdateTaxClass UpdateTaxClass godoc
+//
+//	@Summary		Update a tax class
+//	@Description	update the name and the description of a tax class
+//	@Tags			Tax
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		int						true	"the id of the tax class"
+//	@Param			TaxClassRequest		body		payload.TaxClassRequest	true	"the tax class"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		404					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/tax-classes/:id 	[put]
+func (h *TaxHandler) HandleUpdateTaxClass(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleUpdateTaxClass", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	taxClassId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if taxClassId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("UPDATE_TAX_CLASS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	var updateRequest payload.TaxClassRequest
+	if err := c.ShouldBindJSON(&updateRequest); err != nil {
+		h.p.Logger.Error("UPDATE_TAX_CLASS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	taxClass, err := h.usecase.UpdateTaxClass(c, taxClassId, &updateRequest)
+	if err != nil {
+		h.p.Logger.Error("UPDATE_TAX_CLASS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.TaxClassToTaxClassResponse(taxClass), "")
+}
+
+// HandleDeleteTaxClass DeleteTaxClass godoc
+//
+//	@Summary		Delete a tax class
+//	@Description	delete a tax class with its rates, its products and categories are not taxed anymore
+//	@Tags			Tax
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		int	true	"the id of the tax class"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		404					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/tax-classes/:id 	[delete]
+func (h *TaxHandler) HandleDeleteTaxClass(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleDeleteTaxClass", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	taxClassId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if taxClassId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("DELETE_TAX_CLASS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	if err := h.usecase.DeleteTaxClass(c, taxClassId); err != nil {
+		h.p.Logger.Error("DELETE_TAX_CLASS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, nil, "tax class deleted")
+}
+
+// HandleGetTaxRatesByClassID GetTaxRatesByClassID godoc
+//
+//	@Summary		Get the rates of a tax class
+//	@Description	get every rate of a tax class by region
+//	@Tags			Tax
+//	@Accept			json
+//	@Produce		json
+//	@Param			id							path		int	true	"the id of the tax class"
+//	@Success		200							{object}	payload.AppResponse
+//	@Failure		400							{object}	payload.AppError
+//	@Failure		404							{object}	payload.AppError
+//	@Failure		500							{object}	payload.AppError
+//	@Router			/tax-classes/:id/rates 	[get]
+func (h *TaxHandler) HandleGetTaxRatesByClassID(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleGetTaxRatesByClassID", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	taxClassId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if taxClassId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("GET_TAX_RATES_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	listTaxRates, err := h.usecase.GetTaxRatesByClassID(c, taxClassId)
+	if err != nil {
+		h.p.Logger.Error("GET_TAX_RATES_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.TaxRatesToTaxRateResponses(listTaxRates), "")
+}
+
+// HandleCreateTaxRate CreateTaxRate godoc
+//
+//	@Summary		Create a tax rate
+//	@Description	add the rate of a tax class in a region, an empty region applies to the whole country
+//	@Tags			Tax
+//	@Accept			json
+//	@Produce		json
+//	@Param			id							path		int						true	"the id of the tax class"
+//	@Param			TaxRateRequest				body		payload.TaxRateRequest	true	"the new tax rate"
+//	@Success		200							{object}	payload.AppResponse
+//	@Failure		400							{object}	payload.AppError
+//	@Failure		404							{object}	payload.AppError
+//	@Failure		500							{object}	payload.AppError
+//	@Router			/tax-classes/:id/rates 	[post]
+func (h *TaxHandler) HandleCreateTaxRate(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleCreateTaxRate", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	taxClassId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if taxClassId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("CREATE_TAX_RATE_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	var createRequest payload.TaxRateRequest
+	if err := c.ShouldBindJSON(&createRequest); err != nil {
+		h.p.Logger.Error("CREATE_TAX_RATE_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	taxRate, err := h.usecase.CreateTaxRate(c, taxClassId, &createRequest)
+	if err != nil {
+		h.p.Logger.Error("CREATE_TAX_RATE_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.TaxRateToTaxRateResponse(taxRate), "")
+}
+
+// HandleUpdateTaxRate UpdateTaxRate godoc
+//
+//	@Summary		Update a tax rate
+//	@Description	update a tax rate, orders already placed keep the tax they were placed with
+//	@Tags			Tax
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		int						true	"the id of the tax rate"
+//	@Param			TaxRateRequest		body		payload.TaxRateRequest	true	"the tax rate"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		404					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/tax-rates/:id 		[put]
+func (h *TaxHandler) HandleUpdateTaxRate(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleUpdateTaxRate", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	taxRateId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if taxRateId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("UPDATE_TAX_RATE_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	var updateRequest payload.TaxRateRequest
+	if err := c.ShouldBindJSON(&updateRequest); err != nil {
+		h.p.Logger.Error("UPDATE_TAX_RATE_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	taxRate, err := h.usecase.UpdateTaxRate(c, taxRateId, &updateRequest)
+	if err != nil {
+		h.p.Logger.Error("UPDATE_TAX_RATE_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.TaxRateToTaxRateResponse(taxRate), "")
+}
+
+// HandleDeleteTaxRate DeleteTaxRate godoc
+//
+//	@Summary		Delete a tax rate
+//	@Description	delete a tax rate, the rate of the whole country applies to its region again
+//	@Tags			Tax
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		int	true	"the id of the tax rate"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		404					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/tax-rates/:id 		[delete]
+func (h *TaxHandler) HandleDeleteTaxRate(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleDeleteTaxRate", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	taxRateId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if taxRateId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("DELETE_TAX_RATE_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	if err := h.usecase.DeleteTaxRate(c, taxRateId); err != nil {
+		h.p.Logger.Error("DELETE_TAX_RATE_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, nil, "tax rate deleted")
+}
\ No newline at end of file
@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"pm/application"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/mapper"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+	"strconv"
+)
+
+type WarehouseHandler struct {
+	p       *base.Persistence
+	usecase application.WarehouseUsecase
+}
+
+func NewWarehouseHandler(p *base.Persistence) *WarehouseHandler {
+	usecase := application.NewWarehouseUsecase(p)
+	return &WarehouseHandler{p, usecase}
+}
+
+// HandleGetAllWarehouses GetAllWarehouses godoc
+//
+//	@Summary		Get all warehouses
+//	@Description	get every warehouse in its priority order, the one stock is allocated from first comes first
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Success		200				{object}	payload.AppResponse
+//	@Failure		500				{object}	payload.AppError
+//	@Router			/warehouses 	[get]
+func (h *WarehouseHandler) HandleGetAllWarehouses(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleGetAllWarehouses", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	listWarehouses, err := h.usecase.GetAllWarehouses(c)
+	if err != nil {
+		h.p.Logger.Error("GET_ALL_WAREHOUSES_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.WarehousesToWarehouseResponses(listWarehouses), "")
+}
+
+// HandleCreateWarehouse CreateWarehouse godoc
+//
+//	@Summary		Create a warehouse
+//	@Description	create a warehouse, the first one takes over all the stock there is
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Param			WarehouseRequest	body		payload.WarehouseRequest	true	"the new warehouse"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/warehouses 		[post]
+func (h *WarehouseHandler) HandleCreateWarehouse(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleCreateWarehouse", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	var createRequest payload.WarehouseRequest
+	if err := c.ShouldBindJSON(&createRequest); err != nil {
+		h.p.Logger.Error("CREATE_WAREHOUSE_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	warehouse, err := h.usecase.CreateWarehouse(c, &createRequest)
+	if err != nil {
+		h.p.Logger.Error("CREATE_WAREHOUSE_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.WarehouseToWarehouseResponse(warehouse), "")
+}
+
+// HandleUpdateWarehouse UpdateWarehouse godoc
+//
+//	@Summary		Update a warehouse
+//	@Description	update the code, name, address and priority of a warehouse
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		int							true	"the id of the warehouse"
+//	@Param			WarehouseRequest	body		payload.WarehouseRequest	true	"the warehouse"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		404					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/warehouses/:id 	[put]
+func (h *WarehouseHandler) HandleUpdateWarehouse(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleUpdateWarehouse", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	warehouseId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if warehouseId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("UPDATE_WAREHOUSE_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	var updateRequest payload.WarehouseRequest
+	if err := c.ShouldBindJSON(&updateRequest); err != nil {
+		h.p.Logger.Error("UPDATE_WAREHOUSE_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	warehouse, err := h.usecase.UpdateWarehouse(c, warehouseId, &updateRequest)
+	if err != nil {
+		h.p.Logger.Error("UPDATE_WAREHOUSE_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.WarehouseToWarehouseResponse(warehouse), "")
+}
+
+// HandleDeleteWarehouse DeleteWarehouse godoc
+//
+//	@Summary		Delete a warehouse
+//	@Description	delete a warehouse, its stock has to be transferred to another one first
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		int	true	"the id of the warehouse"
+//	@Success		200					{object}	payload.AppResponse
+//	@Failure		400					{object}	payload.AppError
+//	@Failure		404					{object}	payload.AppError
+//	@Failure		500					{object}	payload.AppError
+//	@Router			/warehouses/:id 	[delete]
+func (h *WarehouseHandler) HandleDeleteWarehouse(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleDeleteWarehouse", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	warehouseId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if warehouseId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("DELETE_WAREHOUSE_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	if err := h.usecase.DeleteWarehouse(c, warehouseId); err != nil {
+		h.p.Logger.Error("DELETE_WAREHOUSE_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, nil, "warehouse deleted")
+}
+
+// HandleGetStockLevels GetStockLevels godoc
+//
+//	@Summary		Get the stock of a warehouse
+//	@Description	get how many of every product and variant a warehouse holds
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Param			id						path		int	true	"the id of the warehouse"
+//	@Success		200						{object}	payload.AppResponse
+//	@Failure		400						{object}	payload.AppError
+//	@Failure		404						{object}	payload.AppError
+//	@Failure		500						{object}	payload.AppError
+//	@Router			/warehouses/:id/stock 	[get]
+func (h *WarehouseHandler) HandleGetStockLevels(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleGetStockLevels", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	warehouseId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if warehouseId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("GET_STOCK_LEVELS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	stocks, err := h.usecase.GetStockLevels(c, warehouseId)
+	if err != nil {
+		h.p.Logger.Error("GET_STOCK_LEVELS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.WarehouseStocksToWarehouseStockResponses(stocks), "")
+}
+
+// HandleTransferStock TransferStock godoc
+//
+//	@Summary		Transfer stock between warehouses
+//	@Description	move stock of a product, or of one of its variants, from a warehouse to another
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Param			StockTransferRequest		body		payload.StockTransferRequest	true	"the transfer"
+//	@Success		200							{object}	payload.AppResponse
+//	@Failure		400							{object}	payload.AppError
+//	@Failure		404							{object}	payload.AppError
+//	@Failure		500							{object}	payload.AppError
+//	@Router			/warehouses/transfers 		[post]
+func (h *WarehouseHandler) HandleTransferStock(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleTransferStock", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	var transferRequest payload.StockTransferRequest
+	if err := c.ShouldBindJSON(&transferRequest); err != nil {
+		h.p.Logger.Error("TRANSFER_STOCK_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrInvalidRequest(err))
+		return
+	}
+
+	requester, err := getRequesterFromContext(c)
+	if err != nil {
+		h.p.Logger.Error("TRANSFER_STOCK_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	transfer, err := h.usecase.TransferStock(c, requester, &transferRequest)
+	if err != nil {
+		h.p.Logger.Error("TRANSFER_STOCK_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.StockTransferToStockTransferResponse(transfer), "")
+}
+
+// HandleGetPickingLists GetPickingLists godoc
+//
+//	@Summary		Get the picking lists of an order
+//	@Description	get, for every warehouse the order is allocated from, the items and quantities to pick there
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Param			id								path		int	true	"the id of the order"
+//	@Success		200								{object}	payload.AppResponse
+//	@Failure		400								{object}	payload.AppError
+//	@Failure		404								{object}	payload.AppError
+//	@Failure		500								{object}	payload.AppError
+//	@Router			/orders/:id/picking-lists 		[get]
+func (h *WarehouseHandler) HandleGetPickingLists(c *gin.Context) {
+	span := h.p.Logger.Start(c, "handlers/HandleGetPickingLists", h.p.Logger.SetContextWithSpanFunc())
+	defer span.End()
+
+	orderId, _ := strconv.ParseInt(removeSlashFromParam(c.Param("id")), 10, 64)
+	if orderId == 0 {
+		err := errors.New("param [id] is required")
+		h.p.Logger.Error("GET_PICKING_LISTS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(payload.ErrParamRequired(err))
+		return
+	}
+
+	lists, err := h.usecase.GetPickingLists(c, orderId)
+	if err != nil {
+		h.p.Logger.Error("GET_PICKING_LISTS_FAILED", map[string]interface{}{"message": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	utils.HttpSuccessResponse(c, mapper.PickingListsToPickingListResponses(lists), "")
+}
\ No newline at end of file
@@ -17,6 +17,7 @@ const (
 	authorizationHeader = "Authorization"
 	bearerPrefix        = "Bearer "
 	userContextKey      = "user"
+	roleContextKey      = "role"
 	subject             = "subject"
 	roleKey             = "role"
 )
@@ -83,7 +84,7 @@ func AuthMiddleware(p *base.Persistence, roles ...int64) gin.HandlerFunc {
 
 			roleFromClaims := roleInt
 
-			if user.RoleID == roleFromClaims && !slices.Contains(roles, roleFromClaims) {
+			if user.RoleID != roleFromClaims || !slices.Contains(roles, roleFromClaims) {
 				errP := payload.ErrPermissionDenied(errors.New("You don't have permission to access this resource"))
 				p.Logger.Error("AUTHORIZATION_FAILED", map[string]interface{}{"error": errP.Error()})
 				c.AbortWithStatusJSON(http.StatusForbidden, errP)
@@ -91,6 +92,7 @@ func AuthMiddleware(p *base.Persistence, roles ...int64) gin.HandlerFunc {
 			}
 		}
 		c.Set(userContextKey, id)
+		c.Set(roleContextKey, user.RoleID)
 
 		p.Logger.Info("AUTH_MIDDLEWARE_SUCCESSFULLY", map[string]interface{}{})
 
@@ -0,0 +1,213 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"net/http"
+	"pm/domain/entity"
+	idempotencyRepo "pm/domain/repository/idempotency"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/implementations/idempotency"
+	"pm/infrastructure/persistences/base"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyKeyTTL         = 24 * time.Hour
+	idempotencyKeyLockTimeout = time.Minute
+	idempotencyKeyLockRefresh = idempotencyKeyLockTimeout / 3
+)
+
+// bodyCaptureWriter keeps a copy of everything the handler writes so it can be stored for replays
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w bodyCaptureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w bodyCaptureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware replays the stored response when a request is retried with the same Idempotency-Key header.
+// A retry with the same key but another payload is rejected. Keys belong to the user who sent them, so it must run
+// after AuthMiddleware. Requests without the header are not affected
+func IdempotencyMiddleware(p *base.Persistence) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(c.GetHeader(idempotencyKeyHeader))
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		span := p.Logger.Start(c, "IDEMPOTENCY_MIDDLEWARE", p.Logger.SetContextWithSpanFunc())
+		defer span.End()
+		p.Logger.Info("IDEMPOTENCY_MIDDLEWARE", map[string]interface{}{"key": key})
+
+		if len(key) > maxIdempotencyKeyLength {
+			errK := payload.ErrInvalidRequest(fmt.Errorf("%s must not be longer than %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength))
+			p.Logger.Error("IDEMPOTENCY_FAILED", map[string]interface{}{"error": errK.Error()})
+			c.AbortWithStatusJSON(errK.StatusCode, errK)
+			return
+		}
+		userID, err := strconv.ParseInt(fmt.Sprintf("%v", c.Value(userContextKey)), 10, 64)
+		if err != nil {
+			errU := payload.ErrInternal(errors.New("idempotency keys need an authenticated user"))
+			p.Logger.Error("IDEMPOTENCY_FAILED", map[string]interface{}{"error": errU.Error()})
+			c.AbortWithStatusJSON(errU.StatusCode, errU)
+			return
+		}
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			errB := payload.ErrInvalidRequest(err)
+			p.Logger.Error("IDEMPOTENCY_FAILED", map[string]interface{}{"error": errB.Error()})
+			c.AbortWithStatusJSON(errB.StatusCode, errB)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+
+		repo := idempotency.NewIdempotencyRepository(c, p, p.GormDB)
+		record, reserved, err := lockIdempotencyKey(span, repo, userID, key, hashRequest(c, body))
+		if err != nil {
+			p.Logger.Error("IDEMPOTENCY_FAILED", map[string]interface{}{"error": err.Error()})
+			var appErr *payload.AppError
+			if !errors.As(err, &appErr) {
+				appErr = payload.ErrInternal(err)
+			}
+			c.AbortWithStatusJSON(appErr.StatusCode, appErr)
+			return
+		}
+		if !reserved {
+			p.Logger.Info("IDEMPOTENCY_REPLAYED", map[string]interface{}{"key": key, "status_code": record.ResponseCode})
+			c.Header(idempotentReplayedHeader, "true")
+			c.Data(record.ResponseCode, record.ContentType, []byte(record.ResponseBody))
+			c.Abort()
+			return
+		}
+
+		writer := bodyCaptureWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+		// deferred so a panicking handler does not leave the lock refreshed forever
+		defer keepIdempotencyKeyLocked(p, span, repo, record)()
+		c.Next()
+
+		// errors are rendered later by ErrorHandlingMiddleware, so failed requests release the key to allow a retry
+		status := writer.Status()
+		if len(c.Errors) > 0 || status < http.StatusOK || status >= http.StatusMultipleChoices {
+			if err := repo.Release(span, record); err != nil {
+				p.Logger.Error("IDEMPOTENCY_RELEASE_FAILED", map[string]interface{}{"error": err.Error()})
+			}
+			return
+		}
+
+		record.ResponseCode = status
+		record.ContentType = writer.Header().Get("Content-Type")
+		record.ResponseBody = writer.body.String()
+		if err := repo.Complete(span, record); err != nil {
+			p.Logger.Error("IDEMPOTENCY_COMPLETE_FAILED", map[string]interface{}{"error": err.Error()})
+		}
+	}
+}
+
+// lockIdempotencyKey reserves the key for this request when it is not used yet and reports true. Otherwise it
+// returns the completed record to replay, or an error when the payload differs or the first request still runs
+func lockIdempotencyKey(span trace.Span, repo idempotencyRepo.IdempotencyRepository, userID int64, key, requestHash string) (*entity.IdempotencyKey, bool, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		existing, err := repo.GetByKey(span, userID, key)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if existing == nil {
+			record := &entity.IdempotencyKey{
+				UserID:      uint(userID),
+				Key:         key,
+				RequestHash: requestHash,
+				Status:      entity.IdempotencyStatusInProgress,
+				ExpiresAt:   time.Now().Add(idempotencyKeyTTL),
+			}
+			ok, err := repo.Reserve(span, record)
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				return record, true, nil
+			}
+			// another request reserved the key in the meantime, read it again
+			continue
+		}
+
+		if existing.RequestHash != requestHash {
+			return nil, false, payload.ErrIdempotencyKeyReused(fmt.Errorf("%s [%s] was already used for another request", idempotencyKeyHeader, key))
+		}
+		if existing.Status == entity.IdempotencyStatusCompleted {
+			return existing, false, nil
+		}
+		if time.Since(existing.UpdatedAt) > idempotencyKeyLockTimeout {
+			// the request holding the key refreshes its lock while it runs, so it died once the lock is stale. The lock
+			// is checked again on delete in case it was refreshed in the meantime
+			released, err := repo.ReleaseStale(span, existing, time.Now().Add(-idempotencyKeyLockTimeout))
+			if err != nil {
+				return nil, false, err
+			}
+			if released {
+				continue
+			}
+		}
+		break
+	}
+	return nil, false, payload.ErrIdempotencyRequestInProgress(fmt.Errorf("a request with %s [%s] is still being processed", idempotencyKeyHeader, key))
+}
+
+// keepIdempotencyKeyLocked refreshes the lock of the request on the key until the returned func is called, so the key
+// is never taken over while the request still runs however long it takes
+func keepIdempotencyKeyLocked(p *base.Persistence, span trace.Span, repo idempotencyRepo.IdempotencyRepository, record *entity.IdempotencyKey) func() {
+	// the refreshes work on a copy, the record itself is completed by the request once it is done
+	held := *record
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(idempotencyKeyLockRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := repo.Extend(span, &held); err != nil {
+					p.Logger.Error("IDEMPOTENCY_EXTEND_FAILED", map[string]interface{}{"error": err.Error()})
+				}
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-stopped
+	}
+}
+
+// hashRequest fingerprints the request with its actual path, so the same key and body sent for another resource, such
+// as another order, is a different request
+func hashRequest(c *gin.Context, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
\ No newline at end of file
@@ -175,3 +175,28 @@ func ErrInvalidToken(err error) *AppError {
 func ErrPermissionDenied(err error) *AppError {
 	return NewCustomError(err, err.Error(), "ErrPermissionDenied")
 }
+
+func ErrInvalidSort(err error) *AppError {
+	return NewCustomError(err, err.Error(), "ErrInvalidSort")
+}
+
+func ErrInvalidCursor(err error) *AppError {
+	return NewCustomError(err, err.Error(), "ErrInvalidCursor")
+}
+
+func ErrInvalidOrderTransition(from, to string) *AppError {
+	err := fmt.Errorf("cannot move order from status %s to %s", from, to)
+	return NewFullErrorResponse(http.StatusConflict, err, err.Error(), err.Error(), "ErrInvalidOrderTransition")
+}
+
+func ErrInvalidSignature(err error) *AppError {
+	return NewUnauthorized(err, err.Error(), "ErrInvalidSignature")
+}
+
+func ErrIdempotencyKeyReused(err error) *AppError {
+	return NewFullErrorResponse(http.StatusUnprocessableEntity, err, err.Error(), err.Error(), "ErrIdempotencyKeyReused")
+}
+
+func ErrIdempotencyRequestInProgress(err error) *AppError {
+	return NewFullErrorResponse(http.StatusConflict, err, err.Error(), err.Error(), "ErrIdempotencyRequestInProgress")
+}
\ No newline at end of file
@@ -1,33 +1,115 @@
 package payload
 
-import "pm/domain/entity"
+import (
+	"pm/domain/entity"
+	"time"
+)
 
 type CreateProductRequest struct {
-	Name        string  `json:"name" validate:"required"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" validate:"required,gte=0"`
-	CategoryID  int64   `json:"categoryId" validate:"required"`
-	Stock       int64   `json:"stock" validate:"gte=0"`
-	Image       string  `json:"imagePath"`
+	Name             string   `json:"name" validate:"required"`
+	Description      string   `json:"description"`
+	Price            float64  `json:"price" validate:"required,gte=0"`
+	CategoryID       int64    `json:"categoryId" validate:"required"`
+	TaxClassID       *uint    `json:"taxClassId"`
+	Stock            int64    `json:"stock" validate:"gte=0"`
+	ReorderThreshold *int64   `json:"reorderThreshold" validate:"omitempty,gte=0"`
+	Tags             []string `json:"tags" validate:"max=20,dive,max=50"`
+	Image            string   `json:"imagePath"`
 }
 
 type UpdateProductRequest struct {
-	ID          int64   `json:"id"`
-	Name        string  `json:"name" validate:"required"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" validate:"required,gte=0"`
-	CategoryID  int64   `json:"categoryId" validate:"required"`
-	Stock       int64   `json:"stock" validate:"gte=0"`
-	Image       string  `json:"imagePath"`
+	ID               int64    `json:"id"`
+	Name             string   `json:"name" validate:"required"`
+	Description      string   `json:"description"`
+	Price            float64  `json:"price" validate:"required,gte=0"`
+	CategoryID       int64    `json:"categoryId" validate:"required"`
+	TaxClassID       *uint    `json:"taxClassId"`
+	Stock            int64    `json:"stock" validate:"gte=0"`
+	ReorderThreshold *int64   `json:"reorderThreshold" validate:"omitempty,gte=0"`
+	Tags             []string `json:"tags" validate:"max=20,dive,max=50"`
+	Image            string   `json:"imagePath"`
+}
+
+type ProductVariantRequest struct {
+	SKU     string                 `json:"sku" validate:"required,max=64"`
+	Price   *float64               `json:"price" validate:"omitempty,gte=0"`
+	Stock   int64                  `json:"stock" validate:"gte=0"`
+	Image   string                 `json:"imagePath"`
+	Options []VariantOptionRequest `json:"options" validate:"dive"`
+}
+
+// StockAdjustmentRequest moves the stock of a product, or of one of its variants, by a signed quantity. The stock
+// is moved in the given warehouse, or in the first one when there are warehouses and none is given
+type StockAdjustmentRequest struct {
+	VariantID   *uint  `json:"variantId"`
+	WarehouseID *uint  `json:"warehouseId"`
+	Quantity    int64  `json:"quantity" validate:"required"`
+	Reference   string `json:"reference" validate:"max=100"`
+}
+
+type WarehouseRequest struct {
+	Code     string `json:"code" validate:"required,max=30"`
+	Name     string `json:"name" validate:"required"`
+	Address  string `json:"address"`
+	Priority int    `json:"priority" validate:"gte=0"`
+}
+
+type StockTransferRequest struct {
+	FromWarehouseID uint  `json:"fromWarehouseId" validate:"required"`
+	ToWarehouseID   uint  `json:"toWarehouseId" validate:"required,nefield=FromWarehouseID"`
+	ProductID       uint  `json:"productId" validate:"required"`
+	VariantID       *uint `json:"variantId"`
+	Quantity        int64 `json:"quantity" validate:"required,gt=0"`
+}
+
+type SupplierRequest struct {
+	Name    string `json:"name" validate:"required,max=255"`
+	Email   string `json:"email" validate:"omitempty,email,max=255"`
+	Phone   string `json:"phone" validate:"max=50"`
+	Address string `json:"address"`
+}
+
+// PurchaseOrderRequest is a purchase order as drafted, its lines replace the ones it had
+type PurchaseOrderRequest struct {
+	SupplierID  uint                       `json:"supplierId" validate:"required"`
+	WarehouseID *uint                      `json:"warehouseId"`
+	Note        string                     `json:"note"`
+	Lines       []PurchaseOrderLineRequest `json:"lines" validate:"required,min=1,dive"`
+}
+
+type PurchaseOrderLineRequest struct {
+	ProductID uint    `json:"productId" validate:"required"`
+	VariantID *uint   `json:"variantId"`
+	Quantity  int64   `json:"quantity" validate:"required,gt=0"`
+	UnitCost  float64 `json:"unitCost" validate:"gte=0"`
+}
+
+// ReceiveGoodsRequest is the goods that arrived for a purchase order. They are received into the given warehouse,
+// or into the warehouse of the purchase order when none is given
+type ReceiveGoodsRequest struct {
+	WarehouseID *uint                     `json:"warehouseId"`
+	Lines       []ReceiveGoodsLineRequest `json:"lines" validate:"required,min=1,dive"`
+}
+
+type ReceiveGoodsLineRequest struct {
+	LineID   uint  `json:"lineId" validate:"required"`
+	Quantity int64 `json:"quantity" validate:"required,gt=0"`
+}
+
+type VariantOptionRequest struct {
+	Name  string `json:"name" validate:"required,max=50"`
+	Value string `json:"value" validate:"required,max=100"`
 }
 
 type CreateCategoryRequest struct {
-	Name string `json:"name" validate:"required"`
+	Name       string `json:"name" validate:"required"`
+	TaxClassID *uint  `json:"taxClassId"`
 }
 
 type UpdateCategoryRequest struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	TaxClassID *uint  `json:"taxClassId"`
 }
 
 type UserRequest struct {
@@ -44,18 +126,24 @@ type LoginRequest struct {
 }
 
 type UpdateOrderRequest struct {
+	Status string `json:"status" validate:"required"`
+	Reason string `json:"reason" validate:"max=255"`
+}
+
+type CancelOrderRequest struct {
+	Reason string `json:"reason" validate:"max=255"`
 }
 
 type CreateOrderRequest struct {
-	OrderItems []OrderItemRequest `json:"orderItems"`
-	UserID     uint               `json:"userId"`
-	Status     string             `json:"status"`
+	OrderItems    []OrderItemRequest `json:"orderItems"`
+	AddressID     uint               `json:"addressId"`
+	PromotionCode string             `json:"promotionCode" validate:"max=50"`
 }
 
 type OrderItemRequest struct {
-	ProductID uint    `json:"productId" validate:"required"`
-	Quantity  int     `json:"quantity" validate:"required"`
-	Price     float64 `json:"price" validate:"required"`
+	ProductID uint  `json:"productId" validate:"required"`
+	VariantID *uint `json:"variantId"`
+	Quantity  int   `json:"quantity" validate:"required,gt=0"`
 }
 
 type OrderItemsRequest struct {
@@ -69,4 +157,94 @@ type MailRequest struct {
 	IsTemplate   bool        `json:"isTemplate"`
 	TemplateCode string      `json:"templateCode"`
 	Attachment   interface{} `json:"attachment"`
+}
+
+type InitiatePaymentRequest struct {
+	Provider string `json:"provider" validate:"max=50"`
+}
+
+type RefundItemRequest struct {
+	OrderItemID uint `json:"orderItemId" validate:"required"`
+	Quantity    int  `json:"quantity" validate:"required,gt=0"`
+}
+
+type CreateRefundRequest struct {
+	Items   []RefundItemRequest `json:"items" validate:"dive"`
+	Restock bool                `json:"restock"`
+	Reason  string              `json:"reason" validate:"max=255"`
+}
+
+type AddCartItemRequest struct {
+	ProductID uint  `json:"productId" validate:"required"`
+	VariantID *uint `json:"variantId"`
+	Quantity  int   `json:"quantity" validate:"required,gt=0"`
+}
+
+type UpdateCartItemRequest struct {
+	Quantity int `json:"quantity" validate:"required,gt=0"`
+}
+
+type ShipOrderRequest struct {
+	ShippingMethod string `json:"shippingMethod" validate:"max=50"`
+	TrackingNumber string `json:"trackingNumber" validate:"max=100"`
+}
+
+type CheckoutRequest struct {
+	AddressID     uint   `json:"addressId"`
+	PromotionCode string `json:"promotionCode" validate:"max=50"`
+}
+
+type CreateAddressRequest struct {
+	RecipientName string `json:"recipientName" validate:"required,max=150"`
+	Phone         string `json:"phone" validate:"required,max=20"`
+	Line1         string `json:"line1" validate:"required,max=255"`
+	Line2         string `json:"line2" validate:"max=255"`
+	Ward          string `json:"ward" validate:"max=100"`
+	District      string `json:"district" validate:"max=100"`
+	City          string `json:"city" validate:"required,max=100"`
+	Country       string `json:"country" validate:"required,len=2"`
+	PostalCode    string `json:"postalCode" validate:"max=20"`
+	IsDefault     bool   `json:"isDefault"`
+}
+
+type UpdateAddressRequest struct {
+	RecipientName string `json:"recipientName" validate:"required,max=150"`
+	Phone         string `json:"phone" validate:"required,max=20"`
+	Line1         string `json:"line1" validate:"required,max=255"`
+	Line2         string `json:"line2" validate:"max=255"`
+	Ward          string `json:"ward" validate:"max=100"`
+	District      string `json:"district" validate:"max=100"`
+	City          string `json:"city" validate:"required,max=100"`
+	Country       string `json:"country" validate:"required,len=2"`
+	PostalCode    string `json:"postalCode" validate:"max=20"`
+}
+
+type PromotionRequest struct {
+	Code          string     `json:"code" validate:"required,max=50"`
+	Description   string     `json:"description" validate:"max=255"`
+	Type          string     `json:"type" validate:"required"`
+	Value         float64    `json:"value" validate:"gte=0"`
+	MaxDiscount   float64    `json:"maxDiscount" validate:"gte=0"`
+	MinOrderValue float64    `json:"minOrderValue" validate:"gte=0"`
+	BuyQuantity   int        `json:"buyQuantity" validate:"gte=0"`
+	GetQuantity   int        `json:"getQuantity" validate:"gte=0"`
+	ProductID     *uint      `json:"productId"`
+	CategoryID    *int64     `json:"categoryId"`
+	StartsAt      *time.Time `json:"startsAt"`
+	EndsAt        *time.Time `json:"endsAt"`
+	UsageLimit    int        `json:"usageLimit" validate:"gte=0"`
+	PerUserLimit  int        `json:"perUserLimit" validate:"gte=0"`
+	Active        bool       `json:"active"`
+}
+
+type TaxClassRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=255"`
+}
+
+type TaxRateRequest struct {
+	Name    string  `json:"name" validate:"max=100"`
+	Country string  `json:"country" validate:"required,len=2"`
+	Region  string  `json:"region" validate:"max=100"`
+	Rate    float64 `json:"rate" validate:"gte=0,lte=100"`
 }
\ No newline at end of file
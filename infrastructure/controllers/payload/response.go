@@ -1,6 +1,9 @@
 package payload
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type AuditTime struct {
 	CreatedAt time.Time `json:"createdAt"`
@@ -8,31 +11,199 @@ type AuditTime struct {
 }
 
 type PaginationResponse struct {
-	Limit         int   `json:"limit"`
-	Page          int   `json:"page"`
-	TotalElements int64 `json:"totalElements"`
-	TotalPages    int   `json:"totalPages"`
+	Limit         int    `json:"limit"`
+	Page          int    `json:"page"`
+	TotalElements int64  `json:"totalElements"`
+	TotalPages    int    `json:"totalPages"`
+	NextCursor    string `json:"nextCursor,omitempty"`
+	PrevCursor    string `json:"prevCursor,omitempty"`
 }
 
 type ProductResponse struct {
-	ID          uint    `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	CategoryID  int64   `json:"categoryId"`
-	Stock       int64   `json:"stock"`
-	Image       string  `json:"imagePath"`
+	ID               uint                     `json:"id"`
+	Name             string                   `json:"name"`
+	Description      string                   `json:"description"`
+	Price            float64                  `json:"price"`
+	CategoryID       int64                    `json:"categoryId"`
+	TaxClassID       *uint                    `json:"taxClassId"`
+	Stock            int64                    `json:"stock"`
+	ReorderThreshold *int64                   `json:"reorderThreshold"`
+	Tags             []string                 `json:"tags"`
+	Image            string                   `json:"imagePath"`
+	Variants         []ProductVariantResponse `json:"variants"`
+	AuditTime
+}
+
+type ProductVariantResponse struct {
+	ID            uint                    `json:"id"`
+	ProductID     uint                    `json:"productId"`
+	SKU           string                  `json:"sku"`
+	Price         float64                 `json:"price"`
+	PriceOverride *float64                `json:"priceOverride"`
+	Stock         int64                   `json:"stock"`
+	Image         string                  `json:"imagePath"`
+	Options       []VariantOptionResponse `json:"options"`
+	AuditTime
+}
+
+type SuggestionResponse struct {
+	Kind  string `json:"kind"`
+	ID    uint   `json:"id"`
+	Label string `json:"label"`
+}
+
+type StockMovementResponse struct {
+	ID        uint      `json:"id"`
+	ProductID uint      `json:"productId"`
+	VariantID *uint     `json:"variantId"`
+	Quantity  int64     `json:"quantity"`
+	Balance   int64     `json:"balance"`
+	Reason    string    `json:"reason"`
+	Reference string    `json:"reference"`
+	ActorID   *uint     `json:"actorId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type StockHistoryResponse struct {
+	ProductID uint                    `json:"productId"`
+	VariantID *uint                   `json:"variantId"`
+	Stock     int64                   `json:"stock"`
+	Ledger    int64                   `json:"ledger"`
+	Movements []StockMovementResponse `json:"movements"`
+	PaginationResponse
+}
+
+type NotificationResponse struct {
+	ID        uint       `json:"id"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	ReadAt    *time.Time `json:"readAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+type ListNotificationResponses struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	PaginationResponse
+}
+
+type WarehouseResponse struct {
+	ID       uint   `json:"id"`
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Address  string `json:"address"`
+	Priority int    `json:"priority"`
 	AuditTime
 }
 
+type WarehouseStockResponse struct {
+	WarehouseID uint      `json:"warehouseId"`
+	ProductID   uint      `json:"productId"`
+	VariantID   *uint     `json:"variantId"`
+	Quantity    int64     `json:"quantity"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type StockTransferResponse struct {
+	ID              uint      `json:"id"`
+	FromWarehouseID uint      `json:"fromWarehouseId"`
+	ToWarehouseID   uint      `json:"toWarehouseId"`
+	ProductID       uint      `json:"productId"`
+	VariantID       *uint     `json:"variantId"`
+	Quantity        int64     `json:"quantity"`
+	ActorID         *uint     `json:"actorId"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+type SupplierResponse struct {
+	ID      uint   `json:"id"`
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Phone   string `json:"phone"`
+	Address string `json:"address"`
+	AuditTime
+}
+
+type ListSupplierResponses struct {
+	Suppliers []SupplierResponse `json:"suppliers"`
+	PaginationResponse
+}
+
+type PurchaseOrderResponse struct {
+	ID          uint                        `json:"id"`
+	Supplier    SupplierResponse            `json:"supplier"`
+	Status      string                      `json:"status"`
+	WarehouseID *uint                       `json:"warehouseId"`
+	Note        string                      `json:"note"`
+	Total       float64                     `json:"total"`
+	CreatedBy   *uint                       `json:"createdBy"`
+	SentAt      *time.Time                  `json:"sentAt"`
+	ReceivedAt  *time.Time                  `json:"receivedAt"`
+	Lines       []PurchaseOrderLineResponse `json:"lines"`
+	AuditTime
+}
+
+type PurchaseOrderLineResponse struct {
+	ID               uint    `json:"id"`
+	ProductID        uint    `json:"productId"`
+	VariantID        *uint   `json:"variantId"`
+	Quantity         int64   `json:"quantity"`
+	ReceivedQuantity int64   `json:"receivedQuantity"`
+	UnitCost         float64 `json:"unitCost"`
+}
+
+type ListPurchaseOrderResponses struct {
+	PurchaseOrders []PurchaseOrderResponse `json:"purchaseOrders"`
+	PaginationResponse
+}
+
+type PickingListResponse struct {
+	Warehouse WarehouseResponse     `json:"warehouse"`
+	Lines     []PickingLineResponse `json:"lines"`
+}
+
+type PickingLineResponse struct {
+	OrderItemID uint   `json:"orderItemId"`
+	ProductID   uint   `json:"productId"`
+	VariantID   *uint  `json:"variantId"`
+	Name        string `json:"name"`
+	SKU         string `json:"sku"`
+	Quantity    int    `json:"quantity"`
+}
+
+type VariantOptionResponse struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
 type ListProductResponses struct {
-	Products []ProductResponse `json:"products"`
+	Products []ProductResponse      `json:"products"`
+	Facets   *ProductFacetsResponse `json:"facets,omitempty"`
 	PaginationResponse
 }
 
+type ProductFacetsResponse struct {
+	Categories   []FacetCountResponse     `json:"categories"`
+	PriceBuckets []FacetCountResponse     `json:"priceBuckets"`
+	Attributes   []AttributeFacetResponse `json:"attributes"`
+	InStock      int64                    `json:"inStock"`
+}
+
+type FacetCountResponse struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int64  `json:"count"`
+}
+
+type AttributeFacetResponse struct {
+	Name   string               `json:"name"`
+	Values []FacetCountResponse `json:"values"`
+}
+
 type CategoryResponse struct {
-	ID   uint   `json:"id"`
-	Name string `json:"name"`
+	ID         uint   `json:"id"`
+	Name       string `json:"name"`
+	TaxClassID *uint  `json:"taxClassId"`
 	AuditTime
 }
 
@@ -60,19 +231,36 @@ type AuthResponse struct {
 }
 
 type OrderResponse struct {
-	ID         int64               `json:"id"`
-	UserID     int64               `json:"userId"`
-	Status     string              `json:"status"`
-	OrderItems []OrderItemResponse `json:"orderItems"`
-	Total      float64             `json:"total"`
+	ID            int64               `json:"id"`
+	UserID        int64               `json:"userId"`
+	Status        string              `json:"status"`
+	OrderItems    []OrderItemResponse `json:"orderItems"`
+	Subtotal      float64             `json:"subtotal"`
+	DiscountTotal float64             `json:"discountTotal"`
+	TaxTotal      float64             `json:"taxTotal"`
+	GrandTotal    float64             `json:"grandTotal"`
+	Total         float64             `json:"total"`
+	ShippingFee   float64             `json:"shippingFee"`
+	PromotionCode string              `json:"promotionCode"`
+
+	ShippingAddress ShippingAddressResponse `json:"shippingAddress"`
+	ShippingMethod  string                  `json:"shippingMethod"`
+	TrackingNumber  string                  `json:"trackingNumber"`
+	ShippedAt       *time.Time              `json:"shippedAt"`
+	DeliveredAt     *time.Time              `json:"deliveredAt"`
 	AuditTime
 }
 
 type OrderItemResponse struct {
-	ID        int64   `json:"id"`
-	ProductID int64   `json:"productId"`
-	Quantity  int     `json:"quantity"`
-	Price     float64 `json:"price"`
+	ID          int64   `json:"id"`
+	ProductID   int64   `json:"productId"`
+	VariantID   *uint   `json:"variantId"`
+	Quantity    int     `json:"quantity"`
+	Price       float64 `json:"price"`
+	TaxRate     float64 `json:"taxRate"`
+	TaxAmount   float64 `json:"taxAmount"`
+	NetAmount   float64 `json:"netAmount"`
+	GrossAmount float64 `json:"grossAmount"`
 	AuditTime
 }
 
@@ -84,4 +272,144 @@ type ListOrderResponses struct {
 type ListOrderItemResponses struct {
 	Orders []OrderItemResponse `json:"orderItems"`
 	PaginationResponse
+}
+
+type PaymentResponse struct {
+	ID            int64      `json:"id"`
+	OrderID       int64      `json:"orderId"`
+	Provider      string     `json:"provider"`
+	ProviderRef   string     `json:"providerRef"`
+	Amount        float64    `json:"amount"`
+	Currency      string     `json:"currency"`
+	Status        string     `json:"status"`
+	FailureReason string     `json:"failureReason,omitempty"`
+	RedirectURL   string     `json:"redirectUrl,omitempty"`
+	CapturedAt    *time.Time `json:"capturedAt"`
+	AuditTime
+}
+
+type InvoiceResponse struct {
+	ID         int64      `json:"id"`
+	OrderID    int64      `json:"orderId"`
+	Number     string     `json:"number"`
+	IssuedAt   time.Time  `json:"issuedAt"`
+	Currency   string     `json:"currency"`
+	GrandTotal float64    `json:"grandTotal"`
+	FileURL    string     `json:"fileUrl"`
+	EmailedAt  *time.Time `json:"emailedAt"`
+	AuditTime
+}
+
+type RefundItemResponse struct {
+	ID          int64   `json:"id"`
+	OrderItemID int64   `json:"orderItemId"`
+	ProductID   int64   `json:"productId"`
+	Quantity    int     `json:"quantity"`
+	Amount      float64 `json:"amount"`
+}
+
+type RefundResponse struct {
+	ID            int64                `json:"id"`
+	OrderID       int64                `json:"orderId"`
+	PaymentID     int64                `json:"paymentId"`
+	Amount        float64              `json:"amount"`
+	Reason        string               `json:"reason"`
+	Restock       bool                 `json:"restock"`
+	Status        string               `json:"status"`
+	ProviderRef   string               `json:"providerRef"`
+	FailureReason string               `json:"failureReason"`
+	CreatedBy     int64                `json:"createdBy"`
+	RefundItems   []RefundItemResponse `json:"refundItems"`
+	AuditTime
+}
+
+type OrderEventResponse struct {
+	ID        int64           `json:"id"`
+	OrderID   int64           `json:"orderId"`
+	ActorID   *int64          `json:"actorId"`
+	Type      string          `json:"type"`
+	Diff      json.RawMessage `json:"diff" swaggertype:"object"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+type CartItemResponse struct {
+	ProductID int64   `json:"productId"`
+	VariantID *uint   `json:"variantId"`
+	Name      string  `json:"name"`
+	Variant   string  `json:"variant"`
+	Image     string  `json:"image"`
+	Price     float64 `json:"price"`
+	Quantity  int     `json:"quantity"`
+	Total     float64 `json:"total"`
+	Stock     int64   `json:"stock"`
+	Available bool    `json:"available"`
+	InStock   bool    `json:"inStock"`
+}
+
+type CartResponse struct {
+	Items       []CartItemResponse `json:"items"`
+	Subtotal    float64            `json:"subtotal"`
+	CanCheckout bool               `json:"canCheckout"`
+}
+
+type ShippingAddressResponse struct {
+	RecipientName string `json:"recipientName"`
+	Phone         string `json:"phone"`
+	Line1         string `json:"line1"`
+	Line2         string `json:"line2"`
+	Ward          string `json:"ward"`
+	District      string `json:"district"`
+	City          string `json:"city"`
+	Country       string `json:"country"`
+	PostalCode    string `json:"postalCode"`
+}
+
+type AddressResponse struct {
+	ID        int64 `json:"id"`
+	IsDefault bool  `json:"isDefault"`
+	ShippingAddressResponse
+	AuditTime
+}
+
+type PromotionResponse struct {
+	ID            int64      `json:"id"`
+	Code          string     `json:"code"`
+	Description   string     `json:"description"`
+	Type          string     `json:"type"`
+	Value         float64    `json:"value"`
+	MaxDiscount   float64    `json:"maxDiscount"`
+	MinOrderValue float64    `json:"minOrderValue"`
+	BuyQuantity   int        `json:"buyQuantity"`
+	GetQuantity   int        `json:"getQuantity"`
+	ProductID     *uint      `json:"productId"`
+	CategoryID    *int64     `json:"categoryId"`
+	StartsAt      *time.Time `json:"startsAt"`
+	EndsAt        *time.Time `json:"endsAt"`
+	UsageLimit    int        `json:"usageLimit"`
+	PerUserLimit  int        `json:"perUserLimit"`
+	UsedCount     int        `json:"usedCount"`
+	Active        bool       `json:"active"`
+	AuditTime
+}
+
+type ListPromotionResponses struct {
+	Promotions []PromotionResponse `json:"promotions"`
+	PaginationResponse
+}
+
+type TaxClassResponse struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	AuditTime
+}
+
+type TaxRateResponse struct {
+	ID         int64   `json:"id"`
+	TaxClassID int64   `json:"taxClassId"`
+	Name       string  `json:"name"`
+	Country    string  `json:"country"`
+	Region     string  `json:"region"`
+	Rate       float64 `json:"rate"`
+	AuditTime
 }
\ No newline at end of file
@@ -0,0 +1,119 @@
+package addresses
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"pm/domain/entity"
+	"pm/domain/repository/addresses"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/persistences/base"
+)
+
+const entityName = "addresses"
+
+type AddressRepository struct {
+	c  *gin.Context
+	p  *base.Persistence
+	db *gorm.DB
+}
+
+func NewAddressRepository(c *gin.Context, p *base.Persistence, db *gorm.DB) addresses.AddressRepository {
+	return AddressRepository{c, p, db}
+}
+
+func (a AddressRepository) Create(address *entity.Address) error {
+	span := a.p.Logger.Start(a.c, "CREATE_ADDRESS_DATABASE")
+	defer span.End()
+	a.p.Logger.Info("STARTING: CREATE ADDRESS", map[string]interface{}{"address": address})
+
+	if err := a.db.Create(address).Error; err != nil {
+		a.p.Logger.Error("CREATE_ADDRESS: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+// Update saves the fields of the address but never its default flag, which only SetDefault changes
+func (a AddressRepository) Update(address *entity.Address) error {
+	span := a.p.Logger.Start(a.c, "UPDATE_ADDRESS_DATABASE")
+	defer span.End()
+	a.p.Logger.Info("STARTING: UPDATE ADDRESS", map[string]interface{}{"address": address})
+
+	err := a.db.Model(address).
+		Select("RecipientName", "Phone", "Line1", "Line2", "Ward", "District", "City", "Country", "PostalCode").
+		Updates(address).Error
+	if err != nil {
+		a.p.Logger.Error("UPDATE_ADDRESS: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+func (a AddressRepository) GetAddressByID(id int64) (*entity.Address, error) {
+	var address entity.Address
+	if err := a.db.First(&address, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payload.ErrEntityNotFound(entityName, err)
+		}
+		return nil, payload.ErrDB(err)
+	}
+	return &address, nil
+}
+
+// GetAddressesByUserID returns the address book of the user, the default address first
+func (a AddressRepository) GetAddressesByUserID(userID int64) ([]entity.Address, error) {
+	listAddresses := make([]entity.Address, 0)
+	err := a.db.Where("user_id = ?", userID).
+		Order("is_default desc, id asc").
+		Find(&listAddresses).Error
+	if err != nil {
+		a.p.Logger.Error("GET_ADDRESSES_BY_USER_ID: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrDB(err)
+	}
+	return listAddresses, nil
+}
+
+func (a AddressRepository) GetDefaultAddress(userID int64) (*entity.Address, error) {
+	var address entity.Address
+	if err := a.db.Where("user_id = ? AND is_default = ?", userID, true).First(&address).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payload.ErrEntityNotFound(entityName, err)
+		}
+		return nil, payload.ErrDB(err)
+	}
+	return &address, nil
+}
+
+func (a AddressRepository) DeleteAddress(address *entity.Address) error {
+	span := a.p.Logger.Start(a.c, "DELETE_ADDRESS_DATABASE")
+	defer span.End()
+
+	if err := a.db.Delete(address).Error; err != nil {
+		a.p.Logger.Error("DELETE_ADDRESS: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+// SetDefault makes the address the default one of its user and clears the flag of the previous default address
+func (a AddressRepository) SetDefault(address *entity.Address) error {
+	span := a.p.Logger.Start(a.c, "SET_DEFAULT_ADDRESS_DATABASE")
+	defer span.End()
+
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&entity.Address{}).
+			Where("user_id = ? AND is_default = ? AND id <> ?", address.UserID, true, address.ID).
+			Update("is_default", false).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(address).Update("is_default", true).Error
+	})
+	if err != nil {
+		a.p.Logger.Error("SET_DEFAULT_ADDRESS: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	address.IsDefault = true
+	return nil
+}
\ No newline at end of file
@@ -37,6 +37,14 @@ func (redisDriver *RedisCacheRepository) GetHash(key string, property string, sr
 	}
 }
 
+// GetAllHash returns every property of the hash, unlike GetHash it tells a missing hash apart from an unreachable redis
+func (redisDriver *RedisCacheRepository) GetAllHash(key string) (map[string]string, error) {
+	if redisDriver.rdb == nil {
+		return nil, fmt.Errorf("RedisDriver not found")
+	}
+	return redisDriver.rdb.HGetAll(redisDriver.ctx, key).Result()
+}
+
 func (redisDriver *RedisCacheRepository) DeleteHash(key string, property string) error {
 	if redisDriver.rdb == nil {
 		return fmt.Errorf("RedisDriver not found")
@@ -60,4 +68,11 @@ func (redisDriver *RedisCacheRepository) SetExpireKey(key string, expiration tim
 		return fmt.Errorf("RedisDriver not found")
 	}
 	return redisDriver.rdb.Expire(redisDriver.ctx, key, expiration).Err()
+}
+
+func (redisDriver *RedisCacheRepository) DeleteKey(key string) error {
+	if redisDriver.rdb == nil {
+		return fmt.Errorf("RedisDriver not found")
+	}
+	return redisDriver.rdb.Del(redisDriver.ctx, key).Err()
 }
\ No newline at end of file
@@ -0,0 +1,197 @@
+package carts
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"pm/domain/entity"
+	"pm/domain/repository/caches"
+	"pm/domain/repository/carts"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/implementations/cache"
+	"pm/infrastructure/persistences/base"
+	"sort"
+	"strconv"
+)
+
+const cartHashKey = "cart"
+
+// CartRepository writes every change of a cart through to the database and to a redis hash per user, carts are
+// read from redis and only fall back to the database when redis is down or has lost the cart
+type CartRepository struct {
+	c     *gin.Context
+	p     *base.Persistence
+	db    *gorm.DB
+	cache caches.RedisCacheRepository
+}
+
+func NewCartRepository(c *gin.Context, p *base.Persistence, db *gorm.DB) carts.CartRepository {
+	var redisCache caches.RedisCacheRepository
+	if p.Redis.RedisDB != nil {
+		redisCache = cache.NewRedisCacheRepository(p.Redis.RedisDB, p.Ctx)
+	}
+	return CartRepository{c, p, db, redisCache}
+}
+
+func (cr CartRepository) GetCartItems(userID int64) ([]entity.CartItem, error) {
+	span := cr.p.Logger.Start(cr.c, "GET_CART_ITEMS_DATABASE")
+	defer span.End()
+
+	if cr.cache != nil {
+		cached, err := cr.cache.GetAllHash(cartKey(userID))
+		if err == nil && len(cached) > 0 {
+			items := make([]entity.CartItem, 0)
+			for _, v := range cached {
+				var item entity.CartItem
+				if err := json.Unmarshal([]byte(v), &item); err != nil {
+					cr.p.Logger.Error("GET_CART_ITEMS: ERROR DECODE CACHED ITEM", map[string]interface{}{"error": err.Error()})
+					continue
+				}
+				items = append(items, item)
+			}
+			sortCartItems(items)
+			return items, nil
+		}
+		if err != nil {
+			cr.p.Logger.Error("GET_CART_ITEMS: ERROR REDIS, FALLING BACK TO DATABASE", map[string]interface{}{"error": err.Error()})
+		}
+	}
+
+	items := make([]entity.CartItem, 0)
+	if err := cr.db.Where("user_id = ?", userID).Find(&items).Error; err != nil {
+		cr.p.Logger.Error("GET_CART_ITEMS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrDB(err)
+	}
+	sortCartItems(items)
+	cr.cacheCartItems(userID, items...)
+	return items, nil
+}
+
+// SetCartItem saves the quantity of the product, or of its variant, in the cart, adding the line when the cart does
+// not have it yet
+func (cr CartRepository) SetCartItem(item *entity.CartItem) error {
+	span := cr.p.Logger.Start(cr.c, "SET_CART_ITEM_DATABASE")
+	defer span.End()
+	cr.p.Logger.Info("STARTING: SET CART ITEM", map[string]interface{}{"cart_item": item})
+
+	conflict := clause.OnConflict{
+		Columns:     []clause.Column{{Name: "user_id"}, {Name: "product_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "variant_id IS NULL"}}},
+		DoUpdates:   clause.AssignmentColumns([]string{"quantity", "updated_at"}),
+	}
+	if item.VariantID != nil {
+		conflict.Columns = []clause.Column{{Name: "user_id"}, {Name: "product_id"}, {Name: "variant_id"}}
+		conflict.TargetWhere = clause.Where{}
+	}
+	// the returning clause reads the saved line back, so an updated line keeps the id and creation time it had
+	err := cr.db.Clauses(conflict, clause.Returning{}).Create(item).Error
+	if err != nil {
+		cr.p.Logger.Error("SET_CART_ITEM: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+
+	// the whole cart is written again, writing only this line to a hash that expired would cache a partial cart
+	items := make([]entity.CartItem, 0)
+	if err := cr.db.Where("user_id = ?", item.UserID).Find(&items).Error; err != nil {
+		cr.invalidate(int64(item.UserID), err)
+		return nil
+	}
+	cr.cacheCartItems(int64(item.UserID), items...)
+	return nil
+}
+
+// RemoveCartItem removes the line of the product, or of the given variant of it, from the cart
+func (cr CartRepository) RemoveCartItem(userID int64, productID int64, variantID *uint) error {
+	span := cr.p.Logger.Start(cr.c, "REMOVE_CART_ITEM_DATABASE")
+	defer span.End()
+
+	db := cr.db.Unscoped().Where("user_id = ? AND product_id = ?", userID, productID)
+	if variantID != nil {
+		db = db.Where("variant_id = ?", *variantID)
+	} else {
+		db = db.Where("variant_id IS NULL")
+	}
+	// lines are deleted for good, a soft deleted line would block adding the product again
+	if err := db.Delete(&entity.CartItem{}).Error; err != nil {
+		cr.p.Logger.Error("REMOVE_CART_ITEM: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+
+	if cr.cache != nil {
+		if err := cr.cache.DeleteHash(cartKey(userID), cartItemField(uint(productID), variantID)); err != nil {
+			cr.invalidate(userID, err)
+		}
+	}
+	return nil
+}
+
+func (cr CartRepository) ClearCart(userID int64) error {
+	span := cr.p.Logger.Start(cr.c, "CLEAR_CART_DATABASE")
+	defer span.End()
+
+	if err := cr.db.Unscoped().Where("user_id = ?", userID).Delete(&entity.CartItem{}).Error; err != nil {
+		cr.p.Logger.Error("CLEAR_CART: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+
+	if cr.cache != nil {
+		if err := cr.cache.DeleteKey(cartKey(userID)); err != nil {
+			cr.p.Logger.Error("CLEAR_CART: ERROR REDIS", map[string]interface{}{"error": err.Error()})
+		}
+	}
+	return nil
+}
+
+// cacheCartItems copies the lines to redis, the database already has them so a failure only costs a cache miss
+func (cr CartRepository) cacheCartItems(userID int64, items ...entity.CartItem) {
+	if cr.cache == nil || len(items) == 0 {
+		return
+	}
+	key := cartKey(userID)
+	for _, item := range items {
+		value, err := json.Marshal(item)
+		if err != nil {
+			cr.invalidate(userID, err)
+			return
+		}
+		if err := cr.cache.SetHashObject(key, cartItemField(item.ProductID, item.VariantID), value); err != nil {
+			cr.invalidate(userID, err)
+			return
+		}
+	}
+	if cr.p.Redis.KeyExpirationTime > 0 {
+		if err := cr.cache.SetExpireKey(key, cr.p.Redis.KeyExpirationTime); err != nil {
+			cr.p.Logger.Error("CACHE_CART: ERROR SET EXPIRATION", map[string]interface{}{"error": err.Error()})
+		}
+	}
+}
+
+// invalidate drops the cached cart after a failed write, so the next read reloads it from the database
+// instead of serving a cart that misses the change
+func (cr CartRepository) invalidate(userID int64, cause error) {
+	cr.p.Logger.Error("CACHE_CART: ERROR", map[string]interface{}{"error": cause.Error()})
+	if err := cr.cache.DeleteKey(cartKey(userID)); err != nil {
+		cr.p.Logger.Error("CACHE_CART: ERROR INVALIDATE", map[string]interface{}{"error": err.Error()})
+	}
+}
+
+func cartKey(userID int64) string {
+	return fmt.Sprintf("%s:%d", cartHashKey, userID)
+}
+
+// cartItemField names the line of a product in the hash of the cart, lines of variants are named after both ids
+func cartItemField(productID uint, variantID *uint) string {
+	if variantID == nil {
+		return strconv.FormatUint(uint64(productID), 10)
+	}
+	return fmt.Sprintf("%d:%d", productID, *variantID)
+}
+
+// sortCartItems keeps the lines in the order they were added, redis hashes have no order
+func sortCartItems(items []entity.CartItem) {
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+}
\ No newline at end of file
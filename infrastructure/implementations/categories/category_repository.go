@@ -10,6 +10,7 @@ import (
 	"pm/domain/repository/categories"
 	"pm/infrastructure/controllers/payload"
 	"pm/infrastructure/persistences/base"
+	"pm/utils"
 )
 
 type CategoryRepository struct {
@@ -91,16 +92,26 @@ func (c CategoryRepository) GetAllCategories(parentSpan trace.Span, filter *enti
 	categories := make([]entity.Category, 0)
 	var totalRows int64
 	db := c.db
-	db = db.Model(&entity.Category{}).Debug().Count(&totalRows)
+	db = db.Model(&entity.Category{}).Debug()
 	if filter != nil {
-		db = db.Scopes(applyFilter(filter)).Count(&totalRows)
+		db = db.Scopes(applyFilter(filter))
 	}
-	if err := db.Scopes(paginate(pagination)).Find(&categories).Error; err != nil {
+	// a list paged from a cursor is not counted, counting is what gets slow on large tables
+	if !pagination.IsCursor() {
+		db = db.Count(&totalRows)
+	}
+	if err := db.Scopes(utils.Paginate(pagination, entity.CategorySortColumns)).Find(&categories).Error; err != nil {
 		c.p.Logger.Error("GET_ALL_CATEGORIES_FAILED", map[string]interface{}{"message": err.Error()})
 		return nil, payload.ErrDB(err)
 	}
-	pagination.TotalRows = totalRows
-	pagination.TotalPages = int(math.Ceil(float64(totalRows) / float64(pagination.Limit)))
+	if !pagination.IsCursor() {
+		pagination.TotalRows = totalRows
+		pagination.TotalPages = int(math.Ceil(float64(totalRows) / float64(pagination.GetLimit())))
+	}
+	if err := utils.SetCursors(c.db, pagination, entity.CategorySortColumns, &categories); err != nil {
+		c.p.Logger.Error("GET_ALL_CATEGORIES_FAILED", map[string]interface{}{"message": err.Error()})
+		return nil, payload.ErrInternal(err)
+	}
 	c.p.Logger.Info("GET_ALL_CATEGORIES_SUCCESSFULLY", map[string]interface{}{"raw_data": categories, "pagination": pagination})
 	return categories, nil
 }
@@ -124,12 +135,6 @@ func (c CategoryRepository) DeleteCategory(parentSpan trace.Span, category *enti
 	return nil
 }
 
-func paginate(pagination *entity.Pagination) func(db *gorm.DB) *gorm.DB {
-	return func(db *gorm.DB) *gorm.DB {
-		return db.Offset(pagination.GetOffset()).Limit(pagination.GetLimit()).Order(pagination.GetSort())
-	}
-}
-
 func applyFilter(filter *entity.CategoryFilter) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
 		return db.Scopes(
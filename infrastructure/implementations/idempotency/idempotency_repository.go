@@ -0,0 +1,176 @@
+package idempotency
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"pm/domain/entity"
+	"pm/domain/repository/idempotency"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/persistences/base"
+	"time"
+)
+
+const (
+	redisKeyPrefix = "idempotency"
+)
+
+type IdempotencyRepository struct {
+	db *gorm.DB
+	p  *base.Persistence
+	c  *gin.Context
+}
+
+func NewIdempotencyRepository(c *gin.Context, p *base.Persistence, db *gorm.DB) idempotency.IdempotencyRepository {
+	return IdempotencyRepository{db, p, c}
+}
+
+// GetByKey looks the key up on redis first and falls back to the database. It returns nil without an error when
+// the user has not used the key yet or when the key has expired
+func (i IdempotencyRepository) GetByKey(parentSpan trace.Span, userID int64, key string) (*entity.IdempotencyKey, error) {
+	span := i.p.Logger.Start(i.c, "GET_IDEMPOTENCY_KEY: DATABASE", i.p.Logger.UseGivenSpan(parentSpan))
+	defer span.End()
+
+	if record := i.getFromRedis(userID, key); record != nil && !record.IsExpired() {
+		return record, nil
+	}
+
+	var record entity.IdempotencyKey
+	if err := i.db.Where("user_id = ? AND key = ?", userID, key).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		i.p.Logger.Error("GET_IDEMPOTENCY_KEY: ERROR", map[string]interface{}{"error": err.Error()}, i.p.Logger.UseGivenSpan(span))
+		return nil, payload.ErrDB(err)
+	}
+	if record.IsExpired() {
+		if err := i.Release(span, &record); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	if record.Status == entity.IdempotencyStatusCompleted {
+		i.setToRedis(&record)
+	}
+	return &record, nil
+}
+
+// Reserve inserts the key as in progress. It reports false without an error when another request holds the key already
+func (i IdempotencyRepository) Reserve(parentSpan trace.Span, record *entity.IdempotencyKey) (bool, error) {
+	span := i.p.Logger.Start(i.c, "RESERVE_IDEMPOTENCY_KEY: DATABASE", i.p.Logger.UseGivenSpan(parentSpan))
+	defer span.End()
+
+	result := i.db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if err := result.Error; err != nil {
+		i.p.Logger.Error("RESERVE_IDEMPOTENCY_KEY: ERROR", map[string]interface{}{"error": err.Error()}, i.p.Logger.UseGivenSpan(span))
+		return false, payload.ErrDB(err)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Complete stores the response of the request holding the key so every retry replays it
+func (i IdempotencyRepository) Complete(parentSpan trace.Span, record *entity.IdempotencyKey) error {
+	span := i.p.Logger.Start(i.c, "COMPLETE_IDEMPOTENCY_KEY: DATABASE", i.p.Logger.UseGivenSpan(parentSpan))
+	defer span.End()
+
+	record.Status = entity.IdempotencyStatusCompleted
+	err := i.db.Model(record).
+		Select("Status", "ResponseCode", "ContentType", "ResponseBody").
+		Updates(record).Error
+	if err != nil {
+		i.p.Logger.Error("COMPLETE_IDEMPOTENCY_KEY: ERROR", map[string]interface{}{"error": err.Error()}, i.p.Logger.UseGivenSpan(span))
+		return payload.ErrDB(err)
+	}
+
+	i.setToRedis(record)
+	return nil
+}
+
+// Release removes the key so the request can be sent again with it, it is used when the request did not succeed
+func (i IdempotencyRepository) Release(parentSpan trace.Span, record *entity.IdempotencyKey) error {
+	span := i.p.Logger.Start(i.c, "RELEASE_IDEMPOTENCY_KEY: DATABASE", i.p.Logger.UseGivenSpan(parentSpan))
+	defer span.End()
+
+	if err := i.db.Unscoped().Delete(record).Error; err != nil {
+		i.p.Logger.Error("RELEASE_IDEMPOTENCY_KEY: ERROR", map[string]interface{}{"error": err.Error()}, i.p.Logger.UseGivenSpan(span))
+		return payload.ErrDB(err)
+	}
+	if rdb := i.p.Redis.RedisDB; rdb != nil {
+		rdb.Del(i.p.Ctx, redisKey(int64(record.UserID), record.Key))
+	}
+	return nil
+}
+
+// Extend refreshes the lock the request holding the key has on it, as long as the key is still in progress
+func (i IdempotencyRepository) Extend(parentSpan trace.Span, record *entity.IdempotencyKey) error {
+	span := i.p.Logger.Start(i.c, "EXTEND_IDEMPOTENCY_KEY: DATABASE", i.p.Logger.UseGivenSpan(parentSpan))
+	defer span.End()
+
+	err := i.db.Model(record).
+		Where("status = ?", entity.IdempotencyStatusInProgress).
+		Update("updated_at", time.Now()).Error
+	if err != nil {
+		i.p.Logger.Error("EXTEND_IDEMPOTENCY_KEY: ERROR", map[string]interface{}{"error": err.Error()}, i.p.Logger.UseGivenSpan(span))
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+// ReleaseStale removes the key only while it is still in progress and its lock was last refreshed before staleBefore,
+// which means the request holding it is gone. It reports whether the key was removed
+func (i IdempotencyRepository) ReleaseStale(parentSpan trace.Span, record *entity.IdempotencyKey, staleBefore time.Time) (bool, error) {
+	span := i.p.Logger.Start(i.c, "RELEASE_STALE_IDEMPOTENCY_KEY: DATABASE", i.p.Logger.UseGivenSpan(parentSpan))
+	defer span.End()
+
+	result := i.db.Unscoped().
+		Where("status = ? AND updated_at < ?", entity.IdempotencyStatusInProgress, staleBefore).
+		Delete(record)
+	if err := result.Error; err != nil {
+		i.p.Logger.Error("RELEASE_STALE_IDEMPOTENCY_KEY: ERROR", map[string]interface{}{"error": err.Error()}, i.p.Logger.UseGivenSpan(span))
+		return false, payload.ErrDB(err)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (i IdempotencyRepository) getFromRedis(userID int64, key string) *entity.IdempotencyKey {
+	rdb := i.p.Redis.RedisDB
+	if rdb == nil {
+		return nil
+	}
+	val, err := rdb.Get(i.p.Ctx, redisKey(userID, key)).Result()
+	if err != nil {
+		return nil
+	}
+	var record entity.IdempotencyKey
+	if err := json.Unmarshal([]byte(val), &record); err != nil {
+		return nil
+	}
+	return &record
+}
+
+// setToRedis caches a completed key until it expires, the database stays the source of truth when redis is down
+func (i IdempotencyRepository) setToRedis(record *entity.IdempotencyKey) {
+	rdb := i.p.Redis.RedisDB
+	if rdb == nil {
+		return
+	}
+	ttl := time.Until(record.ExpiresAt)
+	if ttl <= 0 {
+		return
+	}
+	val, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	if err := rdb.Set(i.p.Ctx, redisKey(int64(record.UserID), record.Key), val, ttl).Err(); err != nil {
+		i.p.Logger.Error("CACHE_IDEMPOTENCY_KEY: ERROR", map[string]interface{}{"error": err.Error()})
+	}
+}
+
+func redisKey(userID int64, key string) string {
+	return fmt.Sprintf("%s:%d:%s", redisKeyPrefix, userID, key)
+}
\ No newline at end of file
@@ -0,0 +1,68 @@
+package invoices
+
+import (
+	"bytes"
+	"html/template"
+	"pm/domain/entity"
+	"strconv"
+)
+
+const ContentType = "text/html; charset=utf-8"
+
+var invoiceTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"money": func(v float64) string {
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	},
+	"percent": func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64) + "%"
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Invoice {{.Number}}</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; }
+td.amount, th.amount { text-align: right; }
+</style>
+</head>
+<body>
+<h1>Invoice {{.Number}}</h1>
+<p>Issued {{.IssuedAt.Format "2006-01-02"}} for order #{{.OrderID}}</p>
+<p>
+<strong>{{.SellerName}}</strong><br>
+{{if .SellerAddress}}{{.SellerAddress}}<br>{{end}}
+{{if .SellerTaxCode}}Tax code: {{.SellerTaxCode}}{{end}}
+</p>
+<p>
+<strong>Bill to</strong><br>
+{{.BillingAddress.RecipientName}}<br>
+{{.BillingAddress.Line1}}{{if .BillingAddress.Line2}}, {{.BillingAddress.Line2}}{{end}}<br>
+{{.BillingAddress.City}} {{.BillingAddress.PostalCode}}, {{.BillingAddress.Country}}
+</p>
+<table>
+<tr><th>Item</th><th class="amount">Quantity</th><th class="amount">Unit price</th><th class="amount">Net</th><th class="amount">Tax rate</th><th class="amount">Tax</th><th class="amount">Total</th></tr>
+{{range .Lines}}<tr><td>{{.Description}}{{if .SKU}}<br>SKU {{.SKU}}{{end}}</td><td class="amount">{{.Quantity}}</td><td class="amount">{{money .UnitPrice}}</td><td class="amount">{{money .NetAmount}}</td><td class="amount">{{percent .TaxRate}}</td><td class="amount">{{money .TaxAmount}}</td><td class="amount">{{money .GrossAmount}}</td></tr>
+{{end}}</table>
+<table>
+<tr><td>Subtotal</td><td class="amount">{{money .Subtotal}} {{.Currency}}</td></tr>
+<tr><td>Shipping</td><td class="amount">{{money .ShippingFee}} {{.Currency}}</td></tr>
+{{if .DiscountTotal}}<tr><td>Discount{{if .PromotionCode}} ({{.PromotionCode}}){{end}}</td><td class="amount">-{{money .DiscountTotal}} {{.Currency}}</td></tr>
+{{end}}<tr><td>Tax</td><td class="amount">{{money .TaxTotal}} {{.Currency}}</td></tr>
+<tr><th>Total</th><th class="amount">{{money .GrandTotal}} {{.Currency}}</th></tr>
+</table>
+</body>
+</html>
+`))
+
+// RenderInvoice renders the document of the invoice. It only reads what the invoice copied when it was issued,
+// so the same invoice always renders the same document
+func RenderInvoice(invoice *entity.Invoice) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := invoiceTemplate.Execute(&buf, invoice); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
\ No newline at end of file
@@ -0,0 +1,91 @@
+package invoices
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"pm/domain/entity"
+	"pm/domain/repository/invoices"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/persistences/base"
+)
+
+const entityName = "invoices"
+
+type InvoiceRepository struct {
+	c  *gin.Context
+	p  *base.Persistence
+	db *gorm.DB
+}
+
+func NewInvoiceRepository(c *gin.Context, p *base.Persistence, db *gorm.DB) invoices.InvoiceRepository {
+	return InvoiceRepository{c, p, db}
+}
+
+// NextNumber takes the next number of the series. The sequence row stays locked until the surrounding transaction
+// ends, so it must be called in the transaction that saves the invoice: a rolled back invoice gives its number back
+// and the series never has a gap
+func (ir InvoiceRepository) NextNumber(series string) (int64, error) {
+	span := ir.p.Logger.Start(ir.c, "NEXT_INVOICE_NUMBER_DATABASE")
+	defer span.End()
+
+	sequence := entity.InvoiceSequence{Series: series}
+	if err := ir.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&sequence).Error; err != nil {
+		ir.p.Logger.Error("NEXT_INVOICE_NUMBER: ERROR", map[string]interface{}{"error": err.Error()})
+		return 0, payload.ErrDB(err)
+	}
+	if err := ir.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&sequence, "series = ?", series).Error; err != nil {
+		ir.p.Logger.Error("NEXT_INVOICE_NUMBER: ERROR", map[string]interface{}{"error": err.Error()})
+		return 0, payload.ErrDB(err)
+	}
+	sequence.LastNumber++
+	err := ir.db.Model(&entity.InvoiceSequence{}).
+		Where("series = ?", series).
+		Update("last_number", sequence.LastNumber).Error
+	if err != nil {
+		ir.p.Logger.Error("NEXT_INVOICE_NUMBER: ERROR", map[string]interface{}{"error": err.Error()})
+		return 0, payload.ErrDB(err)
+	}
+	return sequence.LastNumber, nil
+}
+
+func (ir InvoiceRepository) Create(invoice *entity.Invoice) error {
+	span := ir.p.Logger.Start(ir.c, "CREATE_INVOICE_DATABASE")
+	defer span.End()
+	ir.p.Logger.Info("STARTING: CREATE INVOICE", map[string]interface{}{"order_id": invoice.OrderID, "number": invoice.Number})
+
+	if err := ir.db.Create(invoice).Error; err != nil {
+		ir.p.Logger.Error("CREATE_INVOICE: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+func (ir InvoiceRepository) GetInvoiceByOrderID(orderID int64) (*entity.Invoice, error) {
+	var invoice entity.Invoice
+	err := ir.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("order_id = ?", orderID).First(&invoice).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payload.ErrEntityNotFound(entityName, fmt.Errorf("order [%d] has no invoice", orderID))
+		}
+		return nil, payload.ErrDB(err)
+	}
+	return &invoice, nil
+}
+
+// UpdateDelivery saves where the document of the invoice was stored and when it was emailed, the invoice itself
+// never changes once issued
+func (ir InvoiceRepository) UpdateDelivery(invoice *entity.Invoice) error {
+	err := ir.db.Model(invoice).
+		Select("FileURL", "EmailedAt").
+		Updates(invoice).Error
+	if err != nil {
+		ir.p.Logger.Error("UPDATE_INVOICE_DELIVERY: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
\ No newline at end of file
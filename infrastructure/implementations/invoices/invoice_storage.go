@@ -0,0 +1,53 @@
+package invoices
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"pm/domain/repository/invoices"
+	"pm/infrastructure/config"
+	"pm/utils"
+)
+
+const (
+	StorageLocal    = "local"
+	StorageSupabase = "supabase"
+)
+
+// NewInvoiceStorage returns the storage configured for the invoices, new storages only have to be added here
+func NewInvoiceStorage(cfg config.InvoiceConfig) (invoices.InvoiceStorage, error) {
+	switch cfg.Storage {
+	case StorageLocal:
+		return localStorage{cfg.Dir}, nil
+	case StorageSupabase:
+		return supabaseStorage{cfg.Bucket}, nil
+	default:
+		return nil, fmt.Errorf("unknown invoice storage [%s]", cfg.Storage)
+	}
+}
+
+// localStorage writes the invoices in a directory of the server, the path of the file is returned
+type localStorage struct {
+	dir string
+}
+
+func (s localStorage) Store(fileName string, content []byte) (string, error) {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(s.dir, fileName)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// supabaseStorage uploads the invoices to a bucket, the url of the file is returned
+type supabaseStorage struct {
+	bucket string
+}
+
+func (s supabaseStorage) Store(fileName string, content []byte) (string, error) {
+	return utils.SupabaseStorageUploadFile(s.bucket, fileName, bytes.NewReader(content), "text/html")
+}
\ No newline at end of file
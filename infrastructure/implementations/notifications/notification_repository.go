@@ -0,0 +1,75 @@
+package notifications
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"math"
+	"pm/domain/entity"
+	"pm/domain/repository/notifications"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+	"time"
+)
+
+const entityName = "notifications"
+
+type NotificationRepository struct {
+	c  *gin.Context
+	p  *base.Persistence
+	db *gorm.DB
+}
+
+func NewNotificationRepository(c *gin.Context, p *base.Persistence, db *gorm.DB) notifications.NotificationRepository {
+	return NotificationRepository{c, p, db}
+}
+
+// GetAllNotifications returns the notifications newest first, only the unread ones when the filter asks for them
+func (nr NotificationRepository) GetAllNotifications(filter *entity.NotificationFilter, pagination *entity.Pagination) ([]entity.Notification, error) {
+	span := nr.p.Logger.Start(nr.c, "GET_ALL_NOTIFICATIONS_DATABASE")
+	defer span.End()
+
+	var totalRows int64
+	listNotifications := make([]entity.Notification, 0)
+	db := nr.db.Model(&entity.Notification{})
+	if filter != nil && filter.Unread {
+		db = db.Where("read_at IS NULL")
+	}
+	db = db.Count(&totalRows)
+	err := db.Scopes(utils.Paginate(pagination, entity.NotificationSortColumns, entity.SortField{Column: "notifications.created_at", Desc: true})).
+		Find(&listNotifications).Error
+	if err != nil {
+		nr.p.Logger.Error("GET_ALL_NOTIFICATIONS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrDB(err)
+	}
+	pagination.TotalRows = totalRows
+	pagination.TotalPages = int(math.Ceil(float64(totalRows) / float64(pagination.GetLimit())))
+	return listNotifications, nil
+}
+
+func (nr NotificationRepository) GetNotificationByID(id int64) (*entity.Notification, error) {
+	var notification entity.Notification
+	if err := nr.db.First(&notification, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payload.ErrEntityNotFound(entityName, fmt.Errorf("notification with id [%d] not found", id))
+		}
+		return nil, payload.ErrDB(err)
+	}
+	return &notification, nil
+}
+
+// MarkRead marks the notification read, a notification already read keeps the time it was first read
+func (nr NotificationRepository) MarkRead(notification *entity.Notification) error {
+	if notification.ReadAt != nil {
+		return nil
+	}
+	now := time.Now()
+	if err := nr.db.Model(notification).Update("read_at", now).Error; err != nil {
+		nr.p.Logger.Error("MARK_NOTIFICATION_READ: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	notification.ReadAt = &now
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,44 @@
+package order_events
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"pm/domain/entity"
+	"pm/domain/repository/order_events"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/persistences/base"
+)
+
+type OrderEventRepository struct {
+	c  *gin.Context
+	p  *base.Persistence
+	db *gorm.DB
+}
+
+func NewOrderEventRepository(c *gin.Context, p *base.Persistence, db *gorm.DB) order_events.OrderEventRepository {
+	return OrderEventRepository{c, p, db}
+}
+
+func (o OrderEventRepository) Create(event *entity.OrderEvent) error {
+	if err := o.db.Create(event).Error; err != nil {
+		o.p.Logger.Error("CREATE_ORDER_EVENT: ERROR", map[string]interface{}{"error": err.Error(), "event": event})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+// GetEventsByOrderID returns the history of the order, oldest event first
+func (o OrderEventRepository) GetEventsByOrderID(orderID int64) ([]entity.OrderEvent, error) {
+	span := o.p.Logger.Start(o.c, "GET_ORDER_EVENTS_DATABASE")
+	defer span.End()
+
+	events := make([]entity.OrderEvent, 0)
+	err := o.db.Where("order_id = ?", orderID).
+		Order("created_at asc, id asc").
+		Find(&events).Error
+	if err != nil {
+		o.p.Logger.Error("GET_ORDER_EVENTS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrDB(err)
+	}
+	return events, nil
+}
\ No newline at end of file
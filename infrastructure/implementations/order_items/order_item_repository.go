@@ -5,10 +5,12 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
+	"math"
 	"pm/domain/entity"
 	orderItems "pm/domain/repository/order_items"
 	"pm/infrastructure/controllers/payload"
 	"pm/infrastructure/persistences/base"
+	"pm/utils"
 )
 
 type OrderItemRepository struct {
@@ -32,27 +34,27 @@ func (o OrderItemRepository) CreateNewOrderItems(items []entity.OrderItem) error
 func (o OrderItemRepository) UpdateOrderItems(items []entity.OrderItem) ([]entity.OrderItem, error) {
 	span := o.p.Logger.Start(o.c, "UPDATE_ORDER_ITEMS: REPO", o.p.Logger.UseGivenSpan(o.parentSpan))
 	defer span.End()
-	db := o.db.Begin()
-	if err := db.Error; err != nil {
-		o.p.Logger.Error("UPDATE_ORDER_ITEMS: ERROR BEGIN TRANSACTION", map[string]interface{}{"error": err.Error()}, o.p.Logger.UseGivenSpan(span))
-		return nil, payload.ErrDB(err)
-	}
 
-	for k, _ := range items {
-		if err := db.Debug().Updates(&items[k]).Error; err != nil {
-			db.Rollback()
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				o.p.Logger.Error("UPDATE_ORDER_ITEMS: ERROR ORDER_ITEM NOT FOUND", map[string]interface{}{"error": err.Error(), "order_item_id": items[k].Model.ID}, o.p.Logger.UseGivenSpan(span))
-				return nil, payload.ErrEntityNotFound("order_items", err)
+	// Transaction joins the caller's transaction when the repository is built on one
+	err := o.db.Transaction(func(db *gorm.DB) error {
+		for k, _ := range items {
+			// the variant is always written, an item moved to a product without variants must lose its old one
+			if err := db.Debug().Select("OrderID", "ProductID", "VariantID", "Quantity", "Price").Updates(&items[k]).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					o.p.Logger.Error("UPDATE_ORDER_ITEMS: ERROR ORDER_ITEM NOT FOUND", map[string]interface{}{"error": err.Error(), "order_item_id": items[k].Model.ID}, o.p.Logger.UseGivenSpan(span))
+					return payload.ErrEntityNotFound("order_items", err)
+				}
+				o.p.Logger.Error("UPDATE_ORDER_ITEMS: ERROR", map[string]interface{}{"error": err.Error()}, o.p.Logger.UseGivenSpan(span))
+				return payload.ErrDB(err)
 			}
-			o.p.Logger.Error("UPDATE_ORDER_ITEMS: ERROR", map[string]interface{}{"error": err.Error()}, o.p.Logger.UseGivenSpan(span))
-			return nil, payload.ErrDB(err)
 		}
-	}
-
-	if err := db.Commit().Error; err != nil {
-		db.Rollback()
-		o.p.Logger.Error("UPDATE_ORDER_ITEMS: ERROR COMMIT TRANSACTION", map[string]interface{}{"error": err.Error()}, o.p.Logger.UseGivenSpan(span))
+		return nil
+	})
+	if err != nil {
+		o.p.Logger.Error("UPDATE_ORDER_ITEMS: ERROR TRANSACTION", map[string]interface{}{"error": err.Error()}, o.p.Logger.UseGivenSpan(span))
+		if _, ok := err.(*payload.AppError); ok {
+			return nil, err
+		}
 		return nil, payload.ErrDB(err)
 	}
 
@@ -74,25 +76,38 @@ func (o OrderItemRepository) GetOrderItemByID(id int64) (*entity.OrderItem, erro
 	return &orderItem, nil
 }
 
-func (o OrderItemRepository) GetAllOrderItems() ([]entity.OrderItem, error) {
+func (o OrderItemRepository) GetAllOrderItems(pagination *entity.Pagination) ([]entity.OrderItem, error) {
 	span := o.p.Logger.Start(o.c, "GET_ALL_ORDER_ITEM: REPO", o.p.Logger.UseGivenSpan(o.parentSpan))
 	defer span.End()
-	var orderItems []entity.OrderItem
-	if err := o.db.Debug().Find(&orderItems).Error; err != nil {
+
+	var totalRows int64
+	orderItems := make([]entity.OrderItem, 0)
+	db := o.db.Model(&entity.OrderItem{})
+	// a list paged from a cursor is not counted, counting is what gets slow on large tables
+	if !pagination.IsCursor() {
+		if err := db.Count(&totalRows).Error; err != nil {
+			o.p.Logger.Error("GET_ALL_ORDER_ITEM: ERROR DB", map[string]interface{}{"error": err.Error()}, o.p.Logger.UseGivenSpan(span))
+			return nil, payload.ErrDB(err)
+		}
+	}
+	if err := db.Debug().Scopes(utils.Paginate(pagination, entity.OrderItemSortColumns)).Find(&orderItems).Error; err != nil {
 		o.p.Logger.Error("GET_ALL_ORDER_ITEM: ERROR DB", map[string]interface{}{"error": err.Error()}, o.p.Logger.UseGivenSpan(span))
 		return nil, payload.ErrDB(err)
 	}
+	if !pagination.IsCursor() {
+		pagination.TotalRows = totalRows
+		pagination.TotalPages = int(math.Ceil(float64(totalRows) / float64(pagination.GetLimit())))
+	}
+	if err := utils.SetCursors(o.db, pagination, entity.OrderItemSortColumns, &orderItems); err != nil {
+		o.p.Logger.Error("GET_ALL_ORDER_ITEM: ERROR", map[string]interface{}{"error": err.Error()}, o.p.Logger.UseGivenSpan(span))
+		return nil, payload.ErrInternal(err)
+	}
 	return orderItems, nil
 }
 
 func (o OrderItemRepository) DeleteOrderItemByID(id int64) error {
 	span := o.p.Logger.Start(o.c, "DELETE_ORDER_ITEM: REPO", o.p.Logger.UseGivenSpan(o.parentSpan))
 	defer span.End()
-	db := o.db.Begin()
-	if err := db.Error; err != nil {
-		o.p.Logger.Error("DELETE_ORDER_ITEM: ERROR BEGIN TRANSACTION", map[string]interface{}{"error": err.Error()}, o.p.Logger.UseGivenSpan(span))
-		return payload.ErrDB(err)
-	}
 
 	orderItem, err := o.GetOrderItemByID(id)
 	if err != nil {
@@ -100,16 +115,11 @@ func (o OrderItemRepository) DeleteOrderItemByID(id int64) error {
 		return err
 	}
 
-	if err := db.Model(&entity.OrderItem{}).Debug().Delete(&orderItem).Error; err != nil {
+	if err := o.db.Model(&entity.OrderItem{}).Debug().Delete(&orderItem).Error; err != nil {
 		o.p.Logger.Error("DELETE_ORDER_ITEM: ERROR", map[string]interface{}{"error": err.Error()}, o.p.Logger.UseGivenSpan(span))
 		return payload.ErrDB(err)
 	}
 
-	if err := db.Commit().Error; err != nil {
-		o.p.Logger.Error("DELETE_ORDER_ITEM: ERROR COMMIT TRANSACTION", map[string]interface{}{"error": err.Error()}, o.p.Logger.UseGivenSpan(span))
-		return payload.ErrDB(err)
-	}
-
 	return nil
 }
 
@@ -5,10 +5,18 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"math"
 	"pm/domain/entity"
 	"pm/domain/repository/orders"
 	"pm/infrastructure/controllers/payload"
 	"pm/infrastructure/persistences/base"
+	"pm/utils"
+	"time"
+)
+
+const (
+	entityName string = "orders"
 )
 
 type OrderRepository struct {
@@ -53,27 +61,171 @@ func (o OrderRepository) Create(order *entity.Order) error {
 	defer span.End()
 	o.p.Logger.Info("STARTING: CREATE ORDER", map[string]interface{}{"order": order})
 
-	tx := o.db.Begin()
-
-	if err := tx.Create(&order).Error; err != nil {
+	// Transaction joins the caller's transaction when the repository is built on one
+	err := o.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&order).Error
+	})
+	if err != nil {
 		o.p.Logger.Error("CREATE_ORDER: ERROR_DB_CREATE", map[string]interface{}{"error": err.Error()})
-		tx.Rollback()
 		return payload.ErrDB(err)
 	}
 
-	if err := tx.Commit().Error; err != nil {
-		o.p.Logger.Error("CREATE_ORDER: ERROR_DB", map[string]interface{}{"error": err.Error()})
+	o.p.Logger.Info("CREATE_ORDER_SUCCESSFULLY", map[string]interface{}{"order": order})
+
+	return nil
+}
+
+func (o OrderRepository) Update(order *entity.Order) (*entity.Order, error) {
+	span := o.p.Logger.Start(o.c, "UPDATE_ORDER_DATABASE")
+	defer span.End()
+	o.p.Logger.Info("STARTING: UPDATE ORDER", map[string]interface{}{"order": order})
+
+	if err := o.db.Model(order).Omit("OrderItems").Updates(order).Error; err != nil {
+		o.p.Logger.Error("UPDATE_ORDER: ERROR", map[string]interface{}{"error": err.Error()})
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payload.ErrEntityNotFound(entityName, err)
+		}
+		return nil, payload.ErrDB(err)
+	}
+
+	o.p.Logger.Info("UPDATE_ORDER_SUCCESSFULLY", map[string]interface{}{"order": order})
+	return order, nil
+}
+
+// UpdateStatus moves the order to the given status only if its status in the database is still the one
+// loaded into order, so two concurrent transitions cannot both succeed
+func (o OrderRepository) UpdateStatus(order *entity.Order, status string) error {
+	span := o.p.Logger.Start(o.c, "UPDATE_ORDER_STATUS_DATABASE")
+	defer span.End()
+	o.p.Logger.Info("STARTING: UPDATE ORDER STATUS", map[string]interface{}{"order_id": order.ID, "from": order.Status, "to": status})
+
+	updates := map[string]interface{}{"status": status}
+	now := time.Now()
+	if status == entity.OrderStatusDelivered {
+		updates["delivered_at"] = now
+	}
+	result := o.db.Model(&entity.Order{}).
+		Where("id = ? AND status = ?", order.ID, order.Status).
+		Updates(updates)
+	if err := result.Error; err != nil {
+		o.p.Logger.Error("UPDATE_ORDER_STATUS: ERROR", map[string]interface{}{"error": err.Error()})
 		return payload.ErrDB(err)
 	}
+	if result.RowsAffected == 0 {
+		err := payload.ErrInvalidOrderTransition(order.Status, status)
+		o.p.Logger.Error("UPDATE_ORDER_STATUS: ERROR STATUS CHANGED CONCURRENTLY", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+	order.Status = status
+	if status == entity.OrderStatusDelivered {
+		order.DeliveredAt = &now
+	}
 
-	o.p.Logger.Info("CREATE_ORDER_SUCCESSFULLY", map[string]interface{}{"order": order})
+	o.p.Logger.Info("UPDATE_ORDER_STATUS_SUCCESSFULLY", map[string]interface{}{"order_id": order.ID, "status": status})
+	return nil
+}
+
+// UpdateTotals saves only the money columns of the order and the tax of its items, so it never overwrites a status
+// changed in the meantime
+func (o OrderRepository) UpdateTotals(order *entity.Order) error {
+	span := o.p.Logger.Start(o.c, "UPDATE_ORDER_TOTALS_DATABASE")
+	defer span.End()
+
+	err := o.db.Model(order).
+		Select("Subtotal", "ShippingFee", "DiscountTotal", "TaxTotal", "GrandTotal").
+		Updates(order).Error
+	if err != nil {
+		o.p.Logger.Error("UPDATE_ORDER_TOTALS: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	for i := range order.OrderItems {
+		err := o.db.Model(&order.OrderItems[i]).
+			Select("TaxRate", "TaxAmount").
+			Updates(&order.OrderItems[i]).Error
+		if err != nil {
+			o.p.Logger.Error("UPDATE_ORDER_TOTALS: ERROR", map[string]interface{}{"error": err.Error()})
+			return payload.ErrDB(err)
+		}
+	}
 
+	o.p.Logger.Info("UPDATE_ORDER_TOTALS_SUCCESSFULLY", map[string]interface{}{"order_id": order.ID, "grand_total": order.GrandTotal})
 	return nil
 }
 
-func (o OrderRepository) Update(order *entity.Order) (*entity.Order, error) {
-	//TODO implement me
-	panic("implement me")
+// Cancel moves the order to cancelled and records who cancelled it and why, guarded by the status loaded into order
+func (o OrderRepository) Cancel(order *entity.Order, cancelledBy int64, reason string) error {
+	span := o.p.Logger.Start(o.c, "CANCEL_ORDER_DATABASE")
+	defer span.End()
+	o.p.Logger.Info("STARTING: CANCEL ORDER", map[string]interface{}{"order_id": order.ID, "cancelled_by": cancelledBy, "reason": reason})
+
+	now := time.Now()
+	result := o.db.Model(&entity.Order{}).
+		Where("id = ? AND status = ?", order.ID, order.Status).
+		Updates(map[string]interface{}{
+			"status":        entity.OrderStatusCancelled,
+			"cancelled_by":  cancelledBy,
+			"cancel_reason": reason,
+			"cancelled_at":  now,
+		})
+	if err := result.Error; err != nil {
+		o.p.Logger.Error("CANCEL_ORDER: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	if result.RowsAffected == 0 {
+		err := payload.ErrInvalidOrderTransition(order.Status, entity.OrderStatusCancelled)
+		o.p.Logger.Error("CANCEL_ORDER: ERROR STATUS CHANGED CONCURRENTLY", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+	order.Status = entity.OrderStatusCancelled
+	order.CancelledBy = uint(cancelledBy)
+	order.CancelReason = reason
+	order.CancelledAt = &now
+
+	o.p.Logger.Info("CANCEL_ORDER_SUCCESSFULLY", map[string]interface{}{"order": order})
+	return nil
+}
+
+// Ship moves the order to shipped with its delivery information, with the same guard on the current status as
+// UpdateStatus. The shipping method and the tracking number are kept when they are not given
+func (o OrderRepository) Ship(order *entity.Order, shippingMethod string, trackingNumber string) error {
+	span := o.p.Logger.Start(o.c, "SHIP_ORDER_DATABASE")
+	defer span.End()
+	o.p.Logger.Info("STARTING: SHIP ORDER", map[string]interface{}{"order_id": order.ID, "shipping_method": shippingMethod, "tracking_number": trackingNumber})
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":     entity.OrderStatusShipped,
+		"shipped_at": now,
+	}
+	if shippingMethod != "" {
+		updates["shipping_method"] = shippingMethod
+	}
+	if trackingNumber != "" {
+		updates["tracking_number"] = trackingNumber
+	}
+	result := o.db.Model(&entity.Order{}).
+		Where("id = ? AND status = ?", order.ID, order.Status).
+		Updates(updates)
+	if err := result.Error; err != nil {
+		o.p.Logger.Error("SHIP_ORDER: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	if result.RowsAffected == 0 {
+		err := payload.ErrInvalidOrderTransition(order.Status, entity.OrderStatusShipped)
+		o.p.Logger.Error("SHIP_ORDER: ERROR STATUS CHANGED CONCURRENTLY", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+	order.Status = entity.OrderStatusShipped
+	order.ShippedAt = &now
+	if shippingMethod != "" {
+		order.ShippingMethod = shippingMethod
+	}
+	if trackingNumber != "" {
+		order.TrackingNumber = trackingNumber
+	}
+
+	o.p.Logger.Info("SHIP_ORDER_SUCCESSFULLY", map[string]interface{}{"order": order})
+	return nil
 }
 
 func (o OrderRepository) GetOrderByID(id int64) (*entity.Order, error) {
@@ -86,17 +238,144 @@ func (o OrderRepository) GetOrderByID(id int64) (*entity.Order, error) {
 	//err := o.db.Model(&order).Where("order.id = ?", id).Association("OrderItems").Find(&order.OrderItems)
 	err := o.db.Preload("OrderItems").First(&order, id).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payload.ErrEntityNotFound(entityName, err)
+		}
 		return nil, payload.ErrDB(err)
 	}
 	return &order, nil
 }
 
-func (o OrderRepository) GetAllOrders(pagination *entity.Pagination) ([]entity.Order, error) {
-	//TODO implement me
-	panic("implement me")
+// LockOrder takes a row lock on the order until the surrounding transaction ends, so work that has to look at the
+// order and its payments as a whole cannot run twice at the same time
+func (o OrderRepository) LockOrder(id int64) error {
+	err := o.db.Model(&entity.Order{}).Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&entity.Order{}, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return payload.ErrEntityNotFound(entityName, err)
+		}
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+func (o OrderRepository) GetAllOrders(filter *entity.OrderFilter, pagination *entity.Pagination) ([]entity.Order, error) {
+	span := o.p.Logger.Start(o.c, "GET_ALL_ORDERS_DATABASE")
+	defer span.End()
+	o.p.Logger.Info("STARTING: GET ALL ORDERS", map[string]interface{}{"params": struct {
+		Filter     interface{} `json:"filter"`
+		Pagination interface{} `json:"pagination"`
+	}{
+		Filter:     filter,
+		Pagination: pagination,
+	}})
+
+	var totalRows int64
+	orders := make([]entity.Order, 0)
+	db := o.db.Model(&entity.Order{})
+	if filter != nil {
+		db = db.Scopes(applyFilter(filter))
+	}
+	// a list paged from a cursor is not counted, counting is what gets slow on large tables
+	if !pagination.IsCursor() {
+		if err := db.Count(&totalRows).Error; err != nil {
+			o.p.Logger.Error("GET_ALL_ORDERS: ERROR", map[string]interface{}{"error": err.Error()})
+			return nil, payload.ErrDB(err)
+		}
+	}
+	if err := db.Scopes(utils.Paginate(pagination, entity.OrderSortColumns)).Preload("OrderItems").Find(&orders).Error; err != nil {
+		o.p.Logger.Error("GET_ALL_ORDERS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrDB(err)
+	}
+	if !pagination.IsCursor() {
+		pagination.TotalRows = totalRows
+		pagination.TotalPages = int(math.Ceil(float64(totalRows) / float64(pagination.GetLimit())))
+	}
+	if err := utils.SetCursors(o.db, pagination, entity.OrderSortColumns, &orders); err != nil {
+		o.p.Logger.Error("GET_ALL_ORDERS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrInternal(err)
+	}
+
+	o.p.Logger.Info("GET_ALL_ORDERS_SUCCESSFULLY", map[string]interface{}{"orders": orders, "pagination": pagination})
+	return orders, nil
 }
 
 func (o OrderRepository) DeleteOrder(order *entity.Order) error {
-	//TODO implement me
-	panic("implement me")
+	span := o.p.Logger.Start(o.c, "DELETE_ORDER_DATABASE")
+	defer span.End()
+	o.p.Logger.Info("STARTING: DELETE ORDER", map[string]interface{}{"order_id": order.ID})
+
+	if err := o.db.Select("OrderItems").Delete(order).Error; err != nil {
+		o.p.Logger.Error("DELETE_ORDER: ERROR", map[string]interface{}{"error": err.Error()})
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return payload.ErrEntityNotFound(entityName, err)
+		}
+		return payload.ErrDB(err)
+	}
+
+	o.p.Logger.Info("DELETE_ORDER_SUCCESSFULLY", map[string]interface{}{"order_id": order.ID})
+	return nil
+}
+
+func applyFilter(f *entity.OrderFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Scopes(
+			applyStatusFilter(f),
+			applyUserIDFilter(f),
+			applyProductIDFilter(f),
+			applyTotalFilter(f),
+			applyCreatedAtFilter(f),
+		)
+	}
+}
+
+func applyStatusFilter(f *entity.OrderFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if f.Status != "" {
+			db = db.Where("orders.status = ?", f.Status)
+		}
+		return db
+	}
+}
+
+func applyUserIDFilter(f *entity.OrderFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if f.UserID != 0 {
+			db = db.Where("orders.user_id = ?", f.UserID)
+		}
+		return db
+	}
+}
+
+func applyProductIDFilter(f *entity.OrderFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if f.ProductID != 0 {
+			db = db.Where("EXISTS (SELECT 1 FROM order_items oi WHERE oi.order_id = orders.id AND oi.product_id = ? AND oi.deleted_at IS NULL)", f.ProductID)
+		}
+		return db
+	}
+}
+
+func applyTotalFilter(f *entity.OrderFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if f.TotalFrom != 0 {
+			db = db.Where("orders.grand_total >= ?", f.TotalFrom)
+		}
+		if f.TotalTo != 0 {
+			db = db.Where("orders.grand_total <= ?", f.TotalTo)
+		}
+		return db
+	}
+}
+
+func applyCreatedAtFilter(f *entity.OrderFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if f.CreatedAtFrom != nil {
+			db = db.Where("orders.created_at >= ?", *f.CreatedAtFrom)
+		}
+		if f.CreatedAtTo != nil {
+			db = db.Where("orders.created_at <= ?", *f.CreatedAtTo)
+		}
+		return db
+	}
 }
\ No newline at end of file
@@ -0,0 +1,41 @@
+package payments
+
+import (
+	"errors"
+	"fmt"
+	"pm/domain/repository/payments"
+	"pm/infrastructure/config"
+	"pm/infrastructure/implementations/payments/mock"
+)
+
+// NewPaymentGateway returns the gateway registered under name, new gateways only have to be added here. Only the
+// gateway chosen in the configuration is registered, so callbacks claiming to come from any other one, the mock
+// gateway included, are refused
+func NewPaymentGateway(name string, cfg config.PaymentConfig) (payments.PaymentGateway, error) {
+	if cfg.Gateway == "" {
+		return nil, errors.New("payments are disabled, no payment gateway is configured")
+	}
+	if name != cfg.Gateway {
+		return nil, fmt.Errorf("payment gateway [%s] is not enabled", name)
+	}
+
+	switch name {
+	case mock.Name:
+		if cfg.MockSecret == "" {
+			return nil, fmt.Errorf("payment gateway [%s] has no secret to sign its callbacks with", name)
+		}
+		return mock.NewMockGateway(cfg.MockSecret), nil
+	default:
+		return nil, fmt.Errorf("unknown payment gateway [%s]", name)
+	}
+}
+
+// ValidatePaymentConfig makes sure the configured gateway exists and has its secret, so a server never starts
+// accepting callbacks it cannot verify. Having no gateway at all only disables payments
+func ValidatePaymentConfig(cfg config.PaymentConfig) error {
+	if cfg.Gateway == "" {
+		return nil
+	}
+	_, err := NewPaymentGateway(cfg.Gateway, cfg)
+	return err
+}
\ No newline at end of file
@@ -0,0 +1,114 @@
+package mock
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"pm/domain/entity"
+	"pm/domain/repository/payments"
+)
+
+const Name = "mock"
+
+// Gateway is a local payment gateway for development and tests. It approves every payment with a positive amount
+// right away and signs its callbacks with HMAC-SHA256 over the raw body
+type Gateway struct {
+	secret []byte
+}
+
+func NewMockGateway(secret string) payments.PaymentGateway {
+	return Gateway{secret: []byte(secret)}
+}
+
+func (g Gateway) Name() string {
+	return Name
+}
+
+func (g Gateway) Initiate(payment *entity.Payment) (*payments.GatewayResult, error) {
+	if payment.Amount <= 0 {
+		return &payments.GatewayResult{
+			Status:        entity.PaymentStatusFailed,
+			FailureReason: "the amount must be greater than 0",
+		}, nil
+	}
+
+	ref := make([]byte, 8)
+	if _, err := rand.Read(ref); err != nil {
+		return nil, err
+	}
+	providerRef := fmt.Sprintf("mock_%s", hex.EncodeToString(ref))
+	return &payments.GatewayResult{
+		ProviderRef: providerRef,
+		Status:      entity.PaymentStatusAuthorized,
+		RedirectURL: fmt.Sprintf("mock://payments/%s", providerRef),
+	}, nil
+}
+
+func (g Gateway) Capture(payment *entity.Payment) (*payments.GatewayResult, error) {
+	if payment.ProviderRef == "" {
+		return nil, errors.New("the payment was never initiated on the gateway")
+	}
+	return &payments.GatewayResult{
+		ProviderRef: payment.ProviderRef,
+		Status:      entity.PaymentStatusCaptured,
+	}, nil
+}
+
+func (g Gateway) Void(payment *entity.Payment) (*payments.GatewayResult, error) {
+	if payment.ProviderRef == "" {
+		return nil, errors.New("the payment was never initiated on the gateway")
+	}
+	return &payments.GatewayResult{
+		ProviderRef: payment.ProviderRef,
+		Status:      entity.PaymentStatusVoided,
+	}, nil
+}
+
+func (g Gateway) Refund(payment *entity.Payment, amount float64) (*payments.GatewayResult, error) {
+	if payment.ProviderRef == "" {
+		return nil, errors.New("the payment was never initiated on the gateway")
+	}
+	if amount <= 0 || amount > payment.Amount {
+		return &payments.GatewayResult{
+			Status:        entity.RefundStatusFailed,
+			FailureReason: fmt.Sprintf("cannot refund %v of a payment of %v", amount, payment.Amount),
+		}, nil
+	}
+
+	ref := make([]byte, 8)
+	if _, err := rand.Read(ref); err != nil {
+		return nil, err
+	}
+	return &payments.GatewayResult{
+		ProviderRef: fmt.Sprintf("mock_refund_%s", hex.EncodeToString(ref)),
+		Status:      entity.RefundStatusSucceeded,
+	}, nil
+}
+
+func (g Gateway) ParseCallback(body []byte, signature string) (*payments.CallbackEvent, error) {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, g.sign(body)) {
+		return nil, entity.ErrInvalidPaymentSignature
+	}
+
+	var event payments.CallbackEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// Sign returns the hex signature the gateway puts on a callback body, it lets developers fake callbacks locally
+func (g Gateway) Sign(body []byte) string {
+	return hex.EncodeToString(g.sign(body))
+}
+
+func (g Gateway) sign(body []byte) []byte {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
\ No newline at end of file
@@ -0,0 +1,98 @@
+package payments
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"pm/domain/entity"
+	"pm/domain/repository/payments"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/persistences/base"
+)
+
+const (
+	entityName = "payments"
+)
+
+type PaymentRepository struct {
+	c  *gin.Context
+	p  *base.Persistence
+	db *gorm.DB
+}
+
+func NewPaymentRepository(c *gin.Context, p *base.Persistence, db *gorm.DB) payments.PaymentRepository {
+	return PaymentRepository{c, p, db}
+}
+
+func (pr PaymentRepository) Create(payment *entity.Payment) error {
+	span := pr.p.Logger.Start(pr.c, "CREATE_PAYMENT_DATABASE")
+	defer span.End()
+	pr.p.Logger.Info("STARTING: CREATE PAYMENT", map[string]interface{}{"payment": payment})
+
+	if err := pr.db.Create(payment).Error; err != nil {
+		pr.p.Logger.Error("CREATE_PAYMENT: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+
+	pr.p.Logger.Info("CREATE_PAYMENT_SUCCESSFULLY", map[string]interface{}{"payment": payment})
+	return nil
+}
+
+// Update saves the fields of the payment the gateway can change
+func (pr PaymentRepository) Update(payment *entity.Payment) error {
+	span := pr.p.Logger.Start(pr.c, "UPDATE_PAYMENT_DATABASE")
+	defer span.End()
+	pr.p.Logger.Info("STARTING: UPDATE PAYMENT", map[string]interface{}{"payment": payment})
+
+	err := pr.db.Model(payment).
+		Select("ProviderRef", "Status", "FailureReason", "CapturedAt").
+		Updates(payment).Error
+	if err != nil {
+		pr.p.Logger.Error("UPDATE_PAYMENT: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+
+	pr.p.Logger.Info("UPDATE_PAYMENT_SUCCESSFULLY", map[string]interface{}{"payment": payment})
+	return nil
+}
+
+// GetPaymentByID reads the payment with a row lock, so it has to run in a transaction for the lock to be held
+// until the payment is updated
+func (pr PaymentRepository) GetPaymentByID(id int64) (*entity.Payment, error) {
+	var payment entity.Payment
+	err := pr.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&payment, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payload.ErrEntityNotFound(entityName, err)
+		}
+		pr.p.Logger.Error("GET_PAYMENT_BY_ID: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrDB(err)
+	}
+	return &payment, nil
+}
+
+// GetPaymentByProviderRef reads the payment a gateway knows under providerRef with a row lock, like GetPaymentByID
+func (pr PaymentRepository) GetPaymentByProviderRef(provider string, providerRef string) (*entity.Payment, error) {
+	var payment entity.Payment
+	err := pr.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("provider = ? AND provider_ref = ?", provider, providerRef).
+		First(&payment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payload.ErrEntityNotFound(entityName, err)
+		}
+		pr.p.Logger.Error("GET_PAYMENT_BY_PROVIDER_REF: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrDB(err)
+	}
+	return &payment, nil
+}
+
+func (pr PaymentRepository) GetPaymentsByOrderID(orderID int64) ([]entity.Payment, error) {
+	listPayments := make([]entity.Payment, 0)
+	if err := pr.db.Where("order_id = ?", orderID).Order("id asc").Find(&listPayments).Error; err != nil {
+		pr.p.Logger.Error("GET_PAYMENTS_BY_ORDER_ID: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrDB(err)
+	}
+	return listPayments, nil
+}
\ No newline at end of file
@@ -6,15 +6,21 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"math"
 	"pm/domain/entity"
 	"pm/domain/repository/products"
 	"pm/infrastructure/controllers/payload"
 	"pm/infrastructure/persistences/base"
+	"pm/utils"
+	"slices"
+	"sort"
+	"strings"
 )
 
 const (
-	entityName string = "products"
+	entityName        string = "products"
+	variantEntityName string = "product variants"
 )
 
 type ProductRepository struct {
@@ -49,6 +55,10 @@ func (prodRepo *ProductRepository) Create(parentSpan trace.Span, product *entity
 	prodRepo.p.Logger.Info("CREATE_PRODUCT", map[string]interface{}{"data": product}, prodRepo.p.Logger.UseGivenSpan(span))
 
 	db := prodRepo.db
+	if err := prodRepo.indexSearchText(product); err != nil {
+		prodRepo.p.Logger.Error("CREATE_PRODUCT_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+		return err
+	}
 	err := db.Create(&product).Error
 	if err != nil {
 		prodRepo.p.Logger.Error("CREATE_PRODUCT_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
@@ -58,12 +68,22 @@ func (prodRepo *ProductRepository) Create(parentSpan trace.Span, product *entity
 	return nil
 }
 
+// Update saves the product as given, cleared fields included, but not its stock which only moves through the stock
+// ledger nor its cost price which only receiving goods moves
 func (prodRepo *ProductRepository) Update(parentSpan trace.Span, product *entity.Product) (*entity.Product, error) {
 	span := prodRepo.p.Logger.Start(prodRepo.c, "UPDATE_PRODUCT_DATABASE", prodRepo.p.Logger.UseGivenSpan(parentSpan))
 	defer span.End()
 	prodRepo.p.Logger.Info("UPDATE_PRODUCT", map[string]interface{}{"data": product}, prodRepo.p.Logger.UseGivenSpan(span))
 	db := prodRepo.db
-	if err := db.Debug().Model(&product).Updates(&product).Error; err != nil {
+	if err := prodRepo.indexSearchText(product); err != nil {
+		prodRepo.p.Logger.Error("UPDATE_PRODUCT_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+		return nil, err
+	}
+	err := db.Debug().Model(&product).
+		Select("*").
+		Omit(clause.Associations, "ID", "CreatedAt", "DeletedAt", "Stock", "CostPrice").
+		Updates(&product).Error
+	if err != nil {
 		prodRepo.p.Logger.Error("UPDATE_PRODUCT_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
 		return nil, err
 	}
@@ -72,29 +92,6 @@ func (prodRepo *ProductRepository) Update(parentSpan trace.Span, product *entity
 	return product, nil
 }
 
-func (prodRepo *ProductRepository) UpdateMultiProduct(parentSpan trace.Span, products ...entity.Product) ([]entity.Product, error) {
-
-	tx := prodRepo.db.Begin()
-	prodRepo.p.Logger.Info("UPDATE_PRODUCT_STOCK", map[string]interface{}{"products": products})
-	for index, p := range products {
-		if err := tx.Model(&products[index]).Update("stock", p.Stock).Error; err != nil {
-			prodRepo.p.Logger.Error("UPDATE_PRODUCT_STOCK_FAILED", map[string]interface{}{"product": p, "message": err.Error()})
-			tx.Rollback()
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, payload.ErrEntityNotFound(entityName, err)
-			}
-			return nil, payload.ErrDB(err)
-		}
-	}
-
-	if err := tx.Commit().Error; err != nil {
-		prodRepo.p.Logger.Error("UPDATE_PRODUCT_STOCK_FAILED", map[string]interface{}{"products": products, "message": err.Error()})
-		return nil, payload.ErrDB(err)
-	}
-	prodRepo.p.Logger.Info("UPDATE_PRODUCT_STOCK_SUCCESSFULLY", map[string]interface{}{"products": products})
-	return products, nil
-}
-
 func (prodRepo *ProductRepository) GetProductByID(parentSpan trace.Span, id int64) (*entity.Product, error) {
 	span := prodRepo.p.Logger.Start(prodRepo.c, "GET_PRODUCT_BY_ID_DATABASE", prodRepo.p.Logger.UseGivenSpan(parentSpan))
 	defer span.End()
@@ -102,7 +99,7 @@ func (prodRepo *ProductRepository) GetProductByID(parentSpan trace.Span, id int6
 
 	db := prodRepo.db
 	var product entity.Product
-	if err := db.Model(&entity.Product{}).Where("id = ?", id).First(&product).Error; err != nil {
+	if err := db.Model(&entity.Product{}).Scopes(preloadVariants(false)).Where("id = ?", id).First(&product).Error; err != nil {
 		prodRepo.p.Logger.Info("GET_PRODUCT_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, payload.ErrEntityNotFound(entityName, err)
@@ -114,6 +111,28 @@ func (prodRepo *ProductRepository) GetProductByID(parentSpan trace.Span, id int6
 	return &product, nil
 }
 
+// GetProductByIDForUpdate reads the product like GetProductByID and takes a row lock on it until the surrounding
+// transaction ends, so its stock cannot move between the read and a change computed from it
+func (prodRepo *ProductRepository) GetProductByIDForUpdate(parentSpan trace.Span, id int64) (*entity.Product, error) {
+	span := prodRepo.p.Logger.Start(prodRepo.c, "GET_PRODUCT_BY_ID_FOR_UPDATE_DATABASE", prodRepo.p.Logger.UseGivenSpan(parentSpan))
+	defer span.End()
+
+	var product entity.Product
+	err := prodRepo.db.Model(&entity.Product{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Scopes(preloadVariants(false)).
+		Where("id = ?", id).
+		First(&product).Error
+	if err != nil {
+		prodRepo.p.Logger.Info("GET_PRODUCT_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payload.ErrEntityNotFound(entityName, err)
+		}
+		return nil, payload.ErrDB(err)
+	}
+	return &product, nil
+}
+
 func (prodRepo *ProductRepository) GetAllProducts(parentSpan trace.Span, filter *entity.ProductFilter, pagination *entity.Pagination) ([]entity.Product, error) {
 	var span trace.Span
 	if parentSpan != nil {
@@ -133,22 +152,31 @@ func (prodRepo *ProductRepository) GetAllProducts(parentSpan trace.Span, filter
 	var totalRows int64
 	products := make([]entity.Product, 0)
 	db := prodRepo.db
-	db = db.Model(entity.Product{}).Count(&totalRows)
+	db = db.Model(entity.Product{})
 	if filter != nil {
-		db = db.Scopes(applyFilter(filter)).Count(&totalRows)
+		db = db.Scopes(applyFilter(filter))
 	}
 	if pagination != nil {
-		if err := db.Scopes(paginate(pagination)).Find(&products).Error; err != nil {
+		// a list paged from a cursor is not counted, counting is what gets slow on large tables
+		if !pagination.IsCursor() {
+			db = db.Count(&totalRows)
+		}
+		if err := db.Scopes(utils.Paginate(pagination, entity.ProductSortColumns), preloadVariants(false)).Find(&products).Error; err != nil {
 			prodRepo.p.Logger.Error("GET_ALL_PRODUCTS_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
 			return nil, payload.ErrDB(err)
 		}
-		pagination.TotalRows = totalRows
-		totalPages := int(math.Ceil(float64(totalRows) / float64(pagination.Limit)))
-		pagination.TotalPages = totalPages
+		if !pagination.IsCursor() {
+			pagination.TotalRows = totalRows
+			pagination.TotalPages = int(math.Ceil(float64(totalRows) / float64(pagination.GetLimit())))
+		}
+		if err := utils.SetCursors(prodRepo.db, pagination, entity.ProductSortColumns, &products); err != nil {
+			prodRepo.p.Logger.Error("GET_ALL_PRODUCTS_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+			return nil, payload.ErrInternal(err)
+		}
 		prodRepo.p.Logger.Info("GET_ALL_PRODUCTS_SUCCESSFULLY", map[string]interface{}{"products": products, "filter": filter, "pagination": pagination}, prodRepo.p.Logger.UseGivenSpan(span))
 		return products, nil
 	}
-	if err := db.Find(&products).Error; err != nil {
+	if err := db.Scopes(preloadVariants(false)).Find(&products).Error; err != nil {
 		prodRepo.p.Logger.Error("GET_ALL_PRODUCTS_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
 		return nil, payload.ErrDB(err)
 	}
@@ -157,6 +185,99 @@ func (prodRepo *ProductRepository) GetAllProducts(parentSpan trace.Span, filter
 	return products, nil
 }
 
+// GetProductFacets counts the products matching the filter by category, price bucket, option value and stock. Every
+// facet is counted without its own filter, the other filters still apply
+func (prodRepo *ProductRepository) GetProductFacets(parentSpan trace.Span, filter *entity.ProductFilter, buckets []entity.PriceBucket) (*entity.ProductFacets, error) {
+	span := prodRepo.p.Logger.Start(prodRepo.c, "GET_PRODUCT_FACETS_DATABASE", prodRepo.p.Logger.UseGivenSpan(parentSpan))
+	defer span.End()
+	prodRepo.p.Logger.Info("GET_PRODUCT_FACETS", map[string]interface{}{"filter": filter}, prodRepo.p.Logger.UseGivenSpan(span))
+
+	facets := &entity.ProductFacets{}
+	var err error
+	if facets.Categories, err = prodRepo.categoryFacet(filter); err != nil {
+		prodRepo.p.Logger.Error("GET_PRODUCT_FACETS_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+		return nil, payload.ErrDB(err)
+	}
+	if facets.PriceBuckets, err = prodRepo.priceBucketFacet(filter, buckets); err != nil {
+		prodRepo.p.Logger.Error("GET_PRODUCT_FACETS_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+		return nil, payload.ErrDB(err)
+	}
+	if facets.Attributes, err = prodRepo.attributeFacets(filter); err != nil {
+		prodRepo.p.Logger.Error("GET_PRODUCT_FACETS_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+		return nil, payload.ErrDB(err)
+	}
+	inStock := *filter
+	inStock.InStock = true
+	if err := prodRepo.filtered(&inStock).Count(&facets.InStock).Error; err != nil {
+		prodRepo.p.Logger.Error("GET_PRODUCT_FACETS_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+		return nil, payload.ErrDB(err)
+	}
+
+	prodRepo.p.Logger.Info("GET_PRODUCT_FACETS_SUCCESSFULLY", map[string]interface{}{"facets": facets}, prodRepo.p.Logger.UseGivenSpan(span))
+	return facets, nil
+}
+
+// SearchProducts returns the products matching the words of the search, by full-text or within words, the most
+// relevant first. Matching in the name counts more than matching anywhere else
+func (prodRepo *ProductRepository) SearchProducts(parentSpan trace.Span, search *entity.ProductSearch, pagination *entity.Pagination) ([]entity.Product, error) {
+	span := prodRepo.p.Logger.Start(prodRepo.c, "SEARCH_PRODUCTS_DATABASE", prodRepo.p.Logger.UseGivenSpan(parentSpan))
+	defer span.End()
+	prodRepo.p.Logger.Info("SEARCH_PRODUCTS", map[string]interface{}{"search": search, "pagination": pagination}, prodRepo.p.Logger.UseGivenSpan(span))
+
+	var totalRows int64
+	products := make([]entity.Product, 0)
+	query := utils.NormalizeSearchText(search.Query)
+	if query == "" {
+		return products, nil
+	}
+	db := prodRepo.db.Model(&entity.Product{}).
+		Where(`to_tsvector('simple', search_text) @@ plainto_tsquery('simple', ?) OR search_text LIKE ? ESCAPE '\'`, query, utils.LikeContains(query))
+	if search.CategoryID != 0 {
+		db = db.Where("category_id = ?", search.CategoryID)
+	}
+	db = db.Count(&totalRows)
+	// the most relevant first unless another sort is asked for, the rank leads the sort and the id only breaks ties
+	var sortByRank []entity.SortField
+	if pagination.Sort == "" {
+		db = db.Select("*, ts_rank(to_tsvector('simple', search_text), plainto_tsquery('simple', ?)) + similarity(search_name, ?) AS rank", query, query)
+		sortByRank = append(sortByRank, entity.SortField{Column: "rank", Desc: true})
+	}
+	err := db.Scopes(utils.Paginate(pagination, entity.ProductSortColumns, sortByRank...), preloadVariants(false)).
+		Find(&products).Error
+	if err != nil {
+		prodRepo.p.Logger.Error("SEARCH_PRODUCTS_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+		return nil, payload.ErrDB(err)
+	}
+	pagination.TotalRows = totalRows
+	pagination.TotalPages = int(math.Ceil(float64(totalRows) / float64(pagination.GetLimit())))
+
+	prodRepo.p.Logger.Info("SEARCH_PRODUCTS_SUCCESSFULLY", map[string]interface{}{"products": len(products)}, prodRepo.p.Logger.UseGivenSpan(span))
+	return products, nil
+}
+
+// ReindexCategory refreshes the search text of the products of the category, which holds the name of the category
+func (prodRepo *ProductRepository) ReindexCategory(parentSpan trace.Span, category *entity.Category) error {
+	span := prodRepo.p.Logger.Start(prodRepo.c, "REINDEX_CATEGORY_DATABASE", prodRepo.p.Logger.UseGivenSpan(parentSpan))
+	defer span.End()
+
+	products := make([]entity.Product, 0)
+	if err := prodRepo.db.Unscoped().Where("category_id = ?", category.ID).Find(&products).Error; err != nil {
+		prodRepo.p.Logger.Error("REINDEX_CATEGORY_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+		return payload.ErrDB(err)
+	}
+	for _, product := range products {
+		err := prodRepo.db.Unscoped().Model(&product).UpdateColumns(map[string]interface{}{
+			"search_name": utils.NormalizeSearchText(product.Name),
+			"search_text": utils.ProductSearchText(&product, category.Name),
+		}).Error
+		if err != nil {
+			prodRepo.p.Logger.Error("REINDEX_CATEGORY_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+			return payload.ErrDB(err)
+		}
+	}
+	return nil
+}
+
 func (prodRepo *ProductRepository) DeleteProduct(parentSpan trace.Span, product *entity.Product) error {
 	span := prodRepo.p.Logger.Start(prodRepo.c, "DELETE_PRODUCT_DATABASE", prodRepo.p.Logger.UseGivenSpan(parentSpan))
 	defer span.End()
@@ -181,7 +302,7 @@ func (prodRepo *ProductRepository) GetProductByOrderItem(parentSpan trace.Span,
 	products := make([]entity.Product, 0)
 	for _, v := range orderItems {
 		var p entity.Product
-		err := prodRepo.db.Where("id = ?", v.ProductID).First(&p).Error
+		err := prodRepo.db.Scopes(preloadVariants(false)).Where("id = ?", v.ProductID).First(&p).Error
 		if err != nil {
 			prodRepo.p.Logger.Error("GET_PRODUCT_ERROR", map[string]interface{}{"message": err.Error()})
 			return nil, err
@@ -193,10 +314,28 @@ func (prodRepo *ProductRepository) GetProductByOrderItem(parentSpan trace.Span,
 	return products, nil
 }
 
+// GetProductsByIDs returns the products with the given ids and their variants, deleted ones included, for the
+// records that still refer to them such as the lines of an invoice
+func (prodRepo *ProductRepository) GetProductsByIDs(parentSpan trace.Span, ids ...uint) ([]entity.Product, error) {
+	span := prodRepo.p.Logger.Start(prodRepo.c, "GET_PRODUCTS_BY_IDS", prodRepo.p.Logger.UseGivenSpan(parentSpan))
+	defer span.End()
+
+	products := make([]entity.Product, 0)
+	if len(ids) == 0 {
+		return products, nil
+	}
+	if err := prodRepo.db.Unscoped().Scopes(preloadVariants(true)).Where("id IN ?", ids).Find(&products).Error; err != nil {
+		prodRepo.p.Logger.Error("GET_PRODUCTS_BY_IDS_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+		return nil, payload.ErrDB(err)
+	}
+	return products, nil
+}
+
 // IsAvailableStockByOrderItems
 /**
 // Param: array OrderItem
 // return: ([]Product, nil) when all the product in order is available, (nil, error) when one of products is not available
+// an item of a product with variants is checked against the stock of its variant
 */
 func (prodRepo *ProductRepository) IsAvailableStockByOrderItems(parentSpan trace.Span, orderItems ...entity.OrderItem) ([]entity.Product, error) {
 	span := prodRepo.p.Logger.Start(prodRepo.c, "CHECK_STOCK", prodRepo.p.Logger.UseGivenSpan(parentSpan))
@@ -206,17 +345,20 @@ func (prodRepo *ProductRepository) IsAvailableStockByOrderItems(parentSpan trace
 	ps := make([]entity.Product, 0)
 	for _, o := range orderItems {
 		var p entity.Product
-		if err := prodRepo.p.GormDB.Model(&entity.Product{}).Where("id = ?", o.ProductID).First(&p).Error; err != nil {
+		if err := prodRepo.p.GormDB.Model(&entity.Product{}).Scopes(preloadVariants(false)).Where("id = ?", o.ProductID).First(&p).Error; err != nil {
 			prodRepo.p.Logger.Error("CHECK_STOCK_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				return nil, payload.ErrEntityNotFound("products", err)
 			}
 			return nil, payload.ErrDB(err)
 		}
-		if p.Stock-int64(o.Quantity) < 0 {
-			err := fmt.Errorf("the product %v is out of stock", o.ProductID)
+		stock, err := stockOf(&p, o)
+		if err == nil && stock-int64(o.Quantity) < 0 {
+			err = payload.ErrInvalidRequest(fmt.Errorf("%s is out of stock", stockName(o)))
+		}
+		if err != nil {
 			prodRepo.p.Logger.Error("CHECK_STOCK_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
-			return nil, payload.ErrInvalidRequest(err)
+			return nil, err
 		}
 		ps = append(ps, p)
 	}
@@ -225,10 +367,452 @@ func (prodRepo *ProductRepository) IsAvailableStockByOrderItems(parentSpan trace
 	return ps, nil
 }
 
-func paginate(pagination *entity.Pagination) func(db *gorm.DB) *gorm.DB {
+// IncreaseStock gives the quantity of every order item back to its product, or to its variant, and returns the products
+// with their new stock
+func (prodRepo *ProductRepository) IncreaseStock(parentSpan trace.Span, orderItems ...entity.OrderItem) ([]entity.Product, error) {
+	span := prodRepo.p.Logger.Start(prodRepo.c, "INCREASE_STOCK", prodRepo.p.Logger.UseGivenSpan(parentSpan))
+	defer span.End()
+	prodRepo.p.Logger.Info("INCREASE_STOCK", map[string]interface{}{"data": orderItems}, prodRepo.p.Logger.UseGivenSpan(span))
+
+	ps := make([]entity.Product, 0)
+	for _, o := range orderItems {
+		// deleted products and variants still get their stock back so the numbers stay right if they are restored
+		result := stockRow(prodRepo.db.Unscoped(), o).
+			Update("stock", gorm.Expr("stock + ?", o.Quantity))
+		if err := result.Error; err != nil {
+			prodRepo.p.Logger.Error("INCREASE_STOCK_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+			return nil, payload.ErrDB(err)
+		}
+		if result.RowsAffected == 0 {
+			err := fmt.Errorf("%s does not exist", stockName(o))
+			prodRepo.p.Logger.Error("INCREASE_STOCK_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+			return nil, payload.ErrEntityNotFound(entityName, err)
+		}
+
+		var p entity.Product
+		if err := prodRepo.db.Unscoped().Model(&entity.Product{}).Scopes(preloadVariants(false)).Where("id = ?", o.ProductID).First(&p).Error; err != nil {
+			prodRepo.p.Logger.Error("INCREASE_STOCK_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+			return nil, payload.ErrDB(err)
+		}
+		ps = append(ps, p)
+	}
+
+	prodRepo.p.Logger.Info("INCREASE_STOCK_SUCCESSFULLY", map[string]interface{}{"products": ps}, prodRepo.p.Logger.UseGivenSpan(span))
+	return ps, nil
+}
+
+// DecreaseStock takes the quantity of every order item from its product, or from its variant when the product has
+// variants, with a conditional update, so the stock can never go below zero even when several orders reserve the
+// same product at the same time. It must run in the same transaction as the order it reserves stock for
+func (prodRepo *ProductRepository) DecreaseStock(parentSpan trace.Span, orderItems ...entity.OrderItem) ([]entity.Product, error) {
+	span := prodRepo.p.Logger.Start(prodRepo.c, "DECREASE_STOCK", prodRepo.p.Logger.UseGivenSpan(parentSpan))
+	defer span.End()
+	prodRepo.p.Logger.Info("DECREASE_STOCK", map[string]interface{}{"data": orderItems}, prodRepo.p.Logger.UseGivenSpan(span))
+
+	ps := make([]entity.Product, 0)
+	for _, o := range orderItems {
+		if o.Quantity <= 0 {
+			err := fmt.Errorf("the quantity of product %v must be greater than 0", o.ProductID)
+			prodRepo.p.Logger.Error("DECREASE_STOCK_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+			return nil, payload.ErrInvalidRequest(err)
+		}
+
+		result := stockRow(prodRepo.db, o).
+			Where("stock >= ?", o.Quantity).
+			Update("stock", gorm.Expr("stock - ?", o.Quantity))
+		if err := result.Error; err != nil {
+			prodRepo.p.Logger.Error("DECREASE_STOCK_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+			return nil, payload.ErrDB(err)
+		}
+
+		var p entity.Product
+		if err := prodRepo.db.Model(&entity.Product{}).Scopes(preloadVariants(false)).Where("id = ?", o.ProductID).First(&p).Error; err != nil {
+			prodRepo.p.Logger.Error("DECREASE_STOCK_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, payload.ErrEntityNotFound(entityName, err)
+			}
+			return nil, payload.ErrDB(err)
+		}
+		if result.RowsAffected == 0 {
+			_, err := stockOf(&p, o)
+			if err == nil {
+				err = payload.ErrInvalidRequest(fmt.Errorf("%s is out of stock", stockName(o)))
+			}
+			prodRepo.p.Logger.Error("DECREASE_STOCK_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+			return nil, err
+		}
+		ps = append(ps, p)
+	}
+
+	prodRepo.p.Logger.Info("DECREASE_STOCK_SUCCESSFULLY", map[string]interface{}{"products": ps}, prodRepo.p.Logger.UseGivenSpan(span))
+	return ps, nil
+}
+
+// AdjustStock moves the stock of the item's product, or of its variant, by delta, which may be negative, with a
+// conditional update so the stock never goes below zero. It returns the product with its new stock
+func (prodRepo *ProductRepository) AdjustStock(parentSpan trace.Span, item entity.OrderItem, delta int64) (*entity.Product, error) {
+	span := prodRepo.p.Logger.Start(prodRepo.c, "ADJUST_STOCK", prodRepo.p.Logger.UseGivenSpan(parentSpan))
+	defer span.End()
+	prodRepo.p.Logger.Info("ADJUST_STOCK", map[string]interface{}{"data": item, "delta": delta}, prodRepo.p.Logger.UseGivenSpan(span))
+
+	result := stockRow(prodRepo.db, item).
+		Where("stock + ? >= 0", delta).
+		Update("stock", gorm.Expr("stock + ?", delta))
+	if err := result.Error; err != nil {
+		prodRepo.p.Logger.Error("ADJUST_STOCK_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+		return nil, payload.ErrDB(err)
+	}
+
+	var p entity.Product
+	if err := prodRepo.db.Model(&entity.Product{}).Scopes(preloadVariants(false)).Where("id = ?", item.ProductID).First(&p).Error; err != nil {
+		prodRepo.p.Logger.Error("ADJUST_STOCK_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payload.ErrEntityNotFound(entityName, err)
+		}
+		return nil, payload.ErrDB(err)
+	}
+	if result.RowsAffected == 0 {
+		_, err := stockOf(&p, item)
+		if err == nil {
+			err = payload.ErrInvalidRequest(fmt.Errorf("%s does not have enough stock", stockName(item)))
+		}
+		prodRepo.p.Logger.Error("ADJUST_STOCK_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+		return nil, err
+	}
+
+	prodRepo.p.Logger.Info("ADJUST_STOCK_SUCCESSFULLY", map[string]interface{}{"product": p.ID}, prodRepo.p.Logger.UseGivenSpan(span))
+	return &p, nil
+}
+
+// UpdateCostPrice saves the cost of goods of the product, which only receiving goods from suppliers moves
+func (prodRepo *ProductRepository) UpdateCostPrice(parentSpan trace.Span, product *entity.Product, cost float64) error {
+	span := prodRepo.p.Logger.Start(prodRepo.c, "UPDATE_COST_PRICE_DATABASE", prodRepo.p.Logger.UseGivenSpan(parentSpan))
+	defer span.End()
+	prodRepo.p.Logger.Info("UPDATE_COST_PRICE", map[string]interface{}{"product": product.ID, "cost": cost}, prodRepo.p.Logger.UseGivenSpan(span))
+
+	if err := prodRepo.db.Model(product).Update("cost_price", cost).Error; err != nil {
+		prodRepo.p.Logger.Error("UPDATE_COST_PRICE_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+		return payload.ErrDB(err)
+	}
+	product.CostPrice = cost
+	return nil
+}
+
+func (prodRepo *ProductRepository) CreateVariant(parentSpan trace.Span, variant *entity.ProductVariant) error {
+	span := prodRepo.p.Logger.Start(prodRepo.c, "CREATE_VARIANT_DATABASE", prodRepo.p.Logger.UseGivenSpan(parentSpan))
+	defer span.End()
+	prodRepo.p.Logger.Info("CREATE_VARIANT", map[string]interface{}{"data": variant}, prodRepo.p.Logger.UseGivenSpan(span))
+
+	if err := prodRepo.db.Create(variant).Error; err != nil {
+		prodRepo.p.Logger.Error("CREATE_VARIANT_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+// UpdateVariant saves the variant and replaces its options with the ones it has now. Its stock is left as it is, it
+// only moves through the stock ledger
+func (prodRepo *ProductRepository) UpdateVariant(parentSpan trace.Span, variant *entity.ProductVariant) error {
+	span := prodRepo.p.Logger.Start(prodRepo.c, "UPDATE_VARIANT_DATABASE", prodRepo.p.Logger.UseGivenSpan(parentSpan))
+	defer span.End()
+	prodRepo.p.Logger.Info("UPDATE_VARIANT", map[string]interface{}{"data": variant}, prodRepo.p.Logger.UseGivenSpan(span))
+
+	err := prodRepo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(variant).
+			Select("SKU", "Price", "Image").
+			Updates(variant).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("variant_id = ?", variant.ID).Delete(&entity.ProductVariantOption{}).Error; err != nil {
+			return err
+		}
+		for i := range variant.Options {
+			variant.Options[i].ID = 0
+			variant.Options[i].VariantID = variant.ID
+		}
+		if len(variant.Options) == 0 {
+			return nil
+		}
+		return tx.Create(&variant.Options).Error
+	})
+	if err != nil {
+		prodRepo.p.Logger.Error("UPDATE_VARIANT_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+func (prodRepo *ProductRepository) GetVariantByID(parentSpan trace.Span, productID int64, variantID int64) (*entity.ProductVariant, error) {
+	span := prodRepo.p.Logger.Start(prodRepo.c, "GET_VARIANT_BY_ID_DATABASE", prodRepo.p.Logger.UseGivenSpan(parentSpan))
+	defer span.End()
+
+	var variant entity.ProductVariant
+	err := prodRepo.db.Preload("Options", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("id = ? AND product_id = ?", variantID, productID).First(&variant).Error
+	if err != nil {
+		prodRepo.p.Logger.Error("GET_VARIANT_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payload.ErrEntityNotFound(variantEntityName, fmt.Errorf("variant [%d] of product [%d] not found", variantID, productID))
+		}
+		return nil, payload.ErrDB(err)
+	}
+	return &variant, nil
+}
+
+func (prodRepo *ProductRepository) GetVariantBySKU(parentSpan trace.Span, sku string) (*entity.ProductVariant, error) {
+	span := prodRepo.p.Logger.Start(prodRepo.c, "GET_VARIANT_BY_SKU_DATABASE", prodRepo.p.Logger.UseGivenSpan(parentSpan))
+	defer span.End()
+
+	var variant entity.ProductVariant
+	if err := prodRepo.db.Where("sku = ?", entity.NormalizeSKU(sku)).First(&variant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payload.ErrEntityNotFound(variantEntityName, fmt.Errorf("sku [%s] not found", sku))
+		}
+		prodRepo.p.Logger.Error("GET_VARIANT_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+		return nil, payload.ErrDB(err)
+	}
+	return &variant, nil
+}
+
+// DeleteVariant deletes the variant, orders that have it keep referring to it
+func (prodRepo *ProductRepository) DeleteVariant(parentSpan trace.Span, variant *entity.ProductVariant) error {
+	span := prodRepo.p.Logger.Start(prodRepo.c, "DELETE_VARIANT_DATABASE", prodRepo.p.Logger.UseGivenSpan(parentSpan))
+	defer span.End()
+
+	if err := prodRepo.db.Delete(variant).Error; err != nil {
+		prodRepo.p.Logger.Error("DELETE_VARIANT_FAILED", map[string]interface{}{"message": err.Error()}, prodRepo.p.Logger.UseGivenSpan(span))
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+// stockRow selects the row the item takes its stock from, its variant or its product. The stock of a product with
+// variants lives on the variants, so such a product is never selected for an item without a variant
+func stockRow(db *gorm.DB, o entity.OrderItem) *gorm.DB {
+	if o.VariantID != nil {
+		return db.Model(&entity.ProductVariant{}).Where("id = ? AND product_id = ?", *o.VariantID, o.ProductID)
+	}
+	return db.Model(&entity.Product{}).
+		Where("id = ?", o.ProductID).
+		Where("NOT EXISTS (SELECT 1 FROM product_variants WHERE product_variants.product_id = products.id AND product_variants.deleted_at IS NULL)")
+}
+
+// stockOf returns the stock the item takes from the product, with an error when the item does not name one of its
+// variants while it has some, or names a variant it does not have
+func stockOf(p *entity.Product, o entity.OrderItem) (int64, error) {
+	if o.VariantID != nil {
+		v := p.Variant(*o.VariantID)
+		if v == nil {
+			return 0, payload.ErrEntityNotFound(variantEntityName, fmt.Errorf("%s does not exist", stockName(o)))
+		}
+		return v.Stock, nil
+	}
+	if p.HasVariants() {
+		return 0, payload.ErrInvalidRequest(fmt.Errorf("the product %v must be ordered by one of its variants", o.ProductID))
+	}
+	return p.Stock, nil
+}
+
+func stockName(o entity.OrderItem) string {
+	if o.VariantID != nil {
+		return fmt.Sprintf("the variant %v of product %v", *o.VariantID, o.ProductID)
+	}
+	return fmt.Sprintf("the product %v", o.ProductID)
+}
+
+// indexSearchText sets the normalized text the product is searched by, from its fields and the name of its category
+func (prodRepo *ProductRepository) indexSearchText(product *entity.Product) error {
+	names := make([]string, 0)
+	err := prodRepo.db.Unscoped().Model(&entity.Category{}).
+		Where("id = ?", product.CategoryID).
+		Pluck("name", &names).Error
+	if err != nil {
+		return payload.ErrDB(err)
+	}
+	categoryName := ""
+	if len(names) > 0 {
+		categoryName = names[0]
+	}
+	product.SearchName = utils.NormalizeSearchText(product.Name)
+	product.SearchText = utils.ProductSearchText(product, categoryName)
+	return nil
+}
+
+// preloadVariants loads the variants of the products with their options, deleted ones too when unscoped is set
+func preloadVariants(unscoped bool) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
-		return db.Offset(pagination.GetOffset()).Limit(pagination.GetLimit()).Order(pagination.GetSort())
+		return db.Preload("Variants", func(db *gorm.DB) *gorm.DB {
+			if unscoped {
+				db = db.Unscoped()
+			}
+			return db.Order("id")
+		}).Preload("Variants.Options", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		})
+	}
+}
+
+// filtered starts a query on the products matching the filter
+func (prodRepo *ProductRepository) filtered(filter *entity.ProductFilter) *gorm.DB {
+	return prodRepo.db.Session(&gorm.Session{NewDB: true}).Model(&entity.Product{}).Scopes(applyFilter(filter))
+}
+
+func (prodRepo *ProductRepository) categoryFacet(filter *entity.ProductFilter) ([]entity.FacetCount, error) {
+	others := *filter
+	others.CategoryID, others.CategoryIDs = 0, nil
+	rows := make([]struct {
+		CategoryID int64
+		Count      int64
+	}, 0)
+	err := prodRepo.filtered(&others).
+		Select("category_id, COUNT(*) AS count").
+		Group("category_id").
+		Order("count DESC, category_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.CategoryID)
 	}
+	names := make(map[int64]string)
+	if len(ids) != 0 {
+		listCategories := make([]entity.Category, 0)
+		if err := prodRepo.db.Session(&gorm.Session{NewDB: true}).Unscoped().Where("id IN ?", ids).Find(&listCategories).Error; err != nil {
+			return nil, err
+		}
+		for _, category := range listCategories {
+			names[int64(category.ID)] = category.Name
+		}
+	}
+	counts := make([]entity.FacetCount, 0, len(rows))
+	for _, row := range rows {
+		counts = append(counts, entity.FacetCount{
+			Value: fmt.Sprintf("%d", row.CategoryID),
+			Label: names[row.CategoryID],
+			Count: row.Count,
+		})
+	}
+	return counts, nil
+}
+
+// priceBucketFacet counts the products of every bucket in one pass, numbering the buckets in a CASE. Empty buckets
+// are listed too so the buckets stay the same from a list to another
+func (prodRepo *ProductRepository) priceBucketFacet(filter *entity.ProductFilter, buckets []entity.PriceBucket) ([]entity.FacetCount, error) {
+	counts := make([]entity.FacetCount, 0, len(buckets))
+	if len(buckets) == 0 {
+		return counts, nil
+	}
+	others := *filter
+	others.PriceBuckets = nil
+	bucketOf := strings.Builder{}
+	args := make([]interface{}, 0, len(buckets)*2)
+	bucketOf.WriteString("CASE")
+	for i, bucket := range buckets {
+		if bucket.To == 0 {
+			bucketOf.WriteString(" WHEN products.price >= ? THEN ?")
+			args = append(args, bucket.From, i)
+			continue
+		}
+		bucketOf.WriteString(" WHEN products.price >= ? AND products.price < ? THEN ?")
+		args = append(args, bucket.From, bucket.To, i)
+	}
+	bucketOf.WriteString(" END AS bucket, COUNT(*) AS count")
+	rows := make([]struct {
+		Bucket *int
+		Count  int64
+	}, 0)
+	if err := prodRepo.filtered(&others).Select(bucketOf.String(), args...).Group("bucket").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, bucket := range buckets {
+		counts = append(counts, entity.FacetCount{Value: bucket.Key()})
+	}
+	for _, row := range rows {
+		if row.Bucket != nil && *row.Bucket >= 0 && *row.Bucket < len(counts) {
+			counts[*row.Bucket].Count = row.Count
+		}
+	}
+	return counts, nil
+}
+
+// attributeFacets counts the products by option value. The options not filtered by are all counted under the whole
+// filter at once, every option filtered by is counted on its own without its values
+func (prodRepo *ProductRepository) attributeFacets(filter *entity.ProductFilter) ([]entity.AttributeFacet, error) {
+	selected, _ := optionValues(filter.Options)
+	rows, err := prodRepo.optionCounts(filter, "")
+	if err != nil {
+		return nil, err
+	}
+	facets := make(map[string][]entity.FacetCount)
+	for _, row := range rows {
+		if !slices.Contains(selected, row.Name) {
+			facets[row.Name] = append(facets[row.Name], entity.FacetCount{Value: row.Value, Count: row.Count})
+		}
+	}
+	for _, name := range selected {
+		others := *filter
+		others.Options = make([]string, 0, len(filter.Options))
+		for _, option := range filter.Options {
+			if optionName, _, ok := entity.ParseVariantOption(option); ok && optionName != name {
+				others.Options = append(others.Options, option)
+			}
+		}
+		rows, err := prodRepo.optionCounts(&others, name)
+		if err != nil {
+			return nil, err
+		}
+		facets[name] = make([]entity.FacetCount, 0, len(rows))
+		for _, row := range rows {
+			facets[name] = append(facets[name], entity.FacetCount{Value: row.Value, Count: row.Count})
+		}
+	}
+
+	attributes := make([]entity.AttributeFacet, 0, len(facets))
+	for name, values := range facets {
+		sort.SliceStable(values, func(i, j int) bool {
+			if values[i].Count != values[j].Count {
+				return values[i].Count > values[j].Count
+			}
+			return values[i].Value < values[j].Value
+		})
+		attributes = append(attributes, entity.AttributeFacet{Name: name, Values: values})
+	}
+	sort.Slice(attributes, func(i, j int) bool {
+		return attributes[i].Name < attributes[j].Name
+	})
+	return attributes, nil
+}
+
+type optionCount struct {
+	Name  string
+	Value string
+	Count int64
+}
+
+// optionCounts counts the products matching the filter by the values of the options of their variants, of the
+// given option only when there is one. Values differing only by case are counted as one
+func (prodRepo *ProductRepository) optionCounts(filter *entity.ProductFilter, name string) ([]optionCount, error) {
+	db := prodRepo.db.Session(&gorm.Session{NewDB: true})
+	products := prodRepo.filtered(filter).Select("products.id")
+	query := db.Model(&entity.ProductVariantOption{}).
+		Select("product_variant_options.name AS name, MIN(product_variant_options.value) AS value, COUNT(DISTINCT product_variants.product_id) AS count").
+		Joins("JOIN product_variants ON product_variants.id = product_variant_options.variant_id AND product_variants.deleted_at IS NULL").
+		Where("product_variants.product_id IN (?)", products).
+		Group("product_variant_options.name, LOWER(product_variant_options.value)")
+	if name != "" {
+		query = query.Where("product_variant_options.name = ?", name)
+	}
+	rows := make([]optionCount, 0)
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
 }
 
 func applyFilter(f *entity.ProductFilter) func(db *gorm.DB) *gorm.DB {
@@ -240,6 +824,9 @@ func applyFilter(f *entity.ProductFilter) func(db *gorm.DB) *gorm.DB {
 			applyPriceFilter(f, db),
 			applyDescriptionFilter(f, db),
 			applyCategoryIDFilter(f, db),
+			applyPriceBucketFilter(f, db),
+			applyInStockFilter(f, db),
+			applyVariantFilter(f, db),
 			applyCreatedAtFilter(f, db),
 			applyUpdatedAtFilter(f, db),
 			applyDeletedFilter(f, db),
@@ -249,8 +836,8 @@ func applyFilter(f *entity.ProductFilter) func(db *gorm.DB) *gorm.DB {
 
 func applyKeywordFilter(f *entity.ProductFilter, db *gorm.DB) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
-		if f.Keyword != "" {
-			db = db.Where("name LIKE ? OR description LIKE ?", "%"+f.Keyword+"%", "%"+f.Keyword+"%")
+		if keyword := utils.NormalizeSearchText(f.Keyword); keyword != "" {
+			db = db.Where(`search_text LIKE ? ESCAPE '\'`, utils.LikeContains(keyword))
 		}
 		return db
 	}
@@ -268,7 +855,7 @@ func applyIDFilter(f *entity.ProductFilter, db *gorm.DB) func(db *gorm.DB) *gorm
 func applyNameFilter(f *entity.ProductFilter, db *gorm.DB) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
 		if f.Name != "" {
-			db = db.Where("name LIKE ?", "%"+f.Name+"%")
+			db = db.Where(`name LIKE ? ESCAPE '\'`, utils.LikeContains(f.Name))
 		}
 		return db
 	}
@@ -289,7 +876,7 @@ func applyPriceFilter(f *entity.ProductFilter, db *gorm.DB) func(db *gorm.DB) *g
 func applyDescriptionFilter(f *entity.ProductFilter, db *gorm.DB) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
 		if f.Description != "" {
-			db = db.Where("description LIKE ?", "%"+f.Description+"%")
+			db = db.Where(`description LIKE ? ESCAPE '\'`, utils.LikeContains(f.Description))
 		}
 		return db
 	}
@@ -297,13 +884,96 @@ func applyDescriptionFilter(f *entity.ProductFilter, db *gorm.DB) func(db *gorm.
 
 func applyCategoryIDFilter(f *entity.ProductFilter, db *gorm.DB) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
-		if f.CategoryID != 0 {
-			db = db.Where("category_id = ?", f.CategoryID)
+		if ids := f.Categories(); len(ids) != 0 {
+			db = db.Where("category_id IN ?", ids)
 		}
 		return db
 	}
 }
 
+// applyPriceBucketFilter keeps the products priced within any of the buckets
+func applyPriceBucketFilter(f *entity.ProductFilter, db *gorm.DB) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		conditions := make([]string, 0, len(f.PriceBuckets))
+		args := make([]interface{}, 0, len(f.PriceBuckets)*2)
+		for _, key := range f.PriceBuckets {
+			bucket, ok := entity.ParsePriceBucket(key)
+			if !ok {
+				continue
+			}
+			if bucket.To == 0 {
+				conditions = append(conditions, "products.price >= ?")
+				args = append(args, bucket.From)
+				continue
+			}
+			conditions = append(conditions, "(products.price >= ? AND products.price < ?)")
+			args = append(args, bucket.From, bucket.To)
+		}
+		if len(conditions) == 0 {
+			return db
+		}
+		return db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+}
+
+// applyInStockFilter keeps the products that can be sold, the ones with stock or with a variant with stock
+func applyInStockFilter(f *entity.ProductFilter, db *gorm.DB) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !f.InStock {
+			return db
+		}
+		variants := db.Session(&gorm.Session{NewDB: true}).
+			Model(&entity.ProductVariant{}).
+			Select("1").
+			Where("product_variants.product_id = products.id")
+		inStockVariants := variants.Session(&gorm.Session{}).Where("product_variants.stock > 0")
+		return db.Where("((products.stock > 0 AND NOT EXISTS (?)) OR EXISTS (?))", variants, inStockVariants)
+	}
+}
+
+// applyVariantFilter keeps the products that have a variant with the SKU and the given option values, all on the
+// same variant. Several values of one option are alternatives, the variant needs one of them
+func applyVariantFilter(f *entity.ProductFilter, db *gorm.DB) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if f.SKU == "" && len(f.Options) == 0 {
+			return db
+		}
+		variants := db.Session(&gorm.Session{NewDB: true}).
+			Model(&entity.ProductVariant{}).
+			Select("product_variants.product_id")
+		if f.SKU != "" {
+			variants = variants.Where("product_variants.sku = ?", entity.NormalizeSKU(f.SKU))
+		}
+		names, values := optionValues(f.Options)
+		for _, name := range names {
+			options := db.Session(&gorm.Session{NewDB: true}).
+				Model(&entity.ProductVariantOption{}).
+				Select("1").
+				Where("product_variant_options.variant_id = product_variants.id").
+				Where("product_variant_options.name = ? AND LOWER(product_variant_options.value) IN ?", name, values[name])
+			variants = variants.Where("EXISTS (?)", options)
+		}
+		return db.Where("products.id IN (?)", variants)
+	}
+}
+
+// optionValues groups the lower cased values of the option filters by option, the options in the order they come
+func optionValues(options []string) ([]string, map[string][]string) {
+	names := make([]string, 0)
+	values := make(map[string][]string)
+	for _, option := range options {
+		name, value, ok := entity.ParseVariantOption(option)
+		if !ok {
+			continue
+		}
+		if _, ok := values[name]; !ok {
+			names = append(names, name)
+		}
+		values[name] = append(values[name], strings.ToLower(value))
+	}
+	return names, values
+}
+
 func applyCreatedAtFilter(f *entity.ProductFilter, db *gorm.DB) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
 		if f.CreatedAtFrom != nil {
@@ -0,0 +1,189 @@
+package promotions
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"math"
+	"pm/domain/entity"
+	"pm/domain/repository/promotions"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+)
+
+const entityName = "promotions"
+
+type PromotionRepository struct {
+	c  *gin.Context
+	p  *base.Persistence
+	db *gorm.DB
+}
+
+func NewPromotionRepository(c *gin.Context, p *base.Persistence, db *gorm.DB) promotions.PromotionRepository {
+	return PromotionRepository{c, p, db}
+}
+
+func (pr PromotionRepository) Create(promotion *entity.Promotion) error {
+	span := pr.p.Logger.Start(pr.c, "CREATE_PROMOTION_DATABASE")
+	defer span.End()
+	pr.p.Logger.Info("STARTING: CREATE PROMOTION", map[string]interface{}{"promotion": promotion})
+
+	if err := pr.db.Create(promotion).Error; err != nil {
+		pr.p.Logger.Error("CREATE_PROMOTION: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+// Update saves the settings of the promotion, never its usage count which only Use and Release move
+func (pr PromotionRepository) Update(promotion *entity.Promotion) error {
+	span := pr.p.Logger.Start(pr.c, "UPDATE_PROMOTION_DATABASE")
+	defer span.End()
+	pr.p.Logger.Info("STARTING: UPDATE PROMOTION", map[string]interface{}{"promotion": promotion})
+
+	err := pr.db.Model(promotion).
+		Select("*").
+		Omit("ID", "CreatedAt", "DeletedAt", "UsedCount").
+		Updates(promotion).Error
+	if err != nil {
+		pr.p.Logger.Error("UPDATE_PROMOTION: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+func (pr PromotionRepository) GetPromotionByID(id int64) (*entity.Promotion, error) {
+	var promotion entity.Promotion
+	if err := pr.db.First(&promotion, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payload.ErrEntityNotFound(entityName, err)
+		}
+		return nil, payload.ErrDB(err)
+	}
+	return &promotion, nil
+}
+
+func (pr PromotionRepository) GetPromotionByCode(code string) (*entity.Promotion, error) {
+	return pr.getPromotionByCode(pr.db, code)
+}
+
+func (pr PromotionRepository) GetAllPromotions(pagination *entity.Pagination) ([]entity.Promotion, error) {
+	span := pr.p.Logger.Start(pr.c, "GET_ALL_PROMOTIONS_DATABASE")
+	defer span.End()
+
+	var totalRows int64
+	listPromotions := make([]entity.Promotion, 0)
+	db := pr.db.Model(&entity.Promotion{}).Count(&totalRows)
+	if err := db.Scopes(utils.Paginate(pagination, entity.PromotionSortColumns)).Find(&listPromotions).Error; err != nil {
+		pr.p.Logger.Error("GET_ALL_PROMOTIONS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrDB(err)
+	}
+	pagination.TotalRows = totalRows
+	pagination.TotalPages = int(math.Ceil(float64(totalRows) / float64(pagination.GetLimit())))
+	return listPromotions, nil
+}
+
+func (pr PromotionRepository) DeletePromotion(promotion *entity.Promotion) error {
+	span := pr.p.Logger.Start(pr.c, "DELETE_PROMOTION_DATABASE")
+	defer span.End()
+
+	if err := pr.db.Delete(promotion).Error; err != nil {
+		pr.p.Logger.Error("DELETE_PROMOTION: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+// LockPromotionByCode loads the promotion with a row lock held until the surrounding transaction ends, so the
+// usage limits of a code are checked and counted by one order at a time
+func (pr PromotionRepository) LockPromotionByCode(code string) (*entity.Promotion, error) {
+	return pr.getPromotionByCode(pr.db.Clauses(clause.Locking{Strength: "UPDATE"}), code)
+}
+
+func (pr PromotionRepository) getPromotionByCode(db *gorm.DB, code string) (*entity.Promotion, error) {
+	var promotion entity.Promotion
+	if err := db.Where("code = ?", entity.NormalizePromotionCode(code)).First(&promotion).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payload.ErrEntityNotFound(entityName, fmt.Errorf("promotion code [%s] not found", code))
+		}
+		return nil, payload.ErrDB(err)
+	}
+	return &promotion, nil
+}
+
+func (pr PromotionRepository) CountUsagesByUser(promotionID uint, userID uint) (int64, error) {
+	var count int64
+	err := pr.db.Model(&entity.PromotionUsage{}).
+		Where("promotion_id = ? AND user_id = ?", promotionID, userID).
+		Count(&count).Error
+	if err != nil {
+		pr.p.Logger.Error("COUNT_PROMOTION_USAGES: ERROR", map[string]interface{}{"error": err.Error()})
+		return 0, payload.ErrDB(err)
+	}
+	return count, nil
+}
+
+// Use counts one use of the promotion by the order. The count only moves while it is under the usage limit,
+// so the limit holds even for a caller that did not lock the promotion
+func (pr PromotionRepository) Use(promotion *entity.Promotion, userID uint, orderID uint) error {
+	span := pr.p.Logger.Start(pr.c, "USE_PROMOTION_DATABASE")
+	defer span.End()
+	pr.p.Logger.Info("STARTING: USE PROMOTION", map[string]interface{}{"promotion_id": promotion.ID, "user_id": userID, "order_id": orderID})
+
+	err := pr.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.Promotion{}).
+			Where("id = ? AND (usage_limit = 0 OR used_count < usage_limit)", promotion.ID).
+			Update("used_count", gorm.Expr("used_count + 1"))
+		if result.Error != nil {
+			return payload.ErrDB(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return payload.ErrInvalidRequest(fmt.Errorf("%w: code %s has reached its usage limit", entity.ErrPromotionNotApplicable, promotion.Code))
+		}
+
+		usage := entity.PromotionUsage{PromotionID: promotion.ID, UserID: userID, OrderID: orderID}
+		if err := tx.Create(&usage).Error; err != nil {
+			return payload.ErrDB(err)
+		}
+		return nil
+	})
+	if err != nil {
+		pr.p.Logger.Error("USE_PROMOTION: ERROR", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+	promotion.UsedCount++
+	return nil
+}
+
+// Release gives the use of a promotion by the order back, it does nothing when the order used none
+func (pr PromotionRepository) Release(orderID uint) error {
+	span := pr.p.Logger.Start(pr.c, "RELEASE_PROMOTION_DATABASE")
+	defer span.End()
+
+	err := pr.db.Transaction(func(tx *gorm.DB) error {
+		usages := make([]entity.PromotionUsage, 0)
+		if err := tx.Where("order_id = ?", orderID).Find(&usages).Error; err != nil {
+			return err
+		}
+		for _, usage := range usages {
+			if err := tx.Delete(&usage).Error; err != nil {
+				return err
+			}
+			err := tx.Model(&entity.Promotion{}).
+				Where("id = ? AND used_count > 0", usage.PromotionID).
+				Update("used_count", gorm.Expr("used_count - 1")).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		pr.p.Logger.Error("RELEASE_PROMOTION: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,172 @@
+package purchase_orders
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"math"
+	"pm/domain/entity"
+	"pm/domain/repository/purchase_orders"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+)
+
+const entityName = "purchase_orders"
+
+type PurchaseOrderRepository struct {
+	c  *gin.Context
+	p  *base.Persistence
+	db *gorm.DB
+}
+
+func NewPurchaseOrderRepository(c *gin.Context, p *base.Persistence, db *gorm.DB) purchase_orders.PurchaseOrderRepository {
+	return PurchaseOrderRepository{c, p, db}
+}
+
+// Create saves the purchase order with its lines
+func (pr PurchaseOrderRepository) Create(purchaseOrder *entity.PurchaseOrder) error {
+	span := pr.p.Logger.Start(pr.c, "CREATE_PURCHASE_ORDER_DATABASE")
+	defer span.End()
+	pr.p.Logger.Info("STARTING: CREATE PURCHASE ORDER", map[string]interface{}{"purchase_order": purchaseOrder})
+
+	if err := pr.db.Omit("Supplier").Create(purchaseOrder).Error; err != nil {
+		pr.p.Logger.Error("CREATE_PURCHASE_ORDER: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+// Update saves what the purchase order orders from whom, its lines are saved by ReplaceLines and its status by
+// UpdateStatus
+func (pr PurchaseOrderRepository) Update(purchaseOrder *entity.PurchaseOrder) error {
+	span := pr.p.Logger.Start(pr.c, "UPDATE_PURCHASE_ORDER_DATABASE")
+	defer span.End()
+	pr.p.Logger.Info("STARTING: UPDATE PURCHASE ORDER", map[string]interface{}{"purchase_order": purchaseOrder})
+
+	err := pr.db.Model(purchaseOrder).
+		Select("SupplierID", "WarehouseID", "Note").
+		Updates(purchaseOrder).Error
+	if err != nil {
+		pr.p.Logger.Error("UPDATE_PURCHASE_ORDER: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+// ReplaceLines deletes the lines of the purchase order and saves the given ones in their place
+func (pr PurchaseOrderRepository) ReplaceLines(purchaseOrder *entity.PurchaseOrder, lines []entity.PurchaseOrderLine) error {
+	span := pr.p.Logger.Start(pr.c, "REPLACE_PURCHASE_ORDER_LINES_DATABASE")
+	defer span.End()
+
+	err := pr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("purchase_order_id = ?", purchaseOrder.ID).Delete(&entity.PurchaseOrderLine{}).Error; err != nil {
+			return err
+		}
+		for i := range lines {
+			lines[i].PurchaseOrderID = purchaseOrder.ID
+		}
+		return tx.Create(&lines).Error
+	})
+	if err != nil {
+		pr.p.Logger.Error("REPLACE_PURCHASE_ORDER_LINES: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	purchaseOrder.Lines = lines
+	return nil
+}
+
+func (pr PurchaseOrderRepository) GetPurchaseOrderByID(id int64) (*entity.PurchaseOrder, error) {
+	return pr.getPurchaseOrderByID(pr.db, id)
+}
+
+// LockPurchaseOrder loads the purchase order with a row lock held until the surrounding transaction ends, so goods
+// are received for it by one request at a time
+func (pr PurchaseOrderRepository) LockPurchaseOrder(id int64) (*entity.PurchaseOrder, error) {
+	return pr.getPurchaseOrderByID(pr.db.Clauses(clause.Locking{Strength: "UPDATE"}), id)
+}
+
+func (pr PurchaseOrderRepository) GetAllPurchaseOrders(filter *entity.PurchaseOrderFilter, pagination *entity.Pagination) ([]entity.PurchaseOrder, error) {
+	span := pr.p.Logger.Start(pr.c, "GET_ALL_PURCHASE_ORDERS_DATABASE")
+	defer span.End()
+
+	var totalRows int64
+	listPurchaseOrders := make([]entity.PurchaseOrder, 0)
+	db := pr.db.Model(&entity.PurchaseOrder{})
+	if filter != nil && filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+	if filter != nil && filter.SupplierID != 0 {
+		db = db.Where("supplier_id = ?", filter.SupplierID)
+	}
+	db = db.Count(&totalRows)
+	err := db.Scopes(utils.Paginate(pagination, entity.PurchaseOrderSortColumns)).
+		Preload("Supplier", unscoped).
+		Preload("Lines", orderLines).
+		Find(&listPurchaseOrders).Error
+	if err != nil {
+		pr.p.Logger.Error("GET_ALL_PURCHASE_ORDERS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrDB(err)
+	}
+	pagination.TotalRows = totalRows
+	pagination.TotalPages = int(math.Ceil(float64(totalRows) / float64(pagination.GetLimit())))
+	return listPurchaseOrders, nil
+}
+
+// UpdateStatus saves the status of the purchase order with the times it was sent and received
+func (pr PurchaseOrderRepository) UpdateStatus(purchaseOrder *entity.PurchaseOrder) error {
+	span := pr.p.Logger.Start(pr.c, "UPDATE_PURCHASE_ORDER_STATUS_DATABASE")
+	defer span.End()
+	pr.p.Logger.Info("STARTING: UPDATE PURCHASE ORDER STATUS", map[string]interface{}{"id": purchaseOrder.ID, "status": purchaseOrder.Status})
+
+	err := pr.db.Model(purchaseOrder).
+		Select("Status", "SentAt", "ReceivedAt").
+		Updates(purchaseOrder).Error
+	if err != nil {
+		pr.p.Logger.Error("UPDATE_PURCHASE_ORDER_STATUS: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+// ReceiveLine counts quantity more of the line as received. The count only moves while it stays within the quantity
+// ordered, so a line is never received more than once
+func (pr PurchaseOrderRepository) ReceiveLine(line *entity.PurchaseOrderLine, quantity int64) error {
+	result := pr.db.Model(&entity.PurchaseOrderLine{}).
+		Where("id = ? AND received_quantity + ? <= quantity", line.ID, quantity).
+		Update("received_quantity", gorm.Expr("received_quantity + ?", quantity))
+	if result.Error != nil {
+		pr.p.Logger.Error("RECEIVE_PURCHASE_ORDER_LINE: ERROR", map[string]interface{}{"error": result.Error.Error()})
+		return payload.ErrDB(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return payload.ErrInvalidRequest(fmt.Errorf("only %d of the line [%d] are left to receive", line.Remaining(), line.ID))
+	}
+	line.ReceivedQuantity += quantity
+	return nil
+}
+
+func (pr PurchaseOrderRepository) getPurchaseOrderByID(db *gorm.DB, id int64) (*entity.PurchaseOrder, error) {
+	var purchaseOrder entity.PurchaseOrder
+	err := db.Preload("Supplier", unscoped).
+		Preload("Lines", orderLines).
+		First(&purchaseOrder, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payload.ErrEntityNotFound(entityName, fmt.Errorf("purchase order with id [%d] not found", id))
+		}
+		return nil, payload.ErrDB(err)
+	}
+	return &purchaseOrder, nil
+}
+
+// unscoped keeps showing the supplier of a purchase order after the supplier is deleted
+func unscoped(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+func orderLines(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
\ No newline at end of file
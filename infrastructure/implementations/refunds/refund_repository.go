@@ -0,0 +1,105 @@
+package refunds
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"pm/domain/entity"
+	"pm/domain/repository/refunds"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/persistences/base"
+)
+
+type RefundRepository struct {
+	c  *gin.Context
+	p  *base.Persistence
+	db *gorm.DB
+}
+
+func NewRefundRepository(c *gin.Context, p *base.Persistence, db *gorm.DB) refunds.RefundRepository {
+	return RefundRepository{c, p, db}
+}
+
+// Create saves the refund together with its items
+func (r RefundRepository) Create(refund *entity.Refund) error {
+	span := r.p.Logger.Start(r.c, "CREATE_REFUND_DATABASE")
+	defer span.End()
+	r.p.Logger.Info("STARTING: CREATE REFUND", map[string]interface{}{"refund": refund})
+
+	if err := r.db.Create(refund).Error; err != nil {
+		r.p.Logger.Error("CREATE_REFUND: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+
+	r.p.Logger.Info("CREATE_REFUND_SUCCESSFULLY", map[string]interface{}{"refund": refund})
+	return nil
+}
+
+// Update saves the outcome the gateway gave for the refund
+func (r RefundRepository) Update(refund *entity.Refund) error {
+	span := r.p.Logger.Start(r.c, "UPDATE_REFUND_DATABASE")
+	defer span.End()
+	r.p.Logger.Info("STARTING: UPDATE REFUND", map[string]interface{}{"refund": refund})
+
+	err := r.db.Model(refund).
+		Select("Status", "ProviderRef", "FailureReason").
+		Updates(refund).Error
+	if err != nil {
+		r.p.Logger.Error("UPDATE_REFUND: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+
+	r.p.Logger.Info("UPDATE_REFUND_SUCCESSFULLY", map[string]interface{}{"refund": refund})
+	return nil
+}
+
+func (r RefundRepository) GetRefundsByOrderID(orderID int64) ([]entity.Refund, error) {
+	listRefunds := make([]entity.Refund, 0)
+	err := r.db.Preload("RefundItems").
+		Where("order_id = ?", orderID).
+		Order("id asc").
+		Find(&listRefunds).Error
+	if err != nil {
+		r.p.Logger.Error("GET_REFUNDS_BY_ORDER_ID: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrDB(err)
+	}
+	return listRefunds, nil
+}
+
+// GetRefundedAmountByPaymentID sums every refund of the payment in one of the given statuses
+func (r RefundRepository) GetRefundedAmountByPaymentID(paymentID int64, statuses ...string) (float64, error) {
+	var refunded float64
+	err := r.db.Model(&entity.Refund{}).
+		Where("payment_id = ? AND status IN ?", paymentID, statuses).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&refunded).Error
+	if err != nil {
+		r.p.Logger.Error("GET_REFUNDED_AMOUNT: ERROR", map[string]interface{}{"error": err.Error()})
+		return 0, payload.ErrDB(err)
+	}
+	return refunded, nil
+}
+
+// GetRefundedQuantitiesByOrderID returns, for every order item of the order, the quantity of the refunds in one of the
+// given statuses
+func (r RefundRepository) GetRefundedQuantitiesByOrderID(orderID int64, statuses ...string) (map[uint]int, error) {
+	var rows []struct {
+		OrderItemID uint
+		Quantity    int
+	}
+	err := r.db.Model(&entity.RefundItem{}).
+		Select("refund_items.order_item_id, SUM(refund_items.quantity) AS quantity").
+		Joins("JOIN refunds ON refunds.id = refund_items.refund_id AND refunds.deleted_at IS NULL").
+		Where("refunds.order_id = ? AND refunds.status IN ?", orderID, statuses).
+		Group("refund_items.order_item_id").
+		Scan(&rows).Error
+	if err != nil {
+		r.p.Logger.Error("GET_REFUNDED_QUANTITIES: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrDB(err)
+	}
+
+	quantities := make(map[uint]int)
+	for _, row := range rows {
+		quantities[row.OrderItemID] = row.Quantity
+	}
+	return quantities, nil
+}
\ No newline at end of file
@@ -0,0 +1,81 @@
+package stock_movements
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"math"
+	"pm/domain/entity"
+	"pm/domain/repository/stock_movements"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+)
+
+type StockMovementRepository struct {
+	c  *gin.Context
+	p  *base.Persistence
+	db *gorm.DB
+}
+
+func NewStockMovementRepository(c *gin.Context, p *base.Persistence, db *gorm.DB) stock_movements.StockMovementRepository {
+	return StockMovementRepository{c, p, db}
+}
+
+func (s StockMovementRepository) Create(movements ...entity.StockMovement) error {
+	if len(movements) == 0 {
+		return nil
+	}
+	if err := s.db.Create(&movements).Error; err != nil {
+		s.p.Logger.Error("CREATE_STOCK_MOVEMENTS: ERROR", map[string]interface{}{"error": err.Error(), "movements": movements})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+// GetStockHistory returns the movements of the product and its variants, or only of one of its variants, newest first
+func (s StockMovementRepository) GetStockHistory(productID int64, variantID *uint, pagination *entity.Pagination) ([]entity.StockMovement, error) {
+	span := s.p.Logger.Start(s.c, "GET_STOCK_HISTORY_DATABASE")
+	defer span.End()
+
+	var totalRows int64
+	movements := make([]entity.StockMovement, 0)
+	db := s.db.Model(&entity.StockMovement{}).Where("product_id = ?", productID)
+	if variantID != nil {
+		db = db.Where("variant_id = ?", *variantID)
+	}
+	db = db.Count(&totalRows)
+	err := db.Scopes(utils.Paginate(pagination, entity.StockMovementSortColumns, entity.SortField{Column: "stock_movements.created_at", Desc: true})).
+		Find(&movements).Error
+	if err != nil {
+		s.p.Logger.Error("GET_STOCK_HISTORY: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrDB(err)
+	}
+	pagination.TotalRows = totalRows
+	pagination.TotalPages = int(math.Ceil(float64(totalRows) / float64(pagination.GetLimit())))
+	return movements, nil
+}
+
+// SumMovements is the stock the ledger gives the product, or one of its variants, to be compared with its counter
+func (s StockMovementRepository) SumMovements(productID int64, variantID *uint) (int64, error) {
+	var sum int64
+	err := s.db.Model(&entity.StockMovement{}).
+		Where("product_id = ?", productID).
+		Scopes(stockOf(variantID)).
+		Select("COALESCE(SUM(quantity), 0)").
+		Scan(&sum).Error
+	if err != nil {
+		s.p.Logger.Error("SUM_STOCK_MOVEMENTS: ERROR", map[string]interface{}{"error": err.Error()})
+		return 0, payload.ErrDB(err)
+	}
+	return sum, nil
+}
+
+// stockOf keeps the movements of one stock counter, the variant's or the product's own
+func stockOf(variantID *uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if variantID != nil {
+			return db.Where("variant_id = ?", *variantID)
+		}
+		return db.Where("variant_id IS NULL")
+	}
+}
\ No newline at end of file
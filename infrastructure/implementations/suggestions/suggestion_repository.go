@@ -0,0 +1,399 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"pm/domain/entity"
+	"pm/domain/repository/suggestions"
+	"pm/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The index is a sorted set whose members all have the same score, so redis keeps them in lexical order and serves
+// every term starting with a prefix as one range. Every suffix of words of a name is a term, so a name is found by
+// any of its words. The words set lists the words of all names for the typo correction, it is only ever added to
+// between rebuilds as a word can be shared by several names
+const (
+	termsKey      = "suggestions:terms"
+	wordsKey      = "suggestions:words"
+	labelsKey     = "suggestions:labels"
+	rebuildSuffix = ":rebuild"
+	separator     = "\x00"
+
+	// maxTermLength bounds the terms, nobody types further than that into a search box
+	maxTermLength = 64
+	// candidatesPerResult is how many terms are read for every suggestion asked for, a name has one term per word
+	candidatesPerResult = 4
+	// maxWordCandidates bounds the words a misspelled word is compared with
+	maxWordCandidates = 2000
+	// maxCorrections is how many spellings of a misspelled word are tried
+	maxCorrections = 3
+	// rebuildBatchSize is how many suggestions are sent to redis at once while rebuilding
+	rebuildBatchSize = 500
+)
+
+type SuggestionRepository struct {
+	rdb *redis.Client
+	ctx context.Context
+}
+
+func NewSuggestionRepository(rdb *redis.Client, ctx context.Context) suggestions.SuggestionRepository {
+	return &SuggestionRepository{rdb, ctx}
+}
+
+// Rebuild indexes the suggestions from scratch. The new index is built aside and replaces the old one at once, so
+// suggestions keep being served while it is built
+func (sr *SuggestionRepository) Rebuild(list []entity.Suggestion) error {
+	if sr.rdb == nil {
+		return fmt.Errorf("RedisDriver not found")
+	}
+	keys := []string{termsKey, wordsKey, labelsKey}
+	if err := sr.rdb.Del(sr.ctx, termsKey+rebuildSuffix, wordsKey+rebuildSuffix, labelsKey+rebuildSuffix).Err(); err != nil {
+		return err
+	}
+	for start := 0; start < len(list); start += rebuildBatchSize {
+		pipe := sr.rdb.Pipeline()
+		for _, s := range list[start:min(start+rebuildBatchSize, len(list))] {
+			sr.add(pipe, rebuildSuffix, s)
+		}
+		if _, err := pipe.Exec(sr.ctx); err != nil {
+			return err
+		}
+	}
+
+	existing := make([]int64, 0, len(keys))
+	for _, key := range keys {
+		count, err := sr.rdb.Exists(sr.ctx, key+rebuildSuffix).Result()
+		if err != nil {
+			return err
+		}
+		existing = append(existing, count)
+	}
+	_, err := sr.rdb.TxPipelined(sr.ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			if existing[i] == 0 {
+				pipe.Del(sr.ctx, key)
+				continue
+			}
+			pipe.Rename(sr.ctx, key+rebuildSuffix, key)
+		}
+		return nil
+	})
+	return err
+}
+
+// Index adds the suggestion to the index, in place of what was indexed for the same product or category before
+func (sr *SuggestionRepository) Index(s entity.Suggestion) error {
+	if sr.rdb == nil {
+		return fmt.Errorf("RedisDriver not found")
+	}
+	key := suggestionKey(s.Kind, s.ID)
+	old, err := sr.rdb.HGet(sr.ctx, labelsKey, key).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	_, err = sr.rdb.TxPipelined(sr.ctx, func(pipe redis.Pipeliner) error {
+		if members := termMembers(old, key); len(members) > 0 {
+			pipe.ZRem(sr.ctx, termsKey, members...)
+		}
+		sr.add(pipe, "", s)
+		return nil
+	})
+	return err
+}
+
+// Remove takes the product or the category out of the index
+func (sr *SuggestionRepository) Remove(kind string, id uint) error {
+	if sr.rdb == nil {
+		return fmt.Errorf("RedisDriver not found")
+	}
+	key := suggestionKey(kind, id)
+	old, err := sr.rdb.HGet(sr.ctx, labelsKey, key).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		return err
+	}
+	_, err = sr.rdb.TxPipelined(sr.ctx, func(pipe redis.Pipeliner) error {
+		if members := termMembers(old, key); len(members) > 0 {
+			pipe.ZRem(sr.ctx, termsKey, members...)
+		}
+		pipe.HDel(sr.ctx, labelsKey, key)
+		return nil
+	})
+	return err
+}
+
+// Suggest returns up to limit products and categories with a word starting like the query, the ones whose name
+// starts like it first. When that is not enough, the words of the query that are one or two typos away from indexed
+// words are corrected and the corrected queries fill up the rest
+func (sr *SuggestionRepository) Suggest(query string, limit int) ([]entity.Suggestion, error) {
+	list := make([]entity.Suggestion, 0)
+	if sr.rdb == nil {
+		return list, fmt.Errorf("RedisDriver not found")
+	}
+	query = utils.NormalizeSearchText(query)
+	if query == "" || limit <= 0 {
+		return list, nil
+	}
+
+	found := make([]string, 0, limit)
+	if err := sr.collect(query, limit, &found); err != nil {
+		return list, err
+	}
+	if len(found) < limit {
+		alternatives, err := sr.corrections(query)
+		if err != nil {
+			return list, err
+		}
+		for _, alternative := range alternatives {
+			if err := sr.collect(alternative, limit, &found); err != nil {
+				return list, err
+			}
+			if len(found) >= limit {
+				break
+			}
+		}
+	}
+	if len(found) == 0 {
+		return list, nil
+	}
+
+	labels, err := sr.rdb.HMGet(sr.ctx, labelsKey, found...).Result()
+	if err != nil {
+		return list, err
+	}
+	for i, key := range found {
+		label, ok := labels[i].(string)
+		kind, id, valid := parseSuggestionKey(key)
+		if !ok || !valid {
+			continue
+		}
+		list = append(list, entity.Suggestion{Kind: kind, ID: id, Label: label})
+	}
+	return list, nil
+}
+
+// add queues the commands indexing the suggestion into the keys with the given suffix
+func (sr *SuggestionRepository) add(pipe redis.Pipeliner, suffix string, s entity.Suggestion) {
+	key := suggestionKey(s.Kind, s.ID)
+	members := termMembers(s.Label, key)
+	if len(members) == 0 {
+		pipe.HDel(sr.ctx, labelsKey+suffix, key)
+		return
+	}
+	terms := make([]redis.Z, 0, len(members))
+	for _, member := range members {
+		terms = append(terms, redis.Z{Member: member})
+	}
+	words := make([]redis.Z, 0)
+	for _, word := range strings.Fields(utils.NormalizeSearchText(s.Label)) {
+		words = append(words, redis.Z{Member: word})
+	}
+	pipe.ZAdd(sr.ctx, termsKey+suffix, terms...)
+	pipe.ZAdd(sr.ctx, wordsKey+suffix, words...)
+	pipe.HSet(sr.ctx, labelsKey+suffix, key, s.Label)
+}
+
+// collect appends to found the keys of the products and categories with a term starting with the prefix, until
+// there are limit of them
+func (sr *SuggestionRepository) collect(prefix string, limit int, found *[]string) error {
+	members, err := sr.rdb.ZRangeByLex(sr.ctx, termsKey, &redis.ZRangeBy{
+		Min:   "[" + prefix,
+		Max:   "[" + prefix + "\xff",
+		Count: int64(limit * candidatesPerResult),
+	}).Result()
+	if err != nil {
+		return err
+	}
+
+	type candidate struct {
+		term     string
+		key      string
+		position int
+	}
+	candidates := make([]candidate, 0, len(members))
+	for _, member := range members {
+		parts := strings.Split(member, separator)
+		if len(parts) != 3 {
+			continue
+		}
+		position, _ := strconv.Atoi(parts[2])
+		candidates = append(candidates, candidate{parts[0], parts[1], position})
+	}
+	// names starting like the prefix come first, the shortest of them first as they are the closest matches
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if (candidates[i].position == 0) != (candidates[j].position == 0) {
+			return candidates[i].position == 0
+		}
+		return len(candidates[i].term) < len(candidates[j].term)
+	})
+	for _, c := range candidates {
+		if len(*found) >= limit {
+			break
+		}
+		if !containsKey(*found, c.key) {
+			*found = append(*found, c.key)
+		}
+	}
+	return nil
+}
+
+// corrections returns the query with one of its words replaced by an indexed word a typo or two away from it, the
+// closest spellings first. The last word is compared with the beginning of the indexed words as it may not be
+// typed in full yet
+func (sr *SuggestionRepository) corrections(query string) ([]string, error) {
+	words := strings.Fields(query)
+	alternatives := make([]string, 0)
+	for i, word := range words {
+		fixes, err := sr.correctWord(word, i == len(words)-1)
+		if err != nil {
+			return nil, err
+		}
+		for _, fix := range fixes {
+			corrected := append(append(append([]string{}, words[:i]...), fix), words[i+1:]...)
+			alternatives = append(alternatives, strings.Join(corrected, " "))
+		}
+	}
+	return alternatives, nil
+}
+
+func (sr *SuggestionRepository) correctWord(word string, prefix bool) ([]string, error) {
+	runes := []rune(word)
+	tolerance := typoTolerance(len(runes))
+	if tolerance == 0 {
+		return nil, nil
+	}
+	// typos are rarely made on the first letter, only the words starting with it are compared
+	first := string(runes[0])
+	candidates, err := sr.rdb.ZRangeByLex(sr.ctx, wordsKey, &redis.ZRangeBy{
+		Min:   "[" + first,
+		Max:   "[" + first + "\xff",
+		Count: maxWordCandidates,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	type fix struct {
+		word     string
+		distance int
+	}
+	fixes := make([]fix, 0)
+	for _, candidate := range candidates {
+		if candidate == word {
+			continue
+		}
+		if d := editDistance(runes, []rune(candidate), prefix); d <= tolerance {
+			fixes = append(fixes, fix{candidate, d})
+		}
+	}
+	sort.SliceStable(fixes, func(i, j int) bool {
+		return fixes[i].distance < fixes[j].distance
+	})
+	words := make([]string, 0, maxCorrections)
+	for _, f := range fixes[:min(len(fixes), maxCorrections)] {
+		words = append(words, f.word)
+	}
+	return words, nil
+}
+
+// typoTolerance is how many typos a word of the given length may have, short words have too many neighbours to
+// correct any
+func typoTolerance(length int) int {
+	switch {
+	case length < 3:
+		return 0
+	case length <= 5:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance counts the insertions, deletions, substitutions and swaps of adjacent letters turning a into b, or
+// into the closest beginning of b when prefix is set
+func editDistance(a, b []rune, prefix bool) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	if !prefix {
+		return d[len(a)][len(b)]
+	}
+	distance := d[len(a)][0]
+	for _, v := range d[len(a)] {
+		distance = min(distance, v)
+	}
+	return distance
+}
+
+// termMembers returns the members indexing the label under the key, one per suffix of its words with the position
+// of the word it starts at
+func termMembers(label string, key string) []interface{} {
+	words := strings.Fields(utils.NormalizeSearchText(label))
+	members := make([]interface{}, 0, len(words))
+	for i := range words {
+		term := truncate(strings.Join(words[i:], " "), maxTermLength)
+		members = append(members, term+separator+key+separator+strconv.Itoa(i))
+	}
+	return members
+}
+
+// truncate cuts the text to at most max bytes without cutting a letter in two
+func truncate(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	for max > 0 && !utf8.RuneStart(text[max]) {
+		max--
+	}
+	return text[:max]
+}
+
+func suggestionKey(kind string, id uint) string {
+	return kind + ":" + strconv.FormatUint(uint64(id), 10)
+}
+
+func parseSuggestionKey(key string) (string, uint, bool) {
+	kind, id, ok := strings.Cut(key, ":")
+	if !ok {
+		return "", 0, false
+	}
+	value, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return kind, uint(value), true
+}
+
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
\ No newline at end of file
@@ -0,0 +1,107 @@
+package suppliers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"math"
+	"pm/domain/entity"
+	"pm/domain/repository/suppliers"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+)
+
+const entityName = "suppliers"
+
+type SupplierRepository struct {
+	c  *gin.Context
+	p  *base.Persistence
+	db *gorm.DB
+}
+
+func NewSupplierRepository(c *gin.Context, p *base.Persistence, db *gorm.DB) suppliers.SupplierRepository {
+	return SupplierRepository{c, p, db}
+}
+
+func (sr SupplierRepository) Create(supplier *entity.Supplier) error {
+	span := sr.p.Logger.Start(sr.c, "CREATE_SUPPLIER_DATABASE")
+	defer span.End()
+	sr.p.Logger.Info("STARTING: CREATE SUPPLIER", map[string]interface{}{"supplier": supplier})
+
+	if err := sr.db.Create(supplier).Error; err != nil {
+		sr.p.Logger.Error("CREATE_SUPPLIER: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+func (sr SupplierRepository) Update(supplier *entity.Supplier) error {
+	span := sr.p.Logger.Start(sr.c, "UPDATE_SUPPLIER_DATABASE")
+	defer span.End()
+	sr.p.Logger.Info("STARTING: UPDATE SUPPLIER", map[string]interface{}{"supplier": supplier})
+
+	err := sr.db.Model(supplier).
+		Select("Name", "Email", "Phone", "Address").
+		Updates(supplier).Error
+	if err != nil {
+		sr.p.Logger.Error("UPDATE_SUPPLIER: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+func (sr SupplierRepository) GetSupplierByID(id int64) (*entity.Supplier, error) {
+	var supplier entity.Supplier
+	if err := sr.db.First(&supplier, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payload.ErrEntityNotFound(entityName, fmt.Errorf("supplier with id [%d] not found", id))
+		}
+		return nil, payload.ErrDB(err)
+	}
+	return &supplier, nil
+}
+
+func (sr SupplierRepository) GetAllSuppliers(pagination *entity.Pagination) ([]entity.Supplier, error) {
+	span := sr.p.Logger.Start(sr.c, "GET_ALL_SUPPLIERS_DATABASE")
+	defer span.End()
+
+	var totalRows int64
+	listSuppliers := make([]entity.Supplier, 0)
+	db := sr.db.Model(&entity.Supplier{}).Count(&totalRows)
+	if err := db.Scopes(utils.Paginate(pagination, entity.SupplierSortColumns)).Find(&listSuppliers).Error; err != nil {
+		sr.p.Logger.Error("GET_ALL_SUPPLIERS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrDB(err)
+	}
+	pagination.TotalRows = totalRows
+	pagination.TotalPages = int(math.Ceil(float64(totalRows) / float64(pagination.GetLimit())))
+	return listSuppliers, nil
+}
+
+// DeleteSupplier deletes the supplier, which must not have purchase orders still waiting for goods
+func (sr SupplierRepository) DeleteSupplier(supplier *entity.Supplier) error {
+	span := sr.p.Logger.Start(sr.c, "DELETE_SUPPLIER_DATABASE")
+	defer span.End()
+
+	var open int64
+	err := sr.db.Model(&entity.PurchaseOrder{}).
+		Where("supplier_id = ? AND status IN ?", supplier.ID, []string{
+			entity.PurchaseOrderStatusDraft,
+			entity.PurchaseOrderStatusSent,
+			entity.PurchaseOrderStatusPartiallyReceived,
+		}).
+		Count(&open).Error
+	if err != nil {
+		sr.p.Logger.Error("DELETE_SUPPLIER: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	if open > 0 {
+		return payload.ErrInvalidRequest(fmt.Errorf("the supplier [%d] still has open purchase orders", supplier.ID))
+	}
+	if err := sr.db.Delete(supplier).Error; err != nil {
+		sr.p.Logger.Error("DELETE_SUPPLIER: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,176 @@
+package taxes
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"pm/domain/entity"
+	"pm/domain/repository/taxes"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/persistences/base"
+)
+
+const (
+	taxClassEntityName = "tax_classes"
+	taxRateEntityName  = "tax_rates"
+)
+
+type TaxRepository struct {
+	c  *gin.Context
+	p  *base.Persistence
+	db *gorm.DB
+}
+
+func NewTaxRepository(c *gin.Context, p *base.Persistence, db *gorm.DB) taxes.TaxRepository {
+	return TaxRepository{c, p, db}
+}
+
+func (t TaxRepository) CreateTaxClass(taxClass *entity.TaxClass) error {
+	span := t.p.Logger.Start(t.c, "CREATE_TAX_CLASS_DATABASE")
+	defer span.End()
+	t.p.Logger.Info("STARTING: CREATE TAX CLASS", map[string]interface{}{"tax_class": taxClass})
+
+	if err := t.db.Create(taxClass).Error; err != nil {
+		t.p.Logger.Error("CREATE_TAX_CLASS: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+func (t TaxRepository) UpdateTaxClass(taxClass *entity.TaxClass) error {
+	span := t.p.Logger.Start(t.c, "UPDATE_TAX_CLASS_DATABASE")
+	defer span.End()
+	t.p.Logger.Info("STARTING: UPDATE TAX CLASS", map[string]interface{}{"tax_class": taxClass})
+
+	if err := t.db.Model(taxClass).Select("Name", "Description").Updates(taxClass).Error; err != nil {
+		t.p.Logger.Error("UPDATE_TAX_CLASS: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+func (t TaxRepository) GetTaxClassByID(id int64) (*entity.TaxClass, error) {
+	var taxClass entity.TaxClass
+	if err := t.db.First(&taxClass, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payload.ErrEntityNotFound(taxClassEntityName, fmt.Errorf("tax class with id [%d] not found", id))
+		}
+		return nil, payload.ErrDB(err)
+	}
+	return &taxClass, nil
+}
+
+func (t TaxRepository) GetAllTaxClasses() ([]entity.TaxClass, error) {
+	listTaxClasses := make([]entity.TaxClass, 0)
+	if err := t.db.Order("name asc").Find(&listTaxClasses).Error; err != nil {
+		t.p.Logger.Error("GET_ALL_TAX_CLASSES: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrDB(err)
+	}
+	return listTaxClasses, nil
+}
+
+// DeleteTaxClass deletes the tax class with its rates, the products and categories of the class are left without
+// a tax class
+func (t TaxRepository) DeleteTaxClass(taxClass *entity.TaxClass) error {
+	span := t.p.Logger.Start(t.c, "DELETE_TAX_CLASS_DATABASE")
+	defer span.End()
+
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tax_class_id = ?", taxClass.ID).Delete(&entity.TaxRate{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.Product{}).Where("tax_class_id = ?", taxClass.ID).Update("tax_class_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.Category{}).Where("tax_class_id = ?", taxClass.ID).Update("tax_class_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(taxClass).Error
+	})
+	if err != nil {
+		t.p.Logger.Error("DELETE_TAX_CLASS: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+func (t TaxRepository) CreateTaxRate(taxRate *entity.TaxRate) error {
+	span := t.p.Logger.Start(t.c, "CREATE_TAX_RATE_DATABASE")
+	defer span.End()
+	t.p.Logger.Info("STARTING: CREATE TAX RATE", map[string]interface{}{"tax_rate": taxRate})
+
+	if err := t.db.Create(taxRate).Error; err != nil {
+		t.p.Logger.Error("CREATE_TAX_RATE: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+func (t TaxRepository) UpdateTaxRate(taxRate *entity.TaxRate) error {
+	span := t.p.Logger.Start(t.c, "UPDATE_TAX_RATE_DATABASE")
+	defer span.End()
+	t.p.Logger.Info("STARTING: UPDATE TAX RATE", map[string]interface{}{"tax_rate": taxRate})
+
+	err := t.db.Model(taxRate).
+		Select("Country", "Region", "Name", "Rate").
+		Updates(taxRate).Error
+	if err != nil {
+		t.p.Logger.Error("UPDATE_TAX_RATE: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+func (t TaxRepository) GetTaxRateByID(id int64) (*entity.TaxRate, error) {
+	var taxRate entity.TaxRate
+	if err := t.db.First(&taxRate, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payload.ErrEntityNotFound(taxRateEntityName, fmt.Errorf("tax rate with id [%d] not found", id))
+		}
+		return nil, payload.ErrDB(err)
+	}
+	return &taxRate, nil
+}
+
+func (t TaxRepository) GetTaxRatesByClassID(taxClassID int64) ([]entity.TaxRate, error) {
+	listTaxRates := make([]entity.TaxRate, 0)
+	err := t.db.Where("tax_class_id = ?", taxClassID).
+		Order("country asc, region asc").
+		Find(&listTaxRates).Error
+	if err != nil {
+		t.p.Logger.Error("GET_TAX_RATES_BY_CLASS_ID: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrDB(err)
+	}
+	return listTaxRates, nil
+}
+
+func (t TaxRepository) DeleteTaxRate(taxRate *entity.TaxRate) error {
+	span := t.p.Logger.Start(t.c, "DELETE_TAX_RATE_DATABASE")
+	defer span.End()
+
+	if err := t.db.Delete(taxRate).Error; err != nil {
+		t.p.Logger.Error("DELETE_TAX_RATE: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+// GetTaxRateForRegion finds the rate of the tax class in the region, falling back to the rate of the whole country.
+// Regions are compared case insensitively
+func (t TaxRepository) GetTaxRateForRegion(taxClassID uint, country string, region string) (*entity.TaxRate, error) {
+	country, region = entity.NormalizeTaxRegion(country, region)
+
+	var taxRate entity.TaxRate
+	err := t.db.Where("tax_class_id = ? AND country = ?", taxClassID, country).
+		Where("lower(region) = lower(?) OR region = ''", region).
+		Order("region desc").
+		First(&taxRate).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payload.ErrEntityNotFound(taxRateEntityName, fmt.Errorf("no tax rate of tax class [%d] in %s %s", taxClassID, country, region))
+		}
+		return nil, payload.ErrDB(err)
+	}
+	return &taxRate, nil
+}
\ No newline at end of file
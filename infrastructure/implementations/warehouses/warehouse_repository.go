@@ -0,0 +1,278 @@
+package warehouses
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"pm/domain/entity"
+	"pm/domain/repository/warehouses"
+	"pm/infrastructure/controllers/payload"
+	"pm/infrastructure/persistences/base"
+)
+
+const entityName = "warehouses"
+
+type WarehouseRepository struct {
+	c  *gin.Context
+	p  *base.Persistence
+	db *gorm.DB
+}
+
+func NewWarehouseRepository(c *gin.Context, p *base.Persistence, db *gorm.DB) warehouses.WarehouseRepository {
+	return WarehouseRepository{c, p, db}
+}
+
+func (wr WarehouseRepository) Create(warehouse *entity.Warehouse) error {
+	span := wr.p.Logger.Start(wr.c, "CREATE_WAREHOUSE_DATABASE")
+	defer span.End()
+	wr.p.Logger.Info("STARTING: CREATE WAREHOUSE", map[string]interface{}{"warehouse": warehouse})
+
+	if err := wr.db.Create(warehouse).Error; err != nil {
+		wr.p.Logger.Error("CREATE_WAREHOUSE: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+func (wr WarehouseRepository) Update(warehouse *entity.Warehouse) error {
+	span := wr.p.Logger.Start(wr.c, "UPDATE_WAREHOUSE_DATABASE")
+	defer span.End()
+	wr.p.Logger.Info("STARTING: UPDATE WAREHOUSE", map[string]interface{}{"warehouse": warehouse})
+
+	err := wr.db.Model(warehouse).
+		Select("Code", "Name", "Address", "Priority").
+		Updates(warehouse).Error
+	if err != nil {
+		wr.p.Logger.Error("UPDATE_WAREHOUSE: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+func (wr WarehouseRepository) GetWarehouseByID(id int64) (*entity.Warehouse, error) {
+	var warehouse entity.Warehouse
+	if err := wr.db.First(&warehouse, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payload.ErrEntityNotFound(entityName, fmt.Errorf("warehouse with id [%d] not found", id))
+		}
+		return nil, payload.ErrDB(err)
+	}
+	return &warehouse, nil
+}
+
+func (wr WarehouseRepository) GetWarehouseByCode(code string) (*entity.Warehouse, error) {
+	var warehouse entity.Warehouse
+	if err := wr.db.Where("code = ?", entity.NormalizeWarehouseCode(code)).First(&warehouse).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, payload.ErrEntityNotFound(entityName, fmt.Errorf("warehouse [%s] not found", code))
+		}
+		return nil, payload.ErrDB(err)
+	}
+	return &warehouse, nil
+}
+
+// GetAllWarehouses returns the warehouses in their priority order, the lowest priority number first
+func (wr WarehouseRepository) GetAllWarehouses() ([]entity.Warehouse, error) {
+	span := wr.p.Logger.Start(wr.c, "GET_ALL_WAREHOUSES_DATABASE")
+	defer span.End()
+
+	listWarehouses := make([]entity.Warehouse, 0)
+	if err := wr.db.Order("priority, id").Find(&listWarehouses).Error; err != nil {
+		wr.p.Logger.Error("GET_ALL_WAREHOUSES: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrDB(err)
+	}
+	return listWarehouses, nil
+}
+
+func (wr WarehouseRepository) CountWarehouses() (int64, error) {
+	var count int64
+	if err := wr.db.Model(&entity.Warehouse{}).Count(&count).Error; err != nil {
+		wr.p.Logger.Error("COUNT_WAREHOUSES: ERROR", map[string]interface{}{"error": err.Error()})
+		return 0, payload.ErrDB(err)
+	}
+	return count, nil
+}
+
+// LockWarehouses keeps any other warehouse from being created until the surrounding transaction ends, so only one
+// of two warehouses created at the same time can see there is none yet and take over the stock
+func (wr WarehouseRepository) LockWarehouses() error {
+	if err := wr.db.Exec("LOCK TABLE warehouses IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+		wr.p.Logger.Error("LOCK_WAREHOUSES: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+// DeleteWarehouse deletes the warehouse, which must not hold any stock anymore
+func (wr WarehouseRepository) DeleteWarehouse(warehouse *entity.Warehouse) error {
+	span := wr.p.Logger.Start(wr.c, "DELETE_WAREHOUSE_DATABASE")
+	defer span.End()
+
+	var stocked int64
+	err := wr.db.Model(&entity.WarehouseStock{}).
+		Where("warehouse_id = ? AND quantity > 0", warehouse.ID).
+		Count(&stocked).Error
+	if err != nil {
+		wr.p.Logger.Error("DELETE_WAREHOUSE: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	if stocked > 0 {
+		return payload.ErrInvalidRequest(fmt.Errorf("the warehouse [%s] still holds stock, transfer it first", warehouse.Code))
+	}
+	if err := wr.db.Delete(warehouse).Error; err != nil {
+		wr.p.Logger.Error("DELETE_WAREHOUSE: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+func (wr WarehouseRepository) GetStockLevels(warehouseID int64) ([]entity.WarehouseStock, error) {
+	stocks := make([]entity.WarehouseStock, 0)
+	err := wr.db.Where("warehouse_id = ? AND quantity > 0", warehouseID).
+		Order("product_id, variant_id").
+		Find(&stocks).Error
+	if err != nil {
+		wr.p.Logger.Error("GET_STOCK_LEVELS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrDB(err)
+	}
+	return stocks, nil
+}
+
+// LockStockLevels returns the stock every warehouse holds of the products, or variants, of the items and locks it
+// until the end of the transaction, so two orders cannot be allocated the same stock
+func (wr WarehouseRepository) LockStockLevels(items ...entity.OrderItem) ([]entity.WarehouseStock, error) {
+	stocks := make([]entity.WarehouseStock, 0)
+	if len(items) == 0 {
+		return stocks, nil
+	}
+
+	keys := wr.db.Where("1 = 0")
+	for _, item := range items {
+		if item.VariantID != nil {
+			keys = keys.Or("variant_id = ?", *item.VariantID)
+			continue
+		}
+		keys = keys.Or("product_id = ? AND variant_id IS NULL", item.ProductID)
+	}
+	err := wr.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where(keys).
+		Order("id").
+		Find(&stocks).Error
+	if err != nil {
+		wr.p.Logger.Error("LOCK_STOCK_LEVELS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrDB(err)
+	}
+	return stocks, nil
+}
+
+// MoveStock moves the stock the warehouse holds of the item's product, or variant, by delta. Stock is taken with a
+// conditional update so a warehouse never holds less than nothing
+func (wr WarehouseRepository) MoveStock(warehouseID uint, item entity.OrderItem, delta int64) error {
+	wr.p.Logger.Info("MOVE_WAREHOUSE_STOCK", map[string]interface{}{"warehouse_id": warehouseID, "item": item, "delta": delta})
+
+	if delta >= 0 {
+		stock := entity.WarehouseStock{WarehouseID: warehouseID, ProductID: item.ProductID, VariantID: item.VariantID, Quantity: delta}
+		conflict := clause.OnConflict{
+			Columns:     []clause.Column{{Name: "warehouse_id"}, {Name: "product_id"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "variant_id IS NULL"}}},
+			DoUpdates:   clause.Assignments(map[string]interface{}{"quantity": gorm.Expr("warehouse_stocks.quantity + ?", delta), "updated_at": gorm.Expr("NOW()")}),
+		}
+		if item.VariantID != nil {
+			conflict.Columns = []clause.Column{{Name: "warehouse_id"}, {Name: "variant_id"}}
+			conflict.TargetWhere = clause.Where{}
+		}
+		if err := wr.db.Clauses(conflict).Create(&stock).Error; err != nil {
+			wr.p.Logger.Error("MOVE_WAREHOUSE_STOCK: ERROR", map[string]interface{}{"error": err.Error()})
+			return payload.ErrDB(err)
+		}
+		return nil
+	}
+
+	db := wr.db.Model(&entity.WarehouseStock{}).Where("warehouse_id = ?", warehouseID)
+	if item.VariantID != nil {
+		db = db.Where("variant_id = ?", *item.VariantID)
+	} else {
+		db = db.Where("product_id = ? AND variant_id IS NULL", item.ProductID)
+	}
+	result := db.Where("quantity + ? >= 0", delta).
+		Updates(map[string]interface{}{"quantity": gorm.Expr("quantity + ?", delta), "updated_at": gorm.Expr("NOW()")})
+	if err := result.Error; err != nil {
+		wr.p.Logger.Error("MOVE_WAREHOUSE_STOCK: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	if result.RowsAffected == 0 {
+		err := fmt.Errorf("the warehouse %v does not have enough stock of the product %v", warehouseID, item.ProductID)
+		if item.VariantID != nil {
+			err = fmt.Errorf("the warehouse %v does not have enough stock of the variant %v of product %v", warehouseID, *item.VariantID, item.ProductID)
+		}
+		wr.p.Logger.Error("MOVE_WAREHOUSE_STOCK: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrInvalidRequest(err)
+	}
+	return nil
+}
+
+// TakeOverStock puts all the stock there is into the warehouse, which is what the first warehouse does so the stock
+// counters stay the sum of the stock of the warehouses
+func (wr WarehouseRepository) TakeOverStock(warehouseID uint) error {
+	err := wr.db.Exec(`INSERT INTO warehouse_stocks (warehouse_id, product_id, variant_id, quantity, updated_at)
+		SELECT ?, p.id, NULL, p.stock, NOW() FROM products p
+		WHERE p.stock > 0
+		AND NOT EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id AND v.deleted_at IS NULL)
+		UNION ALL
+		SELECT ?, v.product_id, v.id, v.stock, NOW() FROM product_variants v
+		WHERE v.stock > 0`, warehouseID, warehouseID).Error
+	if err != nil {
+		wr.p.Logger.Error("TAKE_OVER_STOCK: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+func (wr WarehouseRepository) CreateTransfer(transfer *entity.StockTransfer) error {
+	if err := wr.db.Create(transfer).Error; err != nil {
+		wr.p.Logger.Error("CREATE_STOCK_TRANSFER: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+func (wr WarehouseRepository) CreateAllocations(allocations ...entity.OrderItemAllocation) error {
+	if len(allocations) == 0 {
+		return nil
+	}
+	if err := wr.db.Create(&allocations).Error; err != nil {
+		wr.p.Logger.Error("CREATE_ALLOCATIONS: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
+
+func (wr WarehouseRepository) GetAllocations(orderItemIDs ...uint) ([]entity.OrderItemAllocation, error) {
+	allocations := make([]entity.OrderItemAllocation, 0)
+	if len(orderItemIDs) == 0 {
+		return allocations, nil
+	}
+	if err := wr.db.Where("order_item_id IN ?", orderItemIDs).Order("id").Find(&allocations).Error; err != nil {
+		wr.p.Logger.Error("GET_ALLOCATIONS: ERROR", map[string]interface{}{"error": err.Error()})
+		return nil, payload.ErrDB(err)
+	}
+	return allocations, nil
+}
+
+// ReduceAllocation takes quantity off the allocation and deletes it once nothing is left
+func (wr WarehouseRepository) ReduceAllocation(allocation *entity.OrderItemAllocation, quantity int) error {
+	allocation.Quantity -= quantity
+	var err error
+	if allocation.Quantity > 0 {
+		err = wr.db.Model(allocation).Update("quantity", allocation.Quantity).Error
+	} else {
+		err = wr.db.Delete(allocation).Error
+	}
+	if err != nil {
+		wr.p.Logger.Error("REDUCE_ALLOCATION: ERROR", map[string]interface{}{"error": err.Error()})
+		return payload.ErrDB(err)
+	}
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"pm/domain/entity"
+	"pm/infrastructure/implementations/suggestions"
+	"pm/infrastructure/persistences/base"
+)
+
+// BuildSuggestionIndex rebuilds the search suggestions from the products and categories, which also drops whatever
+// the incremental updates missed
+func BuildSuggestionIndex(p *base.Persistence) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		fmt.Println("error trying to initialize logger")
+		return
+	}
+	defer logger.Sync()
+	sugar := logger.Sugar()
+
+	sugar.Debugw("GO_ROUTINE_BUILD_SUGGESTION_INDEX")
+	products := make([]entity.Product, 0)
+	if err := p.GormDB.Select("id", "name").Find(&products).Error; err != nil {
+		sugar.Errorw("ERROR_BUILD_SUGGESTION_INDEX", map[string]interface{}{"message": err.Error()})
+		return
+	}
+	categories := make([]entity.Category, 0)
+	if err := p.GormDB.Select("id", "name").Find(&categories).Error; err != nil {
+		sugar.Errorw("ERROR_BUILD_SUGGESTION_INDEX", map[string]interface{}{"message": err.Error()})
+		return
+	}
+
+	list := make([]entity.Suggestion, 0, len(products)+len(categories))
+	for i := range products {
+		list = append(list, entity.ProductSuggestion(&products[i]))
+	}
+	for i := range categories {
+		list = append(list, entity.CategorySuggestion(&categories[i]))
+	}
+	err = suggestions.NewSuggestionRepository(p.Redis.RedisDB, p.Ctx).Rebuild(list)
+	if err != nil {
+		sugar.Errorw("ERROR_BUILD_SUGGESTION_INDEX", map[string]interface{}{"message": err.Error()})
+		return
+	}
+	sugar.Infow("BUILD_SUGGESTION_INDEX_SUCCESSFULLY", map[string]interface{}{"suggestions": len(list)})
+}
\ No newline at end of file
@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"pm/domain/entity"
+	"pm/infrastructure/persistences/base"
+	"pm/utils"
+)
+
+// IndexProductSearch fills the search text of the products that have none yet, the ones created before products
+// were indexed for search. It only touches those, so it is cheap to run on every start
+func IndexProductSearch(p *base.Persistence) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		fmt.Println("error trying to initialize logger")
+		return
+	}
+	defer logger.Sync()
+	sugar := logger.Sugar()
+
+	sugar.Infow("INDEX_PRODUCT_SEARCH")
+	categoryNames := make(map[int64]string)
+	listCategories := make([]entity.Category, 0)
+	if err := p.GormDB.Unscoped().Find(&listCategories).Error; err != nil {
+		sugar.Errorw("ERROR_INDEX_PRODUCT_SEARCH", map[string]interface{}{"message": err.Error()})
+		return
+	}
+	for _, category := range listCategories {
+		categoryNames[int64(category.ID)] = category.Name
+	}
+
+	indexed := 0
+	batch := make([]entity.Product, 0)
+	err = p.GormDB.Unscoped().
+		Where("search_text IS NULL OR search_text = ''").
+		FindInBatches(&batch, 100, func(tx *gorm.DB, _ int) error {
+			for _, product := range batch {
+				err := p.GormDB.Unscoped().Model(&product).UpdateColumns(map[string]interface{}{
+					"search_name": utils.NormalizeSearchText(product.Name),
+					"search_text": utils.ProductSearchText(&product, categoryNames[product.CategoryID]),
+				}).Error
+				if err != nil {
+					return err
+				}
+				indexed++
+			}
+			return nil
+		}).Error
+	if err != nil {
+		sugar.Errorw("ERROR_INDEX_PRODUCT_SEARCH", map[string]interface{}{"message": err.Error()})
+		return
+	}
+	sugar.Infow("INDEX_PRODUCT_SEARCH_SUCCESSFULLY", map[string]interface{}{"products": indexed})
+}
\ No newline at end of file
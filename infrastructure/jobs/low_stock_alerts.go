@@ -0,0 +1,144 @@
+package jobs
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"pm/domain/entity"
+	"pm/infrastructure/implementations/mailer"
+	"pm/infrastructure/persistences/base"
+	"strings"
+	"time"
+)
+
+type lowStock struct {
+	ProductID uint
+	VariantID *uint
+	Name      string
+	SKU       string
+	Stock     int64
+	Threshold int64
+}
+
+func (l lowStock) key() string {
+	if l.VariantID != nil {
+		return fmt.Sprintf("%d/%d", l.ProductID, *l.VariantID)
+	}
+	return fmt.Sprintf("%d", l.ProductID)
+}
+
+func lowStockAlertKey(a entity.LowStockAlert) string {
+	return lowStock{ProductID: a.ProductID, VariantID: a.VariantID}.key()
+}
+
+// AlertLowStock reports the products, and the variants, at or below the reorder threshold of their product in one
+// digest, sent by email to the admins and raised as a notification. A shortage already reported is not reported
+// again until its stock has been brought back above the threshold
+func AlertLowStock(p *base.Persistence) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		fmt.Println("error trying to initialize logger")
+		return
+	}
+	defer logger.Sync()
+	sugar := logger.Sugar()
+
+	sugar.Infow("ALERT_LOW_STOCK")
+	low := make([]lowStock, 0)
+	err = p.GormDB.Raw(`SELECT p.id AS product_id, NULL AS variant_id, p.name, '' AS sku, p.stock, p.reorder_threshold AS threshold
+		FROM products p
+		WHERE p.deleted_at IS NULL AND p.reorder_threshold IS NOT NULL AND p.stock <= p.reorder_threshold
+		AND NOT EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id AND v.deleted_at IS NULL)
+		UNION ALL
+		SELECT p.id, v.id, p.name, v.sku, v.stock, p.reorder_threshold
+		FROM product_variants v JOIN products p ON p.id = v.product_id
+		WHERE v.deleted_at IS NULL AND p.deleted_at IS NULL AND p.reorder_threshold IS NOT NULL AND v.stock <= p.reorder_threshold
+		ORDER BY 1, 2`).
+		Scan(&low).Error
+	if err != nil {
+		sugar.Errorw("ERROR_ALERT_LOW_STOCK", map[string]interface{}{"message": err.Error()})
+		return
+	}
+
+	reported := make([]lowStock, 0)
+	var notification entity.Notification
+	err = p.GormDB.Transaction(func(tx *gorm.DB) error {
+		open := make([]entity.LowStockAlert, 0)
+		if err := tx.Where("resolved_at IS NULL").Find(&open).Error; err != nil {
+			return err
+		}
+		stillLow := make(map[string]bool)
+		for _, l := range low {
+			stillLow[l.key()] = true
+		}
+		alerted := make(map[string]bool)
+		restocked := make([]uint, 0)
+		for _, a := range open {
+			alerted[lowStockAlertKey(a)] = true
+			if !stillLow[lowStockAlertKey(a)] {
+				restocked = append(restocked, a.ID)
+			}
+		}
+		if len(restocked) > 0 {
+			if err := tx.Model(&entity.LowStockAlert{}).Where("id IN ?", restocked).Update("resolved_at", time.Now()).Error; err != nil {
+				return err
+			}
+		}
+
+		alerts := make([]entity.LowStockAlert, 0)
+		for _, l := range low {
+			if alerted[l.key()] {
+				continue
+			}
+			reported = append(reported, l)
+			alerts = append(alerts, entity.LowStockAlert{ProductID: l.ProductID, VariantID: l.VariantID, Stock: l.Stock, Threshold: l.Threshold})
+		}
+		if len(alerts) == 0 {
+			return nil
+		}
+		if err := tx.Create(&alerts).Error; err != nil {
+			return err
+		}
+		notification = entity.Notification{
+			Type:  entity.NotificationTypeLowStock,
+			Title: fmt.Sprintf("Low stock: %d to reorder", len(reported)),
+			Body:  lowStockDigest(reported),
+		}
+		return tx.Create(&notification).Error
+	})
+	if err != nil {
+		sugar.Errorw("ERROR_ALERT_LOW_STOCK", map[string]interface{}{"message": err.Error()})
+		return
+	}
+	if len(reported) == 0 {
+		sugar.Infow("ALERT_LOW_STOCK_SUCCESSFULLY", map[string]interface{}{"reported": 0})
+		return
+	}
+
+	receivers := make([]string, 0)
+	err = p.GormDB.Model(&entity.User{}).Where("role_id = ?", entity.RoleAdmin).Pluck("email", &receivers).Error
+	if err != nil {
+		sugar.Errorw("ERROR_ALERT_LOW_STOCK", map[string]interface{}{"message": err.Error()})
+		return
+	}
+	if len(receivers) > 0 {
+		if err := mailer.NewMailerRepository(p).SendEmailWithPlainText(notification.Body, notification.Title, receivers, nil); err != nil {
+			sugar.Errorw("ERROR_ALERT_LOW_STOCK", map[string]interface{}{"message": err.Error()})
+			return
+		}
+	}
+	sugar.Infow("ALERT_LOW_STOCK_SUCCESSFULLY", map[string]interface{}{"reported": len(reported)})
+}
+
+func lowStockDigest(reported []lowStock) string {
+	var b strings.Builder
+	b.WriteString("These products are at or below their reorder threshold:\n\n")
+	for _, l := range reported {
+		name := l.Name
+		if l.SKU != "" {
+			name = fmt.Sprintf("%s (SKU %s)", l.Name, l.SKU)
+		}
+		fmt.Fprintf(&b, "- %s: %d left, reorder threshold %d\n", name, l.Stock, l.Threshold)
+	}
+	return b.String()
+}
\ No newline at end of file
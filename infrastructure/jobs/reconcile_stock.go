@@ -0,0 +1,79 @@
+package jobs
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"pm/domain/entity"
+	"pm/infrastructure/persistences/base"
+	"time"
+)
+
+// ReconcileStock compares every stock counter, of the products and of their variants, with the sum of its movements
+// in the stock ledger and records a StockDrift for each one that does not match. Stock that was there before the
+// ledger, which has no movement at all, first gets an opening movement so it is not reported as drift
+func ReconcileStock(p *base.Persistence) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		fmt.Println("error trying to initialize logger")
+		return
+	}
+	defer logger.Sync()
+	sugar := logger.Sugar()
+
+	sugar.Infow("RECONCILE_STOCK")
+	if err := backfillOpeningStock(p.GormDB); err != nil {
+		sugar.Errorw("ERROR_RECONCILE_STOCK", map[string]interface{}{"message": err.Error()})
+		return
+	}
+
+	drifts := make([]entity.StockDrift, 0)
+	err = p.GormDB.Raw(`SELECT p.id AS product_id, NULL AS variant_id, p.stock AS counter, COALESCE(SUM(m.quantity), 0) AS ledger
+		FROM products p
+		LEFT JOIN stock_movements m ON m.product_id = p.id AND m.variant_id IS NULL
+		GROUP BY p.id, p.stock
+		HAVING p.stock <> COALESCE(SUM(m.quantity), 0)
+		UNION ALL
+		SELECT v.product_id, v.id AS variant_id, v.stock AS counter, COALESCE(SUM(m.quantity), 0) AS ledger
+		FROM product_variants v
+		LEFT JOIN stock_movements m ON m.variant_id = v.id
+		GROUP BY v.id, v.product_id, v.stock
+		HAVING v.stock <> COALESCE(SUM(m.quantity), 0)`).
+		Scan(&drifts).Error
+	if err != nil {
+		sugar.Errorw("ERROR_RECONCILE_STOCK", map[string]interface{}{"message": err.Error()})
+		return
+	}
+	if len(drifts) == 0 {
+		sugar.Infow("RECONCILE_STOCK_SUCCESSFULLY", map[string]interface{}{"drifts": 0})
+		return
+	}
+
+	now := time.Now()
+	for i := range drifts {
+		drifts[i].Difference = drifts[i].Counter - drifts[i].Ledger
+		drifts[i].DetectedAt = now
+		sugar.Warnw("STOCK_DRIFT_DETECTED", map[string]interface{}{"drift": drifts[i]})
+	}
+	if err := p.GormDB.Create(&drifts).Error; err != nil {
+		sugar.Errorw("ERROR_RECONCILE_STOCK", map[string]interface{}{"message": err.Error()})
+		return
+	}
+	sugar.Infow("RECONCILE_STOCK_SUCCESSFULLY", map[string]interface{}{"drifts": len(drifts)})
+}
+
+func backfillOpeningStock(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`INSERT INTO stock_movements (product_id, variant_id, quantity, balance, reason, reference, created_at)
+			SELECT p.id, NULL, p.stock, p.stock, ?, ?, NOW() FROM products p
+			WHERE NOT EXISTS (SELECT 1 FROM stock_movements m WHERE m.product_id = p.id AND m.variant_id IS NULL)`,
+			entity.StockReasonOpening, entity.StockReferenceBackfill).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec(`INSERT INTO stock_movements (product_id, variant_id, quantity, balance, reason, reference, created_at)
+			SELECT v.product_id, v.id, v.stock, v.stock, ?, ?, NOW() FROM product_variants v
+			WHERE NOT EXISTS (SELECT 1 FROM stock_movements m WHERE m.variant_id = v.id)`,
+			entity.StockReasonOpening, entity.StockReferenceBackfill).Error
+	})
+}
\ No newline at end of file
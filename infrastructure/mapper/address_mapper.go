@@ -0,0 +1,67 @@
+package mapper
+
+import (
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+)
+
+func CreateAddressPayloadToAddress(reqPayload *payload.CreateAddressRequest, userID uint) *entity.Address {
+	return &entity.Address{
+		UserID:        userID,
+		RecipientName: reqPayload.RecipientName,
+		Phone:         reqPayload.Phone,
+		Line1:         reqPayload.Line1,
+		Line2:         reqPayload.Line2,
+		Ward:          reqPayload.Ward,
+		District:      reqPayload.District,
+		City:          reqPayload.City,
+		Country:       reqPayload.Country,
+		PostalCode:    reqPayload.PostalCode,
+	}
+}
+
+func UpdateAddressPayloadToAddress(reqPayload *payload.UpdateAddressRequest, address *entity.Address) {
+	address.RecipientName = reqPayload.RecipientName
+	address.Phone = reqPayload.Phone
+	address.Line1 = reqPayload.Line1
+	address.Line2 = reqPayload.Line2
+	address.Ward = reqPayload.Ward
+	address.District = reqPayload.District
+	address.City = reqPayload.City
+	address.Country = reqPayload.Country
+	address.PostalCode = reqPayload.PostalCode
+}
+
+func AddressToAddressResponse(e *entity.Address) payload.AddressResponse {
+	return payload.AddressResponse{
+		ID:                      int64(e.ID),
+		IsDefault:               e.IsDefault,
+		ShippingAddressResponse: ShippingAddressToShippingAddressResponse(e.ToShippingAddress()),
+		AuditTime: payload.AuditTime{
+			CreatedAt: e.CreatedAt,
+			UpdatedAt: e.UpdatedAt,
+		},
+	}
+}
+
+func AddressesToAddressResponses(listEntities []entity.Address) []payload.AddressResponse {
+	addressResponses := make([]payload.AddressResponse, 0)
+	for _, v := range listEntities {
+		addressResponses = append(addressResponses, AddressToAddressResponse(&v))
+	}
+	return addressResponses
+}
+
+func ShippingAddressToShippingAddressResponse(e entity.ShippingAddress) payload.ShippingAddressResponse {
+	return payload.ShippingAddressResponse{
+		RecipientName: e.RecipientName,
+		Phone:         e.Phone,
+		Line1:         e.Line1,
+		Line2:         e.Line2,
+		Ward:          e.Ward,
+		District:      e.District,
+		City:          e.City,
+		Country:       e.Country,
+		PostalCode:    e.PostalCode,
+	}
+}
\ No newline at end of file
@@ -0,0 +1,38 @@
+package mapper
+
+import (
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+)
+
+func CartToCartResponse(cart *entity.Cart) payload.CartResponse {
+	items := make([]payload.CartItemResponse, 0)
+	for _, line := range cart.Lines {
+		item := payload.CartItemResponse{
+			ProductID: int64(line.ProductID),
+			VariantID: line.VariantID,
+			Quantity:  line.Quantity,
+			Total:     line.Total(),
+			Available: line.IsAvailable(),
+			InStock:   line.IsInStock(),
+		}
+		if line.Product != nil {
+			item.Name = line.Product.Name
+			item.Image = line.Product.Image
+			item.Price = line.Product.UnitPrice(line.VariantID)
+			item.Stock = line.Product.StockOf(line.VariantID)
+		}
+		if variant := line.Variant(); variant != nil {
+			item.Variant = variant.Describe()
+			if variant.Image != "" {
+				item.Image = variant.Image
+			}
+		}
+		items = append(items, item)
+	}
+	return payload.CartResponse{
+		Items:       items,
+		Subtotal:    cart.Subtotal(),
+		CanCheckout: cart.CanCheckout(),
+	}
+}
\ No newline at end of file
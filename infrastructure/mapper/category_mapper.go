@@ -7,8 +7,9 @@ import (
 
 func CategoryToCategoryResponse(e *entity.Category) payload.CategoryResponse {
 	return payload.CategoryResponse{
-		ID:   e.ID,
-		Name: e.Name,
+		ID:         e.ID,
+		Name:       e.Name,
+		TaxClassID: e.TaxClassID,
 		AuditTime: payload.AuditTime{
 			UpdatedAt: e.UpdatedAt,
 			CreatedAt: e.CreatedAt,
@@ -31,11 +32,13 @@ func CategoriesToListCategoriesResponse(listEntities []entity.Category, paginati
 
 func CreateCatePayloadToCategory(reqPayload *payload.CreateCategoryRequest) *entity.Category {
 	return &entity.Category{
-		Name: reqPayload.Name,
+		Name:       reqPayload.Name,
+		TaxClassID: reqPayload.TaxClassID,
 	}
 }
 
 func UpdateCategory(old *entity.Category, updatePayload *payload.UpdateCategoryRequest) {
 	old.Name = updatePayload.Name
+	old.TaxClassID = updatePayload.TaxClassID
 
 }
\ No newline at end of file
@@ -0,0 +1,23 @@
+package mapper
+
+import (
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+)
+
+func InvoiceToInvoiceResponse(e *entity.Invoice) payload.InvoiceResponse {
+	return payload.InvoiceResponse{
+		ID:         int64(e.ID),
+		OrderID:    int64(e.OrderID),
+		Number:     e.Number,
+		IssuedAt:   e.IssuedAt,
+		Currency:   e.Currency,
+		GrandTotal: e.GrandTotal,
+		FileURL:    e.FileURL,
+		EmailedAt:  e.EmailedAt,
+		AuditTime: payload.AuditTime{
+			CreatedAt: e.CreatedAt,
+			UpdatedAt: e.UpdatedAt,
+		},
+	}
+}
\ No newline at end of file
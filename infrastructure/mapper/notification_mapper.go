@@ -0,0 +1,28 @@
+package mapper
+
+import (
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+)
+
+func NotificationToNotificationResponse(e *entity.Notification) payload.NotificationResponse {
+	return payload.NotificationResponse{
+		ID:        e.ID,
+		Type:      e.Type,
+		Title:     e.Title,
+		Body:      e.Body,
+		ReadAt:    e.ReadAt,
+		CreatedAt: e.CreatedAt,
+	}
+}
+
+func NotificationsToListNotificationResponses(listEntities []entity.Notification, pagination *entity.Pagination) payload.ListNotificationResponses {
+	notificationResponses := make([]payload.NotificationResponse, 0)
+	for _, v := range listEntities {
+		notificationResponses = append(notificationResponses, NotificationToNotificationResponse(&v))
+	}
+	return payload.ListNotificationResponses{
+		Notifications:      notificationResponses,
+		PaginationResponse: PaginationToPaginationResponse(pagination),
+	}
+}
\ No newline at end of file
@@ -0,0 +1,31 @@
+package mapper
+
+import (
+	"encoding/json"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+)
+
+func OrderEventToOrderEventResponse(e *entity.OrderEvent) payload.OrderEventResponse {
+	var actorID *int64
+	if e.ActorID != nil {
+		id := int64(*e.ActorID)
+		actorID = &id
+	}
+	return payload.OrderEventResponse{
+		ID:        int64(e.ID),
+		OrderID:   int64(e.OrderID),
+		ActorID:   actorID,
+		Type:      e.Type,
+		Diff:      json.RawMessage(e.Diff),
+		CreatedAt: e.CreatedAt,
+	}
+}
+
+func OrderEventsToOrderEventResponses(listEntities []entity.OrderEvent) []payload.OrderEventResponse {
+	orderEventResponses := make([]payload.OrderEventResponse, 0)
+	for _, v := range listEntities {
+		orderEventResponses = append(orderEventResponses, OrderEventToOrderEventResponse(&v))
+	}
+	return orderEventResponses
+}
\ No newline at end of file
@@ -5,11 +5,11 @@ import (
 	"pm/infrastructure/controllers/payload"
 )
 
-func CreateOrderPayloadToOrder(reqPayload *payload.CreateOrderRequest) entity.Order {
+func CreateOrderPayloadToOrder(reqPayload *payload.CreateOrderRequest, userID uint) entity.Order {
 	orderItems := OrderItemRequestsToOrderItems(reqPayload.OrderItems)
 	return entity.Order{
-		UserID:     reqPayload.UserID,
-		Status:     reqPayload.Status,
+		UserID:     userID,
+		Status:     entity.OrderStatusPending,
 		OrderItems: orderItems,
 	}
 }
@@ -20,8 +20,8 @@ func OrderItemRequestsToOrderItems(items []payload.OrderItemRequest) []entity.Or
 		orderItem := entity.OrderItem{
 			OrderID:   0,
 			ProductID: v.ProductID,
+			VariantID: v.VariantID,
 			Quantity:  v.Quantity,
-			Price:     v.Price,
 		}
 		result = append(result, orderItem)
 	}
@@ -35,11 +35,23 @@ func OrderItemRequestsToOrderItems(items []payload.OrderItemRequest) []entity.Or
 func OrderToOrderResponse(e *entity.Order) payload.OrderResponse {
 	orderItems := OrderItemsToOrderItemResponses(e.OrderItems)
 	return payload.OrderResponse{
-		ID:         int64(e.ID),
-		UserID:     int64(e.UserID),
-		Status:     e.Status,
-		OrderItems: orderItems,
-		Total:      0,
+		ID:            int64(e.ID),
+		UserID:        int64(e.UserID),
+		Status:        e.Status,
+		OrderItems:    orderItems,
+		Subtotal:      e.Subtotal,
+		DiscountTotal: e.DiscountTotal,
+		TaxTotal:      e.TaxTotal,
+		GrandTotal:    e.GrandTotal,
+		Total:         e.GrandTotal,
+		ShippingFee:   e.ShippingFee,
+		PromotionCode: e.PromotionCode,
+
+		ShippingAddress: ShippingAddressToShippingAddressResponse(e.ShippingAddress),
+		ShippingMethod:  e.ShippingMethod,
+		TrackingNumber:  e.TrackingNumber,
+		ShippedAt:       e.ShippedAt,
+		DeliveredAt:     e.DeliveredAt,
 		AuditTime: payload.AuditTime{
 			CreatedAt: e.CreatedAt,
 			UpdatedAt: e.UpdatedAt,
@@ -47,12 +59,30 @@ func OrderToOrderResponse(e *entity.Order) payload.OrderResponse {
 	}
 }
 
+func OrdersToListOrderResponses(listEntities []entity.Order, pagination *entity.Pagination) payload.ListOrderResponses {
+	listOrderResponse := make([]payload.OrderResponse, 0)
+	for _, o := range listEntities {
+		orderResponse := OrderToOrderResponse(&o)
+		listOrderResponse = append(listOrderResponse, orderResponse)
+	}
+
+	return payload.ListOrderResponses{
+		Orders:             listOrderResponse,
+		PaginationResponse: PaginationToPaginationResponse(pagination),
+	}
+}
+
 func OrderItemToOrderItemResponse(e *entity.OrderItem) payload.OrderItemResponse {
 	return payload.OrderItemResponse{
-		ID:        int64(e.ID),
-		ProductID: int64(e.ProductID),
-		Quantity:  e.Quantity,
-		Price:     e.Price,
+		ID:          int64(e.ID),
+		ProductID:   int64(e.ProductID),
+		VariantID:   e.VariantID,
+		Quantity:    e.Quantity,
+		Price:       e.Price,
+		TaxRate:     e.TaxRate,
+		TaxAmount:   e.TaxAmount,
+		NetAmount:   entity.RoundMoney(e.Price * float64(e.Quantity)),
+		GrossAmount: entity.RoundMoney(e.Price*float64(e.Quantity) + e.TaxAmount),
 		AuditTime: payload.AuditTime{
 			CreatedAt: e.CreatedAt,
 			UpdatedAt: e.UpdatedAt,
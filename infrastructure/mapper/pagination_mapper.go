@@ -11,5 +11,7 @@ func PaginationToPaginationResponse(pagination *entity.Pagination) payload.Pagin
 		Page:          pagination.Page,
 		TotalElements: pagination.TotalRows,
 		TotalPages:    pagination.TotalPages,
+		NextCursor:    pagination.NextCursor,
+		PrevCursor:    pagination.PrevCursor,
 	}
 }
\ No newline at end of file
@@ -0,0 +1,32 @@
+package mapper
+
+import (
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+)
+
+func PaymentToPaymentResponse(e *entity.Payment) payload.PaymentResponse {
+	return payload.PaymentResponse{
+		ID:            int64(e.ID),
+		OrderID:       int64(e.OrderID),
+		Provider:      e.Provider,
+		ProviderRef:   e.ProviderRef,
+		Amount:        e.Amount,
+		Currency:      e.Currency,
+		Status:        e.Status,
+		FailureReason: e.FailureReason,
+		CapturedAt:    e.CapturedAt,
+		AuditTime: payload.AuditTime{
+			CreatedAt: e.CreatedAt,
+			UpdatedAt: e.UpdatedAt,
+		},
+	}
+}
+
+func PaymentsToPaymentResponses(listEntities []entity.Payment) []payload.PaymentResponse {
+	paymentResponses := make([]payload.PaymentResponse, 0)
+	for _, v := range listEntities {
+		paymentResponses = append(paymentResponses, PaymentToPaymentResponse(&v))
+	}
+	return paymentResponses
+}
\ No newline at end of file
@@ -3,17 +3,22 @@ package mapper
 import (
 	"pm/domain/entity"
 	"pm/infrastructure/controllers/payload"
+	"strings"
 )
 
 func ProductToProductResponse(product *entity.Product) payload.ProductResponse {
 	return payload.ProductResponse{
-		ID:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		CategoryID:  product.CategoryID,
-		Stock:       product.Stock,
-		Image:       product.Image,
+		ID:               product.ID,
+		Name:             product.Name,
+		Description:      product.Description,
+		Price:            product.Price,
+		CategoryID:       product.CategoryID,
+		TaxClassID:       product.TaxClassID,
+		Stock:            product.Stock,
+		ReorderThreshold: product.ReorderThreshold,
+		Tags:             product.TagList(),
+		Image:            product.Image,
+		Variants:         VariantsToVariantResponses(product, product.Variants),
 		AuditTime: payload.AuditTime{
 			UpdatedAt: product.UpdatedAt,
 			CreatedAt: product.CreatedAt,
@@ -21,6 +26,51 @@ func ProductToProductResponse(product *entity.Product) payload.ProductResponse {
 	}
 }
 
+func VariantToVariantResponse(product *entity.Product, variant *entity.ProductVariant) payload.ProductVariantResponse {
+	options := make([]payload.VariantOptionResponse, 0)
+	for _, o := range variant.Options {
+		options = append(options, payload.VariantOptionResponse{Name: o.Name, Value: o.Value})
+	}
+	return payload.ProductVariantResponse{
+		ID:            variant.ID,
+		ProductID:     variant.ProductID,
+		SKU:           variant.SKU,
+		Price:         variant.PriceOf(product),
+		PriceOverride: variant.Price,
+		Stock:         variant.Stock,
+		Image:         variant.Image,
+		Options:       options,
+		AuditTime: payload.AuditTime{
+			UpdatedAt: variant.UpdatedAt,
+			CreatedAt: variant.CreatedAt,
+		},
+	}
+}
+
+func VariantsToVariantResponses(product *entity.Product, variants []entity.ProductVariant) []payload.ProductVariantResponse {
+	responses := make([]payload.ProductVariantResponse, 0)
+	for _, v := range variants {
+		responses = append(responses, VariantToVariantResponse(product, &v))
+	}
+	return responses
+}
+
+// VariantPayloadToVariant copies the request into the variant, option names are kept lower case so the same option
+// is always spelled the same way
+func VariantPayloadToVariant(reqPayload *payload.ProductVariantRequest, variant *entity.ProductVariant) {
+	variant.SKU = entity.NormalizeSKU(reqPayload.SKU)
+	variant.Price = reqPayload.Price
+	variant.Stock = reqPayload.Stock
+	variant.Image = reqPayload.Image
+	variant.Options = make([]entity.ProductVariantOption, 0)
+	for _, o := range reqPayload.Options {
+		variant.Options = append(variant.Options, entity.ProductVariantOption{
+			Name:  entity.NormalizeVariantOptionName(o.Name),
+			Value: strings.TrimSpace(o.Value),
+		})
+	}
+}
+
 func ProdsToListProdsResponse(products []entity.Product, pagination *entity.Pagination) payload.ListProductResponses {
 	listProdResponse := make([]payload.ProductResponse, 0)
 	for _, p := range products {
@@ -33,14 +83,41 @@ func ProdsToListProdsResponse(products []entity.Product, pagination *entity.Pagi
 	}
 }
 
+func FacetsToProductFacetsResponse(facets *entity.ProductFacets) *payload.ProductFacetsResponse {
+	attributes := make([]payload.AttributeFacetResponse, 0, len(facets.Attributes))
+	for _, a := range facets.Attributes {
+		attributes = append(attributes, payload.AttributeFacetResponse{
+			Name:   a.Name,
+			Values: facetCountsToFacetCountResponses(a.Values),
+		})
+	}
+	return &payload.ProductFacetsResponse{
+		Categories:   facetCountsToFacetCountResponses(facets.Categories),
+		PriceBuckets: facetCountsToFacetCountResponses(facets.PriceBuckets),
+		Attributes:   attributes,
+		InStock:      facets.InStock,
+	}
+}
+
+func facetCountsToFacetCountResponses(counts []entity.FacetCount) []payload.FacetCountResponse {
+	responses := make([]payload.FacetCountResponse, 0, len(counts))
+	for _, c := range counts {
+		responses = append(responses, payload.FacetCountResponse{Value: c.Value, Label: c.Label, Count: c.Count})
+	}
+	return responses
+}
+
 func PayloadToProduct(reqPayload *payload.CreateProductRequest) *entity.Product {
 	return &entity.Product{
-		Name:        reqPayload.Name,
-		Description: reqPayload.Description,
-		Price:       reqPayload.Price,
-		CategoryID:  reqPayload.CategoryID,
-		Stock:       reqPayload.Stock,
-		Image:       reqPayload.Image,
+		Name:             reqPayload.Name,
+		Description:      reqPayload.Description,
+		Price:            reqPayload.Price,
+		CategoryID:       reqPayload.CategoryID,
+		TaxClassID:       reqPayload.TaxClassID,
+		Stock:            reqPayload.Stock,
+		ReorderThreshold: reqPayload.ReorderThreshold,
+		Tags:             entity.NormalizeTags(reqPayload.Tags),
+		Image:            reqPayload.Image,
 	}
 }
 
@@ -50,4 +127,7 @@ func UpdateProduct(oldProd *entity.Product, updatePayload *payload.UpdateProduct
 	oldProd.Stock = updatePayload.Stock
 	oldProd.Price = updatePayload.Price
 	oldProd.Image = updatePayload.Image
+	oldProd.TaxClassID = updatePayload.TaxClassID
+	oldProd.ReorderThreshold = updatePayload.ReorderThreshold
+	oldProd.Tags = entity.NormalizeTags(updatePayload.Tags)
 }
\ No newline at end of file
@@ -0,0 +1,62 @@
+package mapper
+
+import (
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+)
+
+// PromotionPayloadToPromotion fills the promotion with the request, it is used for both creating and updating
+func PromotionPayloadToPromotion(reqPayload *payload.PromotionRequest, promotion *entity.Promotion) {
+	promotion.Code = entity.NormalizePromotionCode(reqPayload.Code)
+	promotion.Description = reqPayload.Description
+	promotion.Type = reqPayload.Type
+	promotion.Value = reqPayload.Value
+	promotion.MaxDiscount = reqPayload.MaxDiscount
+	promotion.MinOrderValue = reqPayload.MinOrderValue
+	promotion.BuyQuantity = reqPayload.BuyQuantity
+	promotion.GetQuantity = reqPayload.GetQuantity
+	promotion.ProductID = reqPayload.ProductID
+	promotion.CategoryID = reqPayload.CategoryID
+	promotion.StartsAt = reqPayload.StartsAt
+	promotion.EndsAt = reqPayload.EndsAt
+	promotion.UsageLimit = reqPayload.UsageLimit
+	promotion.PerUserLimit = reqPayload.PerUserLimit
+	promotion.Active = reqPayload.Active
+}
+
+func PromotionToPromotionResponse(e *entity.Promotion) payload.PromotionResponse {
+	return payload.PromotionResponse{
+		ID:            int64(e.ID),
+		Code:          e.Code,
+		Description:   e.Description,
+		Type:          e.Type,
+		Value:         e.Value,
+		MaxDiscount:   e.MaxDiscount,
+		MinOrderValue: e.MinOrderValue,
+		BuyQuantity:   e.BuyQuantity,
+		GetQuantity:   e.GetQuantity,
+		ProductID:     e.ProductID,
+		CategoryID:    e.CategoryID,
+		StartsAt:      e.StartsAt,
+		EndsAt:        e.EndsAt,
+		UsageLimit:    e.UsageLimit,
+		PerUserLimit:  e.PerUserLimit,
+		UsedCount:     e.UsedCount,
+		Active:        e.Active,
+		AuditTime: payload.AuditTime{
+			CreatedAt: e.CreatedAt,
+			UpdatedAt: e.UpdatedAt,
+		},
+	}
+}
+
+func PromotionsToListPromotionResponses(listEntities []entity.Promotion, pagination *entity.Pagination) payload.ListPromotionResponses {
+	promotionResponses := make([]payload.PromotionResponse, 0)
+	for _, v := range listEntities {
+		promotionResponses = append(promotionResponses, PromotionToPromotionResponse(&v))
+	}
+	return payload.ListPromotionResponses{
+		Promotions:         promotionResponses,
+		PaginationResponse: PaginationToPaginationResponse(pagination),
+	}
+}
\ No newline at end of file
@@ -0,0 +1,98 @@
+package mapper
+
+import (
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+)
+
+func SupplierPayloadToSupplier(reqPayload *payload.SupplierRequest, supplier *entity.Supplier) {
+	supplier.Name = reqPayload.Name
+	supplier.Email = reqPayload.Email
+	supplier.Phone = reqPayload.Phone
+	supplier.Address = reqPayload.Address
+}
+
+func SupplierToSupplierResponse(e *entity.Supplier) payload.SupplierResponse {
+	return payload.SupplierResponse{
+		ID:      e.ID,
+		Name:    e.Name,
+		Email:   e.Email,
+		Phone:   e.Phone,
+		Address: e.Address,
+		AuditTime: payload.AuditTime{
+			CreatedAt: e.CreatedAt,
+			UpdatedAt: e.UpdatedAt,
+		},
+	}
+}
+
+func SuppliersToListSupplierResponses(listEntities []entity.Supplier, pagination *entity.Pagination) payload.ListSupplierResponses {
+	supplierResponses := make([]payload.SupplierResponse, 0)
+	for _, v := range listEntities {
+		supplierResponses = append(supplierResponses, SupplierToSupplierResponse(&v))
+	}
+	return payload.ListSupplierResponses{
+		Suppliers:          supplierResponses,
+		PaginationResponse: PaginationToPaginationResponse(pagination),
+	}
+}
+
+func PurchaseOrderPayloadToPurchaseOrder(reqPayload *payload.PurchaseOrderRequest, purchaseOrder *entity.PurchaseOrder) {
+	purchaseOrder.SupplierID = reqPayload.SupplierID
+	purchaseOrder.WarehouseID = reqPayload.WarehouseID
+	purchaseOrder.Note = reqPayload.Note
+}
+
+func PurchaseOrderLinePayloadsToPurchaseOrderLines(reqPayloads []payload.PurchaseOrderLineRequest) []entity.PurchaseOrderLine {
+	lines := make([]entity.PurchaseOrderLine, 0)
+	for _, v := range reqPayloads {
+		lines = append(lines, entity.PurchaseOrderLine{
+			ProductID: v.ProductID,
+			VariantID: v.VariantID,
+			Quantity:  v.Quantity,
+			UnitCost:  v.UnitCost,
+		})
+	}
+	return lines
+}
+
+func PurchaseOrderToPurchaseOrderResponse(e *entity.PurchaseOrder) payload.PurchaseOrderResponse {
+	lineResponses := make([]payload.PurchaseOrderLineResponse, 0)
+	for _, line := range e.Lines {
+		lineResponses = append(lineResponses, payload.PurchaseOrderLineResponse{
+			ID:               line.ID,
+			ProductID:        line.ProductID,
+			VariantID:        line.VariantID,
+			Quantity:         line.Quantity,
+			ReceivedQuantity: line.ReceivedQuantity,
+			UnitCost:         line.UnitCost,
+		})
+	}
+	return payload.PurchaseOrderResponse{
+		ID:          e.ID,
+		Supplier:    SupplierToSupplierResponse(&e.Supplier),
+		Status:      e.Status,
+		WarehouseID: e.WarehouseID,
+		Note:        e.Note,
+		Total:       e.Total(),
+		CreatedBy:   e.CreatedBy,
+		SentAt:      e.SentAt,
+		ReceivedAt:  e.ReceivedAt,
+		Lines:       lineResponses,
+		AuditTime: payload.AuditTime{
+			CreatedAt: e.CreatedAt,
+			UpdatedAt: e.UpdatedAt,
+		},
+	}
+}
+
+func PurchaseOrdersToListPurchaseOrderResponses(listEntities []entity.PurchaseOrder, pagination *entity.Pagination) payload.ListPurchaseOrderResponses {
+	purchaseOrderResponses := make([]payload.PurchaseOrderResponse, 0)
+	for _, v := range listEntities {
+		purchaseOrderResponses = append(purchaseOrderResponses, PurchaseOrderToPurchaseOrderResponse(&v))
+	}
+	return payload.ListPurchaseOrderResponses{
+		PurchaseOrders:     purchaseOrderResponses,
+		PaginationResponse: PaginationToPaginationResponse(pagination),
+	}
+}
\ No newline at end of file
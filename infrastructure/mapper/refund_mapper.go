@@ -0,0 +1,44 @@
+package mapper
+
+import (
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+)
+
+func RefundToRefundResponse(e *entity.Refund) payload.RefundResponse {
+	refundItems := make([]payload.RefundItemResponse, 0)
+	for _, v := range e.RefundItems {
+		refundItems = append(refundItems, payload.RefundItemResponse{
+			ID:          int64(v.ID),
+			OrderItemID: int64(v.OrderItemID),
+			ProductID:   int64(v.ProductID),
+			Quantity:    v.Quantity,
+			Amount:      v.Amount,
+		})
+	}
+	return payload.RefundResponse{
+		ID:            int64(e.ID),
+		OrderID:       int64(e.OrderID),
+		PaymentID:     int64(e.PaymentID),
+		Amount:        e.Amount,
+		Reason:        e.Reason,
+		Restock:       e.Restock,
+		Status:        e.Status,
+		ProviderRef:   e.ProviderRef,
+		FailureReason: e.FailureReason,
+		CreatedBy:     int64(e.CreatedBy),
+		RefundItems:   refundItems,
+		AuditTime: payload.AuditTime{
+			CreatedAt: e.CreatedAt,
+			UpdatedAt: e.UpdatedAt,
+		},
+	}
+}
+
+func RefundsToRefundResponses(listEntities []entity.Refund) []payload.RefundResponse {
+	refundResponses := make([]payload.RefundResponse, 0)
+	for _, v := range listEntities {
+		refundResponses = append(refundResponses, RefundToRefundResponse(&v))
+	}
+	return refundResponses
+}
\ No newline at end of file
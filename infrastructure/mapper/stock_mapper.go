@@ -0,0 +1,35 @@
+package mapper
+
+import (
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+)
+
+func StockMovementToStockMovementResponse(e *entity.StockMovement) payload.StockMovementResponse {
+	return payload.StockMovementResponse{
+		ID:        e.ID,
+		ProductID: e.ProductID,
+		VariantID: e.VariantID,
+		Quantity:  e.Quantity,
+		Balance:   e.Balance,
+		Reason:    e.Reason,
+		Reference: e.Reference,
+		ActorID:   e.ActorID,
+		CreatedAt: e.CreatedAt,
+	}
+}
+
+func StockHistoryToStockHistoryResponse(history *entity.StockHistory, pagination *entity.Pagination) payload.StockHistoryResponse {
+	movementResponses := make([]payload.StockMovementResponse, 0)
+	for _, v := range history.Movements {
+		movementResponses = append(movementResponses, StockMovementToStockMovementResponse(&v))
+	}
+	return payload.StockHistoryResponse{
+		ProductID:          history.ProductID,
+		VariantID:          history.VariantID,
+		Stock:              history.Stock,
+		Ledger:             history.Ledger,
+		Movements:          movementResponses,
+		PaginationResponse: PaginationToPaginationResponse(pagination),
+	}
+}
\ No newline at end of file
@@ -0,0 +1,18 @@
+package mapper
+
+import (
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+)
+
+func SuggestionsToSuggestionResponses(listEntities []entity.Suggestion) []payload.SuggestionResponse {
+	suggestionResponses := make([]payload.SuggestionResponse, 0)
+	for _, v := range listEntities {
+		suggestionResponses = append(suggestionResponses, payload.SuggestionResponse{
+			Kind:  v.Kind,
+			ID:    v.ID,
+			Label: v.Label,
+		})
+	}
+	return suggestionResponses
+}
\ No newline at end of file
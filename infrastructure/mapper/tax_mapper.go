@@ -0,0 +1,60 @@
+package mapper
+
+import (
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+)
+
+func TaxClassPayloadToTaxClass(reqPayload *payload.TaxClassRequest, taxClass *entity.TaxClass) {
+	taxClass.Name = reqPayload.Name
+	taxClass.Description = reqPayload.Description
+}
+
+func TaxRatePayloadToTaxRate(reqPayload *payload.TaxRateRequest, taxRate *entity.TaxRate) {
+	taxRate.Name = reqPayload.Name
+	taxRate.Country, taxRate.Region = entity.NormalizeTaxRegion(reqPayload.Country, reqPayload.Region)
+	taxRate.Rate = reqPayload.Rate
+}
+
+func TaxClassToTaxClassResponse(e *entity.TaxClass) payload.TaxClassResponse {
+	return payload.TaxClassResponse{
+		ID:          int64(e.ID),
+		Name:        e.Name,
+		Description: e.Description,
+		AuditTime: payload.AuditTime{
+			CreatedAt: e.CreatedAt,
+			UpdatedAt: e.UpdatedAt,
+		},
+	}
+}
+
+func TaxClassesToTaxClassResponses(listEntities []entity.TaxClass) []payload.TaxClassResponse {
+	taxClassResponses := make([]payload.TaxClassResponse, 0)
+	for _, v := range listEntities {
+		taxClassResponses = append(taxClassResponses, TaxClassToTaxClassResponse(&v))
+	}
+	return taxClassResponses
+}
+
+func TaxRateToTaxRateResponse(e *entity.TaxRate) payload.TaxRateResponse {
+	return payload.TaxRateResponse{
+		ID:         int64(e.ID),
+		TaxClassID: int64(e.TaxClassID),
+		Name:       e.Name,
+		Country:    e.Country,
+		Region:     e.Region,
+		Rate:       e.Rate,
+		AuditTime: payload.AuditTime{
+			CreatedAt: e.CreatedAt,
+			UpdatedAt: e.UpdatedAt,
+		},
+	}
+}
+
+func TaxRatesToTaxRateResponses(listEntities []entity.TaxRate) []payload.TaxRateResponse {
+	taxRateResponses := make([]payload.TaxRateResponse, 0)
+	for _, v := range listEntities {
+		taxRateResponses = append(taxRateResponses, TaxRateToTaxRateResponse(&v))
+	}
+	return taxRateResponses
+}
\ No newline at end of file
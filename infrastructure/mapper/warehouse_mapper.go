@@ -0,0 +1,84 @@
+package mapper
+
+import (
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/payload"
+)
+
+func WarehousePayloadToWarehouse(reqPayload *payload.WarehouseRequest, warehouse *entity.Warehouse) {
+	warehouse.Code = entity.NormalizeWarehouseCode(reqPayload.Code)
+	warehouse.Name = reqPayload.Name
+	warehouse.Address = reqPayload.Address
+	warehouse.Priority = reqPayload.Priority
+}
+
+func WarehouseToWarehouseResponse(e *entity.Warehouse) payload.WarehouseResponse {
+	return payload.WarehouseResponse{
+		ID:       e.ID,
+		Code:     e.Code,
+		Name:     e.Name,
+		Address:  e.Address,
+		Priority: e.Priority,
+		AuditTime: payload.AuditTime{
+			CreatedAt: e.CreatedAt,
+			UpdatedAt: e.UpdatedAt,
+		},
+	}
+}
+
+func WarehousesToWarehouseResponses(listEntities []entity.Warehouse) []payload.WarehouseResponse {
+	warehouseResponses := make([]payload.WarehouseResponse, 0)
+	for _, v := range listEntities {
+		warehouseResponses = append(warehouseResponses, WarehouseToWarehouseResponse(&v))
+	}
+	return warehouseResponses
+}
+
+func WarehouseStocksToWarehouseStockResponses(listEntities []entity.WarehouseStock) []payload.WarehouseStockResponse {
+	stockResponses := make([]payload.WarehouseStockResponse, 0)
+	for _, v := range listEntities {
+		stockResponses = append(stockResponses, payload.WarehouseStockResponse{
+			WarehouseID: v.WarehouseID,
+			ProductID:   v.ProductID,
+			VariantID:   v.VariantID,
+			Quantity:    v.Quantity,
+			UpdatedAt:   v.UpdatedAt,
+		})
+	}
+	return stockResponses
+}
+
+func StockTransferToStockTransferResponse(e *entity.StockTransfer) payload.StockTransferResponse {
+	return payload.StockTransferResponse{
+		ID:              e.ID,
+		FromWarehouseID: e.FromWarehouseID,
+		ToWarehouseID:   e.ToWarehouseID,
+		ProductID:       e.ProductID,
+		VariantID:       e.VariantID,
+		Quantity:        e.Quantity,
+		ActorID:         e.ActorID,
+		CreatedAt:       e.CreatedAt,
+	}
+}
+
+func PickingListsToPickingListResponses(lists []entity.PickingList) []payload.PickingListResponse {
+	listResponses := make([]payload.PickingListResponse, 0)
+	for _, list := range lists {
+		lineResponses := make([]payload.PickingLineResponse, 0)
+		for _, line := range list.Lines {
+			lineResponses = append(lineResponses, payload.PickingLineResponse{
+				OrderItemID: line.OrderItemID,
+				ProductID:   line.ProductID,
+				VariantID:   line.VariantID,
+				Name:        line.Name,
+				SKU:         line.SKU,
+				Quantity:    line.Quantity,
+			})
+		}
+		listResponses = append(listResponses, payload.PickingListResponse{
+			Warehouse: WarehouseToWarehouseResponse(&list.Warehouse),
+			Lines:     lineResponses,
+		})
+	}
+	return listResponses
+}
\ No newline at end of file
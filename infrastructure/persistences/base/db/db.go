@@ -34,6 +34,33 @@ func Migrate(db *gorm.DB) error {
 		&entity.Order{},
 		&entity.OrderItem{},
 		&entity.User{},
+		&entity.IdempotencyKey{},
+		&entity.Payment{},
+		&entity.Refund{},
+		&entity.RefundItem{},
+		&entity.OrderEvent{},
+		&entity.CartItem{},
+		&entity.Address{},
+		&entity.Promotion{},
+		&entity.PromotionUsage{},
+		&entity.TaxClass{},
+		&entity.TaxRate{},
+		&entity.InvoiceSequence{},
+		&entity.Invoice{},
+		&entity.InvoiceLine{},
+		&entity.ProductVariant{},
+		&entity.ProductVariantOption{},
+		&entity.StockMovement{},
+		&entity.StockDrift{},
+		&entity.Warehouse{},
+		&entity.WarehouseStock{},
+		&entity.StockTransfer{},
+		&entity.OrderItemAllocation{},
+		&entity.LowStockAlert{},
+		&entity.Notification{},
+		&entity.Supplier{},
+		&entity.PurchaseOrder{},
+		&entity.PurchaseOrderLine{},
 	)
 }
 
@@ -46,10 +73,27 @@ func SetupDatabase(dsn string) (*gorm.DB, error) {
 	if err := Migrate(db); err != nil {
 		return nil, err
 	}
+	if err := createSearchIndexes(db); err != nil {
+		return nil, err
+	}
 
 	return db, nil
 }
 
+// createSearchIndexes indexes the search text of the products for full-text search and for trigram matching, which
+// serves the searches within words and their ranking. CockroachDB has trigrams built in, Postgres needs the pg_trgm
+// extension and the search cannot run without it
+func createSearchIndexes(db *gorm.DB) error {
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		return fmt.Errorf("error creating extension pg_trgm: %w", err)
+	}
+	err := db.Exec("CREATE INDEX IF NOT EXISTS idx_products_search_text_trgm ON products USING GIN (search_text gin_trgm_ops)").Error
+	if err != nil {
+		return err
+	}
+	return db.Exec("CREATE INDEX IF NOT EXISTS idx_products_search_text_fts ON products USING GIN (to_tsvector('simple', search_text))").Error
+}
+
 func GetDSN(username, password, domain, port, dbName string) string {
 	dsn := fmt.Sprintf("postgresql://%s:%s@%s:%s/%s?sslmode=verify-full",
 		username,
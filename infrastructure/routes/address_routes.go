@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"pm/infrastructure/controllers/handlers"
+	"pm/infrastructure/controllers/middleware"
+	"pm/infrastructure/persistences/base"
+)
+
+type AddressRoutes struct {
+	p       *base.Persistence
+	handler *handlers.AddressHandler
+}
+
+func NewAddressRoutes(p *base.Persistence, handler *handlers.AddressHandler) *AddressRoutes {
+	return &AddressRoutes{p, handler}
+}
+
+func (r *AddressRoutes) RegisterRoutes(routerGroup *gin.RouterGroup) {
+	addressRouter := routerGroup.Group("/addresses").Use(middleware.AuthMiddleware(r.p))
+	{
+		addressRouter.GET("", r.handler.HandleGetAddresses)
+		addressRouter.POST("", r.handler.HandleCreateAddress)
+		addressRouter.GET("/:id", r.handler.HandleGetAddressByID)
+		addressRouter.PUT("/:id", r.handler.HandleUpdateAddress)
+		addressRouter.DELETE("/:id", r.handler.HandleDeleteAddress)
+		addressRouter.POST("/:id/default", r.handler.HandleSetDefaultAddress)
+	}
+}
\ No newline at end of file
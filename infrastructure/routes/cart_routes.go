@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"pm/infrastructure/controllers/handlers"
+	"pm/infrastructure/controllers/middleware"
+	"pm/infrastructure/persistences/base"
+)
+
+type CartRoutes struct {
+	p       *base.Persistence
+	handler *handlers.CartHandler
+}
+
+func NewCartRoutes(p *base.Persistence, handler *handlers.CartHandler) *CartRoutes {
+	return &CartRoutes{p, handler}
+}
+
+func (r *CartRoutes) RegisterRoutes(routerGroup *gin.RouterGroup) {
+	cartRouter := routerGroup.Group("/cart").Use(middleware.AuthMiddleware(r.p))
+	{
+		cartRouter.GET("", r.handler.HandleGetCart)
+		cartRouter.DELETE("", r.handler.HandleClearCart)
+		cartRouter.POST("/items", r.handler.HandleAddCartItem)
+		cartRouter.PUT("/items/:productId", r.handler.HandleUpdateCartItem)
+		cartRouter.DELETE("/items/:productId", r.handler.HandleRemoveCartItem)
+		cartRouter.POST("/checkout", middleware.IdempotencyMiddleware(r.p), r.handler.HandleCheckout)
+	}
+}
\ No newline at end of file
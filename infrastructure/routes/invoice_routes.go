@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"pm/infrastructure/controllers/handlers"
+	"pm/infrastructure/controllers/middleware"
+	"pm/infrastructure/persistences/base"
+)
+
+type InvoiceRoutes struct {
+	p       *base.Persistence
+	handler *handlers.InvoiceHandler
+}
+
+func NewInvoiceRoutes(p *base.Persistence, handler *handlers.InvoiceHandler) *InvoiceRoutes {
+	return &InvoiceRoutes{p, handler}
+}
+
+func (r *InvoiceRoutes) RegisterRoutes(routerGroup *gin.RouterGroup) {
+	orderInvoice := routerGroup.Group("/orders/:id/invoice").Use(middleware.AuthMiddleware(r.p))
+	{
+		orderInvoice.GET("", r.handler.HandleDownloadInvoice)
+		orderInvoice.POST("/email", r.handler.HandleEmailInvoice)
+	}
+}
\ No newline at end of file
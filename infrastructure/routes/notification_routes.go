@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/handlers"
+	"pm/infrastructure/controllers/middleware"
+	"pm/infrastructure/persistences/base"
+)
+
+type NotificationRoutes struct {
+	p       *base.Persistence
+	handler *handlers.NotificationHandler
+}
+
+func NewNotificationRoutes(p *base.Persistence, handler *handlers.NotificationHandler) *NotificationRoutes {
+	return &NotificationRoutes{p, handler}
+}
+
+func (r *NotificationRoutes) RegisterRoutes(routerGroup *gin.RouterGroup) {
+	notificationRouter := routerGroup.Group("/notifications").Use(middleware.AuthMiddleware(r.p, entity.RoleAdmin))
+	{
+		notificationRouter.GET("", r.handler.HandleGetAllNotifications)
+		notificationRouter.PUT("/:id/read", r.handler.HandleMarkNotificationRead)
+	}
+}
\ No newline at end of file
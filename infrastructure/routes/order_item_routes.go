@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"pm/domain/entity"
 	"pm/infrastructure/controllers/handlers"
 	"pm/infrastructure/controllers/middleware"
 	"pm/infrastructure/persistences/base"
@@ -25,7 +26,7 @@ func (router *OrderItemRoutes) RegisterRoutes(routerGroup *gin.RouterGroup) {
 		orderItems.GET("/:id", router.handler.HandleGetOrderItemByID)
 		orderItems.DELETE("/:id", router.handler.HandleDeleteOrderItemByID)
 		orderItems.PUT("", router.handler.HandleUpdateOrderItemByID)
-		orderItems.POST("", router.handler.HandleCreateNewOrderItem)
-		orderItems.GET("", router.handler.HandleGetAllOrderItems)
+		orderItems.POST("", middleware.IdempotencyMiddleware(router.p), router.handler.HandleCreateNewOrderItem)
+		orderItems.GET("", middleware.AuthMiddleware(router.p, entity.RoleAdmin), router.handler.HandleGetAllOrderItems)
 	}
 }
\ No newline at end of file
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"pm/domain/entity"
 	"pm/infrastructure/controllers/handlers"
 	"pm/infrastructure/controllers/middleware"
 	"pm/infrastructure/persistences/base"
@@ -19,10 +20,15 @@ func NewOrderRoutes(p *base.Persistence, handler *handlers.OrderHandler) *OrderR
 func (r *OrderRoutes) RegisterRoutes(routerGroup *gin.RouterGroup) {
 	ordersRouter := routerGroup.Group("/orders").Use(middleware.AuthMiddleware(r.p))
 	{
-		ordersRouter.POST("", r.handler.HandleCreateOrder)
-		ordersRouter.PUT("/:id", r.handler.HandleUpdateOrderByID)
+		ordersRouter.POST("", middleware.IdempotencyMiddleware(r.p), r.handler.HandleCreateOrder)
+		ordersRouter.PUT("/:id", middleware.AuthMiddleware(r.p, entity.RoleAdmin), r.handler.HandleUpdateOrderByID)
 		ordersRouter.GET("", r.handler.HandleGetAllOrders)
 		ordersRouter.GET("/:id", r.handler.HandleGetOrderByID)
-		ordersRouter.DELETE("/:id", r.handler.HandleDeleteOrderByID)
+		ordersRouter.GET("/:id/history", r.handler.HandleGetOrderHistory)
+		ordersRouter.DELETE("/:id", middleware.AuthMiddleware(r.p, entity.RoleAdmin), r.handler.HandleDeleteOrderByID)
+		ordersRouter.POST("/:id/confirm", middleware.AuthMiddleware(r.p, entity.RoleAdmin), r.handler.HandleConfirmOrder)
+		ordersRouter.POST("/:id/ship", middleware.AuthMiddleware(r.p, entity.RoleAdmin), r.handler.HandleShipOrder)
+		ordersRouter.POST("/:id/deliver", middleware.AuthMiddleware(r.p, entity.RoleAdmin), r.handler.HandleDeliverOrder)
+		ordersRouter.POST("/:id/cancel", r.handler.HandleCancelOrder)
 	}
 }
\ No newline at end of file
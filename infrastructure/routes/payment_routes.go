@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/handlers"
+	"pm/infrastructure/controllers/middleware"
+	"pm/infrastructure/persistences/base"
+)
+
+type PaymentRoutes struct {
+	p       *base.Persistence
+	handler *handlers.PaymentHandler
+}
+
+func NewPaymentRoutes(p *base.Persistence, handler *handlers.PaymentHandler) *PaymentRoutes {
+	return &PaymentRoutes{p, handler}
+}
+
+func (r *PaymentRoutes) RegisterRoutes(routerGroup *gin.RouterGroup) {
+	orderPayments := routerGroup.Group("/orders/:id/payments").Use(middleware.AuthMiddleware(r.p))
+	{
+		orderPayments.POST("", middleware.IdempotencyMiddleware(r.p), r.handler.HandleInitiatePayment)
+		orderPayments.GET("", r.handler.HandleGetPaymentsByOrderID)
+		orderPayments.POST("/:paymentId/capture", middleware.AuthMiddleware(r.p, entity.RoleAdmin), r.handler.HandleCapturePayment)
+	}
+
+	// gateways call back without a user token, the callbacks are authenticated by their signature
+	payments := routerGroup.Group("/payments")
+	{
+		payments.POST("/callback/:provider", r.handler.HandlePaymentCallback)
+	}
+}
\ No newline at end of file
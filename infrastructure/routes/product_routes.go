@@ -21,7 +21,8 @@ func (router *ProductRoutes) RegisterRoutes(routerGroup *gin.RouterGroup) {
 	products := routerGroup.Group("/products")
 	{
 		products.POST("", middleware.AuthMiddleware(router.p, entity.RoleUser), router.handler.HandleCreateProduct)
-		products.GET("/search", middleware.AuthMiddleware(router.p), router.handler.HandleGetAllProducts)
+		products.GET("/search", middleware.AuthMiddleware(router.p), router.handler.HandleSearchProducts)
+		products.GET("/suggest", middleware.AuthMiddleware(router.p), router.handler.HandleSuggestProducts)
 		products.GET("", router.handler.HandleGetAllProducts)
 		products.GET("/:id", middleware.AuthMiddleware(router.p), router.handler.HandleGetProductByID)
 		products.DELETE("/:id", middleware.AuthMiddleware(router.p, entity.RoleAdmin), router.handler.HandleDeleteProductByID)
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/handlers"
+	"pm/infrastructure/controllers/middleware"
+	"pm/infrastructure/persistences/base"
+)
+
+type ProductVariantRoutes struct {
+	p       *base.Persistence
+	handler *handlers.ProductVariantHandler
+}
+
+func NewProductVariantRoutes(p *base.Persistence, handler *handlers.ProductVariantHandler) *ProductVariantRoutes {
+	return &ProductVariantRoutes{p, handler}
+}
+
+func (r *ProductVariantRoutes) RegisterRoutes(routerGroup *gin.RouterGroup) {
+	productVariants := routerGroup.Group("/products/:id/variants").Use(middleware.AuthMiddleware(r.p))
+	{
+		productVariants.GET("", r.handler.HandleGetVariants)
+		productVariants.POST("", middleware.AuthMiddleware(r.p, entity.RoleAdmin), r.handler.HandleCreateVariant)
+		productVariants.PUT("/:variantId", middleware.AuthMiddleware(r.p, entity.RoleAdmin), r.handler.HandleUpdateVariant)
+		productVariants.DELETE("/:variantId", middleware.AuthMiddleware(r.p, entity.RoleAdmin), r.handler.HandleDeleteVariant)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/handlers"
+	"pm/infrastructure/controllers/middleware"
+	"pm/infrastructure/persistences/base"
+)
+
+type PromotionRoutes struct {
+	p       *base.Persistence
+	handler *handlers.PromotionHandler
+}
+
+func NewPromotionRoutes(p *base.Persistence, handler *handlers.PromotionHandler) *PromotionRoutes {
+	return &PromotionRoutes{p, handler}
+}
+
+func (r *PromotionRoutes) RegisterRoutes(routerGroup *gin.RouterGroup) {
+	promotionRouter := routerGroup.Group("/promotions").Use(middleware.AuthMiddleware(r.p, entity.RoleAdmin))
+	{
+		promotionRouter.GET("", r.handler.HandleGetAllPromotions)
+		promotionRouter.POST("", r.handler.HandleCreatePromotion)
+		promotionRouter.GET("/:id", r.handler.HandleGetPromotionByID)
+		promotionRouter.PUT("/:id", r.handler.HandleUpdatePromotion)
+		promotionRouter.DELETE("/:id", r.handler.HandleDeletePromotion)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/handlers"
+	"pm/infrastructure/controllers/middleware"
+	"pm/infrastructure/persistences/base"
+)
+
+type PurchaseOrderRoutes struct {
+	p       *base.Persistence
+	handler *handlers.PurchaseOrderHandler
+}
+
+func NewPurchaseOrderRoutes(p *base.Persistence, handler *handlers.PurchaseOrderHandler) *PurchaseOrderRoutes {
+	return &PurchaseOrderRoutes{p, handler}
+}
+
+func (r *PurchaseOrderRoutes) RegisterRoutes(routerGroup *gin.RouterGroup) {
+	purchaseOrderRouter := routerGroup.Group("/purchase-orders").Use(middleware.AuthMiddleware(r.p, entity.RoleAdmin))
+	{
+		purchaseOrderRouter.GET("", r.handler.HandleGetAllPurchaseOrders)
+		purchaseOrderRouter.POST("", r.handler.HandleCreatePurchaseOrder)
+		purchaseOrderRouter.GET("/:id", r.handler.HandleGetPurchaseOrderByID)
+		purchaseOrderRouter.PUT("/:id", r.handler.HandleUpdatePurchaseOrder)
+		purchaseOrderRouter.POST("/:id/send", r.handler.HandleSendPurchaseOrder)
+		purchaseOrderRouter.POST("/:id/cancel", r.handler.HandleCancelPurchaseOrder)
+		purchaseOrderRouter.POST("/:id/receipts", r.handler.HandleReceiveGoods)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/handlers"
+	"pm/infrastructure/controllers/middleware"
+	"pm/infrastructure/persistences/base"
+)
+
+type RefundRoutes struct {
+	p       *base.Persistence
+	handler *handlers.RefundHandler
+}
+
+func NewRefundRoutes(p *base.Persistence, handler *handlers.RefundHandler) *RefundRoutes {
+	return &RefundRoutes{p, handler}
+}
+
+func (r *RefundRoutes) RegisterRoutes(routerGroup *gin.RouterGroup) {
+	orderRefunds := routerGroup.Group("/orders/:id/refunds").Use(middleware.AuthMiddleware(r.p))
+	{
+		orderRefunds.POST("", middleware.AuthMiddleware(r.p, entity.RoleAdmin), r.handler.HandleCreateRefund)
+		orderRefunds.GET("", r.handler.HandleGetRefundsByOrderID)
+	}
+}
\ No newline at end of file
@@ -11,6 +11,7 @@ import (
 	"pm/infrastructure/config"
 	"pm/infrastructure/controllers/handlers"
 	"pm/infrastructure/controllers/middleware"
+	"pm/infrastructure/implementations/payments"
 	"pm/infrastructure/jobs"
 	"pm/infrastructure/persistences/base"
 	"pm/utils"
@@ -36,19 +37,39 @@ func (s *Server) Run() {
 	router.RemoteIPHeaders = []string{"X-Forwarded-For", "X-Real-IP"}
 
 	s.InitHelpers()
+	if err := payments.ValidatePaymentConfig(s.appConfig.PaymentConfig); err != nil {
+		log.Fatal("error configuring payments: ", err)
+	}
 
 	s.SetUpRoutes(router)
 
+	// the schedules of robfig/cron start with the seconds
 	c := cron.New()
-	err := c.AddFunc("*/10 * * * *", func() {
+	err := c.AddFunc("0 */10 * * * *", func() {
 		jobs.LoadProductToRedis(s.Persistence)
+		jobs.BuildSuggestionIndex(s.Persistence)
 	})
 
+	if err != nil {
+		fmt.Println("Error adding cron job:", err)
+	}
+	err = c.AddFunc(s.appConfig.StockConfig.ReconcileSchedule, func() {
+		jobs.ReconcileStock(s.Persistence)
+	})
+	if err != nil {
+		fmt.Println("Error adding cron job:", err)
+	}
+	err = c.AddFunc(s.appConfig.StockConfig.LowStockSchedule, func() {
+		jobs.AlertLowStock(s.Persistence)
+	})
 	if err != nil {
 		fmt.Println("Error adding cron job:", err)
 	}
 
-	//go c.Run()
+	c.Start()
+	defer c.Stop()
+	go jobs.IndexProductSearch(s.Persistence)
+	go jobs.BuildSuggestionIndex(s.Persistence)
 
 	err = router.Run(fmt.Sprintf(":%s", s.Port))
 	if err != nil {
@@ -65,6 +86,19 @@ func (s *Server) SetUpRoutes(router *gin.Engine) {
 	userHandler := handlers.NewUserHandler(s.Persistence)
 	orderHandler := handlers.NewOrderHandler(s.Persistence)
 	orderItemHandler := handlers.NewOrderItemHandler(s.Persistence)
+	paymentHandler := handlers.NewPaymentHandler(s.Persistence)
+	refundHandler := handlers.NewRefundHandler(s.Persistence)
+	cartHandler := handlers.NewCartHandler(s.Persistence)
+	addressHandler := handlers.NewAddressHandler(s.Persistence)
+	promotionHandler := handlers.NewPromotionHandler(s.Persistence)
+	taxHandler := handlers.NewTaxHandler(s.Persistence)
+	invoiceHandler := handlers.NewInvoiceHandler(s.Persistence)
+	productVariantHandler := handlers.NewProductVariantHandler(s.Persistence)
+	stockHandler := handlers.NewStockHandler(s.Persistence)
+	warehouseHandler := handlers.NewWarehouseHandler(s.Persistence)
+	notificationHandler := handlers.NewNotificationHandler(s.Persistence)
+	supplierHandler := handlers.NewSupplierHandler(s.Persistence)
+	purchaseOrderHandler := handlers.NewPurchaseOrderHandler(s.Persistence)
 
 	mailRoute := NewMailRoutes(s.Persistence, mailHandler)
 	productRoute := NewProductRoutes(s.Persistence, productHandler)
@@ -73,6 +107,19 @@ func (s *Server) SetUpRoutes(router *gin.Engine) {
 	userRoute := NewUserRoutes(s.Persistence, userHandler)
 	orderRoute := NewOrderRoutes(s.Persistence, orderHandler)
 	orderItemRoute := NewOrderItemRoutes(s.Persistence, orderItemHandler)
+	paymentRoute := NewPaymentRoutes(s.Persistence, paymentHandler)
+	refundRoute := NewRefundRoutes(s.Persistence, refundHandler)
+	cartRoute := NewCartRoutes(s.Persistence, cartHandler)
+	addressRoute := NewAddressRoutes(s.Persistence, addressHandler)
+	promotionRoute := NewPromotionRoutes(s.Persistence, promotionHandler)
+	taxRoute := NewTaxRoutes(s.Persistence, taxHandler)
+	invoiceRoute := NewInvoiceRoutes(s.Persistence, invoiceHandler)
+	productVariantRoute := NewProductVariantRoutes(s.Persistence, productVariantHandler)
+	stockRoute := NewStockRoutes(s.Persistence, stockHandler)
+	warehouseRoute := NewWarehouseRoutes(s.Persistence, warehouseHandler)
+	notificationRoute := NewNotificationRoutes(s.Persistence, notificationHandler)
+	supplierRoute := NewSupplierRoutes(s.Persistence, supplierHandler)
+	purchaseOrderRoute := NewPurchaseOrderRoutes(s.Persistence, purchaseOrderHandler)
 
 	router.Use(middleware.HoneycombHandler(), middleware.ErrorHandlingMiddleware())
 
@@ -87,6 +134,19 @@ func (s *Server) SetUpRoutes(router *gin.Engine) {
 	userRoute.RegisterRoutes(v1)
 	orderRoute.RegisterRoutes(v1)
 	orderItemRoute.RegisterRoutes(v1)
+	paymentRoute.RegisterRoutes(v1)
+	refundRoute.RegisterRoutes(v1)
+	cartRoute.RegisterRoutes(v1)
+	addressRoute.RegisterRoutes(v1)
+	promotionRoute.RegisterRoutes(v1)
+	taxRoute.RegisterRoutes(v1)
+	invoiceRoute.RegisterRoutes(v1)
+	productVariantRoute.RegisterRoutes(v1)
+	stockRoute.RegisterRoutes(v1)
+	warehouseRoute.RegisterRoutes(v1)
+	notificationRoute.RegisterRoutes(v1)
+	supplierRoute.RegisterRoutes(v1)
+	purchaseOrderRoute.RegisterRoutes(v1)
 }
 
 func (s *Server) InitHelpers() {
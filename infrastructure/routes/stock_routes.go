@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/handlers"
+	"pm/infrastructure/controllers/middleware"
+	"pm/infrastructure/persistences/base"
+)
+
+type StockRoutes struct {
+	p       *base.Persistence
+	handler *handlers.StockHandler
+}
+
+func NewStockRoutes(p *base.Persistence, handler *handlers.StockHandler) *StockRoutes {
+	return &StockRoutes{p, handler}
+}
+
+func (r *StockRoutes) RegisterRoutes(routerGroup *gin.RouterGroup) {
+	stock := routerGroup.Group("/products/:id").Use(middleware.AuthMiddleware(r.p, entity.RoleAdmin))
+	{
+		stock.GET("/stock-history", r.handler.HandleGetStockHistory)
+		stock.POST("/stock-adjustments", r.handler.HandleAdjustStock)
+	}
+}
\ No newline at end of file
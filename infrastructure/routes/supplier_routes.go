@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/handlers"
+	"pm/infrastructure/controllers/middleware"
+	"pm/infrastructure/persistences/base"
+)
+
+type SupplierRoutes struct {
+	p       *base.Persistence
+	handler *handlers.SupplierHandler
+}
+
+func NewSupplierRoutes(p *base.Persistence, handler *handlers.SupplierHandler) *SupplierRoutes {
+	return &SupplierRoutes{p, handler}
+}
+
+func (r *SupplierRoutes) RegisterRoutes(routerGroup *gin.RouterGroup) {
+	supplierRouter := routerGroup.Group("/suppliers").Use(middleware.AuthMiddleware(r.p, entity.RoleAdmin))
+	{
+		supplierRouter.GET("", r.handler.HandleGetAllSuppliers)
+		supplierRouter.POST("", r.handler.HandleCreateSupplier)
+		supplierRouter.GET("/:id", r.handler.HandleGetSupplierByID)
+		supplierRouter.PUT("/:id", r.handler.HandleUpdateSupplier)
+		supplierRouter.DELETE("/:id", r.handler.HandleDeleteSupplier)
+	}
+}
\ No newline at end of file
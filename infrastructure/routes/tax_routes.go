@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/handlers"
+	"pm/infrastructure/controllers/middleware"
+	"pm/infrastructure/persistences/base"
+)
+
+type TaxRoutes struct {
+	p       *base.Persistence
+	handler *handlers.TaxHandler
+}
+
+func NewTaxRoutes(p *base.Persistence, handler *handlers.TaxHandler) *TaxRoutes {
+	return &TaxRoutes{p, handler}
+}
+
+func (r *TaxRoutes) RegisterRoutes(routerGroup *gin.RouterGroup) {
+	taxClassRouter := routerGroup.Group("/tax-classes").Use(middleware.AuthMiddleware(r.p, entity.RoleAdmin))
+	{
+		taxClassRouter.GET("", r.handler.HandleGetAllTaxClasses)
+		taxClassRouter.POST("", r.handler.HandleCreateTaxClass)
+		taxClassRouter.GET("/:id", r.handler.HandleGetTaxClassByID)
+		taxClassRouter.PUT("/:id", r.handler.HandleUpdateTaxClass)
+		taxClassRouter.DELETE("/:id", r.handler.HandleDeleteTaxClass)
+		taxClassRouter.GET("/:id/rates", r.handler.HandleGetTaxRatesByClassID)
+		taxClassRouter.POST("/:id/rates", r.handler.HandleCreateTaxRate)
+	}
+
+	taxRateRouter := routerGroup.Group("/tax-rates").Use(middleware.AuthMiddleware(r.p, entity.RoleAdmin))
+	{
+		taxRateRouter.PUT("/:id", r.handler.HandleUpdateTaxRate)
+		taxRateRouter.DELETE("/:id", r.handler.HandleDeleteTaxRate)
+	}
+}
\ No newline at end of file
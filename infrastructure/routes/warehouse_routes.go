@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"pm/domain/entity"
+	"pm/infrastructure/controllers/handlers"
+	"pm/infrastructure/controllers/middleware"
+	"pm/infrastructure/persistences/base"
+)
+
+type WarehouseRoutes struct {
+	p       *base.Persistence
+	handler *handlers.WarehouseHandler
+}
+
+func NewWarehouseRoutes(p *base.Persistence, handler *handlers.WarehouseHandler) *WarehouseRoutes {
+	return &WarehouseRoutes{p, handler}
+}
+
+func (r *WarehouseRoutes) RegisterRoutes(routerGroup *gin.RouterGroup) {
+	warehouseRouter := routerGroup.Group("/warehouses").Use(middleware.AuthMiddleware(r.p, entity.RoleAdmin))
+	{
+		warehouseRouter.GET("", r.handler.HandleGetAllWarehouses)
+		warehouseRouter.POST("", r.handler.HandleCreateWarehouse)
+		warehouseRouter.POST("/transfers", r.handler.HandleTransferStock)
+		warehouseRouter.PUT("/:id", r.handler.HandleUpdateWarehouse)
+		warehouseRouter.DELETE("/:id", r.handler.HandleDeleteWarehouse)
+		warehouseRouter.GET("/:id/stock", r.handler.HandleGetStockLevels)
+	}
+
+	pickingRouter := routerGroup.Group("/orders/:id/picking-lists").Use(middleware.AuthMiddleware(r.p, entity.RoleAdmin))
+	{
+		pickingRouter.GET("", r.handler.HandleGetPickingLists)
+	}
+}
\ No newline at end of file
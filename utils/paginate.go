@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"pm/domain/entity"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// cursorSchemas caches the schemas of the entities the cursors are read from and written for
+var cursorSchemas sync.Map
+
+// Paginate takes the page of the list and sorts it by the sort of the pagination, the list is sorted by defaults
+// when there is none. From a cursor, the page is the rows past the cursor in the sort of the cursor, with one row
+// more telling whether there are more of them, SetCursors takes it off
+func Paginate(pagination *entity.Pagination, sortable entity.SortableColumns, defaults ...entity.SortField) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if pagination.IsCursor() {
+			return db.Scopes(seek(pagination, sortable)).Limit(pagination.GetLimit() + 1)
+		}
+		return db.Scopes(Sort(pagination.Sort, sortable, defaults...)).
+			Offset(pagination.GetOffset()).
+			Limit(pagination.GetLimit())
+	}
+}
+
+// Sort orders the query by the sort, which can only go by the sortable columns. A sort by anything else fails the
+// query, the handlers reject it before it gets here
+func Sort(spec string, sortable entity.SortableColumns, defaults ...entity.SortField) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		fields, err := entity.ParseSort(spec, sortable, defaults...)
+		if err != nil {
+			db.AddError(err)
+			return db
+		}
+		return orderBy(db, fields, false)
+	}
+}
+
+// seek keeps the rows past the cursor, the ones sorting after its row or before it for a backward cursor. Over
+// several columns, a row is past the cursor when it sorts past it on a column and the same on every column before.
+// A backward cursor reads the list the other way around, SetCursors puts the rows back in order
+func seek(pagination *entity.Pagination, sortable entity.SortableColumns) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		cursor, err := entity.DecodeCursor(pagination.Cursor, sortable)
+		if err != nil {
+			db.AddError(err)
+			return db
+		}
+		model := db.Statement.Model
+		if model == nil {
+			model = db.Statement.Dest
+		}
+		values, err := cursorValues(db, model, cursor)
+		if err != nil {
+			db.AddError(err)
+			return db
+		}
+
+		conditions := make([]string, 0, len(cursor.Fields))
+		args := make([]interface{}, 0)
+		for i, f := range cursor.Fields {
+			parts := make([]string, 0, i+1)
+			for j := 0; j < i; j++ {
+				parts = append(parts, cursor.Fields[j].Column+" = ?")
+				args = append(args, values[j])
+			}
+			operator := ">"
+			if f.Desc != cursor.Backward {
+				operator = "<"
+			}
+			parts = append(parts, f.Column+" "+operator+" ?")
+			args = append(args, values[i])
+			conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+		}
+		db = db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+		return orderBy(db, cursor.Sort(), cursor.Backward)
+	}
+}
+
+func orderBy(db *gorm.DB, fields []entity.SortField, reverse bool) *gorm.DB {
+	for _, f := range fields {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: f.Column, Raw: true}, Desc: f.Desc != reverse})
+	}
+	return db
+}
+
+// SetCursors sets the cursors to the pages around the page of rows read with Paginate, rows being a pointer to the
+// slice the page was read into. A page read from a cursor loses its extra row and is put back in order. There is
+// a cursor to the next page only when there are more rows, and to the previous page only when the page is not the
+// first one
+func SetCursors(db *gorm.DB, pagination *entity.Pagination, sortable entity.SortableColumns, rows interface{}, defaults ...entity.SortField) error {
+	list := reflect.ValueOf(rows)
+	if list.Kind() != reflect.Ptr || list.Elem().Kind() != reflect.Slice {
+		return errors.New("the rows to set the cursors from must be a pointer to a slice")
+	}
+	list = list.Elem()
+	pagination.NextCursor, pagination.PrevCursor = "", ""
+
+	var (
+		fields           []entity.SortField
+		hasNext, hasPrev bool
+	)
+	if pagination.IsCursor() {
+		cursor, err := entity.DecodeCursor(pagination.Cursor, sortable)
+		if err != nil {
+			return err
+		}
+		fields = cursor.Sort()
+		more := list.Len() > pagination.GetLimit()
+		if more {
+			list.Set(list.Slice(0, pagination.GetLimit()))
+		}
+		if cursor.Backward {
+			swap := reflect.Swapper(list.Interface())
+			for i, j := 0, list.Len()-1; i < j; i, j = i+1, j-1 {
+				swap(i, j)
+			}
+		}
+		// the row of the cursor lies on the side the cursor was read from
+		hasNext, hasPrev = more || cursor.Backward, more || !cursor.Backward
+	} else {
+		var err error
+		if fields, err = entity.ParseSort(pagination.Sort, sortable, defaults...); err != nil {
+			return err
+		}
+		hasNext = int64(pagination.GetOffset()+list.Len()) < pagination.TotalRows
+		hasPrev = pagination.GetOffset() > 0
+	}
+	if list.Len() == 0 {
+		return nil
+	}
+
+	s, err := schema.Parse(rows, &cursorSchemas, db.NamingStrategy)
+	if err != nil {
+		return err
+	}
+	if hasNext {
+		if pagination.NextCursor, err = cursorAt(s, fields, list.Index(list.Len()-1), false); err != nil {
+			return err
+		}
+	}
+	if hasPrev {
+		if pagination.PrevCursor, err = cursorAt(s, fields, list.Index(0), true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cursorAt takes a cursor at the row, reading the values of the columns of the sort off the row
+func cursorAt(s *schema.Schema, fields []entity.SortField, row reflect.Value, backward bool) (string, error) {
+	values := make([]interface{}, 0, len(fields))
+	for _, f := range fields {
+		field, err := cursorField(s, f.Column)
+		if err != nil {
+			return "", err
+		}
+		value, _ := field.ValueOf(context.Background(), row)
+		values = append(values, value)
+	}
+	cursor, err := entity.NewCursor(fields, values, backward)
+	if err != nil {
+		return "", err
+	}
+	return cursor.Encode()
+}
+
+// cursorValues reads the values of the cursor into the types of the fields of the model they were taken from, so they
+// are compared with the columns as what they are
+func cursorValues(db *gorm.DB, model interface{}, cursor *entity.Cursor) ([]interface{}, error) {
+	s, err := schema.Parse(model, &cursorSchemas, db.NamingStrategy)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, 0, len(cursor.Values))
+	for i, f := range cursor.Fields {
+		field, err := cursorField(s, f.Column)
+		if err != nil {
+			return nil, err
+		}
+		value := reflect.New(field.FieldType)
+		if err := json.Unmarshal(cursor.Values[i], value.Interface()); err != nil {
+			return nil, fmt.Errorf("the cursor has no valid value for %s: %w", f.Column, err)
+		}
+		values = append(values, value.Elem().Interface())
+	}
+	return values, nil
+}
+
+func cursorField(s *schema.Schema, column string) (*schema.Field, error) {
+	name := column
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		name = column[i+1:]
+	}
+	field := s.LookUpField(name)
+	if field == nil {
+		return nil, fmt.Errorf("%s has no column %s", s.Table, column)
+	}
+	return field, nil
+}
\ No newline at end of file
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"pm/domain/entity"
+	"strings"
+	"unicode"
+)
+
+// NormalizeSearchText folds the text the way products are indexed for search: lower case, without accents, with đ
+// spelled d as it has no accent to remove, and with anything but letters and digits turned into single spaces
+func NormalizeSearchText(input string) string {
+	input = removeAccents(strings.ToLower(input))
+	input = strings.ReplaceAll(input, "đ", "d")
+	return strings.Join(strings.FieldsFunc(input, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// ProductSearchText is the normalized text a product is searched by: its name, description, category name and tags
+func ProductSearchText(product *entity.Product, categoryName string) string {
+	return NormalizeSearchText(strings.Join([]string{
+		product.Name,
+		product.Description,
+		categoryName,
+		strings.Join(product.TagList(), " "),
+	}, " "))
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// LikeContains is the LIKE pattern of the text anywhere in a column, with its own % and _ matched as they are. It is
+// meant for LIKE ... ESCAPE '\'
+func LikeContains(input string) string {
+	return "%" + likeEscaper.Replace(input) + "%"
+}
\ No newline at end of file
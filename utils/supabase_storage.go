@@ -4,7 +4,6 @@ import (
 	"fmt"
 	storage_go "github.com/supabase-community/storage-go"
 	"io"
-	"mime/multipart"
 	"pm/infrastructure/controllers/payload"
 	"pm/infrastructure/persistences/base"
 )
@@ -15,7 +14,7 @@ func InitSupabaseStorage(p *base.Persistence) {
 	supabaseStorage = p.SupabaseStorage
 }
 
-func SupabaseStorageUploadFile(bucketID, fileName string, file multipart.File, contentType string) (string, error) {
+func SupabaseStorageUploadFile(bucketID, fileName string, file io.Reader, contentType string) (string, error) {
 	Upsert := true
 	//contentType := "image/jpeg"
 	_, e := uploadFileToBucket(bucketID, fileName, file, storage_go.FileOptions{